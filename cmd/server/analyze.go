@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/lint"
+	"github.com/fidde/otlp_cardinality_checker/internal/offline"
+	"github.com/fidde/otlp_cardinality_checker/internal/report"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+const analyzeUsage = `Usage: occ analyze [flags] <files...>
+
+Analyzes OTLP files written by the collector file exporter (JSON lines or
+length-prefixed protobuf, optionally gzip/zstd compressed) and prints a
+cardinality report.
+
+Flags:
+`
+
+// runAnalyze implements the "analyze" subcommand and returns the process
+// exit code.
+func runAnalyze(args []string) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), analyzeUsage)
+		fs.PrintDefaults()
+	}
+	signalFlag := fs.String("signal", "", "signal of protobuf files whose name does not say it: metrics, traces, logs or profiles")
+	reportOutput := fs.String("report-output", "", "write the report to this file instead of stdout")
+	reportFormat := fs.String("report-format", "text", "report format: text or json")
+	sessionExport := fs.String("session-export", "", "also write the analyzed state as a session file")
+	exitOnThreshold := fs.Bool("exit-on-threshold", false, "exit non-zero when the report contains warnings or critical findings")
+	lintRulesFile := fs.String("lint-rules", "", "naming lint rules file to check names against")
+	spanMetricsFile := fs.String("spanmetrics-config", "", "spanmetrics connector config to project the series of")
+
+	// Flags may appear before or after the file list.
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return 2
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	signal, err := offline.ParseSignal(*signalFlag)
+	if err != nil {
+		log.Printf("Invalid --signal: %v", err)
+		return 2
+	}
+	if *reportFormat != "json" && *reportFormat != "text" {
+		log.Printf("Invalid --report-format %q: must be 'json' or 'text'", *reportFormat)
+		return 2
+	}
+
+	var lintRules *lint.RuleSet
+	if *lintRulesFile != "" {
+		lintRules, err = lint.Load(*lintRulesFile)
+		if err != nil {
+			log.Printf("Invalid --lint-rules: %v", err)
+			return 2
+		}
+	}
+
+	var spanMetrics *spanmetrics.Estimator
+	if *spanMetricsFile != "" {
+		cfg, err := spanmetrics.Load(*spanMetricsFile)
+		if err != nil {
+			log.Printf("Invalid --spanmetrics-config: %v", err)
+			return 2
+		}
+		spanMetrics = spanmetrics.New(cfg)
+	}
+
+	storageCfg := storage.DefaultConfig()
+	storageCfg.UseAutoTemplate = getEnvBool("USE_AUTOTEMPLATE", true)
+	store := storage.NewStorage(storageCfg)
+	defer store.Close()
+
+	ctx := context.Background()
+	a := offline.New(store)
+	defer a.Close()
+	a.SetSpanMetrics(spanMetrics)
+
+	for _, path := range files {
+		stats, err := a.AnalyzeFile(ctx, path, signal)
+		if err != nil {
+			log.Printf("Error analyzing %v", err)
+			return 1
+		}
+		var parts []string
+		for sig, n := range stats.Requests {
+			parts = append(parts, fmt.Sprintf("%s=%d", sig, n))
+		}
+		sort.Strings(parts)
+		log.Printf("Analyzed %s (%s): requests %s", path, stats.Format, strings.Join(parts, " "))
+		if stats.Skipped > 0 {
+			log.Printf("Skipped %d unrecognized records in %s", stats.Skipped, path)
+		}
+	}
+
+	gen := report.NewGenerator(store)
+	gen.Lint = lintRules
+	gen.SpanMetrics = spanMetrics
+	rpt, err := gen.Generate(ctx, 0)
+	if err != nil {
+		log.Printf("Error generating report: %v", err)
+		return 1
+	}
+	var formatted []byte
+	switch *reportFormat {
+	case "json":
+		formatted, err = report.FormatJSON(rpt)
+	default:
+		formatted, err = report.FormatText(rpt)
+	}
+	if err != nil {
+		log.Printf("Error formatting report: %v", err)
+		return 1
+	}
+	if *reportOutput != "" {
+		if err := os.WriteFile(*reportOutput, formatted, 0644); err != nil {
+			log.Printf("Error writing report to %s: %v", *reportOutput, err)
+			return 1
+		}
+		log.Printf("Report written to %s", *reportOutput)
+	} else {
+		fmt.Println(string(formatted))
+	}
+
+	if *sessionExport != "" {
+		if err := exportSession(ctx, store, *sessionExport); err != nil {
+			log.Printf("Error exporting session: %v", err)
+			return 1
+		}
+		log.Printf("Session exported to %s", *sessionExport)
+	}
+
+	if *exitOnThreshold {
+		return rpt.MaxExitCode()
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -16,12 +17,23 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/alert"
 	"github.com/fidde/otlp_cardinality_checker/internal/api"
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/forward"
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/lint"
 	"github.com/fidde/otlp_cardinality_checker/internal/receiver"
 	"github.com/fidde/otlp_cardinality_checker/internal/report"
+	"github.com/fidde/otlp_cardinality_checker/internal/scrape"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/internal/tlsutil"
 	"github.com/fidde/otlp_cardinality_checker/internal/version"
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 )
 
@@ -32,6 +44,11 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Offline batch analysis of recorded OTLP files.
+	if len(os.Args) >= 2 && os.Args[1] == "analyze" {
+		os.Exit(runAnalyze(os.Args[2:]))
+	}
+
 	// Parse --watch-fields=key1,key2 flag (additive at startup for Kafka replay).
 	var watchFieldsRaw string
 	for _, arg := range os.Args[1:] {
@@ -56,6 +73,36 @@ func main() {
 	reportFormat := parseStringFlag("--report-format", "OCC_REPORT_FORMAT")
 	exitOnThreshold := parseBoolFlag("--exit-on-threshold", "OCC_EXIT_ON_THRESHOLD")
 	sessionExport := parseStringFlag("--session-export", "OCC_SESSION_EXPORT")
+	scrapeTargetsRaw := parseStringFlag("--scrape-targets", "OCC_SCRAPE_TARGETS")
+	scrapeIntervalStr := parseStringFlag("--scrape-interval", "OCC_SCRAPE_INTERVAL")
+	syslogAddr := parseStringFlag("--syslog-addr", "OCC_SYSLOG_ADDR")
+	tailPodLogs := parseBoolFlag("--tail-pod-logs", "OCC_TAIL_POD_LOGS")
+	podLogIncludeRaw := parseStringFlag("--pod-log-include", "OCC_POD_LOG_INCLUDE")
+	podLogCheckpoint := parseStringFlag("--pod-log-checkpoint", "OCC_POD_LOG_CHECKPOINT")
+	podLogStartAt := parseStringFlag("--pod-log-start-at", "OCC_POD_LOG_START_AT")
+	forwardEndpoint := parseStringFlag("--forward-endpoint", "OCC_FORWARD_ENDPOINT")
+	forwardProtocol := parseStringFlag("--forward-protocol", "OCC_FORWARD_PROTOCOL")
+	forwardSignalsRaw := parseStringFlag("--forward-signals", "OCC_FORWARD_SIGNALS")
+	forwardHeadersRaw := parseStringFlag("--forward-headers", "OCC_FORWARD_HEADERS")
+	forwardCompression := parseStringFlag("--forward-compression", "OCC_FORWARD_COMPRESSION")
+	forwardQueueSizeStr := parseStringFlag("--forward-queue-size", "OCC_FORWARD_QUEUE_SIZE")
+	forwardMaxRetriesStr := parseStringFlag("--forward-max-retries", "OCC_FORWARD_MAX_RETRIES")
+	forwardInsecure := parseBoolFlag("--forward-insecure", "OCC_FORWARD_INSECURE")
+	authTokensRaw := parseStringFlag("--auth-tokens", "OCC_AUTH_TOKENS")
+	authHtpasswd := parseStringFlag("--auth-htpasswd", "OCC_AUTH_HTPASSWD")
+	authAdminUsers := parseStringFlag("--auth-admin-users", "OCC_AUTH_ADMIN_USERS")
+	ingestQueueSizeStr := parseStringFlag("--ingest-queue-size", "OCC_INGEST_QUEUE_SIZE")
+	ingestWorkersStr := parseStringFlag("--ingest-workers", "OCC_INGEST_WORKERS")
+	multiTenant := parseBoolFlag("--multi-tenant", "OCC_MULTI_TENANT")
+	tenantHeader := parseStringFlag("--tenant-header", "OCC_TENANT_HEADER")
+	maxTenantsStr := parseStringFlag("--max-tenants", "OCC_MAX_TENANTS")
+	tenantLimitsFile := parseStringFlag("--tenant-limits-file", "OCC_TENANT_LIMITS_FILE")
+	lintRulesFile := parseStringFlag("--lint-rules", "OCC_LINT_RULES")
+	windowResolutionStr := parseStringFlag("--window-resolution", "OCC_WINDOW_RESOLUTION")
+	windowHorizonStr := parseStringFlag("--window-horizon", "OCC_WINDOW_HORIZON")
+	historyIntervalStr := parseStringFlag("--history-interval", "OCC_HISTORY_INTERVAL")
+	alertRulesFile := parseStringFlag("--alert-rules", "OCC_ALERT_RULES")
+	spanMetricsFile := parseStringFlag("--spanmetrics-config", "OCC_SPANMETRICS_CONFIG")
 
 	if reportFormat == "" {
 		reportFormat = "text"
@@ -73,6 +120,193 @@ func main() {
 		}
 	}
 
+	scrapeCfg := scrape.DefaultConfig()
+	if scrapeTargetsRaw != "" {
+		targets, err := scrape.ParseTargets(scrapeTargetsRaw)
+		if err != nil {
+			log.Fatalf("Invalid --scrape-targets: %v", err)
+		}
+		scrapeCfg.Targets = targets
+	}
+	if scrapeIntervalStr != "" {
+		interval, err := time.ParseDuration(scrapeIntervalStr)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid --scrape-interval %q: must be a positive duration", scrapeIntervalStr)
+		}
+		scrapeCfg.Interval = interval
+	}
+
+	// Sliding windows cost up to ~21 KB per metric, attribute and label, so
+	// they stay off unless a horizon is given.
+	windowCfg := hyperloglog.DefaultWindowConfig()
+	windowCfg.Horizon = 0
+	if windowResolutionStr != "" {
+		resolution, err := time.ParseDuration(windowResolutionStr)
+		if err != nil || resolution <= 0 {
+			log.Fatalf("Invalid --window-resolution %q: must be a positive duration", windowResolutionStr)
+		}
+		windowCfg.Resolution = resolution
+	}
+	if windowHorizonStr != "" {
+		horizon, err := time.ParseDuration(windowHorizonStr)
+		if err != nil || (horizon != 0 && horizon < windowCfg.Resolution) {
+			log.Fatalf("Invalid --window-horizon %q: must be 0 or a duration of at least the window resolution", windowHorizonStr)
+		}
+		windowCfg.Horizon = horizon
+	}
+	models.SetWindowConfig(windowCfg)
+	if models.WindowsEnabled() {
+		log.Printf("Sliding windows enabled (resolution: %s, horizon: %s)", windowCfg.Resolution, models.FormatWindow(models.WindowHorizon()))
+	}
+
+	historyInterval := storage.DefaultHistoryInterval
+	if historyIntervalStr != "" {
+		interval, err := time.ParseDuration(historyIntervalStr)
+		if err != nil || interval < 0 {
+			log.Fatalf("Invalid --history-interval %q: must be a duration, or 0 to disable", historyIntervalStr)
+		}
+		historyInterval = interval
+	}
+
+	podLogCfg := receiver.DefaultPodLogConfig()
+	if podLogIncludeRaw != "" {
+		podLogCfg.Include = nil
+		for _, pattern := range strings.Split(podLogIncludeRaw, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				podLogCfg.Include = append(podLogCfg.Include, pattern)
+			}
+		}
+	}
+	podLogCfg.CheckpointFile = podLogCheckpoint
+	switch podLogStartAt {
+	case "", "end":
+	case "beginning":
+		podLogCfg.StartAtBeginning = true
+	default:
+		log.Fatalf("Invalid --pod-log-start-at %q: must be 'beginning' or 'end'", podLogStartAt)
+	}
+
+	forwardCfg := forward.DefaultConfig()
+	forwardCfg.Endpoint = forwardEndpoint
+	forwardCfg.Insecure = forwardInsecure
+	if forwardProtocol != "" {
+		forwardCfg.Protocol = forwardProtocol
+	}
+	if forwardSignalsRaw != "" {
+		signals, err := forward.ParseSignals(forwardSignalsRaw)
+		if err != nil {
+			log.Fatalf("Invalid --forward-signals: %v", err)
+		}
+		forwardCfg.Signals = signals
+	}
+	if forwardHeadersRaw != "" {
+		headers, err := forward.ParseHeaders(forwardHeadersRaw)
+		if err != nil {
+			log.Fatalf("Invalid --forward-headers: %v", err)
+		}
+		forwardCfg.Headers = headers
+	}
+	if forwardCompression != "" && forwardCompression != "none" {
+		forwardCfg.Compression = forwardCompression
+	}
+	if forwardQueueSizeStr != "" {
+		n, err := strconv.Atoi(forwardQueueSizeStr)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid --forward-queue-size %q: must be a positive integer", forwardQueueSizeStr)
+		}
+		forwardCfg.QueueSize = n
+	}
+	if forwardMaxRetriesStr != "" {
+		n, err := strconv.Atoi(forwardMaxRetriesStr)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid --forward-max-retries %q: must be a non-negative integer", forwardMaxRetriesStr)
+		}
+		forwardCfg.MaxRetries = n
+	}
+
+	// The pipeline is opt-in. With the default queue size of 0 requests are
+	// analyzed and stored before the receiver responds. The queue is bounded
+	// by request count, not bytes, so its memory grows with the size of the
+	// queued requests.
+	ingestCfg := ingest.DefaultConfig()
+	ingestCfg.QueueSize = 0
+	if ingestQueueSizeStr != "" {
+		n, err := strconv.Atoi(ingestQueueSizeStr)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid --ingest-queue-size %q: must be a non-negative integer", ingestQueueSizeStr)
+		}
+		ingestCfg.QueueSize = n
+	}
+	if ingestWorkersStr != "" {
+		n, err := strconv.Atoi(ingestWorkersStr)
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid --ingest-workers %q: must be a positive integer", ingestWorkersStr)
+		}
+		ingestCfg.Workers = n
+	}
+
+	tenantCfg := tenant.Config{Header: tenantHeader}
+	if maxTenantsStr != "" {
+		n, err := strconv.Atoi(maxTenantsStr)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid --max-tenants %q: must be a non-negative integer", maxTenantsStr)
+		}
+		tenantCfg.MaxTenants = n
+	}
+	if tenantLimitsFile != "" {
+		limits, err := tenant.LoadLimits(tenantLimitsFile)
+		if err != nil {
+			log.Fatalf("Invalid --tenant-limits-file: %v", err)
+		}
+		tenantCfg.Limits = limits.Defaults
+		tenantCfg.Overrides = limits.Tenants
+	}
+
+	var lintRules *lint.RuleSet
+	if lintRulesFile != "" {
+		var err error
+		lintRules, err = lint.Load(lintRulesFile)
+		if err != nil {
+			log.Fatalf("Invalid --lint-rules: %v", err)
+		}
+		log.Printf("Loaded %d naming lint rules from %s", lintRules.Len(), lintRulesFile)
+	}
+
+	var alertCfg *alert.Config
+	if alertRulesFile != "" {
+		var err error
+		alertCfg, err = alert.Load(alertRulesFile)
+		if err != nil {
+			log.Fatalf("Invalid --alert-rules: %v", err)
+		}
+	}
+
+	var spanMetrics *spanmetrics.Estimator
+	if spanMetricsFile != "" {
+		cfg, err := spanmetrics.Load(spanMetricsFile)
+		if err != nil {
+			log.Fatalf("Invalid --spanmetrics-config: %v", err)
+		}
+		spanMetrics = spanmetrics.New(cfg)
+		log.Printf("Estimating spanmetrics connector series for dimensions %s", strings.Join(spanMetrics.Dimensions(), ", "))
+	}
+
+	authCfg := auth.Config{HtpasswdFile: authHtpasswd}
+	if authTokensRaw != "" {
+		tokens, err := auth.ParseTokens(authTokensRaw)
+		if err != nil {
+			log.Fatalf("Invalid --auth-tokens: %v", err)
+		}
+		authCfg.Tokens = tokens
+	}
+	if authAdminUsers != "" {
+		authCfg.AdminUsers = strings.Split(authAdminUsers, ",")
+	}
+	authenticator, err := auth.New(authCfg)
+	if err != nil {
+		log.Fatalf("Invalid authentication configuration: %v", err)
+	}
+
 	if minimal {
 		log.Println("Running in minimal mode (UI disabled)")
 	} else {
@@ -116,8 +350,22 @@ func main() {
 		log.Fatalf("--watch-fields specifies %d keys but MaxWatchedFields limit is %d", len(watchFields), storageCfg.MaxWatchedFields)
 	}
 
-	// Create storage (always in-memory)
-	store := storage.NewStorage(storageCfg)
+	// Create storage (always in-memory). With multi-tenancy every tenant
+	// gets its own store and the router picks one per request.
+	var store storage.Storage
+	var tenants *tenant.Registry
+	if multiTenant {
+		tenantCfg.Storage = storageCfg
+		tenantCfg.Sessions = sessions.DefaultConfig()
+		tenants, err = tenant.New(tenantCfg)
+		if err != nil {
+			log.Fatalf("Failed to configure multi-tenancy: %v", err)
+		}
+		store = tenants.Storage()
+		log.Printf("Multi-tenancy enabled (header: %s, max tenants: %d)", tenants.Header(), tenantCfg.MaxTenants)
+	} else {
+		store = storage.NewStorage(storageCfg)
+	}
 	defer func() {
 		if err := store.Close(); err != nil {
 			log.Printf("Error closing storage: %v", err)
@@ -135,11 +383,61 @@ func main() {
 		}
 	}
 
+	// Sample the cardinality history for the growth charts.
+	historyCtx, stopHistory := context.WithCancel(context.Background())
+	defer stopHistory()
+	if historyInterval > 0 {
+		if hs, ok := store.(storage.HistoryStore); ok {
+			record := func(at time.Time) { hs.RecordHistory(historyCtx, at) }
+			if tenants != nil {
+				record = tenants.RecordHistory
+			}
+			log.Printf("Sampling cardinality history every %s (%d samples kept)", historyInterval, models.DefaultHistorySize)
+			go storage.SampleHistory(historyCtx, historyInterval, record)
+		}
+	}
+
+	// Evaluate cardinality explosion alerts.
+	var detector *alert.Detector
+	alertCtx, stopAlerts := context.WithCancel(context.Background())
+	defer stopAlerts()
+	if alertCfg != nil {
+		var err error
+		detector, err = alert.New(alertCfg)
+		if err != nil {
+			log.Fatalf("Invalid --alert-rules: %v", err)
+		}
+		defer detector.Close()
+		log.Printf("Evaluating %d alert rules every %s, notifying %d webhooks", len(alertCfg.Rules), detector.Interval(), len(alertCfg.Webhooks))
+		go detector.Run(alertCtx, store, tenants)
+	}
+
 	// Create OTLP receivers
 	otlpHTTPAddr := getEnv("OTLP_HTTP_ADDR", "0.0.0.0:4318")
 	otlpGRPCAddr := getEnv("OTLP_GRPC_ADDR", "0.0.0.0:4317")
 	httpReceiver := receiver.NewHTTPReceiver(otlpHTTPAddr, store)
 	grpcReceiver := receiver.NewGRPCReceiver(otlpGRPCAddr, store)
+	httpReceiver.TLSConfig = listenerTLS("otlp-http", "OTLP_HTTP")
+	grpcReceiver.TLSConfig = listenerTLS("otlp-grpc", "OTLP_GRPC")
+	otlpHTTPScheme := urlScheme(httpReceiver.TLSConfig)
+	httpReceiver.Auth = authenticator
+	grpcReceiver.Auth = authenticator
+	httpReceiver.Tenants = tenants
+	grpcReceiver.Tenants = tenants
+
+	// Candidate rules registered through the API run in shadow against
+	// everything the receivers accept.
+	simulations := simulate.NewRegistry()
+	httpReceiver.Simulations = simulations
+	grpcReceiver.Simulations = simulations
+	httpReceiver.SpanMetrics = spanMetrics
+	grpcReceiver.SpanMetrics = spanMetrics
+	tenants.OnDrop(httpReceiver.DropTenant)
+	tenants.OnDrop(grpcReceiver.DropTenant)
+	tenants.OnDrop(spanMetrics.DropTenant)
+	if authenticator.Enabled() {
+		log.Printf("Authentication enabled (%d tokens, htpasswd: %q)", len(authCfg.Tokens), authCfg.HtpasswdFile)
+	}
 
 	// Wire up activity tracking for idle timeout.
 	var lastActivity atomic.Int64
@@ -148,9 +446,76 @@ func main() {
 	httpReceiver.OnActivity = notifyActivity
 	grpcReceiver.OnActivity = notifyActivity
 
+	// Analyze and store asynchronously when a queue size is configured.
+	var pipeline *ingest.Pipeline
+	if ingestCfg.QueueSize > 0 {
+		pipeline = ingest.New(ingestCfg)
+		httpReceiver.Pipeline = pipeline
+		grpcReceiver.Pipeline = pipeline
+		log.Printf("Ingestion pipeline: queue %d per signal, %d workers per signal", ingestCfg.QueueSize, ingestCfg.Workers)
+	}
+
+	// Forward analyzed payloads upstream if configured.
+	var forwarder *forward.Forwarder
+	if forwardCfg.Endpoint != "" {
+		var err error
+		forwarder, err = forward.New(forwardCfg)
+		if err != nil {
+			log.Fatalf("Failed to configure forwarding: %v", err)
+		}
+		httpReceiver.Forwarder = forwarder
+		grpcReceiver.Forwarder = forwarder
+		log.Printf("Forwarding %v to %s (%s, queue %d)", forwardCfg.Signals, forwardCfg.Endpoint, forwardCfg.Protocol, forwardCfg.QueueSize)
+	}
+
+	// Start scraping Prometheus/OpenMetrics targets if configured.
+	scrapeCtx, stopScrape := context.WithCancel(context.Background())
+	defer stopScrape()
+	if len(scrapeCfg.Targets) > 0 {
+		scraper := scrape.New(scrapeCfg, store)
+		scraper.OnActivity = notifyActivity
+		scraper.Simulations = simulations
+		for _, t := range scrapeCfg.Targets {
+			log.Printf("Scraping %s (job %q, interval %s)", t.URL, t.Job, scrapeCfg.Interval)
+		}
+		go scraper.Run(scrapeCtx)
+	}
+
+	// Tail container log files if configured.
+	podLogCtx, stopPodLogs := context.WithCancel(context.Background())
+	defer stopPodLogs()
+	podLogsDone := make(chan struct{})
+	if tailPodLogs {
+		podLogReceiver, err := receiver.NewPodLogReceiver(podLogCfg, store)
+		if err != nil {
+			log.Fatalf("Failed to configure pod log tailing: %v", err)
+		}
+		podLogReceiver.OnActivity = notifyActivity
+		podLogReceiver.Pipeline = pipeline
+		podLogReceiver.Simulations = simulations
+		log.Printf("Tailing pod logs from %s (checkpoints: %q)", strings.Join(podLogCfg.Include, ","), podLogCfg.CheckpointFile)
+		go func() {
+			defer close(podLogsDone)
+			podLogReceiver.Run(podLogCtx)
+		}()
+	} else {
+		close(podLogsDone)
+	}
+
+	// Listen for syslog if configured.
+	var syslogReceiver *receiver.SyslogReceiver
+	if syslogAddr != "" {
+		syslogReceiver = receiver.NewSyslogReceiver(syslogAddr, store)
+		syslogReceiver.OnActivity = notifyActivity
+		syslogReceiver.Pipeline = pipeline
+		syslogReceiver.Simulations = simulations
+	}
+
 	// Create REST API server
 	apiAddr := getEnv("API_ADDR", "0.0.0.0:8090")
-	apiServer := api.NewServer(apiAddr, store, api.ServerOptions{DisableUI: minimal})
+	apiTLS := listenerTLS("api", "API")
+	apiScheme := urlScheme(apiTLS)
+	apiServer := api.NewServer(apiAddr, store, api.ServerOptions{DisableUI: minimal, TLSConfig: apiTLS, Auth: authenticator, Ingest: pipeline, Tenants: tenants, LintRules: lintRules, Simulations: simulations, Alerts: detector, SpanMetrics: spanMetrics})
 
 	// Start pprof server for profiling (separate port)
 	pprofAddr := getEnv("PPROF_ADDR", "localhost:6060")
@@ -162,7 +527,7 @@ func main() {
 	}()
 
 	// Start servers in goroutines
-	errChan := make(chan error, 3)
+	errChan := make(chan error, 4)
 
 	go func() {
 		log.Printf("Starting OTLP HTTP receiver on %s", otlpHTTPAddr)
@@ -178,6 +543,15 @@ func main() {
 		}
 	}()
 
+	if syslogReceiver != nil {
+		go func() {
+			log.Printf("Starting syslog receiver on %s (UDP and TCP)", syslogAddr)
+			if err := syslogReceiver.Start(); err != nil {
+				errChan <- fmt.Errorf("syslog receiver error: %w", err)
+			}
+		}()
+	}
+
 	go func() {
 		log.Printf("Starting REST API server on %s", apiAddr)
 		if err := apiServer.Start(); err != nil {
@@ -189,16 +563,22 @@ func main() {
 	time.Sleep(100 * time.Millisecond)
 	log.Println("All servers started successfully")
 	log.Println("OTLP endpoints:")
-	log.Printf("  - HTTP: http://%s/v1/metrics", otlpHTTPAddr)
-	log.Printf("  - HTTP: http://%s/v1/traces", otlpHTTPAddr)
-	log.Printf("  - HTTP: http://%s/v1/logs", otlpHTTPAddr)
+	log.Printf("  - HTTP: %s://%s/v1/metrics", otlpHTTPScheme, otlpHTTPAddr)
+	log.Printf("  - HTTP: %s://%s/v1/traces", otlpHTTPScheme, otlpHTTPAddr)
+	log.Printf("  - HTTP: %s://%s/v1/logs", otlpHTTPScheme, otlpHTTPAddr)
+	log.Printf("  - HTTP: %s://%s/v1development/profiles", otlpHTTPScheme, otlpHTTPAddr)
+	log.Printf("  - Prometheus remote-write: %s://%s/api/v1/write", otlpHTTPScheme, otlpHTTPAddr)
 	log.Printf("  - gRPC: %s", otlpGRPCAddr)
+	if syslogReceiver != nil {
+		log.Printf("  - Syslog: %s (UDP and TCP)", syslogAddr)
+	}
 	log.Println("API endpoints:")
-	log.Printf("  - Metrics: http://%s/api/v1/metrics", apiAddr)
-	log.Printf("  - Spans: http://%s/api/v1/spans", apiAddr)
-	log.Printf("  - Logs: http://%s/api/v1/logs", apiAddr)
-	log.Printf("  - Services: http://%s/api/v1/services", apiAddr)
-	log.Printf("  - Health: http://%s/health", apiAddr)
+	log.Printf("  - Metrics: %s://%s/api/v1/metrics", apiScheme, apiAddr)
+	log.Printf("  - Spans: %s://%s/api/v1/spans", apiScheme, apiAddr)
+	log.Printf("  - Logs: %s://%s/api/v1/logs", apiScheme, apiAddr)
+	log.Printf("  - Profiles: %s://%s/api/v1/profiles", apiScheme, apiAddr)
+	log.Printf("  - Services: %s://%s/api/v1/services", apiScheme, apiAddr)
+	log.Printf("  - Health: %s://%s/health", apiScheme, apiAddr)
 	log.Println("Profiling:")
 	log.Printf("  - pprof: http://%s/debug/pprof", pprofAddr)
 
@@ -246,20 +626,42 @@ func main() {
 	defer cancel()
 
 	log.Println("Shutting down servers...")
+	stopScrape()
 	if err := httpReceiver.Shutdown(shutdownCtx); err != nil {
 		log.Printf("Error shutting down OTLP HTTP receiver: %v", err)
 	}
 	if err := grpcReceiver.Shutdown(shutdownCtx); err != nil {
 		log.Printf("Error shutting down OTLP gRPC receiver: %v", err)
 	}
+	if syslogReceiver != nil {
+		if err := syslogReceiver.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down syslog receiver: %v", err)
+		}
+	}
+	// Stop tailing and wait for the final checkpoint.
+	stopPodLogs()
+	<-podLogsDone
+	// Drain queued requests so the report and exports see all data.
+	if err := pipeline.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error draining ingestion queue: %v", err)
+	}
 	if err := apiServer.Shutdown(shutdownCtx); err != nil {
 		log.Printf("Error shutting down API server: %v", err)
 	}
+	if forwarder != nil {
+		if err := forwarder.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error draining forward queue: %v", err)
+		}
+		stats := forwarder.Stats()
+		log.Printf("Forwarded %d payloads (%d failed, %d dropped)", stats.Sent, stats.Failed, stats.Dropped)
+	}
 
 	// Generate report on shutdown if requested, or always on idle timeout.
 	exitCode := 0
 	if reportOutput != "" || idleTimeout > 0 {
 		gen := report.NewGenerator(store)
+		gen.Lint = lintRules
+		gen.SpanMetrics = spanMetrics
 		rpt, err := gen.Generate(shutdownCtx, idleTimeout)
 		if err != nil {
 			log.Printf("Error generating report: %v", err)
@@ -297,6 +699,8 @@ func main() {
 	} else if exitOnThreshold {
 		// No report requested but exit-on-threshold set: still calculate.
 		gen := report.NewGenerator(store)
+		gen.Lint = lintRules
+		gen.SpanMetrics = spanMetrics
 		rpt, err := gen.Generate(shutdownCtx, 0)
 		if err != nil {
 			log.Printf("Error generating report for threshold check: %v", err)
@@ -325,6 +729,38 @@ func main() {
 	}
 }
 
+// listenerTLS builds the TLS configuration of one listener from the
+// --<name>-tls-* flags or <envPrefix>_TLS_* variables. It returns nil when no
+// certificate is configured.
+func listenerTLS(name, envPrefix string) *tls.Config {
+	cfg := tlsutil.Config{
+		CertFile:     parseStringFlag("--"+name+"-tls-cert", envPrefix+"_TLS_CERT"),
+		KeyFile:      parseStringFlag("--"+name+"-tls-key", envPrefix+"_TLS_KEY"),
+		ClientCAFile: parseStringFlag("--"+name+"-tls-client-ca", envPrefix+"_TLS_CLIENT_CA"),
+		MinVersion:   parseStringFlag("--"+name+"-tls-min-version", envPrefix+"_TLS_MIN_VERSION"),
+	}
+	tlsCfg, err := cfg.ServerConfig()
+	if err != nil {
+		log.Fatalf("Invalid TLS configuration for %s: %v", name, err)
+	}
+	if tlsCfg != nil {
+		mode := "TLS"
+		if cfg.ClientCAFile != "" {
+			mode = "mTLS"
+		}
+		log.Printf("%s enabled for %s listener (cert: %s)", mode, name, cfg.CertFile)
+	}
+	return tlsCfg
+}
+
+// urlScheme returns the scheme to advertise for a listener.
+func urlScheme(tlsCfg *tls.Config) string {
+	if tlsCfg != nil {
+		return "https"
+	}
+	return "http"
+}
+
 // getEnv gets an environment variable with a default fallback.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -373,10 +809,15 @@ func exportSession(ctx context.Context, store storage.Storage, path string) erro
 	metrics, mErr := store.ListMetrics(ctx, "")
 	spans, sErr := store.ListSpans(ctx, "")
 	logs, lErr := store.ListLogs(ctx, "")
+	var profiles []*models.ProfileMetadata
+	var pErr error
+	if ps, ok := store.(storage.ProfileStore); ok {
+		profiles, pErr = ps.ListProfiles(ctx, "")
+	}
 	attrs, aErr := store.ListAttributes(ctx, nil)
 	services, svErr := store.ListServices(ctx)
 
-	for _, err := range []error{mErr, sErr, lErr, aErr, svErr} {
+	for _, err := range []error{mErr, sErr, lErr, pErr, aErr, svErr} {
 		if err != nil {
 			return fmt.Errorf("reading store data: %w", err)
 		}
@@ -387,6 +828,9 @@ func exportSession(ctx context.Context, store storage.Storage, path string) erro
 	if err != nil {
 		return fmt.Errorf("serializing metrics: %w", err)
 	}
+	if cs, ok := store.(storage.MetricConflictStore); ok {
+		sessions.SetMetricIdentities(sMetrics, cs.ConflictingMetricIdentities(ctx))
+	}
 	sSpans, err := serializer.MarshalSpans(spans)
 	if err != nil {
 		return fmt.Errorf("serializing spans: %w", err)
@@ -395,6 +839,10 @@ func exportSession(ctx context.Context, store storage.Storage, path string) erro
 	if err != nil {
 		return fmt.Errorf("serializing logs: %w", err)
 	}
+	sProfiles, err := serializer.MarshalProfiles(profiles)
+	if err != nil {
+		return fmt.Errorf("serializing profiles: %w", err)
+	}
 	sAttrs, err := serializer.MarshalAttributes(attrs)
 	if err != nil {
 		return fmt.Errorf("serializing attributes: %w", err)
@@ -405,17 +853,19 @@ func exportSession(ctx context.Context, store storage.Storage, path string) erro
 		ID:          fmt.Sprintf("export-%d", time.Now().Unix()),
 		Description: "Exported on shutdown",
 		Created:     time.Now().UTC(),
-		Signals:     []string{"metrics", "traces", "logs"},
+		Signals:     []string{"metrics", "traces", "logs", "profiles"},
 		Data: models.SessionData{
 			Metrics:    sMetrics,
 			Spans:      sSpans,
 			Logs:       sLogs,
+			Profiles:   sProfiles,
 			Attributes: sAttrs,
 		},
 		Stats: models.SessionStats{
 			MetricsCount:    len(metrics),
 			SpansCount:      len(spans),
 			LogsCount:       len(logs),
+			ProfilesCount:   len(profiles),
 			AttributesCount: len(attrs),
 			Services:        services,
 		},
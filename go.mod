@@ -6,7 +6,10 @@ toolchain go1.24.9
 
 require (
 	github.com/go-chi/chi/v5 v5.2.3
+	github.com/klauspost/compress v1.18.0
 	go.opentelemetry.io/proto/otlp v1.8.0
+	golang.org/x/crypto v0.42.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250825161204-c5933d9347a5
 	google.golang.org/grpc v1.76.0
 	google.golang.org/protobuf v1.36.10
 	gopkg.in/yaml.v3 v3.0.1
@@ -20,5 +23,4 @@ require (
 	golang.org/x/sys v0.36.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250825161204-c5933d9347a5 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20250825161204-c5933d9347a5 // indirect
 )
@@ -0,0 +1,201 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	if _, err := Load("../../config/alert-rules.yaml"); err != nil {
+		t.Fatalf("example rules: %v", err)
+	}
+
+	cfg, err := Parse([]byte(`
+rules:
+  - name: series
+    target: metric_series
+    threshold: 1000
+webhooks:
+  - url: http://example.com/hook
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Interval != DefaultInterval {
+		t.Errorf("interval = %s, want the default", cfg.Interval)
+	}
+
+	for name, data := range map[string]string{
+		"no name":       "rules: [{target: metric_series, threshold: 1}]",
+		"bad target":    "rules: [{name: a, target: spans, threshold: 1}]",
+		"no condition":  "rules: [{name: a, target: metric_series}]",
+		"bad match":     "rules: [{name: a, target: metric_series, threshold: 1, match: '('}]",
+		"bad severity":  "rules: [{name: a, target: metric_series, threshold: 1, severity: page}]",
+		"duplicate":     "rules: [{name: a, target: metric_series, threshold: 1}, {name: a, target: log_templates, threshold: 1}]",
+		"bad url":       "webhooks: [{url: 'hooks.slack.com/x'}]",
+		"bad format":    "webhooks: [{url: 'http://x', format: discord}]",
+		"bad template":  "webhooks: [{url: 'http://x', template: '{{'}]",
+		"negative time": "interval: -1s",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse succeeded, want error", name)
+		}
+	}
+}
+
+// recorder is a webhook endpoint keeping the bodies it received.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	rec.bodies = append(rec.bodies, string(b))
+	rec.mu.Unlock()
+}
+
+func TestDetector(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewStorage(storage.DefaultConfig())
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	d, err := New(&Config{
+		Rules: []Rule{
+			{Name: "too_many_series", Target: models.AlertTargetMetricSeries, Threshold: 50, Severity: "critical"},
+			{Name: "attribute_explosion", Target: models.AlertTargetAttributeCardinality, Growth: 20, Window: 5 * time.Minute, Match: "_id$"},
+		},
+		Webhooks: []Webhook{{URL: srv.URL}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addSeries := func(n int) {
+		m := models.NewMetricMetadata("http_requests", &models.SumMetric{})
+		for i := 0; i < n; i++ {
+			m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+		}
+		if err := store.StoreMetric(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addValues := func(key string, n int) {
+		for i := 0; i < n; i++ {
+			store.StoreAttributeValue(ctx, key, fmt.Sprintf("v%d", i), "metric", "attribute") //nolint:errcheck
+		}
+	}
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	addSeries(10)
+	addValues("method", 5)
+	d.Evaluate(ctx, store, start)
+	if got := d.Alerts(ctx); len(got) != 0 {
+		t.Fatalf("alerts at start = %+v, want none", got)
+	}
+
+	// A deploy adds user_id and doubles the series past the threshold.
+	addSeries(60)
+	addValues("user_id", 40)
+	addValues("method", 40)
+	d.Evaluate(ctx, store, start.Add(time.Minute))
+	d.Evaluate(ctx, store, start.Add(2*time.Minute))
+	alerts := d.Alerts(ctx)
+	if len(alerts) != 2 {
+		t.Fatalf("alerts = %+v, want series and user_id", alerts)
+	}
+	for _, a := range alerts {
+		if a.State != models.AlertFiring {
+			t.Errorf("%s %s is %s, want firing", a.Rule, a.Name, a.State)
+		}
+		if a.Name == "method" {
+			t.Error("method fired although the rule only matches *_id")
+		}
+	}
+
+	// Once the growth left the window the attribute alert resolves; the
+	// threshold still holds.
+	d.Evaluate(ctx, store, start.Add(8*time.Minute))
+	alerts = d.Alerts(ctx)
+	if len(alerts) != 2 || alerts[0].State != models.AlertFiring || alerts[1].State != models.AlertResolved || alerts[1].Name != "user_id" {
+		t.Fatalf("alerts = %+v, want series firing and user_id resolved", alerts)
+	}
+
+	d.Close()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.bodies) != 3 {
+		t.Fatalf("webhook got %d notifications, want 2 firing and 1 resolved:\n%s", len(rec.bodies), strings.Join(rec.bodies, "\n"))
+	}
+	var last struct {
+		Status string       `json:"status"`
+		Alert  models.Alert `json:"alert"`
+	}
+	if err := json.Unmarshal([]byte(rec.bodies[2]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Status != models.AlertResolved || last.Alert.Name != "user_id" || last.Alert.Peak != 40 {
+		t.Errorf("last notification = %+v, want user_id resolved at peak 40", last)
+	}
+}
+
+func TestWebhookFormats(t *testing.T) {
+	retryDelay = time.Millisecond
+	defer func() { retryDelay = time.Second }()
+
+	var mu sync.Mutex
+	got := map[string]string{}
+	failures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/flaky" && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		got[r.URL.Path] = string(b)
+	}))
+	defer srv.Close()
+
+	webhooks, err := compileWebhooks([]Webhook{
+		{URL: srv.URL + "/slack", Format: "slack"},
+		{URL: srv.URL + "/teams", Format: "teams"},
+		{URL: srv.URL + "/custom", Template: `{"msg": {{ json .Alert.Summary }}, "status": "{{ .Status }}"}`},
+		{URL: srv.URL + "/flaky"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := newNotifier(webhooks)
+	n.notify(models.AlertFiring, models.Alert{Rule: "series", Summary: `metric "up" has 9000 active series`})
+	n.close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, want := range map[string]string{
+		"/slack":  `"text":"[FIRING] series: metric \"up\" has 9000 active series"`,
+		"/teams":  `"@type":"MessageCard"`,
+		"/custom": `"msg": "metric \"up\" has 9000 active series", "status": "firing"`,
+		"/flaky":  `"status":"firing"`,
+	} {
+		if !strings.Contains(got[path], want) {
+			t.Errorf("%s body = %s, want it to contain %s", path, got[path], want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package alert detects cardinality explosions, such as a deploy turning
+// user_id into a metric label, and notifies webhooks when they start and
+// when they end.
+package alert
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// DefaultInterval is how often rules are evaluated.
+const DefaultInterval = 30 * time.Second
+
+// DefaultWindow is the growth window of rules that do not set one.
+const DefaultWindow = 5 * time.Minute
+
+// Rule is one alert rule as written in the rules file. It fires for every
+// name of its target matching Match once any of Threshold, Growth or
+// GrowthPercent is reached, and resolves when none is.
+type Rule struct {
+	Name     string `yaml:"name"`
+	Target   string `yaml:"target"`
+	Severity string `yaml:"severity"` // warning (default) or critical
+
+	// Match limits the rule to names matching this regex.
+	Match string `yaml:"match"`
+
+	// Threshold fires at or above this value.
+	Threshold int64 `yaml:"threshold"`
+	// Growth fires when the value rose by this much within Window.
+	Growth int64 `yaml:"growth"`
+	// GrowthPercent fires when the value rose by this percentage within
+	// Window, once it is at least MinValue.
+	GrowthPercent float64       `yaml:"growth_percent"`
+	MinValue      int64         `yaml:"min_value"`
+	Window        time.Duration `yaml:"window"`
+}
+
+// Webhook is one notification endpoint.
+type Webhook struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Format is json (default), slack or teams.
+	Format string `yaml:"format"`
+	// Template replaces Format with a text/template rendering the body from
+	// .Status and .Alert; the json function quotes a value.
+	Template string            `yaml:"template"`
+	Headers  map[string]string `yaml:"headers"`
+}
+
+// Config is the layout of an alert rules file.
+type Config struct {
+	Interval time.Duration `yaml:"interval"`
+	Rules    []Rule        `yaml:"rules"`
+	Webhooks []Webhook     `yaml:"webhooks"`
+}
+
+var targets = map[string]bool{
+	models.AlertTargetMetricSeries:         true,
+	models.AlertTargetAttributeCardinality: true,
+	models.AlertTargetLogTemplates:         true,
+}
+
+var formats = map[string]bool{"json": true, "slack": true, "teams": true}
+
+type compiledRule struct {
+	Rule
+	match *regexp.Regexp
+}
+
+type compiledWebhook struct {
+	Webhook
+	tmpl *template.Template
+}
+
+// Load reads and validates an alert rules file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading alert rules: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse validates rules in the rules file format and fills in defaults.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing alert rules: %w", err)
+	}
+	if cfg.Interval < 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", cfg.Interval)
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if _, err := compileRules(cfg.Rules); err != nil {
+		return nil, err
+	}
+	if _, err := compileWebhooks(cfg.Webhooks); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func compileRules(rules []Rule) ([]*compiledRule, error) {
+	seen := make(map[string]bool, len(rules))
+	out := make([]*compiledRule, 0, len(rules))
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+
+		c, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func compileRule(r Rule) (*compiledRule, error) {
+	c := &compiledRule{Rule: r}
+	if !targets[r.Target] {
+		return nil, fmt.Errorf("target must be %s, %s or %s, got %q",
+			models.AlertTargetMetricSeries, models.AlertTargetAttributeCardinality, models.AlertTargetLogTemplates, r.Target)
+	}
+
+	switch r.Severity {
+	case "":
+		c.Severity = "warning"
+	case "warning", "critical":
+	default:
+		return nil, fmt.Errorf("severity must be 'warning' or 'critical', got %q", r.Severity)
+	}
+
+	if r.Threshold <= 0 && r.Growth <= 0 && r.GrowthPercent <= 0 {
+		return nil, fmt.Errorf("no condition: set threshold, growth or growth_percent")
+	}
+	if r.Threshold < 0 || r.Growth < 0 || r.GrowthPercent < 0 || r.MinValue < 0 || r.Window < 0 {
+		return nil, fmt.Errorf("threshold, growth, growth_percent, min_value and window must not be negative")
+	}
+	if c.Window == 0 {
+		c.Window = DefaultWindow
+	}
+
+	if r.Match != "" {
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match: %w", err)
+		}
+		c.match = re
+	}
+	return c, nil
+}
+
+func compileWebhooks(webhooks []Webhook) ([]*compiledWebhook, error) {
+	out := make([]*compiledWebhook, 0, len(webhooks))
+	for i, w := range webhooks {
+		name := w.Name
+		if name == "" {
+			name = fmt.Sprintf("webhook %d", i+1)
+		}
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s: url must be an http or https URL, got %q", name, w.URL)
+		}
+
+		c := &compiledWebhook{Webhook: w}
+		c.Name = name
+		if c.Format == "" {
+			c.Format = "json"
+		}
+		if !formats[c.Format] {
+			return nil, fmt.Errorf("%s: format must be json, slack or teams, got %q", name, w.Format)
+		}
+		if w.Template != "" {
+			tmpl, err := template.New(name).Funcs(templateFuncs).Parse(w.Template)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid template: %w", name, err)
+			}
+			c.tmpl = tmpl
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
@@ -0,0 +1,334 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// maxResolved is the number of resolved alerts kept per tenant for the API.
+const maxResolved = 100
+
+// logTemplateCounter is implemented by stores that count log body templates
+// per service.
+type logTemplateCounter interface {
+	CountLogTemplatesByService(ctx context.Context) (map[string]int64, error)
+}
+
+type sample struct {
+	at    int64 // unix nanoseconds
+	value int64
+}
+
+// tenantState is what the detector remembers about one tenant's store.
+type tenantState struct {
+	// evaluated is set after the first evaluation. Names appearing later
+	// are new and grow from zero; names present from the start do not.
+	evaluated bool
+	samples   map[string][]sample // target + "\x00" + name, oldest first
+	firing    map[string]*models.Alert
+	resolved  []*models.Alert // oldest first
+}
+
+// Detector evaluates alert rules against stores and notifies webhooks of
+// alerts that start firing and resolve. Each alert notifies once per
+// state change; while it fires only its values are updated.
+type Detector struct {
+	rules     []*compiledRule
+	interval  time.Duration
+	maxWindow time.Duration
+	notifier  *notifier
+
+	mu      sync.Mutex
+	tenants map[string]*tenantState
+	closed  bool
+}
+
+// New creates a detector for cfg and starts its webhook delivery.
+func New(cfg *Config) (*Detector, error) {
+	rules, err := compileRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := compileWebhooks(cfg.Webhooks)
+	if err != nil {
+		return nil, err
+	}
+	d := &Detector{
+		rules:    rules,
+		interval: cfg.Interval,
+		notifier: newNotifier(webhooks),
+		tenants:  make(map[string]*tenantState),
+	}
+	if d.interval <= 0 {
+		d.interval = DefaultInterval
+	}
+	for _, r := range rules {
+		d.maxWindow = max(d.maxWindow, r.Window)
+	}
+	return d, nil
+}
+
+// Interval returns how often Run evaluates the rules.
+func (d *Detector) Interval() time.Duration {
+	return d.interval
+}
+
+// Close delivers the pending notifications and stops the detector. Later
+// evaluations are ignored.
+func (d *Detector) Close() {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.notifier.close()
+}
+
+// Run evaluates every interval until ctx is done: the store of every
+// tenant when tenants is set, otherwise store.
+func (d *Detector) Run(ctx context.Context, store storage.Storage, tenants *tenant.Registry) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if tenants == nil {
+				d.Evaluate(ctx, store, now)
+				continue
+			}
+			for _, t := range tenants.Tenants() {
+				d.Evaluate(tenant.NewContext(ctx, t), t.Store, now)
+			}
+		}
+	}
+}
+
+// tenantID keys state by the tenant carried by ctx; "" when multi-tenancy
+// is off.
+func tenantID(ctx context.Context) string {
+	if t := tenant.FromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// collect reads the current value of every name of the targets in use.
+func (d *Detector) collect(ctx context.Context, store storage.Storage) map[string]map[string]int64 {
+	used := make(map[string]bool)
+	for _, r := range d.rules {
+		used[r.Target] = true
+	}
+
+	var metrics []*models.MetricMetadata
+	var attrs []*models.AttributeMetadata
+	if used[models.AlertTargetMetricSeries] {
+		metrics, _ = store.ListMetrics(ctx, "")
+	}
+	if used[models.AlertTargetAttributeCardinality] {
+		attrs, _ = store.ListAttributes(ctx, nil)
+	}
+	sample := models.NewHistorySample(metrics, attrs)
+
+	values := map[string]map[string]int64{
+		models.AlertTargetMetricSeries:         sample.Metrics,
+		models.AlertTargetAttributeCardinality: sample.Attributes,
+		models.AlertTargetLogTemplates:         {},
+	}
+	if c, ok := store.(logTemplateCounter); ok && used[models.AlertTargetLogTemplates] {
+		if counts, err := c.CountLogTemplatesByService(ctx); err == nil {
+			values[models.AlertTargetLogTemplates] = counts
+		}
+	}
+	return values
+}
+
+// Evaluate samples the store of the tenant of ctx and updates its alerts.
+func (d *Detector) Evaluate(ctx context.Context, store storage.Storage, now time.Time) {
+	values := d.collect(ctx, store)
+	at := now.UnixNano()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	tid := tenantID(ctx)
+	st := d.tenants[tid]
+	if st == nil {
+		st = &tenantState{samples: make(map[string][]sample), firing: make(map[string]*models.Alert)}
+		d.tenants[tid] = st
+	}
+
+	// Record the samples, dropping names that are gone and samples older
+	// than the longest window.
+	seen := make(map[string]bool, len(st.samples))
+	cutoff := at - int64(d.maxWindow)
+	for target, names := range values {
+		for name, v := range names {
+			key := target + "\x00" + name
+			seen[key] = true
+			samples, ok := st.samples[key]
+			if !ok && st.evaluated {
+				samples = append(samples, sample{at: at, value: 0})
+			}
+			samples = append(samples, sample{at: at, value: v})
+			i := 0
+			for i < len(samples)-1 && samples[i].at < cutoff {
+				i++
+			}
+			st.samples[key] = samples[i:]
+		}
+	}
+	for key := range st.samples {
+		if !seen[key] {
+			delete(st.samples, key)
+		}
+	}
+	st.evaluated = true
+
+	active := make(map[string]bool, len(st.firing))
+	var fired []*models.Alert
+	for _, r := range d.rules {
+		for name, v := range values[r.Target] {
+			if r.match != nil && !r.match.MatchString(name) {
+				continue
+			}
+			base := baseline(st.samples[r.Target+"\x00"+name], at-int64(r.Window))
+			reason := r.check(v, base)
+			if reason == "" {
+				continue
+			}
+			key := r.Name + "\x00" + name
+			active[key] = true
+
+			a := st.firing[key]
+			if a == nil {
+				a = &models.Alert{
+					Rule:      r.Name,
+					Target:    r.Target,
+					Name:      name,
+					Tenant:    tid,
+					Severity:  r.Severity,
+					State:     models.AlertFiring,
+					Window:    models.FormatWindow(r.Window),
+					StartedAt: now,
+				}
+				st.firing[key] = a
+				fired = append(fired, a)
+			}
+			a.Value, a.Growth, a.Peak = v, v-base, max(a.Peak, v)
+			a.Summary = describe(r.Target, name, v) + " " + reason
+			a.UpdatedAt = now
+		}
+	}
+	for _, a := range fired {
+		d.notifier.notify(models.AlertFiring, *a)
+	}
+
+	for key, a := range st.firing {
+		if active[key] {
+			continue
+		}
+		delete(st.firing, key)
+		resolvedAt := now
+		a.State = models.AlertResolved
+		a.ResolvedAt = &resolvedAt
+		a.UpdatedAt = now
+		a.Summary = fmt.Sprintf("%s is back within the %s limits (peak %d)", subject(a.Target, a.Name), a.Rule, a.Peak)
+		st.resolved = append(st.resolved, a)
+		if len(st.resolved) > maxResolved {
+			st.resolved = st.resolved[len(st.resolved)-maxResolved:]
+		}
+		d.notifier.notify(models.AlertResolved, *a)
+	}
+}
+
+// baseline returns the oldest value at or after since, the start of a
+// growth window. The latest sample is always recent enough.
+func baseline(samples []sample, since int64) int64 {
+	for _, s := range samples {
+		if s.at >= since {
+			return s.value
+		}
+	}
+	return 0
+}
+
+// check returns why r fires for value v that was base at the start of the
+// window, or "" if it does not.
+func (r *compiledRule) check(v, base int64) string {
+	growth := v - base
+	window := models.FormatWindow(r.Window)
+	switch {
+	case r.Threshold > 0 && v >= r.Threshold:
+		return fmt.Sprintf("(threshold %d)", r.Threshold)
+	case r.Growth > 0 && growth >= r.Growth:
+		return fmt.Sprintf("(+%d in %s, limit +%d)", growth, window, r.Growth)
+	case r.GrowthPercent > 0 && growth > 0 && v >= r.MinValue:
+		pct := math.Inf(1)
+		if base > 0 {
+			pct = float64(growth) / float64(base) * 100
+		}
+		if pct >= r.GrowthPercent {
+			if base == 0 {
+				return fmt.Sprintf("(new in the last %s, limit +%g%%)", window, r.GrowthPercent)
+			}
+			return fmt.Sprintf("(+%.0f%% in %s, limit +%g%%)", pct, window, r.GrowthPercent)
+		}
+	}
+	return ""
+}
+
+func subject(target, name string) string {
+	switch target {
+	case models.AlertTargetMetricSeries:
+		return "metric " + name
+	case models.AlertTargetAttributeCardinality:
+		return "attribute " + name
+	}
+	return "service " + name
+}
+
+func describe(target, name string, v int64) string {
+	switch target {
+	case models.AlertTargetMetricSeries:
+		return fmt.Sprintf("%s has %d active series", subject(target, name), v)
+	case models.AlertTargetAttributeCardinality:
+		return fmt.Sprintf("%s has %d unique values", subject(target, name), v)
+	}
+	return fmt.Sprintf("%s has %d log templates", subject(target, name), v)
+}
+
+// Alerts returns the alerts of the tenant of ctx: firing ones, oldest
+// first, then the most recently resolved ones, newest first.
+func (d *Detector) Alerts(ctx context.Context) []models.Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := d.tenants[tenantID(ctx)]
+	if st == nil {
+		return []models.Alert{}
+	}
+
+	out := make([]models.Alert, 0, len(st.firing)+len(st.resolved))
+	for _, a := range st.firing {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].StartedAt.Before(out[j].StartedAt)
+		}
+		return out[i].Rule+out[i].Name < out[j].Rule+out[j].Name
+	})
+	for i := len(st.resolved) - 1; i >= 0; i-- {
+		out = append(out, *st.resolved[i])
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// queueSize bounds the notifications waiting for delivery; more are dropped
+// rather than stalling evaluation behind a slow webhook.
+const queueSize = 256
+
+// maxAttempts per webhook and notification, for network errors, 429 and 5xx.
+const maxAttempts = 3
+
+// retryDelay is doubled after every failed attempt. Tests shorten it.
+var retryDelay = time.Second
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Notification is one alert state change, as seen by webhook templates.
+type Notification struct {
+	Status string // firing or resolved
+	Alert  models.Alert
+}
+
+// text is the one-line message of the Slack and Teams payloads.
+func (n Notification) text() string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(n.Status), n.Alert.Rule, n.Alert.Summary)
+}
+
+// notifier delivers notifications to every webhook in the order they were
+// queued, so a resolve never overtakes its firing notification.
+type notifier struct {
+	webhooks []*compiledWebhook
+	client   *http.Client
+	queue    chan Notification
+	done     chan struct{}
+}
+
+func newNotifier(webhooks []*compiledWebhook) *notifier {
+	n := &notifier{
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		queue:    make(chan Notification, queueSize),
+		done:     make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+func (n *notifier) notify(status string, a models.Alert) {
+	if len(n.webhooks) == 0 {
+		return
+	}
+	select {
+	case n.queue <- Notification{Status: status, Alert: a}:
+	default:
+		log.Printf("Alert notification queue full, dropping %s notification for %s %q", status, a.Rule, a.Name)
+	}
+}
+
+func (n *notifier) run() {
+	defer close(n.done)
+	for nt := range n.queue {
+		for _, w := range n.webhooks {
+			if err := n.deliver(w, nt); err != nil {
+				log.Printf("Alert webhook %s: %v", w.Name, err)
+			}
+		}
+	}
+}
+
+// close delivers the queued notifications and stops the notifier.
+func (n *notifier) close() {
+	close(n.queue)
+	<-n.done
+}
+
+func (n *notifier) deliver(w *compiledWebhook, nt Notification) error {
+	body, err := render(w, nt)
+	if err != nil {
+		return err
+	}
+
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err = n.post(w, body)
+		if err == nil {
+			return nil
+		}
+		if attempt == maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// permanentError is a response that retrying will not change.
+type permanentError struct{ status int }
+
+func (e permanentError) Error() string {
+	return fmt.Sprintf("webhook returned %d", e.status)
+}
+
+func (n *notifier) post(w *compiledWebhook, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return permanentError{status: resp.StatusCode}
+}
+
+// render builds the request body of w for nt.
+func render(w *compiledWebhook, nt Notification) ([]byte, error) {
+	if w.tmpl != nil {
+		var b bytes.Buffer
+		if err := w.tmpl.Execute(&b, nt); err != nil {
+			return nil, fmt.Errorf("rendering template: %w", err)
+		}
+		return b.Bytes(), nil
+	}
+
+	switch w.Format {
+	case "slack":
+		return json.Marshal(map[string]string{"text": nt.text()})
+	case "teams":
+		color := "D93F0B"
+		if nt.Status == models.AlertResolved {
+			color = "2DC72D"
+		}
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"themeColor": color,
+			"summary":    nt.text(),
+			"title":      fmt.Sprintf("[%s] %s", strings.ToUpper(nt.Status), nt.Alert.Rule),
+			"text":       nt.Alert.Summary,
+		})
+	}
+	return json.Marshal(struct {
+		Status string       `json:"status"`
+		Alert  models.Alert `json:"alert"`
+	}{nt.Status, nt.Alert})
+}
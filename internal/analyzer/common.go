@@ -37,6 +37,11 @@ func getServiceName(attrs map[string]string, labels ...string) string {
 	return "unknown"
 }
 
+// ServiceName returns the service a resource's data is attributed to.
+func ServiceName(resourceAttrs map[string]string) string {
+	return getServiceName(resourceAttrs)
+}
+
 // extractAttributesToCatalog extracts all attributes and stores them in the catalog.
 // This feeds the global attribute catalog with key-value pairs from telemetry data.
 func extractAttributesToCatalog(ctx context.Context, catalog AttributeCatalog, attrs map[string]string, signalType, scope string) {
@@ -45,6 +45,15 @@ func (a *LogsAnalyzer) SetPodLogEnrichment(enabled bool, labels []string) {
 	a.podLogServiceLabels = labels
 }
 
+// ServiceName returns the service a resource's logs are attributed to. With
+// pod log enrichment on, the configured labels are tried after service.name.
+func (a *LogsAnalyzer) ServiceName(resourceAttrs map[string]string) string {
+	if a.podLogEnrichment {
+		return getServiceName(resourceAttrs, a.podLogServiceLabels...)
+	}
+	return getServiceName(resourceAttrs)
+}
+
 // inferSeverityFromBody scans a log body for level keywords and returns a
 // normalised severity string. Returns "UNSET" when no keyword is recognised.
 // Patterns are evaluated in priority order: ERROR > WARN > INFO > DEBUG.
@@ -121,12 +130,7 @@ func (a *LogsAnalyzer) AnalyzeWithContext(ctx context.Context, req *collogspb.Ex
 		// Extract resource attributes
 		resourceAttrs := extractAttributes(resourceLogs.Resource.GetAttributes())
 
-		var serviceName string
-		if a.podLogEnrichment {
-			serviceName = getServiceName(resourceAttrs, a.podLogServiceLabels...)
-		} else {
-			serviceName = getServiceName(resourceAttrs)
-		}
+		serviceName := a.ServiceName(resourceAttrs)
 
 		// Feed resource attributes to catalog
 		extractAttributesToCatalog(ctx, batch, resourceAttrs, "log", "resource")
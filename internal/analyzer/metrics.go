@@ -89,11 +89,15 @@ func (a *MetricsAnalyzer) analyzeMetricWithContext(
 			IsMonotonic:           data.Sum.IsMonotonic,
 		}
 	case *metricspb.Metric_Histogram:
-		metricData = &models.HistogramMetric{
+		hist := &models.HistogramMetric{
 			DataPointCount:         int64(len(data.Histogram.DataPoints)),
 			AggregationTemporality: models.AggregationTemporality(data.Histogram.AggregationTemporality),
 			ExplicitBounds:         extractUniqueBounds(data.Histogram.DataPoints),
 		}
+		for _, dp := range data.Histogram.DataPoints {
+			hist.AddBucketLayout(dp.ExplicitBounds, serviceName, 1)
+		}
+		metricData = hist
 	case *metricspb.Metric_ExponentialHistogram:
 		metricData = &models.ExponentialHistogramMetric{
 			DataPointCount:         int64(len(data.ExponentialHistogram.DataPoints)),
@@ -156,11 +160,12 @@ func (a *MetricsAnalyzer) extractGaugeKeys(ctx context.Context, catalog Attribut
 			metadata.Services[serviceName]++
 		}
 
+		trackExemplars(metadata, dp.Exemplars)
+
 		attrs := extractAttributes(dp.Attributes)
 		
-		// Track unique series combination (resource attrs included for correct identity)
-		fingerprint := models.CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
-		metadata.AddSeriesFingerprint(fingerprint)
+		// Track the series and its per-label contribution (resource attrs included for correct identity)
+		metadata.AddSeries(resourceAttrs, attrs)
 		
 		for key, value := range attrs {
 			_ = catalog.StoreAttributeValue(ctx, key, value, "metric", "attribute")
@@ -171,6 +176,8 @@ func (a *MetricsAnalyzer) extractGaugeKeys(ctx context.Context, catalog Attribut
 		}
 	}
 
+	metadata.Exemplars.Update(metadata.SampleCount)
+
 	// Update percentages for label keys
 	for _, keyMeta := range metadata.LabelKeys {
 		keyMeta.UpdatePercentage(metadata.SampleCount)
@@ -191,11 +198,12 @@ func (a *MetricsAnalyzer) extractSumKeys(ctx context.Context, catalog AttributeC
 			metadata.Services[serviceName]++
 		}
 
+		trackExemplars(metadata, dp.Exemplars)
+
 		attrs := extractAttributes(dp.Attributes)
 		
-		// Track unique series combination (resource attrs included for correct identity)
-		fingerprint := models.CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
-		metadata.AddSeriesFingerprint(fingerprint)
+		// Track the series and its per-label contribution (resource attrs included for correct identity)
+		metadata.AddSeries(resourceAttrs, attrs)
 		
 		for key, value := range attrs {
 			_ = catalog.StoreAttributeValue(ctx, key, value, "metric", "attribute")
@@ -206,6 +214,8 @@ func (a *MetricsAnalyzer) extractSumKeys(ctx context.Context, catalog AttributeC
 		}
 	}
 
+	metadata.Exemplars.Update(metadata.SampleCount)
+
 	// Update percentages for label keys
 	for _, keyMeta := range metadata.LabelKeys {
 		keyMeta.UpdatePercentage(metadata.SampleCount)
@@ -226,11 +236,12 @@ func (a *MetricsAnalyzer) extractHistogramKeys(ctx context.Context, catalog Attr
 			metadata.Services[serviceName]++
 		}
 
+		trackExemplars(metadata, dp.Exemplars)
+
 		attrs := extractAttributes(dp.Attributes)
 		
-		// Track unique series combination (resource attrs included for correct identity)
-		fingerprint := models.CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
-		metadata.AddSeriesFingerprint(fingerprint)
+		// Track the series and its per-label contribution (resource attrs included for correct identity)
+		metadata.AddSeries(resourceAttrs, attrs)
 		
 		for key, value := range attrs {
 			_ = catalog.StoreAttributeValue(ctx, key, value, "metric", "attribute")
@@ -241,6 +252,8 @@ func (a *MetricsAnalyzer) extractHistogramKeys(ctx context.Context, catalog Attr
 		}
 	}
 
+	metadata.Exemplars.Update(metadata.SampleCount)
+
 	// Update percentages for label keys
 	for _, keyMeta := range metadata.LabelKeys {
 		keyMeta.UpdatePercentage(metadata.SampleCount)
@@ -261,11 +274,12 @@ func (a *MetricsAnalyzer) extractExponentialHistogramKeys(ctx context.Context, c
 			metadata.Services[serviceName]++
 		}
 
+		trackExemplars(metadata, dp.Exemplars)
+
 		attrs := extractAttributes(dp.Attributes)
 		
-		// Track unique series combination (resource attrs included for correct identity)
-		fingerprint := models.CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
-		metadata.AddSeriesFingerprint(fingerprint)
+		// Track the series and its per-label contribution (resource attrs included for correct identity)
+		metadata.AddSeries(resourceAttrs, attrs)
 		
 		for key, value := range attrs {
 			_ = catalog.StoreAttributeValue(ctx, key, value, "metric", "attribute")
@@ -276,6 +290,8 @@ func (a *MetricsAnalyzer) extractExponentialHistogramKeys(ctx context.Context, c
 		}
 	}
 
+	metadata.Exemplars.Update(metadata.SampleCount)
+
 	// Update percentages for label keys
 	for _, keyMeta := range metadata.LabelKeys {
 		keyMeta.UpdatePercentage(metadata.SampleCount)
@@ -298,9 +314,8 @@ func (a *MetricsAnalyzer) extractSummaryKeys(ctx context.Context, catalog Attrib
 
 		attrs := extractAttributes(dp.Attributes)
 		
-		// Track unique series combination (resource attrs included for correct identity)
-		fingerprint := models.CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
-		metadata.AddSeriesFingerprint(fingerprint)
+		// Track the series and its per-label contribution (resource attrs included for correct identity)
+		metadata.AddSeries(resourceAttrs, attrs)
 		
 		for key, value := range attrs {
 			_ = catalog.StoreAttributeValue(ctx, key, value, "metric", "attribute")
@@ -323,6 +338,30 @@ func (a *MetricsAnalyzer) extractSummaryKeys(ctx context.Context, catalog Attrib
 	}
 }
 
+// trackExemplars records the exemplars attached to one data point.
+func trackExemplars(metadata *models.MetricMetadata, exemplars []*metricspb.Exemplar) {
+	if len(exemplars) == 0 {
+		return
+	}
+	if metadata.Exemplars == nil {
+		metadata.Exemplars = models.NewExemplarStats()
+	}
+	metadata.Exemplars.DataPointsWithExemplars++
+	for _, ex := range exemplars {
+		metadata.Exemplars.AddExemplar(extractAttributes(ex.FilteredAttributes), isSetID(ex.TraceId), isSetID(ex.SpanId))
+	}
+}
+
+// isSetID reports whether a trace or span ID is present and not all zeros.
+func isSetID(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // extractUniqueBounds extracts all unique explicit bounds from histogram data points
 func extractUniqueBounds(dataPoints []*metricspb.HistogramDataPoint) []float64 {
 	boundsSet := make(map[float64]bool)
@@ -375,6 +414,12 @@ func extractUniqueScales(dataPoints []*metricspb.ExponentialHistogramDataPoint)
 	return scales
 }
 
+// Attributes converts OTLP attributes to the string map the analyzers
+// work on.
+func Attributes(attrs []*commonpb.KeyValue) map[string]string {
+	return extractAttributes(attrs)
+}
+
 // extractAttributes converts OTLP KeyValue attributes to a map.
 func extractAttributes(attrs []*commonpb.KeyValue) map[string]string {
 	result := make(map[string]string, len(attrs))
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// testExemplarRequest builds a sum with four data points, two of which
+// carry exemplars: one linked to a trace, one with a zeroed trace ID.
+func testExemplarRequest(userIDs ...string) *colmetricspb.ExportMetricsServiceRequest {
+	traceID := []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	var exemplars []*metricspb.Exemplar
+	for _, id := range userIDs {
+		exemplars = append(exemplars, &metricspb.Exemplar{
+			FilteredAttributes: []*commonpb.KeyValue{stringAttr("user.id", id)},
+			TraceId:            traceID,
+			SpanId:             spanID,
+		})
+	}
+	points := []*metricspb.NumberDataPoint{
+		{Attributes: []*commonpb.KeyValue{stringAttr("route", "/a")}, Exemplars: exemplars},
+		{Attributes: []*commonpb.KeyValue{stringAttr("route", "/b")}, Exemplars: []*metricspb.Exemplar{
+			{TraceId: make([]byte, 16)},
+		}},
+		{Attributes: []*commonpb.KeyValue{stringAttr("route", "/c")}},
+		{Attributes: []*commonpb.KeyValue{stringAttr("route", "/d")}},
+	}
+
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", "api")}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{{
+					Name: "http.server.requests",
+					Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{DataPoints: points}},
+				}},
+			}},
+		}},
+	}
+}
+
+func TestMetricsAnalyzer_Exemplars(t *testing.T) {
+	a := NewMetricsAnalyzerWithCatalog(nil)
+	results, err := a.Analyze(testExemplarRequest("u1", "u2", "u3"))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(results))
+	}
+	ex := results[0].Exemplars
+	if ex == nil {
+		t.Fatal("expected exemplar stats")
+	}
+	if ex.DataPointsWithExemplars != 2 || ex.Fraction != 0.5 {
+		t.Errorf("data points with exemplars = %d (fraction %v), want 2 (0.5)", ex.DataPointsWithExemplars, ex.Fraction)
+	}
+	if ex.ExemplarCount != 4 || ex.WithTraceID != 3 || ex.WithSpanID != 3 {
+		t.Errorf("exemplars = %d, with trace ID = %d, with span ID = %d, want 4, 3, 3", ex.ExemplarCount, ex.WithTraceID, ex.WithSpanID)
+	}
+	userID, ok := ex.FilteredAttributeKeys["user.id"]
+	if !ok {
+		t.Fatalf("expected filtered attribute user.id, got %v", ex.FilteredAttributeKeys)
+	}
+	if userID.Cardinality() != 3 || userID.Count != 3 || userID.Percentage != 75 {
+		t.Errorf("user.id cardinality = %d, count = %d, percentage = %v", userID.Cardinality(), userID.Count, userID.Percentage)
+	}
+
+	// Merging another batch must recompute the fraction over both.
+	more, err := a.Analyze(testExemplarRequest("u4"))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	results[0].MergeMetricMetadata(more[0])
+	if ex.DataPointsWithExemplars != 4 || ex.ExemplarCount != 6 || ex.Fraction != 0.5 {
+		t.Errorf("after merge: data points = %d, exemplars = %d, fraction = %v", ex.DataPointsWithExemplars, ex.ExemplarCount, ex.Fraction)
+	}
+}
+
+func TestMetricsAnalyzer_NoExemplars(t *testing.T) {
+	req := testExemplarRequest()
+	for _, dp := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetSum().DataPoints {
+		dp.Exemplars = nil
+	}
+	results, err := NewMetricsAnalyzerWithCatalog(nil).Analyze(req)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if results[0].Exemplars != nil {
+		t.Errorf("expected no exemplar stats, got %+v", results[0].Exemplars)
+	}
+}
+
+func TestMetricsAnalyzer_HistogramBucketLayouts(t *testing.T) {
+	req := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", "api")}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{{
+					Name: "http.server.duration",
+					Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{
+						{Attributes: []*commonpb.KeyValue{stringAttr("route", "/a")}, ExplicitBounds: []float64{1, 10}},
+						{Attributes: []*commonpb.KeyValue{stringAttr("route", "/b")}, ExplicitBounds: []float64{1, 10}},
+						{Attributes: []*commonpb.KeyValue{stringAttr("route", "/c")}, ExplicitBounds: []float64{5, 50, 500}},
+					}}},
+				}},
+			}},
+		}},
+	}
+
+	results, err := NewMetricsAnalyzerWithCatalog(nil).Analyze(req)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	hist := results[0].Data.(*models.HistogramMetric)
+	if len(hist.ExplicitBounds) != 5 {
+		t.Errorf("explicit bounds = %v, want the union of both layouts", hist.ExplicitBounds)
+	}
+	if len(hist.BucketLayouts) != 2 {
+		t.Fatalf("bucket layouts = %d, want 2", len(hist.BucketLayouts))
+	}
+	if l := hist.BucketLayouts[0]; len(l.Bounds) != 2 || l.DataPoints != 2 || l.Services["api"] != 2 {
+		t.Errorf("first layout = %+v", l)
+	}
+}
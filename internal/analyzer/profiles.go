@@ -0,0 +1,204 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// ProfilesAnalyzer extracts metadata from OTLP profiles.
+type ProfilesAnalyzer struct {
+	catalog AttributeCatalog
+}
+
+// NewProfilesAnalyzerWithCatalog creates a new profiles analyzer with attribute catalog.
+func NewProfilesAnalyzerWithCatalog(catalog AttributeCatalog) *ProfilesAnalyzer {
+	return &ProfilesAnalyzer{catalog: catalog}
+}
+
+// Analyze extracts metadata from an OTLP profiles export request.
+func (a *ProfilesAnalyzer) Analyze(req *profilespb.ExportProfilesServiceRequest) ([]*models.ProfileMetadata, error) {
+	return a.AnalyzeWithContext(context.Background(), req)
+}
+
+// AnalyzeWithContext extracts metadata with context for attribute catalog.
+//
+// Strings, attributes, locations and mappings are referenced by index into
+// the request dictionary; out-of-range indices are skipped rather than
+// rejected so one malformed profile does not drop the whole batch.
+func (a *ProfilesAnalyzer) AnalyzeWithContext(ctx context.Context, req *profilespb.ExportProfilesServiceRequest) ([]*models.ProfileMetadata, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	dict := req.GetDictionary()
+	profileMap := make(map[string]*models.ProfileMetadata)
+
+	// Batch catalog deduplicates writes within this request.
+	batch := newBatchCatalog(a.catalog)
+
+	for _, resourceProfiles := range req.ResourceProfiles {
+		resourceAttrs := extractAttributes(resourceProfiles.GetResource().GetAttributes())
+		serviceName := getServiceName(resourceAttrs)
+
+		extractAttributesToCatalog(ctx, batch, resourceAttrs, models.SignalTypeProfile, "resource")
+
+		for _, scopeProfiles := range resourceProfiles.ScopeProfiles {
+			scopeInfo := &models.ScopeMetadata{
+				Name:    scopeProfiles.GetScope().GetName(),
+				Version: scopeProfiles.GetScope().GetVersion(),
+			}
+
+			for _, profile := range scopeProfiles.Profiles {
+				// A profile contributes to every sample type it carries.
+				var targets []*models.ProfileMetadata
+				for _, vt := range profile.SampleType {
+					sampleType := dict.StringAt(vt.TypeStrindex)
+					if sampleType == "" {
+						sampleType = "unknown"
+					}
+					sampleUnit := dict.StringAt(vt.UnitStrindex)
+					key := models.ProfileID(sampleType, sampleUnit)
+
+					metadata, exists := profileMap[key]
+					if !exists {
+						metadata = models.NewProfileMetadata(sampleType, sampleUnit)
+						metadata.ScopeInfo = scopeInfo
+						profileMap[key] = metadata
+					}
+					if metadata.PeriodType == "" && profile.PeriodType != nil {
+						metadata.PeriodType = models.ProfileID(
+							dict.StringAt(profile.PeriodType.TypeStrindex),
+							dict.StringAt(profile.PeriodType.UnitStrindex),
+						)
+					}
+					for resKey := range resourceAttrs {
+						if metadata.ResourceKeys[resKey] == nil {
+							metadata.ResourceKeys[resKey] = models.NewKeyMetadata()
+						}
+					}
+					metadata.ProfileCount++
+					targets = append(targets, metadata)
+				}
+				if len(targets) == 0 {
+					continue
+				}
+
+				profileAttrs := resolveAttributes(dict, profile.AttributeIndices)
+
+				for _, sample := range profile.Sample {
+					sampleAttrs := resolveAttributes(dict, sample.AttributeIndices)
+					locations := sampleLocations(dict, profile, sample)
+
+					for _, metadata := range targets {
+						metadata.SampleCount++
+						if serviceName != "" {
+							metadata.Services[serviceName]++
+						}
+						if len(locations) > metadata.MaxStackDepth {
+							metadata.MaxStackDepth = len(locations)
+						}
+
+						for resKey, resValue := range resourceAttrs {
+							metadata.ResourceKeys[resKey].AddValue(resValue)
+						}
+
+						// Profile-level attributes apply to every sample.
+						for _, attrs := range [][][2]string{profileAttrs, sampleAttrs} {
+							for _, kv := range attrs {
+								_ = batch.StoreAttributeValue(ctx, kv[0], kv[1], models.SignalTypeProfile, "attribute")
+								addKeyValue(metadata.AttributeKeys, kv[0], kv[1])
+							}
+						}
+
+						for _, loc := range locations {
+							for _, kv := range resolveAttributes(dict, loc.AttributeIndices) {
+								addKeyValue(metadata.LocationAttributeKeys, kv[0], kv[1])
+							}
+							if loc.MappingIndex == nil {
+								continue
+							}
+							mapping := dict.MappingAt(*loc.MappingIndex)
+							if mapping == nil {
+								continue
+							}
+							for _, kv := range resolveAttributes(dict, mapping.AttributeIndices) {
+								addKeyValue(metadata.MappingAttributeKeys, kv[0], kv[1])
+							}
+							if filename := dict.StringAt(mapping.FilenameStrindex); filename != "" {
+								if metadata.MappingFiles == nil {
+									metadata.MappingFiles = models.NewKeyMetadata()
+								}
+								metadata.MappingFiles.AddValue(filename)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	results := make([]*models.ProfileMetadata, 0, len(profileMap))
+	for _, metadata := range profileMap {
+		if metadata.SampleCount > 0 {
+			for _, keyMeta := range metadata.AttributeKeys {
+				keyMeta.Percentage = float64(keyMeta.Count) / float64(metadata.SampleCount) * 100
+			}
+			for _, keyMeta := range metadata.ResourceKeys {
+				keyMeta.Percentage = float64(keyMeta.Count) / float64(metadata.SampleCount) * 100
+			}
+		}
+		results = append(results, metadata)
+	}
+
+	return results, nil
+}
+
+// resolveAttributes looks up attribute table indices and returns key/value
+// pairs, skipping indices outside the table.
+func resolveAttributes(dict *profilespb.ProfilesDictionary, indices []int32) [][2]string {
+	if len(indices) == 0 {
+		return nil
+	}
+	attrs := make([][2]string, 0, len(indices))
+	for _, idx := range indices {
+		kv := dict.AttributeAt(idx)
+		if kv == nil || kv.Key == "" {
+			continue
+		}
+		attrs = append(attrs, [2]string{kv.Key, attributeValueToString(kv.Value)})
+	}
+	return attrs
+}
+
+// sampleLocations returns the stack of a sample, leaf first. The sample
+// references a window of Profile.location_indices, which in turn index the
+// dictionary location table.
+func sampleLocations(dict *profilespb.ProfilesDictionary, profile *profilespb.Profile, sample *profilespb.Sample) []*profilespb.Location {
+	start, length := int(sample.LocationsStartIndex), int(sample.LocationsLength)
+	if start < 0 || length <= 0 || start >= len(profile.LocationIndices) {
+		return nil
+	}
+	end := start + length
+	if end > len(profile.LocationIndices) {
+		end = len(profile.LocationIndices)
+	}
+
+	locations := make([]*profilespb.Location, 0, end-start)
+	for _, idx := range profile.LocationIndices[start:end] {
+		if loc := dict.LocationAt(idx); loc != nil {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// addKeyValue records value under key, creating the key metadata on first use.
+func addKeyValue(keys map[string]*models.KeyMetadata, key, value string) {
+	if keys[key] == nil {
+		keys[key] = models.NewKeyMetadata()
+	}
+	keys[key].AddValue(value)
+}
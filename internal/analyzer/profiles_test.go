@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func int32Ptr(v int32) *int32 { return &v }
+
+// testProfilesRequest builds a request with one CPU profile holding two
+// samples: one with a two-frame stack inside libc, one with an
+// out-of-range location window.
+func testProfilesRequest() *profilespb.ExportProfilesServiceRequest {
+	return &profilespb.ExportProfilesServiceRequest{
+		Dictionary: &profilespb.ProfilesDictionary{
+			StringTable: []string{"", "cpu", "nanoseconds", "libc.so.6"},
+			AttributeTable: []*commonpb.KeyValue{
+				stringAttr("thread.name", "worker-1"),
+				stringAttr("thread.name", "worker-2"),
+				stringAttr("process.executable.name", "api"),
+				stringAttr("profile.frame.type", "native"),
+				stringAttr("build.id", "abc123"),
+			},
+			MappingTable: []*profilespb.Mapping{
+				{FilenameStrindex: 3, AttributeIndices: []int32{4}},
+			},
+			LocationTable: []*profilespb.Location{
+				{MappingIndex: int32Ptr(0), AttributeIndices: []int32{3}},
+				{MappingIndex: int32Ptr(0)},
+			},
+		},
+		ResourceProfiles: []*profilespb.ResourceProfiles{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				stringAttr("service.name", "checkout"),
+			}},
+			ScopeProfiles: []*profilespb.ScopeProfiles{{
+				Scope: &commonpb.InstrumentationScope{Name: "ebpf-profiler"},
+				Profiles: []*profilespb.Profile{{
+					SampleType:       []*profilespb.ValueType{{TypeStrindex: 1, UnitStrindex: 2}},
+					PeriodType:       &profilespb.ValueType{TypeStrindex: 1, UnitStrindex: 2},
+					LocationIndices:  []int32{0, 1},
+					AttributeIndices: []int32{2},
+					Sample: []*profilespb.Sample{
+						{LocationsStartIndex: 0, LocationsLength: 2, Value: []int64{10}, AttributeIndices: []int32{0}},
+						{LocationsStartIndex: 5, LocationsLength: 1, Value: []int64{20}, AttributeIndices: []int32{1, 99}},
+					},
+				}},
+			}},
+		}},
+	}
+}
+
+func TestProfilesAnalyzer_Analyze(t *testing.T) {
+	a := NewProfilesAnalyzerWithCatalog(nil)
+	results, err := a.Analyze(testProfilesRequest())
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 sample type, got %d", len(results))
+	}
+
+	p := results[0]
+	if p.ID != "cpu:nanoseconds" || p.PeriodType != "cpu:nanoseconds" {
+		t.Errorf("ID/PeriodType = %q/%q", p.ID, p.PeriodType)
+	}
+	if p.ProfileCount != 1 || p.SampleCount != 2 {
+		t.Errorf("ProfileCount/SampleCount = %d/%d, want 1/2", p.ProfileCount, p.SampleCount)
+	}
+	if p.MaxStackDepth != 2 {
+		t.Errorf("MaxStackDepth = %d, want 2", p.MaxStackDepth)
+	}
+	if p.Services["checkout"] != 2 {
+		t.Errorf("Services = %v", p.Services)
+	}
+	if p.ScopeInfo == nil || p.ScopeInfo.Name != "ebpf-profiler" {
+		t.Errorf("ScopeInfo = %+v", p.ScopeInfo)
+	}
+
+	thread := p.AttributeKeys["thread.name"]
+	if thread == nil || thread.Cardinality() != 2 {
+		t.Errorf("thread.name = %+v, want 2 unique values", thread)
+	}
+	if exe := p.AttributeKeys["process.executable.name"]; exe == nil || exe.Count != 2 {
+		t.Errorf("profile attributes must apply to every sample, got %+v", exe)
+	}
+	if _, ok := p.ResourceKeys["service.name"]; !ok {
+		t.Errorf("ResourceKeys = %v", p.ResourceKeys)
+	}
+	if _, ok := p.LocationAttributeKeys["profile.frame.type"]; !ok {
+		t.Errorf("LocationAttributeKeys = %v", p.LocationAttributeKeys)
+	}
+	if _, ok := p.MappingAttributeKeys["build.id"]; !ok {
+		t.Errorf("MappingAttributeKeys = %v", p.MappingAttributeKeys)
+	}
+	if p.MappingFiles == nil || p.MappingFiles.Cardinality() != 1 {
+		t.Errorf("MappingFiles = %+v", p.MappingFiles)
+	}
+}
+
+func TestProfilesAnalyzer_NilRequest(t *testing.T) {
+	if _, err := NewProfilesAnalyzerWithCatalog(nil).Analyze(nil); err == nil {
+		t.Error("expected error for nil request")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
@@ -32,6 +33,13 @@ func (a *TracesAnalyzer) Analyze(req *coltracepb.ExportTraceServiceRequest) ([]*
 
 // AnalyzeWithContext extracts metadata with context for attribute catalog.
 func (a *TracesAnalyzer) AnalyzeWithContext(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) ([]*models.SpanMetadata, error) {
+	return a.AnalyzeWithSpanMetrics(ctx, req, nil)
+}
+
+// AnalyzeWithSpanMetrics is AnalyzeWithContext that also feeds every span
+// to rec. The caller resolves rec for the request's tenant, since analysis
+// may run on a pipeline worker whose context carries none.
+func (a *TracesAnalyzer) AnalyzeWithSpanMetrics(ctx context.Context, req *coltracepb.ExportTraceServiceRequest, rec *spanmetrics.Recorder) ([]*models.SpanMetadata, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request cannot be nil")
 	}
@@ -41,6 +49,12 @@ func (a *TracesAnalyzer) AnalyzeWithContext(ctx context.Context, req *coltracepb
 	// Batch catalog deduplicates writes within this request.
 	batch := newBatchCatalog(a.catalog)
 
+	// Span attributes are only collected for the spanmetrics estimate.
+	var spanAttrs map[string]string
+	if rec != nil {
+		spanAttrs = make(map[string]string)
+	}
+
 	for _, resourceSpans := range req.ResourceSpans {
 		// Extract resource attributes
 		resourceAttrs := extractAttributes(resourceSpans.Resource.GetAttributes())
@@ -161,7 +175,15 @@ func (a *TracesAnalyzer) AnalyzeWithContext(ctx context.Context, req *coltracepb
 					metadata.AttributeKeys[attrKey] = models.NewKeyMetadata()
 				}
 				metadata.AttributeKeys[attrKey].AddValue(attrValue)
-			})				// Extract event names and attributes
+				if spanAttrs != nil {
+					spanAttrs[attrKey] = attrValue
+				}
+			})
+			if rec != nil {
+				rec.Span(serviceName, resourceAttrs, spanAttrs, span)
+				clear(spanAttrs)
+			}
+				// Extract event names and attributes
 				for _, event := range span.Events {
 					// Track event name
 					found := false
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// listAlerts lists the firing and recently resolved cardinality alerts.
+// GET /api/v1/alerts
+func (s *Server) listAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts := s.alerts.Alerts(r.Context())
+	firing := 0
+	for _, a := range alerts {
+		if a.State == models.AlertFiring {
+			firing++
+		}
+	}
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"alerts": alerts,
+		"firing": firing,
+		"total":  len(alerts),
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/alert"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestListAlerts(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewStorage(storage.DefaultConfig())
+	d, err := alert.New(&alert.Config{Rules: []alert.Rule{
+		{Name: "too_many_series", Target: models.AlertTargetMetricSeries, Threshold: 5},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	s := NewServer(":0", store, ServerOptions{DisableUI: true, Alerts: d})
+
+	m := models.NewMetricMetadata("http_requests", &models.SumMetric{})
+	for i := 0; i < 10; i++ {
+		m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+	}
+	if err := store.StoreMetric(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	d.Evaluate(ctx, store, time.Now())
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Alerts []models.Alert `json:"alerts"`
+		Firing int            `json:"firing"`
+		Total  int            `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Firing != 1 || resp.Total != 1 || resp.Alerts[0].Name != "http_requests" || resp.Alerts[0].Value != 10 {
+		t.Errorf("alerts = %+v, want http_requests firing at 10 series", resp)
+	}
+
+	// Without a detector the route does not exist.
+	w = httptest.NewRecorder()
+	NewServer(":0", store, ServerOptions{DisableUI: true}).router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/alerts", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("without detector: status %d, want 404", w.Code)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
 	"github.com/fidde/otlp_cardinality_checker/internal/version"
 )
 
@@ -16,6 +17,8 @@ type HealthResponse struct {
 	Version   string    `json:"version,omitempty"`
 	Uptime    string    `json:"uptime,omitempty"`
 	Memory    *MemoryStats `json:"memory,omitempty"`
+	// Ingest reports the ingestion queues when the pipeline is enabled.
+	Ingest map[ingest.Signal]ingest.QueueStats `json:"ingest,omitempty"`
 }
 
 // MemoryStats represents memory usage statistics
@@ -44,6 +47,7 @@ func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
 			SysMB:        m.Sys / 1024 / 1024,
 			NumGC:        m.NumGC,
 		},
+		Ingest: s.ingest.Stats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
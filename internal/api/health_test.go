@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+func TestHandleHealth_ReportsIngestQueues(t *testing.T) {
+	pipeline := ingest.New(ingest.Config{QueueSize: 7})
+	defer pipeline.Shutdown(context.Background())
+	s := NewServer(":0", storage.NewStorage(storage.DefaultConfig()), ServerOptions{DisableUI: true, Ingest: pipeline})
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Ingest[ingest.SignalLogs].Capacity; got != 7 {
+		t.Errorf("logs queue capacity = %d, want 7", got)
+	}
+}
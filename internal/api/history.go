@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// getMetricHistory returns how a metric's active series evolved.
+// GET /api/v1/metrics/{name}/history?range=1h&step=1m
+func (s *Server) getMetricHistory(w http.ResponseWriter, r *http.Request) {
+	s.respondHistory(w, r, models.HistoryMetric, chi.URLParam(r, "name"))
+}
+
+// getAttributeHistory returns how an attribute key's cardinality evolved.
+// GET /api/v1/attributes/{key}/history?range=1h&step=1m
+func (s *Server) getAttributeHistory(w http.ResponseWriter, r *http.Request) {
+	s.respondHistory(w, r, models.HistoryAttribute, chi.URLParam(r, "key"))
+}
+
+// getServiceHistory returns how the active series of a service's metrics
+// evolved.
+// GET /api/v1/services/{name}/history?range=1h&step=1m
+func (s *Server) getServiceHistory(w http.ResponseWriter, r *http.Request) {
+	s.respondHistory(w, r, models.HistoryService, chi.URLParam(r, "name"))
+}
+
+// respondHistory answers a history request.
+// Query parameters:
+//   - range: only samples from this long ago onwards (default: all kept)
+//   - step: reduce to one sample, the last, per step (default: every sample)
+func (s *Server) respondHistory(w http.ResponseWriter, r *http.Request, kind models.HistoryKind, name string) {
+	var since time.Time
+	if v := r.URL.Query().Get("range"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid range %q: must be a positive duration such as 1h", v))
+			return
+		}
+		since = time.Now().Add(-d)
+	}
+	var step time.Duration
+	if v := r.URL.Query().Get("step"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid step %q: must be a positive duration such as 1m", v))
+			return
+		}
+		step = d
+	}
+
+	history := s.store.(storage.HistoryStore).History(r.Context())
+	resp, ok := history.Query(kind, name, since, step)
+	if !ok {
+		s.respondError(w, http.StatusNotFound, fmt.Sprintf("no history for %s %q", kind, name))
+		return
+	}
+	s.respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestHistoryEndpoints(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewStorage(storage.DefaultConfig())
+	s := NewServer(":0", store, ServerOptions{DisableUI: true})
+	hs := store.(storage.HistoryStore)
+
+	// A load test ramping from 10 to 30 pods, sampled each minute.
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		m := models.NewMetricMetadata("http_requests", &models.SumMetric{})
+		m.Services = map[string]int64{"checkout": 1}
+		for p := 0; p < (i+1)*10; p++ {
+			m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", p)})
+		}
+		if err := store.StoreMetric(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+		hs.RecordHistory(ctx, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/metrics/http_requests/history")
+	if w.Code != http.StatusOK {
+		t.Fatalf("metric history: status %d: %s", w.Code, w.Body)
+	}
+	var resp models.HistoryResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Points) != 3 || resp.Points[0].Value != 10 || resp.Growth != 20 {
+		t.Errorf("metric history = %+v, want 10 → 30 series", resp)
+	}
+
+	if w := get("/api/v1/services/checkout/history?step=5m"); w.Code != http.StatusOK {
+		t.Errorf("service history: status %d", w.Code)
+	}
+	if w := get("/api/v1/attributes/pod/history"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown attribute: status %d, want 404", w.Code)
+	}
+	if w := get("/api/v1/metrics/http_requests/history?range=soon"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid range: status %d, want 400", w.Code)
+	}
+	if w := get("/api/v1/metrics/http_requests/history?range=1m"); w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte(`"value"`)) {
+		t.Errorf("range before the samples: status %d, body %s", w.Code, w.Body)
+	}
+
+	// The history survives a session save and load.
+	sessionStore, err := sessions.NewWithConfig(sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10, MaxSessionSize: 10 * 1024 * 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewSessionHandlerWithStore(sessionStore, store.(StoreAccessor))
+	rr := httptest.NewRecorder()
+	h.CreateSession(rr, httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBufferString(`{"name":"load-test"}`)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create session: status %d: %s", rr.Code, rr.Body)
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if hs.History(ctx).Len() != 0 {
+		t.Fatal("history kept after clear")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/load-test/load", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("name", "load-test")
+	rr = httptest.NewRecorder()
+	h.LoadSession(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("load session: status %d: %s", rr.Code, rr.Body)
+	}
+	if got, ok := hs.History(ctx).Query(models.HistoryMetric, "http_requests", time.Time{}, 0); !ok || len(got.Points) != 3 {
+		t.Errorf("restored history = %+v, want 3 samples", got)
+	}
+}
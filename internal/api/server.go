@@ -3,6 +3,7 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -15,8 +16,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/alert"
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/lint"
+	"github.com/fidde/otlp_cardinality_checker/internal/semconv"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 	"github.com/fidde/otlp_cardinality_checker/web"
 	"github.com/go-chi/chi/v5"
@@ -29,6 +38,13 @@ type Server struct {
 	router         *chi.Mux
 	server         *http.Server
 	sessionHandler *SessionHandler
+	auth           *auth.Authenticator
+	ingest         *ingest.Pipeline
+	tenants        *tenant.Registry
+	lintRules      *lint.RuleSet
+	simulations    *simulate.Registry
+	alerts         *alert.Detector
+	spanMetrics    *spanmetrics.Estimator
 }
 
 // dbProvider interface for storage backends that provide direct SQL database access.
@@ -126,6 +142,26 @@ func paginateSlice[T any](items []T, params PaginationParams) ([]T, PaginatedRes
 type ServerOptions struct {
 	// DisableUI skips embedded static file serving (for --minimal mode).
 	DisableUI bool
+	// TLSConfig serves the API over HTTPS when set.
+	TLSConfig *tls.Config
+	// Auth protects /api/v1; nil leaves the API open.
+	Auth *auth.Authenticator
+	// Ingest is the receivers' ingestion pipeline, reported by /health.
+	Ingest *ingest.Pipeline
+	// Tenants scopes every /api/v1 request to the tenant named by the
+	// tenant header; nil serves a single shared store.
+	Tenants *tenant.Registry
+	// LintRules are the naming rules checked by /api/v1/lint/violations.
+	LintRules *lint.RuleSet
+	// Simulations is shared with the receivers, which feed it incoming
+	// data; nil disables /api/v1/simulations.
+	Simulations *simulate.Registry
+	// Alerts is the explosion detector whose alerts /api/v1/alerts lists;
+	// nil disables the endpoint.
+	Alerts *alert.Detector
+	// SpanMetrics is fed by the receivers; nil disables
+	// /api/v1/spanmetrics/estimate.
+	SpanMetrics *spanmetrics.Estimator
 }
 
 // NewServer creates a new API server.
@@ -136,8 +172,15 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 	}
 
 	s := &Server{
-		store:  store,
-		router: chi.NewRouter(),
+		store:       store,
+		router:      chi.NewRouter(),
+		auth:        opt.Auth,
+		ingest:      opt.Ingest,
+		tenants:     opt.Tenants,
+		lintRules:   opt.LintRules,
+		simulations: opt.Simulations,
+		alerts:      opt.Alerts,
+		spanMetrics: opt.SpanMetrics,
 	}
 
 	// Middleware
@@ -172,6 +215,11 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 
 	// API routes
 	s.router.Route("/api/v1", func(r chi.Router) {
+		// Every route needs the read role; mutating routes need admin.
+		r.Use(s.requireRead)
+		r.Use(s.tenants.APIMiddleware)
+		admin := s.auth.Require(auth.RoleAdmin)
+
 		// Health endpoint
 		r.Get("/health", s.HandleHealth)
 
@@ -180,7 +228,12 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 
 		// Metrics endpoints
 		r.Get("/metrics", s.listMetrics)
+		if _, ok := s.store.(storage.MetricConflictStore); ok {
+			r.Get("/metrics/conflicts", s.getMetricConflicts) // before {name}
+		}
+		r.Get("/metrics/histogram-layouts", s.getHistogramLayouts)
 		r.Get("/metrics/{name}", s.getMetric)
+		r.Get("/metrics/{name}/contribution", s.getMetricContribution)
 
 		// Spans endpoints
 		r.Get("/spans", s.listSpans)
@@ -196,6 +249,23 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 		r.Get("/logs/patterns/{severity}/{template}", s.getPatternDetails)
 		r.Get("/logs/{severity}", s.getLog) // Generic route - must be last
 
+		// Loki stream label analysis
+		if _, ok := s.store.(storage.LokiStreamStore); ok {
+			r.Get("/loki/streams", s.getLokiStreams)
+		}
+
+		// Semantic convention validation
+		r.Get("/semconv/findings", s.getSemconvFindings)
+
+		// Naming lint
+		r.Get("/lint/violations", s.getLintViolations)
+
+		// Profiles endpoints
+		if _, ok := s.store.(storage.ProfileStore); ok {
+			r.Get("/profiles", s.listProfiles)
+			r.Get("/profiles/{id}", s.getProfile)
+		}
+
 		// Services endpoints
 		r.Get("/services", s.listServices)
 		r.Get("/services/{name}/overview", s.getServiceOverview)
@@ -211,23 +281,57 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 		r.Get("/attributes/{key}/telemetry", s.getAttributeTelemetry)
 
 		// Deep watch endpoints — more specific routes before generic {key}
-		r.Post("/attributes/{key}/watch", s.handleWatchAttribute)
-		r.Delete("/attributes/{key}/watch", s.handleUnwatchAttribute)
+		r.With(admin).Post("/attributes/{key}/watch", s.handleWatchAttribute)
+		r.With(admin).Delete("/attributes/{key}/watch", s.handleUnwatchAttribute)
 		r.Get("/attributes/{key}/watch", s.handleGetWatchedAttribute)
 
 		// Admin endpoints
-		r.Post("/admin/clear", s.clearAllData)
+		r.With(admin).Post("/admin/clear", s.clearAllData)
+		r.With(admin).Get("/tenants", s.listTenants)
+		r.With(admin).Put("/tenants/{id}", s.createTenant)
+		r.With(admin).Delete("/tenants/{id}", s.deleteTenant)
+
+		// Shadow-mode rule simulations
+		if s.simulations != nil {
+			r.Get("/simulations", s.listSimulations)
+			r.With(admin).Post("/simulations", s.createSimulation)
+			r.Get("/simulations/{id}", s.getSimulation)
+			r.With(admin).Delete("/simulations/{id}", s.deleteSimulation)
+		}
+
+		// Cardinality explosion alerts
+		if s.alerts != nil {
+			r.Get("/alerts", s.listAlerts)
+		}
+
+		// Sliding-window cardinality
+		if models.WindowsEnabled() {
+			r.Get("/metrics/{name}/windows", s.getMetricWindows)
+			r.Get("/cardinality/windows", s.getWindowedCardinality)
+		}
+
+		// Spanmetrics connector series projection
+		if s.spanMetrics != nil {
+			r.Get("/spanmetrics/estimate", s.getSpanMetricsEstimate)
+		}
+
+		// Cardinality history
+		if _, ok := s.store.(storage.HistoryStore); ok {
+			r.Get("/metrics/{name}/history", s.getMetricHistory)
+			r.Get("/attributes/{key}/history", s.getAttributeHistory)
+			r.Get("/services/{name}/history", s.getServiceHistory)
+		}
 
 		// Sessions endpoints
 		if s.sessionHandler != nil {
 			r.Get("/sessions", s.sessionHandler.ListSessions)
-			r.Post("/sessions", s.sessionHandler.CreateSession)
+			r.With(admin).Post("/sessions", s.sessionHandler.CreateSession)
 			r.Get("/sessions/diff", s.sessionHandler.DiffSessions)
-			r.Post("/sessions/import", s.sessionHandler.ImportSession)
+			r.With(admin).Post("/sessions/import", s.sessionHandler.ImportSession)
 			r.Get("/sessions/{name}", s.sessionHandler.GetSessionMetadata)
-			r.Delete("/sessions/{name}", s.sessionHandler.DeleteSession)
-			r.Post("/sessions/{name}/load", s.sessionHandler.LoadSession)
-			r.Post("/sessions/{name}/merge", s.sessionHandler.MergeSession)
+			r.With(admin).Delete("/sessions/{name}", s.sessionHandler.DeleteSession)
+			r.With(admin).Post("/sessions/{name}/load", s.sessionHandler.LoadSession)
+			r.With(admin).Post("/sessions/{name}/merge", s.sessionHandler.MergeSession)
 			r.Get("/sessions/{name}/export", s.sessionHandler.ExportSession)
 		}
 	})
@@ -276,13 +380,31 @@ func NewServer(addr string, store storage.Storage, opts ...ServerOptions) *Serve
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		TLSConfig:    opt.TLSConfig,
 	}
 
 	return s
 }
 
-// Start starts the API server.
+// requireRead authenticates /api/v1 requests for the read role. Health and
+// version stay open so liveness probes need no credentials.
+func (s *Server) requireRead(next http.Handler) http.Handler {
+	protected := s.auth.Require(auth.RoleRead)(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/health", "/api/v1/version":
+			next.ServeHTTP(w, r)
+		default:
+			protected.ServeHTTP(w, r)
+		}
+	})
+}
+
+// Start starts the API server, with TLS when configured.
 func (s *Server) Start() error {
+	if s.server.TLSConfig != nil {
+		return s.server.ListenAndServeTLS("", "")
+	}
 	return s.server.ListenAndServe()
 }
 
@@ -406,6 +528,67 @@ func (s *Server) getMetric(w http.ResponseWriter, r *http.Request) {
 	s.respondJSON(w, http.StatusOK, response)
 }
 
+// getMetricContribution ranks a metric's labels by how many series dropping
+// each one would remove.
+func (s *Server) getMetricContribution(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+
+	metric, err := s.store.GetMetric(ctx, name)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.respondError(w, http.StatusNotFound, "metric not found")
+			return
+		}
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, metric.SeriesContribution())
+}
+
+// getMetricConflicts returns metric names that services send with
+// disagreeing type, unit, temporality, monotonicity or description.
+func (s *Server) getMetricConflicts(w http.ResponseWriter, r *http.Request) {
+	conflicts, err := s.store.(storage.MetricConflictStore).GetMetricConflicts(r.Context())
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, conflicts)
+}
+
+// getHistogramLayouts returns the bucket layouts of explicit bucket
+// histograms with their Prometheus series cost and recommendations.
+// Query parameters:
+//   - service: only histograms sent by this service
+//   - max_buckets: flag layouts with more buckets (default: 20)
+//   - expensive_series: recommend exponential histograms from this many
+//     Prometheus series (default: 1000)
+func (s *Server) getHistogramLayouts(w http.ResponseWriter, r *http.Request) {
+	maxBuckets := models.DefaultMaxHistogramBuckets
+	if v := r.URL.Query().Get("max_buckets"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			maxBuckets = parsed
+		}
+	}
+	expensiveSeries := int64(models.DefaultExpensiveHistogramSeries)
+	if v := r.URL.Query().Get("expensive_series"); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil && parsed > 0 {
+			expensiveSeries = parsed
+		}
+	}
+
+	metrics, err := s.store.ListMetrics(r.Context(), r.URL.Query().Get("service"))
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, models.AnalyzeHistogramLayouts(metrics, maxBuckets, expensiveSeries))
+}
+
 // listSpans returns all spans, optionally filtered by service.
 // Supports pagination via ?limit=N&offset=M query parameters.
 func (s *Server) listSpans(w http.ResponseWriter, r *http.Request) {
@@ -570,6 +753,85 @@ func (s *Server) getLog(w http.ResponseWriter, r *http.Request) {
 	s.respondJSON(w, http.StatusOK, log)
 }
 
+// profilesListResponse extends PaginatedResponse with the total number of
+// profiles and samples observed across all sample types.
+type profilesListResponse struct {
+	Data              []*models.ProfileMetadata `json:"data"`
+	Total             int                       `json:"total"`
+	Limit             int                       `json:"limit"`
+	Offset            int                       `json:"offset"`
+	HasMore           bool                      `json:"has_more"`
+	TotalProfileCount int64                     `json:"total_profile_count"`
+	TotalSampleCount  int64                     `json:"total_sample_count"`
+}
+
+// listProfiles returns all profile sample types, optionally filtered by service.
+// Supports pagination via ?limit=N&offset=M query parameters.
+func (s *Server) listProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serviceName := r.URL.Query().Get("service")
+	params := parsePaginationParams(r)
+
+	profiles, err := s.store.(storage.ProfileStore).ListProfiles(ctx, serviceName)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var totalProfileCount, totalSampleCount int64
+	for _, p := range profiles {
+		totalProfileCount += p.ProfileCount
+		totalSampleCount += p.SampleCount
+	}
+
+	// Apply pagination
+	page, paginated := paginateSlice(profiles, params)
+	s.respondJSON(w, http.StatusOK, profilesListResponse{
+		Data:              page,
+		Total:             paginated.Total,
+		Limit:             paginated.Limit,
+		Offset:            paginated.Offset,
+		HasMore:           paginated.HasMore,
+		TotalProfileCount: totalProfileCount,
+		TotalSampleCount:  totalSampleCount,
+	})
+}
+
+// allProfiles returns every profile sample type, or none when the store
+// does not keep profiles.
+func (s *Server) allProfiles(ctx context.Context) ([]*models.ProfileMetadata, error) {
+	ps, ok := s.store.(storage.ProfileStore)
+	if !ok {
+		return nil, nil
+	}
+	return ps.ListProfiles(ctx, "")
+}
+
+// getProfile returns profile metadata for a sample type ID (e.g. "cpu:nanoseconds").
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	// URL decode the profile ID
+	decodedID, err := url.QueryUnescape(id)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid profile id encoding")
+		return
+	}
+
+	profile, err := s.store.(storage.ProfileStore).GetProfile(ctx, decodedID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.respondError(w, http.StatusNotFound, "profile not found")
+			return
+		}
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, profile)
+}
+
 // listLogsByService returns log data grouped by service_name instead of severity.
 // This provides better performance when dealing with high-cardinality severities like UNSET.
 func (s *Server) listLogsByService(w http.ResponseWriter, r *http.Request) {
@@ -872,6 +1134,74 @@ func (s *Server) getLogPatterns(w http.ResponseWriter, r *http.Request) {
 	s.respondJSON(w, http.StatusOK, patterns)
 }
 
+// getLokiStreams returns stream label cardinality for logs received through
+// the Loki push API.
+func (s *Server) getLokiStreams(w http.ResponseWriter, r *http.Request) {
+	streams, err := s.store.(storage.LokiStreamStore).GetLokiStreams(r.Context())
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, streams)
+}
+
+// getSemconvFindings validates metrics, spans and the attribute catalog
+// against the bundled semantic conventions registry.
+// Query parameters:
+//   - signal: only findings for "attribute", "metric" or "span"
+//   - severity: "warning" drops informational findings
+func (s *Server) getSemconvFindings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	metrics, err := s.store.ListMetrics(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	spans, err := s.store.ListSpans(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	attrs, err := s.store.ListAttributes(ctx, nil)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := semconv.Default().Validate(metrics, spans, attrs)
+	s.respondJSON(w, http.StatusOK, resp.Filter(r.URL.Query().Get("signal"), r.URL.Query().Get("severity")))
+}
+
+// getLintViolations checks stored names against the configured naming
+// rules.
+// Query parameters:
+//   - service: only violations sent by this service
+//   - rule: only violations of this rule
+func (s *Server) getLintViolations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	metrics, err := s.store.ListMetrics(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	spans, err := s.store.ListSpans(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logs, err := s.store.ListLogs(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := s.lintRules.Check(metrics, spans, logs)
+	s.respondJSON(w, http.StatusOK, resp.Filter(r.URL.Query().Get("service"), r.URL.Query().Get("rule")))
+}
+
 // getPatternDetails returns detailed information about a specific log pattern.
 // This shows all unique attributes grouped by service for the given severity+template.
 func (s *Server) getPatternDetails(w http.ResponseWriter, r *http.Request) {
@@ -1041,6 +1371,19 @@ func (s *Server) getServiceAttributes(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if profiles, err := s.allProfiles(ctx); err == nil {
+		for _, p := range profiles {
+			if _, hasService := p.Services[decodedName]; hasService {
+				for k := range p.AttributeKeys {
+					seen[k] = struct{}{}
+				}
+				for k := range p.ResourceKeys {
+					seen[k] = struct{}{}
+				}
+			}
+		}
+	}
+
 	keys := make([]string, 0, len(seen))
 	for k := range seen {
 		keys = append(keys, k)
@@ -1322,6 +1665,19 @@ func (s *Server) getAttributeServices(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Search profiles attribute keys and resource keys
+	profiles, err := s.allProfiles(ctx)
+	if err == nil {
+		for _, p := range profiles {
+			if _, ok := p.AttributeKeys[decodedKey]; ok {
+				addServices(p.Services, "profile")
+			}
+			if _, ok := p.ResourceKeys[decodedKey]; ok {
+				addServices(p.Services, "profile")
+			}
+		}
+	}
+
 	results := make([]*ServiceEntry, 0, len(seen))
 	for _, e := range seen {
 		results = append(results, e)
@@ -1339,7 +1695,7 @@ func (s *Server) getAttributeServices(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// getAttributeTelemetry returns all signals (metrics, spans, logs) that use a given attribute key.
+// getAttributeTelemetry returns all signals (metrics, spans, logs, profiles) that use a given attribute key.
 // GET /api/v1/attributes/{key}/telemetry
 func (s *Server) getAttributeTelemetry(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -1399,6 +1755,13 @@ func (s *Server) getAttributeTelemetry(w http.ResponseWriter, r *http.Request) {
 		AttributeCardinality int64            `json:"attribute_cardinality"`
 	}
 
+	type profileEntry struct {
+		ID                   string           `json:"id"`
+		Services             map[string]int64 `json:"services"`
+		AttributeCount       int64            `json:"attribute_count"`
+		AttributeCardinality int64            `json:"attribute_cardinality"`
+	}
+
 	var matchedMetrics []metricEntry
 	if metrics, err := s.store.ListMetrics(ctx, ""); err == nil {
 		for _, m := range metrics {
@@ -1443,15 +1806,31 @@ func (s *Server) getAttributeTelemetry(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var matchedProfiles []profileEntry
+	if profiles, err := s.allProfiles(ctx); err == nil {
+		for _, p := range profiles {
+			if cnt, card := pickKeyMeta(p.AttributeKeys, p.ResourceKeys); cnt > 0 {
+				matchedProfiles = append(matchedProfiles, profileEntry{
+					ID:                   p.ID,
+					Services:             p.Services,
+					AttributeCount:       cnt,
+					AttributeCardinality: card,
+				})
+			}
+		}
+	}
+
 	s.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"key":          decodedKey,
-		"attribute":    attribute,
-		"metrics":      matchedMetrics,
-		"metric_count": len(matchedMetrics),
-		"spans":        matchedSpans,
-		"span_count":   len(matchedSpans),
-		"logs":         matchedLogs,
-		"log_count":    len(matchedLogs),
+		"key":           decodedKey,
+		"attribute":     attribute,
+		"metrics":       matchedMetrics,
+		"metric_count":  len(matchedMetrics),
+		"spans":         matchedSpans,
+		"span_count":    len(matchedSpans),
+		"logs":          matchedLogs,
+		"log_count":     len(matchedLogs),
+		"profiles":      matchedProfiles,
+		"profile_count": len(matchedProfiles),
 	})
 }
 
@@ -1632,6 +2011,67 @@ func (s *Server) handleGetWatchedAttribute(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// listTenants lists every tenant with its usage and limits.
+// GET /api/v1/tenants
+func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
+	if s.tenants == nil {
+		s.respondError(w, http.StatusNotFound, "Multi-tenancy is not enabled")
+		return
+	}
+
+	tenants := s.tenants.List()
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"header":  s.tenants.Header(),
+		"tenants": tenants,
+		"total":   len(tenants),
+	})
+}
+
+// createTenant creates a tenant ahead of its first ingestion, so that
+// sessions can be imported into it. API reads never create tenants.
+// PUT /api/v1/tenants/{id}
+func (s *Server) createTenant(w http.ResponseWriter, r *http.Request) {
+	if s.tenants == nil {
+		s.respondError(w, http.StatusNotFound, "Multi-tenancy is not enabled")
+		return
+	}
+
+	t, err := s.tenants.Resolve(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, tenant.ErrTooManyTenants):
+		s.respondError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, t.Info())
+}
+
+// deleteTenant removes a tenant and its data; its saved sessions stay on
+// disk.
+// DELETE /api/v1/tenants/{id}
+func (s *Server) deleteTenant(w http.ResponseWriter, r *http.Request) {
+	if s.tenants == nil {
+		s.respondError(w, http.StatusNotFound, "Multi-tenancy is not enabled")
+		return
+	}
+
+	err := s.tenants.Remove(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, tenant.ErrUnknownTenant):
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, tenant.ErrDefaultTenant):
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // clearAllData clears all data from the storage.
 // POST /api/v1/admin/clear
 func (s *Server) clearAllData(w http.ResponseWriter, r *http.Request) {
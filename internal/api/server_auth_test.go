@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+// TestAPIServerAuthRoles verifies that read routes accept the read role,
+// mutating routes require admin and health stays open.
+func TestAPIServerAuthRoles(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{Tokens: map[string]auth.Role{
+		"viewer": auth.RoleRead,
+		"root":   auth.RoleAdmin,
+	}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	s := NewServer(":0", storage.NewStorage(storage.DefaultConfig()), ServerOptions{DisableUI: true, Auth: authenticator})
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodGet, "/api/v1/health", "", http.StatusOK},
+		{http.MethodGet, "/api/v1/metrics", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/v1/metrics", "viewer", http.StatusOK},
+		{http.MethodPost, "/api/v1/admin/clear", "viewer", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/admin/clear", "root", http.StatusOK},
+		{http.MethodPost, "/api/v1/attributes/user.id/watch", "viewer", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/sessions/nightly", "viewer", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s as %q: status %d, want %d", tt.method, tt.path, tt.token, w.Code, tt.want)
+		}
+	}
+}
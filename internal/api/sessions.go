@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 	"github.com/go-chi/chi/v5"
 )
@@ -31,6 +33,10 @@ type StoreAccessor interface {
 	MergeSpan(ctx context.Context, span *models.SpanMetadata) error
 	// MergeLog merges a log into the store
 	MergeLog(ctx context.Context, log *models.LogMetadata) error
+	// GetAllProfiles returns all profile metadata for session saving
+	GetAllProfiles(ctx context.Context) ([]*models.ProfileMetadata, error)
+	// MergeProfile merges profile metadata into the store
+	MergeProfile(ctx context.Context, profile *models.ProfileMetadata) error
 	// MergeAttribute merges an attribute into the store
 	MergeAttribute(ctx context.Context, attr *models.AttributeMetadata) error
 	// GetWatchedAll returns all watched attributes for session saving
@@ -85,12 +91,21 @@ func NewSessionHandlerWithStore(sessionStore *sessions.Store, storeAccess StoreA
 	}
 }
 
+// sessionStore returns the session directory of the request's tenant, or the
+// handler's own store when multi-tenancy is off.
+func (h *SessionHandler) sessionStore(ctx context.Context) *sessions.Store {
+	if t := tenant.FromContext(ctx); t != nil {
+		return t.Sessions
+	}
+	return h.store
+}
+
 // ListSessions returns metadata for all saved sessions.
 // GET /api/v1/sessions
 func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	sessionList, err := h.store.List(ctx)
+	sessionList, err := h.sessionStore(ctx).List(ctx)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to list sessions: "+err.Error())
 		return
@@ -114,7 +129,7 @@ func (h *SessionHandler) GetSessionMetadata(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	meta, err := h.store.GetMetadata(ctx, decodedName)
+	meta, err := h.sessionStore(ctx).GetMetadata(ctx, decodedName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Session not found")
@@ -149,37 +164,62 @@ func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	forceStr := r.URL.Query().Get("force")
 	force := forceStr == "true"
 
-	exists, _ := h.store.Exists(ctx, opts.Name)
+	exists, _ := h.sessionStore(ctx).Exists(ctx, opts.Name)
 	if exists && !force {
 		respondError(w, http.StatusConflict, "Session already exists. Use ?force=true to overwrite.")
 		return
 	}
 
 	// Get current store state
-	metrics, spans, logs, attrs, services, err := h.mainStore()
+	var metrics []*models.MetricMetadata
+	var spans []*models.SpanMetadata
+	var logs []*models.LogMetadata
+	var attrs []*models.AttributeMetadata
+	var services []string
+	var err error
+	if h.storeAccess != nil {
+		metrics, spans, logs, attrs, services, err = h.storeAccess.GetAll(ctx)
+	} else {
+		metrics, spans, logs, attrs, services, err = h.mainStore()
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to get store data: "+err.Error())
 		return
 	}
 
-	// Get watched attributes when full store access is available.
+	// Get profiles and watched attributes when full store access is available.
+	var profiles []*models.ProfileMetadata
 	var watchedAttrs []*models.WatchedAttribute
 	if h.storeAccess != nil {
+		if p, perr := h.storeAccess.GetAllProfiles(ctx); perr == nil {
+			profiles = p
+		}
 		if wa, werr := h.storeAccess.GetWatchedAll(ctx); werr == nil {
 			watchedAttrs = wa
 		}
 	}
 
+	var history *models.History
+	if hs, ok := h.storeAccess.(storage.HistoryStore); ok {
+		history = hs.History(ctx)
+	}
+	var identities map[string][]*models.MetricVariant
+	if cs, ok := h.storeAccess.(storage.MetricConflictStore); ok {
+		identities = cs.ConflictingMetricIdentities(ctx)
+	}
+
 	// Create session
 	session, err := h.serializer.CreateSession(
 		ctx,
 		sessions.CreateSessionOptions{
-			Name:        opts.Name,
-			Description: opts.Description,
-			Signals:     opts.Signals,
-			Services:    opts.Services,
+			Name:             opts.Name,
+			Description:      opts.Description,
+			Signals:          opts.Signals,
+			Services:         opts.Services,
+			History:          history,
+			MetricIdentities: identities,
 		},
-		metrics, spans, logs, attrs, services, watchedAttrs,
+		metrics, spans, logs, profiles, attrs, services, watchedAttrs,
 	)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create session: "+err.Error())
@@ -187,7 +227,7 @@ func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save to disk
-	if err := h.store.Save(ctx, session); err != nil {
+	if err := h.sessionStore(ctx).Save(ctx, session); err != nil {
 		if errors.Is(err, models.ErrTooManySessions) {
 			respondError(w, http.StatusConflict, "Maximum number of sessions reached")
 			return
@@ -201,7 +241,7 @@ func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get metadata for response
-	meta, _ := h.store.GetMetadata(ctx, opts.Name)
+	meta, _ := h.sessionStore(ctx).GetMetadata(ctx, opts.Name)
 
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"message": "Session created successfully",
@@ -221,7 +261,7 @@ func (h *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.store.Delete(ctx, decodedName); err != nil {
+	if err := h.sessionStore(ctx).Delete(ctx, decodedName); err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Session not found")
 			return
@@ -250,7 +290,7 @@ func (h *SessionHandler) LoadSession(w http.ResponseWriter, r *http.Request) {
 	signalsFilter := parseSignalsFilter(r.URL.Query().Get("signals"))
 
 	// Load session from disk
-	session, err := h.store.Load(ctx, decodedName)
+	session, err := h.sessionStore(ctx).Load(ctx, decodedName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Session not found")
@@ -323,7 +363,7 @@ func (h *SessionHandler) MergeSession(w http.ResponseWriter, r *http.Request) {
 	signalsFilter := parseSignalsFilter(r.URL.Query().Get("signals"))
 
 	// Load session from disk
-	session, err := h.store.Load(ctx, decodedName)
+	session, err := h.sessionStore(ctx).Load(ctx, decodedName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Session not found")
@@ -368,8 +408,10 @@ type unmarshaledSession struct {
 	metrics    []*models.MetricMetadata
 	spans      []*models.SpanMetadata
 	logs       []*models.LogMetadata
+	profiles   []*models.ProfileMetadata
 	attributes []*models.AttributeMetadata
 	watched    []*models.WatchedAttribute
+	history    *models.SerializedHistory
 }
 
 // unmarshalSession deserializes all signal data from a session without
@@ -397,6 +439,12 @@ func (h *SessionHandler) unmarshalSession(session *models.Session) (*unmarshaled
 			return nil, fmt.Errorf("unmarshal logs: %w", err)
 		}
 	}
+	if len(session.Data.Profiles) > 0 {
+		u.profiles, err = h.serializer.UnmarshalProfiles(session.Data.Profiles)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal profiles: %w", err)
+		}
+	}
 	if len(session.Data.Attributes) > 0 {
 		u.attributes, err = h.serializer.UnmarshalAttributes(session.Data.Attributes)
 		if err != nil {
@@ -409,6 +457,7 @@ func (h *SessionHandler) unmarshalSession(session *models.Session) (*unmarshaled
 			return nil, fmt.Errorf("unmarshal watched attributes: %w", err)
 		}
 	}
+	u.history = session.Data.History
 	return u, nil
 }
 
@@ -418,6 +467,7 @@ func (h *SessionHandler) mergeUnmarshaled(ctx context.Context, u *unmarshaledSes
 		"metrics":            0,
 		"spans":              0,
 		"logs":               0,
+		"profiles":           0,
 		"attributes":         0,
 		"watched_attributes": 0,
 	}
@@ -440,6 +490,12 @@ func (h *SessionHandler) mergeUnmarshaled(ctx context.Context, u *unmarshaledSes
 		}
 		counts["logs"]++
 	}
+	for _, p := range u.profiles {
+		if err := h.storeAccess.MergeProfile(ctx, p); err != nil {
+			return nil, err
+		}
+		counts["profiles"]++
+	}
 	for _, a := range u.attributes {
 		if err := h.storeAccess.MergeAttribute(ctx, a); err != nil {
 			return nil, err
@@ -452,6 +508,11 @@ func (h *SessionHandler) mergeUnmarshaled(ctx context.Context, u *unmarshaledSes
 		}
 		counts["watched_attributes"]++
 	}
+	if hs, ok := h.storeAccess.(storage.HistoryStore); ok && u.history != nil {
+		if err := hs.MergeHistory(ctx, u.history); err != nil {
+			return nil, err
+		}
+	}
 
 	return counts, nil
 }
@@ -477,7 +538,7 @@ func (h *SessionHandler) ExportSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.store.Load(ctx, decodedName)
+	session, err := h.sessionStore(ctx).Load(ctx, decodedName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Session not found")
@@ -516,14 +577,14 @@ func (h *SessionHandler) ImportSession(w http.ResponseWriter, r *http.Request) {
 	forceStr := r.URL.Query().Get("force")
 	force := forceStr == "true"
 
-	exists, _ := h.store.Exists(ctx, session.ID)
+	exists, _ := h.sessionStore(ctx).Exists(ctx, session.ID)
 	if exists && !force {
 		respondError(w, http.StatusConflict, "Session already exists. Use ?force=true to overwrite.")
 		return
 	}
 
 	// Save session
-	if err := h.store.Save(ctx, &session); err != nil {
+	if err := h.sessionStore(ctx).Save(ctx, &session); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to save session: "+err.Error())
 		return
 	}
@@ -548,7 +609,7 @@ func (h *SessionHandler) DiffSessions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Load both sessions
-	fromSession, err := h.store.Load(ctx, fromName)
+	fromSession, err := h.sessionStore(ctx).Load(ctx, fromName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Source session '"+fromName+"' not found")
@@ -558,7 +619,7 @@ func (h *SessionHandler) DiffSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	toSession, err := h.store.Load(ctx, toName)
+	toSession, err := h.sessionStore(ctx).Load(ctx, toName)
 	if err != nil {
 		if errors.Is(err, models.ErrSessionNotFound) {
 			respondError(w, http.StatusNotFound, "Target session '"+toName+"' not found")
@@ -1041,7 +1102,7 @@ func parseSignalsFilter(s string) []string {
 		if sig == "traces" {
 			sig = "spans"
 		}
-		if sig == "metrics" || sig == "spans" || sig == "logs" || sig == "attributes" {
+		if sig == "metrics" || sig == "spans" || sig == "logs" || sig == "profiles" || sig == "attributes" {
 			result = append(result, sig)
 		}
 	}
@@ -1111,6 +1172,8 @@ func filterSessionBySignals(session *models.Session, signals []string) *models.S
 			filtered.Data.Spans = session.Data.Spans
 		case "logs":
 			filtered.Data.Logs = session.Data.Logs
+		case "profiles":
+			filtered.Data.Profiles = session.Data.Profiles
 		case "attributes":
 			filtered.Data.Attributes = session.Data.Attributes
 		}
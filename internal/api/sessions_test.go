@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 	"github.com/go-chi/chi/v5"
@@ -21,6 +22,7 @@ type mockStoreAccessor struct {
 	metrics    []*models.MetricMetadata
 	spans      []*models.SpanMetadata
 	logs       []*models.LogMetadata
+	profiles   []*models.ProfileMetadata
 	attributes []*models.AttributeMetadata
 	services   []string
 }
@@ -61,6 +63,15 @@ func (m *mockStoreAccessor) MergeLog(ctx context.Context, log *models.LogMetadat
 	return nil
 }
 
+func (m *mockStoreAccessor) GetAllProfiles(ctx context.Context) ([]*models.ProfileMetadata, error) {
+	return m.profiles, nil
+}
+
+func (m *mockStoreAccessor) MergeProfile(ctx context.Context, profile *models.ProfileMetadata) error {
+	m.profiles = append(m.profiles, profile)
+	return nil
+}
+
 func (m *mockStoreAccessor) MergeAttribute(ctx context.Context, attr *models.AttributeMetadata) error {
 	m.attributes = append(m.attributes, attr)
 	return nil
@@ -70,6 +81,7 @@ func (m *mockStoreAccessor) Clear(ctx context.Context) error {
 	m.metrics = []*models.MetricMetadata{}
 	m.spans = []*models.SpanMetadata{}
 	m.logs = []*models.LogMetadata{}
+	m.profiles = nil
 	m.attributes = []*models.AttributeMetadata{}
 	m.services = []string{}
 	return nil
@@ -1065,3 +1077,56 @@ func setupTestSessionHandlerWithMockStore(t *testing.T) (*SessionHandler, *mockS
 
 	return handler, mockStore, func() { os.RemoveAll(tmpDir) }
 }
+
+func TestLoadSession_KeepsMetricConflicts(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewStorage(storage.DefaultConfig())
+	for _, unit := range []string{"ms", "s", "ms"} {
+		m := models.NewMetricMetadata("http.server.duration", &models.GaugeMetric{})
+		m.Unit = unit
+		m.SampleCount = 10
+		m.Services["checkout-"+unit] = 10
+		if err := store.StoreMetric(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cs := store.(storage.MetricConflictStore)
+	before, err := cs.GetMetricConflicts(ctx)
+	if err != nil || before.Total != 1 {
+		t.Fatalf("conflicts before save = %+v, %v", before, err)
+	}
+
+	sessionStore, err := sessions.NewWithConfig(sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10, MaxSessionSize: 10 * 1024 * 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewSessionHandlerWithStore(sessionStore, store.(StoreAccessor))
+	rr := httptest.NewRecorder()
+	h.CreateSession(rr, httptest.NewRequest(http.MethodPost, "/api/v1/sessions", bytes.NewBufferString(`{"name":"conflicts"}`)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create session: status %d: %s", rr.Code, rr.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/conflicts/load", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("name", "conflicts")
+	rr = httptest.NewRecorder()
+	h.LoadSession(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("load session: status %d: %s", rr.Code, rr.Body)
+	}
+
+	after, err := cs.GetMetricConflicts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Total != 1 || len(after.Conflicts[0].Variants) != 2 {
+		t.Fatalf("conflicts after load = %+v, want one with 2 variants", after.Conflicts)
+	}
+	for i, v := range after.Conflicts[0].Variants {
+		want := before.Conflicts[0].Variants[i]
+		if v.Unit != want.Unit || v.SampleCount != want.SampleCount || len(v.Services) != len(want.Services) {
+			t.Errorf("variant %d = %+v, want %+v", i, v, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// listSimulations lists the running simulations.
+// GET /api/v1/simulations
+func (s *Server) listSimulations(w http.ResponseWriter, r *http.Request) {
+	sims := s.simulations.List(r.Context())
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"simulations": sims,
+		"total":       len(sims),
+	})
+}
+
+// createSimulation starts running a rule set in shadow against incoming
+// data. Only data received from now on is compared.
+// POST /api/v1/simulations
+func (s *Server) createSimulation(w http.ResponseWriter, r *http.Request) {
+	var spec models.SimulationSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	sim, err := s.simulations.Create(r.Context(), spec)
+	if errors.Is(err, simulate.ErrTooMany) {
+		s.respondError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, sim.Summary())
+}
+
+// getSimulation compares active series, attribute cardinality and sample
+// counts per service with and without the simulation's rules.
+// GET /api/v1/simulations/{id}
+func (s *Server) getSimulation(w http.ResponseWriter, r *http.Request) {
+	sim, err := s.simulations.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, sim.Result())
+}
+
+// deleteSimulation stops a simulation.
+// DELETE /api/v1/simulations/{id}
+func (s *Server) deleteSimulation(w http.ResponseWriter, r *http.Request) {
+	if err := s.simulations.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
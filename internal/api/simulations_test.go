@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+func TestSimulationsLifecycle(t *testing.T) {
+	sims := simulate.NewRegistry()
+	s := NewServer(":0", storage.NewStorage(storage.DefaultConfig()), ServerOptions{DisableUI: true, Simulations: sims})
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := do(http.MethodPost, "/api/v1/simulations", `{"rules":[{"action":"drop_labels"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid rule: status %d, want 400", w.Code)
+	}
+
+	w := do(http.MethodPost, "/api/v1/simulations", `{"name":"no pods","rules":[{"action":"drop_labels","labels":["pod"]}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body)
+	}
+	var created models.SimulationSummary
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two pods of one series each collapse into one series.
+	var dps []*metricspb.NumberDataPoint
+	for _, pod := range []string{"a", "b"} {
+		dps = append(dps, &metricspb.NumberDataPoint{Attributes: []*commonpb.KeyValue{
+			{Key: "pod", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: pod}}},
+		}})
+	}
+	sims.Observer(context.Background()).Metrics(&colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Metrics: []*metricspb.Metric{{Name: "up", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: dps}}}},
+		}}}},
+	})
+
+	w = do(http.MethodGet, "/api/v1/simulations/"+created.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: status %d", w.Code)
+	}
+	var res models.SimulationResult
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "no pods" || res.Totals.Before.ActiveSeries != 2 || res.Totals.After.ActiveSeries != 1 {
+		t.Errorf("result = %+v, want 2 → 1 series", res)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/simulations/"+created.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete: status %d, want 204", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/simulations/"+created.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,10 @@
+package api
+
+import "net/http"
+
+// getSpanMetricsEstimate projects the series the spanmetrics connector
+// would generate from the spans received.
+// GET /api/v1/spanmetrics/estimate
+func (s *Server) getSpanMetricsEstimate(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, s.spanMetrics.Estimate(r.Context()))
+}
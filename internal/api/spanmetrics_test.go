@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestSpanMetricsEstimate(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewStorage(storage.DefaultConfig())
+	cfg, err := spanmetrics.Parse([]byte("dimensions: [{name: http.route}]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	est := spanmetrics.New(cfg)
+	s := NewServer(":0", store, ServerOptions{DisableUI: true, SpanMetrics: est})
+
+	str := func(v string) *commonpb.AnyValue {
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	}
+	var spans []*tracepb.Span
+	for i := 0; i < 30; i++ {
+		spans = append(spans, &tracepb.Span{
+			Name:       "GET",
+			Kind:       tracepb.Span_SPAN_KIND_SERVER,
+			Attributes: []*commonpb.KeyValue{{Key: "http.route", Value: str(fmt.Sprintf("/items/%d", i%3))}},
+		})
+	}
+	req := &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource:   &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{Key: "service.name", Value: str("catalog")}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans}},
+	}}}
+	a := analyzer.NewTracesAnalyzerWithCatalog(store)
+	if _, err := a.AnalyzeWithSpanMetrics(ctx, req, est.Recorder(ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/spanmetrics/estimate", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var resp models.SpanMetricsEstimate
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	// 3 routes; 17 buckets + _sum + _count + calls per combination.
+	if len(resp.Services) != 1 || resp.Services[0].Service != "catalog" || resp.Total.Combinations != 3 || resp.Total.SeriesPrometheus != 60 {
+		t.Errorf("estimate = %+v, want 3 combinations and 60 series for catalog", resp)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestTenantsEndpoint(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+	s := NewServer(":0", reg.Storage(), ServerOptions{DisableUI: true, Tenants: reg})
+
+	teamA, err := reg.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	ctx := context.Background()
+	teamA.Store.StoreMetric(ctx, models.NewMetricMetadata("a.requests", nil))
+	reg.Default().Store.StoreMetric(ctx, models.NewMetricMetadata("default.requests", nil))
+
+	// Clearing team-a leaves the default tenant untouched.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/clear", nil)
+	req.Header.Set(tenant.DefaultHeader, "team-a")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("clear: status %d", w.Code)
+	}
+	if _, err := teamA.Store.GetMetric(ctx, "a.requests"); err == nil {
+		t.Error("team-a still has its metric after clear")
+	}
+	if _, err := reg.Default().Store.GetMetric(ctx, "default.requests"); err != nil {
+		t.Errorf("default tenant lost its metric: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("tenants: status %d", w.Code)
+	}
+	var resp struct {
+		Header  string        `json:"header"`
+		Tenants []tenant.Info `json:"tenants"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Header != tenant.DefaultHeader || len(resp.Tenants) != 2 || resp.Tenants[1].ID != "team-a" {
+		t.Errorf("unexpected listing: %s", w.Body.String())
+	}
+}
+
+func TestTenantsEndpoint_Disabled(t *testing.T) {
+	s := NewServer(":0", storage.NewStorage(storage.DefaultConfig()), ServerOptions{DisableUI: true})
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
+
+func TestTenantsEndpoint_UnknownTenant(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+	s := NewServer(":0", reg.Storage(), ServerOptions{DisableUI: true, Tenants: reg})
+
+	// Reads with an unknown tenant header do not create the tenant.
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+	req.Header.Set(tenant.DefaultHeader, "team-a")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("read of unknown tenant: status %d, want 404", w.Code)
+	}
+	if _, err := reg.Lookup("team-a"); err == nil {
+		t.Fatal("read created the tenant")
+	}
+
+	// The admin path creates it.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/tenants/team-a", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("read after create: status %d, want 200", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/tenants/bad%2Fid", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("create invalid: status %d, want 400", w.Code)
+	}
+}
+
+func TestTenantsEndpoint_Delete(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+	s := NewServer(":0", reg.Storage(), ServerOptions{DisableUI: true, Tenants: reg})
+	if _, err := reg.Resolve("team-a"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id   string
+		code int
+	}{
+		{"team-a", http.StatusNoContent},
+		{"team-a", http.StatusNotFound},
+		{tenant.DefaultID, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/tenants/"+tt.id, nil))
+		if w.Code != tt.code {
+			t.Errorf("delete %s: status %d, want %d", tt.id, w.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// getMetricWindows returns a metric's active series and label cardinality
+// within recent windows next to its cumulative series count.
+// GET /api/v1/metrics/{name}/windows?window=15m,1h
+// Without window the 15m, 1h and 24h windows are returned.
+func (s *Server) getMetricWindows(w http.ResponseWriter, r *http.Request) {
+	windows := models.StandardWindows()
+	if v := r.URL.Query().Get("window"); v != "" {
+		windows = nil
+		for _, part := range strings.Split(v, ",") {
+			d, err := models.ParseWindow(strings.TrimSpace(part))
+			if err != nil {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			windows = append(windows, d)
+		}
+	}
+
+	metric, err := s.store.GetMetric(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			s.respondError(w, http.StatusNotFound, "metric not found")
+			return
+		}
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, metric.Windows(windows))
+}
+
+// getWindowedCardinality ranks metrics by active series and attributes by
+// unique values within a recent window.
+// Query parameters:
+//   - window: the window to count, e.g. 15m or 24h (default: 1h)
+//   - limit: max metrics and attributes to return (default: 100, max: 1000)
+func (s *Server) getWindowedCardinality(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	window := min(time.Hour, models.WindowHorizon())
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := models.ParseWindow(v)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		window = d
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = min(parsed, 1000)
+		}
+	}
+
+	metrics, err := s.store.ListMetrics(ctx, "")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	attrs, err := s.store.ListAttributes(ctx, nil)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, models.RankWindowed(metrics, attrs, window, limit))
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestWindowEndpoints(t *testing.T) {
+	models.SetWindowConfig(hyperloglog.DefaultWindowConfig())
+	defer models.SetWindowConfig(hyperloglog.WindowConfig{})
+	store := storage.NewStorage(storage.DefaultConfig())
+	s := NewServer(":0", store, ServerOptions{DisableUI: true})
+
+	m := models.NewMetricMetadata("http_requests", &models.SumMetric{})
+	for i := 0; i < 5; i++ {
+		m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+	}
+	if err := store.StoreMetric(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/metrics/http_requests/windows")
+	if w.Code != http.StatusOK {
+		t.Fatalf("windows: status %d: %s", w.Code, w.Body)
+	}
+	var resp models.MetricWindowsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Windows) != 3 || resp.Windows[0].Window != "15m" || resp.Windows[0].ActiveSeries != 5 {
+		t.Errorf("windows = %+v, want 5 series in 15m, 1h and 24h", resp.Windows)
+	}
+
+	for _, path := range []string{
+		"/api/v1/metrics/http_requests/windows?window=1w",
+		"/api/v1/metrics/http_requests/windows?window=15m,72h",
+		"/api/v1/cardinality/windows?window=-5m",
+	} {
+		if w := get(path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", path, w.Code)
+		}
+	}
+
+	w = get("/api/v1/cardinality/windows?window=15m")
+	if w.Code != http.StatusOK {
+		t.Fatalf("cardinality windows: status %d: %s", w.Code, w.Body)
+	}
+	var ranked models.WindowedCardinality
+	if err := json.NewDecoder(w.Body).Decode(&ranked); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked.Metrics) != 1 || ranked.Metrics[0].ActiveSeriesWindow != 5 {
+		t.Errorf("ranked metrics = %+v, want http_requests with 5 series", ranked.Metrics)
+	}
+}
+
+func TestWindowEndpoints_Disabled(t *testing.T) {
+	s := NewServer(":0", storage.NewStorage(storage.DefaultConfig()), ServerOptions{DisableUI: true})
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/cardinality/windows", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404 while windows are disabled", w.Code)
+	}
+}
@@ -0,0 +1,229 @@
+// Package auth authenticates OTLP ingestion and REST API requests. Two
+// credential sources are supported and may be combined:
+//
+//   - static bearer tokens / API keys, each bound to a role
+//   - HTTP basic credentials checked against an htpasswd file
+//
+// Roles are ordered: admin may do everything read may do. Mutating API
+// routes require admin, everything else (including ingestion) read.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role is the permission level of a principal.
+type Role int
+
+// Roles, in increasing order of privilege.
+const (
+	RoleNone Role = iota
+	RoleRead
+	RoleAdmin
+)
+
+// String returns the role name used in configuration.
+func (r Role) String() string {
+	switch r {
+	case RoleRead:
+		return "read"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// ParseRole parses "read" or "admin".
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "read", "readonly", "read-only":
+		return RoleRead, nil
+	case "admin":
+		return RoleAdmin, nil
+	}
+	return RoleNone, fmt.Errorf("unknown role %q (want read or admin)", s)
+}
+
+// ParseTokens parses a comma-separated list of role:token pairs, e.g.
+// "admin:s3cret,read:dashboards".
+func ParseTokens(spec string) (map[string]Role, error) {
+	tokens := make(map[string]Role)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		roleName, token, ok := strings.Cut(entry, ":")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("token entry %q: want role:token", entry)
+		}
+		role, err := ParseRole(roleName)
+		if err != nil {
+			return nil, err
+		}
+		tokens[token] = role
+	}
+	return tokens, nil
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Name string
+	Role Role
+}
+
+// Errors returned by Authenticate.
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Config configures an Authenticator.
+type Config struct {
+	// Tokens maps bearer tokens / API keys to their role.
+	Tokens map[string]Role
+	// HtpasswdFile enables HTTP basic authentication. Users get the read
+	// role unless listed in AdminUsers.
+	HtpasswdFile string
+	AdminUsers   []string
+}
+
+// Authenticator checks request credentials. A nil Authenticator admits
+// every request, so callers need no separate "auth disabled" branch.
+type Authenticator struct {
+	tokens   map[[sha256.Size]byte]Role
+	htpasswd *htpasswd
+	admins   map[string]bool
+}
+
+// New builds an authenticator. It returns nil when cfg configures no
+// credentials.
+func New(cfg Config) (*Authenticator, error) {
+	if len(cfg.Tokens) == 0 && cfg.HtpasswdFile == "" {
+		return nil, nil
+	}
+
+	a := &Authenticator{
+		tokens: make(map[[sha256.Size]byte]Role, len(cfg.Tokens)),
+		admins: make(map[string]bool, len(cfg.AdminUsers)),
+	}
+	for token, role := range cfg.Tokens {
+		// Keyed by digest so lookups do not compare secrets byte by byte.
+		a.tokens[sha256.Sum256([]byte(token))] = role
+	}
+	for _, u := range cfg.AdminUsers {
+		a.admins[strings.TrimSpace(u)] = true
+	}
+	if cfg.HtpasswdFile != "" {
+		h, err := loadHtpasswd(cfg.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		a.htpasswd = h
+	}
+	return a, nil
+}
+
+// Enabled reports whether requests must be authenticated.
+func (a *Authenticator) Enabled() bool {
+	return a != nil
+}
+
+// UsesBasic reports whether basic credentials are accepted, so that 401
+// responses can ask browsers for them.
+func (a *Authenticator) UsesBasic() bool {
+	return a != nil && a.htpasswd != nil
+}
+
+// Authenticate checks an Authorization header value ("Bearer <token>" or
+// "Basic <base64>") and, failing that, an API key (X-API-Key).
+func (a *Authenticator) Authenticate(authorization, apiKey string) (*Principal, error) {
+	if a == nil {
+		return &Principal{Name: "anonymous", Role: RoleAdmin}, nil
+	}
+
+	if apiKey != "" {
+		return a.checkToken(apiKey)
+	}
+
+	scheme, value, _ := strings.Cut(strings.TrimSpace(authorization), " ")
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(scheme) {
+	case "":
+		return nil, ErrNoCredentials
+	case "bearer":
+		return a.checkToken(value)
+	case "basic":
+		if a.htpasswd == nil {
+			return nil, ErrInvalidCredentials
+		}
+		user, pass, ok := parseBasic(value)
+		if !ok || !a.htpasswd.verify(user, pass) {
+			return nil, ErrInvalidCredentials
+		}
+		role := RoleRead
+		if a.admins[user] {
+			role = RoleAdmin
+		}
+		return &Principal{Name: user, Role: role}, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// AuthenticateRequest authenticates an HTTP request.
+func (a *Authenticator) AuthenticateRequest(r *http.Request) (*Principal, error) {
+	return a.Authenticate(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+}
+
+// parseBasic decodes the credentials of a basic Authorization header.
+func parseBasic(encoded string) (user, pass string, ok bool) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func (a *Authenticator) checkToken(token string) (*Principal, error) {
+	role, ok := a.tokens[sha256.Sum256([]byte(token))]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{Name: "token:" + role.String(), Role: role}, nil
+}
+
+// Require returns middleware rejecting requests whose principal lacks role:
+// 401 for missing or invalid credentials, 403 for an insufficient role.
+func (a *Authenticator) Require(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := a.AuthenticateRequest(r)
+			if err != nil {
+				a.Unauthorized(w)
+				return
+			}
+			if p.Role < role {
+				http.Error(w, fmt.Sprintf("%s role required", role), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Unauthorized writes a 401 response, challenging for basic credentials
+// when an htpasswd file is configured.
+func (a *Authenticator) Unauthorized(w http.ResponseWriter) {
+	if a.UsesBasic() {
+		w.Header().Set("WWW-Authenticate", `Basic realm="otlp-cardinality-checker"`)
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func basic(user, pass string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+}
+
+func writeHtpasswd(t *testing.T) string {
+	t.Helper()
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("alice-pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha1.Sum([]byte("bob-pw"))
+	content := "# users\nalice:" + string(bcryptHash) + "\nbob:{SHA}" + base64.StdEncoding.EncodeToString(sum[:]) + "\n"
+
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthenticate(t *testing.T) {
+	tokens, err := ParseTokens("admin:root-token, read:viewer-token")
+	if err != nil {
+		t.Fatalf("ParseTokens: %v", err)
+	}
+	a, err := New(Config{Tokens: tokens, HtpasswdFile: writeHtpasswd(t), AdminUsers: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		apiKey        string
+		wantRole      Role
+		wantErr       error
+	}{
+		{name: "admin bearer", authorization: "Bearer root-token", wantRole: RoleAdmin},
+		{name: "read api key", apiKey: "viewer-token", wantRole: RoleRead},
+		{name: "bcrypt admin user", authorization: basic("alice", "alice-pw"), wantRole: RoleAdmin},
+		{name: "sha1 read user", authorization: basic("bob", "bob-pw"), wantRole: RoleRead},
+		{name: "wrong password", authorization: basic("alice", "nope"), wantErr: ErrInvalidCredentials},
+		{name: "unknown token", authorization: "Bearer guess", wantErr: ErrInvalidCredentials},
+		{name: "no credentials", wantErr: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(tt.authorization, tt.apiKey)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && p.Role != tt.wantRole {
+				t.Errorf("role = %v, want %v", p.Role, tt.wantRole)
+			}
+		})
+	}
+
+	// A cached bcrypt verification must not admit a different password.
+	if _, err := a.Authenticate(basic("alice", "alice-pw"), ""); err != nil {
+		t.Errorf("repeat verification: %v", err)
+	}
+	if _, err := a.Authenticate(basic("alice", "alice-pw2"), ""); err == nil {
+		t.Error("different password accepted after caching")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	a, err := New(Config{Tokens: map[string]Role{"r": RoleRead, "a": RoleAdmin}, HtpasswdFile: writeHtpasswd(t)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	handler := a.Require(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "r": http.StatusForbidden, "a": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/clear", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("token %q: status %d, want %d", token, w.Code, want)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Error("401 without basic challenge although htpasswd is configured")
+		}
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	a, err := New(Config{})
+	if a != nil || err != nil {
+		t.Fatalf("New(empty) = %v, %v; want nil, nil", a, err)
+	}
+	if a.Enabled() {
+		t.Error("nil authenticator reports enabled")
+	}
+	if p, err := a.Authenticate("", ""); err != nil || p.Role != RoleAdmin {
+		t.Errorf("nil authenticator must admit everything, got %v, %v", p, err)
+	}
+}
+
+func TestParseTokensAndHtpasswdErrors(t *testing.T) {
+	if _, err := ParseTokens("superuser:x"); err == nil {
+		t.Error("expected unknown role error")
+	}
+	if _, err := ParseTokens("admin"); err == nil {
+		t.Error("expected missing token error")
+	}
+	if _, err := parseHtpasswd([]byte("carol:$apr1$abc$def\n")); err == nil {
+		t.Error("expected unsupported hash error for MD5")
+	}
+}
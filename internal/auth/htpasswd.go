@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// htpasswdCheckInterval bounds how often the file is stat'ed for changes.
+const htpasswdCheckInterval = 5 * time.Second
+
+// htpasswd verifies basic credentials against an Apache htpasswd file with
+// bcrypt ($2y$) or SHA-1 ({SHA}) hashes. The file is re-read when it
+// changes so users can be added without a restart.
+type htpasswd struct {
+	path string
+
+	mu        sync.Mutex
+	users     map[string]string
+	modTime   time.Time
+	lastCheck time.Time
+	// verified caches sha256(user:password) of credentials that passed a
+	// bcrypt check; bcrypt is far too slow to run on every OTLP request.
+	verified map[[sha256.Size]byte]bool
+}
+
+func loadHtpasswd(path string) (*htpasswd, error) {
+	h := &htpasswd{path: path}
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *htpasswd) load() error {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return err
+	}
+	users, err := parseHtpasswd(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", h.path, err)
+	}
+
+	h.mu.Lock()
+	h.users = users
+	h.modTime = info.ModTime()
+	h.lastCheck = time.Now()
+	h.verified = make(map[[sha256.Size]byte]bool)
+	h.mu.Unlock()
+	return nil
+}
+
+func parseHtpasswd(data []byte) (map[string]string, error) {
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(entry, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("line %d: want user:hash", line)
+		}
+		if !strings.HasPrefix(hash, "$2") && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("line %d: unsupported hash for user %q (use bcrypt: htpasswd -B)", line, user)
+		}
+		users[user] = hash
+	}
+	return users, scanner.Err()
+}
+
+// reloadIfChanged re-reads the file when its modification time changed.
+// A broken file is logged and the previous users stay in effect.
+func (h *htpasswd) reloadIfChanged() {
+	h.mu.Lock()
+	if time.Since(h.lastCheck) < htpasswdCheckInterval {
+		h.mu.Unlock()
+		return
+	}
+	h.lastCheck = time.Now()
+	modTime := h.modTime
+	h.mu.Unlock()
+
+	info, err := os.Stat(h.path)
+	if err != nil || info.ModTime().Equal(modTime) {
+		return
+	}
+	if err := h.load(); err != nil {
+		log.Printf("Keeping previous htpasswd users: %v", err)
+	}
+}
+
+func (h *htpasswd) verify(user, pass string) bool {
+	h.reloadIfChanged()
+
+	key := sha256.Sum256([]byte(user + ":" + pass))
+	h.mu.Lock()
+	hash, ok := h.users[user]
+	cached := h.verified[key]
+	h.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if cached {
+		return true
+	}
+
+	var valid bool
+	if strings.HasPrefix(hash, "{SHA}") {
+		sum := sha1.Sum([]byte(pass))
+		want := base64.StdEncoding.EncodeToString(sum[:])
+		valid = subtle.ConstantTimeCompare([]byte(want), []byte(strings.TrimPrefix(hash, "{SHA}"))) == 1
+	} else {
+		valid = bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil
+	}
+
+	if valid {
+		h.mu.Lock()
+		h.verified[key] = true
+		h.mu.Unlock()
+	}
+	return valid
+}
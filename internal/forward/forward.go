@@ -0,0 +1,396 @@
+// Package forward relays OTLP export requests to an upstream endpoint after
+// the receivers have analyzed them, so the checker can run as a transparent
+// hop in an existing pipeline instead of a dead-end sink.
+package forward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Signal identifies the OTLP signal a payload belongs to.
+type Signal string
+
+// Forwardable signals.
+const (
+	SignalMetrics  Signal = "metrics"
+	SignalTraces   Signal = "traces"
+	SignalLogs     Signal = "logs"
+	SignalProfiles Signal = "profiles"
+)
+
+// AllSignals lists every signal that can be forwarded.
+var AllSignals = []Signal{SignalMetrics, SignalTraces, SignalLogs, SignalProfiles}
+
+// Upstream protocols.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// Content types a payload body can be encoded with.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// Config configures the forwarder.
+type Config struct {
+	// Endpoint is host:port for gRPC or a base URL (http://host:4318) for HTTP.
+	Endpoint string
+	Protocol string
+	// Signals lists the signals to forward; others are analyzed only.
+	Signals []Signal
+	// Headers are added to every upstream request (gRPC metadata for gRPC).
+	Headers map[string]string
+	// Insecure disables TLS for gRPC upstreams.
+	Insecure bool
+	// Compression is "gzip" or empty for none.
+	Compression string
+
+	QueueSize      int
+	Workers        int
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+}
+
+// DefaultConfig returns a configuration forwarding every signal over gRPC
+// with the retry schedule of the OpenTelemetry Collector exporters.
+func DefaultConfig() Config {
+	return Config{
+		Protocol:       ProtocolGRPC,
+		Signals:        AllSignals,
+		QueueSize:      1000,
+		Workers:        4,
+		MaxRetries:     5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// ParseSignals parses a comma-separated signal list. "traces" may also be
+// given as "spans".
+func ParseSignals(spec string) ([]Signal, error) {
+	var signals []Signal
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		switch s {
+		case "":
+			continue
+		case "spans":
+			s = string(SignalTraces)
+		}
+		sig := Signal(s)
+		switch sig {
+		case SignalMetrics, SignalTraces, SignalLogs, SignalProfiles:
+			signals = append(signals, sig)
+		default:
+			return nil, fmt.Errorf("unknown signal %q (want metrics, traces, logs or profiles)", s)
+		}
+	}
+	return signals, nil
+}
+
+// ParseHeaders parses a comma-separated list of key=value pairs.
+func ParseHeaders(spec string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("header %q: want key=value", pair)
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// Payload is an encoded OTLP export request exactly as it should reach the
+// upstream.
+type Payload struct {
+	Signal      Signal
+	Body        []byte
+	ContentType string
+	// Headers are sent with this payload only, after Config.Headers, so
+	// that the tenant header of the incoming request reaches the upstream.
+	Headers map[string]string
+}
+
+// Stats reports forwarding counters.
+type Stats struct {
+	Queued  int    `json:"queued"`
+	Sent    uint64 `json:"sent"`
+	Failed  uint64 `json:"failed"`
+	Dropped uint64 `json:"dropped"`
+	Retries uint64 `json:"retries"`
+}
+
+// exporter delivers a single payload to the upstream.
+type exporter interface {
+	export(ctx context.Context, p Payload) error
+	close() error
+}
+
+// exportError wraps an upstream failure with its retry classification.
+type exportError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *exportError) Error() string { return e.err.Error() }
+func (e *exportError) Unwrap() error { return e.err }
+
+// Forwarder queues analyzed payloads and delivers them upstream from a fixed
+// pool of workers. Enqueue never blocks: when the queue is full the payload
+// is dropped and counted, so a slow upstream cannot stall ingestion.
+type Forwarder struct {
+	cfg     Config
+	signals map[Signal]bool
+	exp     exporter
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Payload
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	dropped atomic.Uint64
+	retries atomic.Uint64
+}
+
+// New validates cfg, connects the exporter and starts the workers.
+func New(cfg Config) (*Forwarder, error) {
+	def := DefaultConfig()
+	if cfg.Protocol == "" {
+		cfg.Protocol = def.Protocol
+	}
+	if cfg.Signals == nil {
+		cfg.Signals = def.Signals
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = def.Workers
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = def.InitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.Endpoint == "" {
+		return nil, errors.New("forward endpoint is required")
+	}
+	if cfg.Compression != "" && cfg.Compression != "gzip" {
+		return nil, fmt.Errorf("unsupported compression %q (want gzip or none)", cfg.Compression)
+	}
+
+	var exp exporter
+	var err error
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		exp, err = newGRPCExporter(cfg)
+	case ProtocolHTTP:
+		u, perr := url.Parse(cfg.Endpoint)
+		if perr != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("forward endpoint %q: want http(s)://host:port", cfg.Endpoint)
+		}
+		exp = newHTTPExporter(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported forward protocol %q (want grpc or http)", cfg.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newForwarder(cfg, exp), nil
+}
+
+func newForwarder(cfg Config, exp exporter) *Forwarder {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Forwarder{
+		cfg:     cfg,
+		signals: make(map[Signal]bool, len(cfg.Signals)),
+		exp:     exp,
+		queue:   make(chan Payload, cfg.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for _, s := range cfg.Signals {
+		f.signals[s] = true
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		f.wg.Add(1)
+		go f.worker()
+	}
+	return f
+}
+
+// Enabled reports whether payloads of signal s should be forwarded. It is
+// safe to call on a nil Forwarder, which forwards nothing.
+func (f *Forwarder) Enabled(s Signal) bool {
+	return f != nil && f.signals[s]
+}
+
+// Enqueue queues p for delivery and reports whether it was accepted.
+// Payloads for disabled signals are ignored.
+func (f *Forwarder) Enqueue(p Payload) bool {
+	if !f.Enabled(p.Signal) {
+		return false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		f.dropped.Add(1)
+		return false
+	}
+	select {
+	case f.queue <- p:
+		return true
+	default:
+		if f.dropped.Add(1)%100 == 1 {
+			log.Printf("Forward queue full (%d), dropping %s payloads", f.cfg.QueueSize, p.Signal)
+		}
+		return false
+	}
+}
+
+// EnqueueMessage marshals msg to protobuf and queues it with headers.
+func (f *Forwarder) EnqueueMessage(s Signal, msg proto.Message, headers map[string]string) bool {
+	if !f.Enabled(s) {
+		return false
+	}
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		log.Printf("Forward: marshal %s request: %v", s, err)
+		f.failed.Add(1)
+		return false
+	}
+	return f.Enqueue(Payload{Signal: s, Body: body, ContentType: ContentTypeProtobuf, Headers: headers})
+}
+
+// Stats returns a snapshot of the forwarding counters.
+func (f *Forwarder) Stats() Stats {
+	if f == nil {
+		return Stats{}
+	}
+	return Stats{
+		Queued:  len(f.queue),
+		Sent:    f.sent.Load(),
+		Failed:  f.failed.Load(),
+		Dropped: f.dropped.Load(),
+		Retries: f.retries.Load(),
+	}
+}
+
+// Shutdown stops accepting payloads and waits for the queue to drain. When
+// ctx expires first, in-flight sends are cancelled and the remaining
+// payloads are counted as dropped.
+func (f *Forwarder) Shutdown(ctx context.Context) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	if !f.closed {
+		f.closed = true
+		close(f.queue)
+	}
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		f.cancel()
+		<-done
+		err = ctx.Err()
+	}
+	f.cancel()
+	if cerr := f.exp.close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (f *Forwarder) worker() {
+	defer f.wg.Done()
+	for p := range f.queue {
+		if f.ctx.Err() != nil {
+			f.dropped.Add(1)
+			continue
+		}
+		f.send(p)
+	}
+}
+
+// send delivers p, retrying retryable failures with exponential backoff.
+func (f *Forwarder) send(p Payload) {
+	backoff := f.cfg.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(f.ctx, f.cfg.Timeout)
+		err := f.exp.export(ctx, p)
+		cancel()
+		if err == nil {
+			f.sent.Add(1)
+			return
+		}
+
+		var expErr *exportError
+		retryable := errors.As(err, &expErr) && expErr.retryable
+		if !retryable || attempt >= f.cfg.MaxRetries || f.ctx.Err() != nil {
+			f.failed.Add(1)
+			log.Printf("Forward of %s payload failed after %d attempt(s): %v", p.Signal, attempt+1, err)
+			return
+		}
+
+		wait := backoff
+		if expErr.retryAfter > 0 {
+			wait = expErr.retryAfter
+		}
+		f.retries.Add(1)
+		select {
+		case <-time.After(wait):
+		case <-f.ctx.Done():
+			f.failed.Add(1)
+			return
+		}
+		backoff *= 2
+		if backoff > f.cfg.MaxBackoff {
+			backoff = f.cfg.MaxBackoff
+		}
+	}
+}
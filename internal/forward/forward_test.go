@@ -0,0 +1,309 @@
+package forward
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func testMetricsRequest() *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{{Name: "http_requests_total"}},
+			}},
+		}},
+	}
+}
+
+func fastConfig(endpoint, protocol string) Config {
+	cfg := DefaultConfig()
+	cfg.Endpoint = endpoint
+	cfg.Protocol = protocol
+	cfg.Insecure = true
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	cfg.Timeout = 2 * time.Second
+	return cfg
+}
+
+func shutdown(t *testing.T, f *Forwarder) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := f.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestParseSignals(t *testing.T) {
+	got, err := ParseSignals("metrics, spans,logs")
+	if err != nil {
+		t.Fatalf("ParseSignals: %v", err)
+	}
+	want := []Signal{SignalMetrics, SignalTraces, SignalLogs}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("signal %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ParseSignals("metrics,events"); err == nil {
+		t.Error("expected error for unknown signal")
+	}
+}
+
+func TestForwarder_HTTPRetriesAndPassesPayloadThrough(t *testing.T) {
+	var attempts atomic.Int32
+	var mu sync.Mutex
+	var gotPath, gotType, gotAuth string
+	var gotBody []byte
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		gotPath, gotType, gotAuth, gotBody = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), body
+		mu.Unlock()
+	}))
+	defer upstream.Close()
+
+	cfg := fastConfig(upstream.URL, ProtocolHTTP)
+	cfg.Headers = map[string]string{"Authorization": "Bearer token"}
+	f, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	body := []byte(`{"resourceLogs":[]}`)
+	if !f.Enqueue(Payload{Signal: SignalLogs, Body: body, ContentType: ContentTypeJSON}) {
+		t.Fatal("Enqueue rejected payload")
+	}
+	shutdown(t, f)
+
+	stats := f.Stats()
+	if stats.Sent != 1 || stats.Retries != 1 || stats.Failed != 0 {
+		t.Errorf("stats = %+v, want 1 sent after 1 retry", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if gotPath != "/v1/logs" || gotType != ContentTypeJSON || gotAuth != "Bearer token" {
+		t.Errorf("path/type/auth = %q/%q/%q", gotPath, gotType, gotAuth)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %q, want unchanged %q", gotBody, body)
+	}
+}
+
+func TestForwarder_HTTPDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer upstream.Close()
+
+	f, err := New(fastConfig(upstream.URL, ProtocolHTTP))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	f.Enqueue(Payload{Signal: SignalMetrics, Body: []byte{}, ContentType: ContentTypeProtobuf})
+	shutdown(t, f)
+
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+	if stats := f.Stats(); stats.Failed != 1 {
+		t.Errorf("stats = %+v, want 1 failed", stats)
+	}
+}
+
+// blockingExporter holds every export until release is closed.
+type blockingExporter struct {
+	release chan struct{}
+}
+
+func (e *blockingExporter) export(ctx context.Context, p Payload) error {
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (e *blockingExporter) close() error { return nil }
+
+func TestForwarder_QueueFullDropsAndDisabledSignalsIgnored(t *testing.T) {
+	exp := &blockingExporter{release: make(chan struct{})}
+	cfg := DefaultConfig()
+	cfg.Signals = []Signal{SignalTraces}
+	cfg.QueueSize = 2
+	cfg.Workers = 1
+	f := newForwarder(cfg, exp)
+
+	if f.Enqueue(Payload{Signal: SignalMetrics}) {
+		t.Error("metrics payload accepted although only traces are enabled")
+	}
+
+	// One payload is picked up by the worker, two fill the queue.
+	f.Enqueue(Payload{Signal: SignalTraces})
+	deadline := time.Now().Add(2 * time.Second)
+	for len(f.queue) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	f.Enqueue(Payload{Signal: SignalTraces})
+	f.Enqueue(Payload{Signal: SignalTraces})
+	if f.Enqueue(Payload{Signal: SignalTraces}) {
+		t.Error("payload accepted into a full queue")
+	}
+	if stats := f.Stats(); stats.Dropped != 1 || stats.Queued != 2 {
+		t.Errorf("stats = %+v, want 1 dropped and 2 queued", stats)
+	}
+
+	close(exp.release)
+	shutdown(t, f)
+	if stats := f.Stats(); stats.Sent != 3 {
+		t.Errorf("sent = %d, want 3", stats.Sent)
+	}
+
+	var nilForwarder *Forwarder
+	if nilForwarder.Enabled(SignalMetrics) || nilForwarder.Enqueue(Payload{Signal: SignalMetrics}) {
+		t.Error("nil forwarder must forward nothing")
+	}
+}
+
+type metricsUpstream struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	mu       sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+	tenant   []string
+}
+
+func (s *metricsUpstream) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	s.tenant = append(s.tenant, md.Get("x-scope-orgid")...)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestForwarder_GRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	upstream := &metricsUpstream{}
+	colmetricspb.RegisterMetricsServiceServer(srv, upstream)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	cfg := fastConfig(lis.Addr().String(), ProtocolGRPC)
+	cfg.Headers = map[string]string{"X-Scope-OrgID": "staging"}
+	f, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if !f.EnqueueMessage(SignalMetrics, testMetricsRequest(), nil) {
+		t.Fatal("EnqueueMessage rejected request")
+	}
+	// JSON bodies from OTLP/HTTP are transcoded for gRPC upstreams, and
+	// the tenant header of the request replaces the configured one.
+	f.Enqueue(Payload{
+		Signal:      SignalMetrics,
+		Body:        []byte(`{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"json_metric"}]}]}]}`),
+		ContentType: ContentTypeJSON,
+		Headers:     map[string]string{"X-Scope-OrgID": "team-a"},
+	})
+	shutdown(t, f)
+
+	upstream.mu.Lock()
+	defer upstream.mu.Unlock()
+	if len(upstream.requests) != 2 {
+		t.Fatalf("upstream received %d requests, want 2 (stats %+v)", len(upstream.requests), f.Stats())
+	}
+	names := map[string]bool{}
+	for _, req := range upstream.requests {
+		names[req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Name] = true
+	}
+	if !names["http_requests_total"] || !names["json_metric"] {
+		t.Errorf("upstream metric names = %v", names)
+	}
+	if !proto.Equal(upstream.requests[0], testMetricsRequest()) && !proto.Equal(upstream.requests[1], testMetricsRequest()) {
+		t.Error("protobuf request was altered in transit")
+	}
+	tenants := map[string]bool{}
+	for _, id := range upstream.tenant {
+		tenants[id] = true
+	}
+	if len(upstream.tenant) != 2 || !tenants["staging"] || !tenants["team-a"] {
+		t.Errorf("upstream metadata = %v, want staging and team-a", upstream.tenant)
+	}
+}
+
+func TestGRPCExportError(t *testing.T) {
+	withRetryInfo := func(c codes.Code, delay time.Duration) error {
+		st, err := status.New(c, "busy").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Err()
+	}
+
+	for name, tc := range map[string]struct {
+		err        error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		"unavailable":                   {err: status.Error(codes.Unavailable, "down"), retryable: true},
+		"unavailable with retry info":   {err: withRetryInfo(codes.Unavailable, 3*time.Second), retryable: true, retryAfter: 3 * time.Second},
+		"data loss":                     {err: status.Error(codes.DataLoss, "lost"), retryable: true},
+		"resource exhausted":            {err: status.Error(codes.ResourceExhausted, "too big")},
+		"resource exhausted with retry": {err: withRetryInfo(codes.ResourceExhausted, time.Second), retryable: true, retryAfter: time.Second},
+		"invalid argument":              {err: status.Error(codes.InvalidArgument, "bad")},
+		"invalid argument retry info":   {err: withRetryInfo(codes.InvalidArgument, time.Second)},
+		"unauthenticated":               {err: status.Error(codes.Unauthenticated, "who")},
+	} {
+		got := grpcExportError(tc.err)
+		if got.retryable != tc.retryable || got.retryAfter != tc.retryAfter {
+			t.Errorf("%s: retryable=%v after=%v, want %v after %v", name, got.retryable, got.retryAfter, tc.retryable, tc.retryAfter)
+		}
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"missing endpoint":   {Protocol: ProtocolGRPC},
+		"bad protocol":       {Endpoint: "localhost:4317", Protocol: "kafka"},
+		"http without url":   {Endpoint: "localhost:4318", Protocol: ProtocolHTTP},
+		"unknown compressor": {Endpoint: "http://localhost:4318", Protocol: ProtocolHTTP, Compression: "lz4"},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package forward
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcMethods maps signals to the OTLP/gRPC Export methods.
+var grpcMethods = map[Signal]string{
+	SignalMetrics:  "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export",
+	SignalTraces:   "/opentelemetry.proto.collector.trace.v1.TraceService/Export",
+	SignalLogs:     "/opentelemetry.proto.collector.logs.v1.LogsService/Export",
+	SignalProfiles: profilespb.ProfilesService_Export_FullMethodName,
+}
+
+// grpcExporter sends payloads to an OTLP/gRPC endpoint. Protobuf bodies go
+// on the wire as-is through rawCodec, so unknown fields survive the hop.
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	md      metadata.MD
+	callOps []grpc.CallOption
+}
+
+func newGRPCExporter(cfg Config) (*grpcExporter, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", cfg.Endpoint, err)
+	}
+
+	md := metadata.MD{}
+	for k, v := range cfg.Headers {
+		md.Set(strings.ToLower(k), v)
+	}
+	callOps := []grpc.CallOption{grpc.ForceCodec(rawCodec{})}
+	if cfg.Compression == "gzip" {
+		callOps = append(callOps, grpc.UseCompressor(gzip.Name))
+	}
+	return &grpcExporter{conn: conn, md: md, callOps: callOps}, nil
+}
+
+func (e *grpcExporter) export(ctx context.Context, p Payload) error {
+	body, err := protobufBody(p)
+	if err != nil {
+		return &exportError{err: err}
+	}
+	md := e.md
+	if len(p.Headers) > 0 {
+		md = e.md.Copy()
+		for k, v := range p.Headers {
+			md.Set(strings.ToLower(k), v)
+		}
+	}
+	if len(md) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	var resp rawMessage
+	if err := e.conn.Invoke(ctx, grpcMethods[p.Signal], &rawMessage{data: body}, &resp, e.callOps...); err != nil {
+		return grpcExportError(err)
+	}
+	return nil
+}
+
+func (e *grpcExporter) close() error {
+	return e.conn.Close()
+}
+
+// grpcExportError classifies a failed OTLP/gRPC export per the OTLP
+// specification. ResourceExhausted is only retried when the server attached
+// RetryInfo to say it can recover; the delay of a RetryInfo is honoured for
+// every retryable code.
+func grpcExportError(err error) *exportError {
+	st := status.Convert(err)
+	var retryInfo *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retryInfo = ri
+		}
+	}
+
+	e := &exportError{err: err}
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange,
+		codes.Unavailable, codes.DataLoss:
+		e.retryable = true
+	case codes.ResourceExhausted:
+		e.retryable = retryInfo != nil
+	}
+	if e.retryable && retryInfo != nil {
+		e.retryAfter = retryInfo.GetRetryDelay().AsDuration()
+	}
+	return e
+}
+
+// protobufBody returns the payload as protobuf, transcoding JSON bodies
+// received over OTLP/HTTP. A body labelled JSON that does not parse as JSON
+// is assumed to already be protobuf, mirroring the receivers' fallback.
+func protobufBody(p Payload) ([]byte, error) {
+	if !strings.Contains(strings.ToLower(p.ContentType), "json") {
+		return p.Body, nil
+	}
+
+	var msg proto.Message
+	switch p.Signal {
+	case SignalMetrics:
+		msg = &colmetricspb.ExportMetricsServiceRequest{}
+	case SignalTraces:
+		msg = &coltracepb.ExportTraceServiceRequest{}
+	case SignalLogs:
+		msg = &collogspb.ExportLogsServiceRequest{}
+	case SignalProfiles:
+		msg = &profilespb.ExportProfilesServiceRequest{}
+	default:
+		return nil, fmt.Errorf("unknown signal %q", p.Signal)
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(p.Body, msg); err != nil {
+		return p.Body, nil
+	}
+	return proto.Marshal(msg)
+}
+
+// rawMessage carries pre-encoded protobuf bytes through gRPC.
+type rawMessage struct {
+	data []byte
+}
+
+// rawCodec passes rawMessage bytes through unchanged. It registers as
+// "proto" so the upstream sees a regular application/grpc+proto call.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec: unexpected type %T", v)
+	}
+	return m.data, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected type %T", v)
+	}
+	m.data = append(m.data[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
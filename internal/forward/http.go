@@ -0,0 +1,105 @@
+package forward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpPaths maps signals to the OTLP/HTTP export paths.
+var httpPaths = map[Signal]string{
+	SignalMetrics:  "/v1/metrics",
+	SignalTraces:   "/v1/traces",
+	SignalLogs:     "/v1/logs",
+	SignalProfiles: "/v1development/profiles",
+}
+
+// httpExporter posts payloads to an OTLP/HTTP endpoint with their original
+// content type.
+type httpExporter struct {
+	baseURL     string
+	headers     map[string]string
+	compression string
+	client      *http.Client
+}
+
+func newHTTPExporter(cfg Config) *httpExporter {
+	return &httpExporter{
+		baseURL:     strings.TrimSuffix(cfg.Endpoint, "/"),
+		headers:     cfg.Headers,
+		compression: cfg.Compression,
+		client:      &http.Client{},
+	}
+}
+
+func (e *httpExporter) export(ctx context.Context, p Payload) error {
+	body := p.Body
+	if e.compression == "gzip" {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return fmt.Errorf("gzip: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("gzip: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+httpPaths[p.Signal], bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	contentType := p.ContentType
+	if contentType == "" {
+		contentType = ContentTypeProtobuf
+	}
+	req.Header.Set("Content-Type", contentType)
+	if e.compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &exportError{err: err, retryable: true}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("upstream returned %s", resp.Status)
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return &exportError{err: err, retryable: true, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	return &exportError{err: err}
+}
+
+func (e *httpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds. HTTP dates
+// are not used by OTLP servers and are ignored.
+func parseRetryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
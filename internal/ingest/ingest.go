@@ -0,0 +1,227 @@
+// Package ingest decouples OTLP receivers from analysis and storage. The
+// receivers decode a request, submit the analyze-and-store work to a
+// bounded per-signal queue and acknowledge immediately; a pool of workers
+// drains the queues. A full queue is reported to the caller so it can
+// answer with OTLP backpressure instead of stalling the exporter.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Signal identifies the queue a job is submitted to.
+type Signal string
+
+// Queued signals.
+const (
+	SignalMetrics  Signal = "metrics"
+	SignalTraces   Signal = "traces"
+	SignalLogs     Signal = "logs"
+	SignalProfiles Signal = "profiles"
+)
+
+// AllSignals lists every signal with its own queue.
+var AllSignals = []Signal{SignalMetrics, SignalTraces, SignalLogs, SignalProfiles}
+
+// Errors returned by Submit.
+var (
+	ErrQueueFull = errors.New("ingestion queue full")
+	ErrClosed    = errors.New("ingestion pipeline shutting down")
+)
+
+// Job analyzes and stores one decoded export request.
+type Job func(ctx context.Context) error
+
+// Config configures a Pipeline.
+type Config struct {
+	// QueueSize is the number of export requests buffered per signal.
+	QueueSize int
+	// Workers is the number of goroutines draining each signal's queue.
+	Workers int
+	// RetryAfter is the delay suggested to clients when a queue is full.
+	RetryAfter time.Duration
+}
+
+// DefaultConfig returns the pipeline defaults.
+func DefaultConfig() Config {
+	return Config{
+		QueueSize:  1000,
+		Workers:    4,
+		RetryAfter: time.Second,
+	}
+}
+
+// QueueStats describes one signal's queue.
+type QueueStats struct {
+	Depth     int    `json:"depth"`
+	Capacity  int    `json:"capacity"`
+	Processed uint64 `json:"processed"`
+	Failed    uint64 `json:"failed"`
+	Dropped   uint64 `json:"dropped"`
+}
+
+type queue struct {
+	jobs      chan Job
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// Pipeline runs submitted jobs on per-signal worker pools. A nil Pipeline
+// runs every job synchronously in the caller, which keeps receivers that
+// were built without one behaving as before.
+type Pipeline struct {
+	cfg    Config
+	queues map[Signal]*queue
+
+	mu     sync.RWMutex
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New starts a pipeline. Zero fields of cfg take their defaults.
+func New(cfg Config) *Pipeline {
+	def := DefaultConfig()
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = def.Workers
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = def.RetryAfter
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pipeline{
+		cfg:    cfg,
+		queues: make(map[Signal]*queue, len(AllSignals)),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for _, s := range AllSignals {
+		q := &queue{jobs: make(chan Job, cfg.QueueSize)}
+		p.queues[s] = q
+		for i := 0; i < cfg.Workers; i++ {
+			p.wg.Add(1)
+			go p.worker(s, q)
+		}
+	}
+	return p
+}
+
+// Submit queues job on the signal's queue without blocking. It returns
+// ErrQueueFull when the queue has no room and ErrClosed after Shutdown. On
+// a nil Pipeline the job runs immediately with ctx and its error is
+// returned.
+func (p *Pipeline) Submit(ctx context.Context, s Signal, job Job) error {
+	if p == nil {
+		return job(ctx)
+	}
+	q, ok := p.queues[s]
+	if !ok {
+		return fmt.Errorf("unknown ingestion signal %q", s)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		q.dropped.Add(1)
+		return ErrClosed
+	}
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		if q.dropped.Add(1)%100 == 1 {
+			log.Printf("Ingestion queue for %s full (%d), rejecting requests", s, p.cfg.QueueSize)
+		}
+		return ErrQueueFull
+	}
+}
+
+// RetryAfter returns the delay clients should wait after a rejected submit.
+func (p *Pipeline) RetryAfter() time.Duration {
+	if p == nil {
+		return DefaultConfig().RetryAfter
+	}
+	return p.cfg.RetryAfter
+}
+
+// Stats returns a snapshot of every queue, keyed by signal. It returns nil
+// for a nil Pipeline.
+func (p *Pipeline) Stats() map[Signal]QueueStats {
+	if p == nil {
+		return nil
+	}
+	stats := make(map[Signal]QueueStats, len(p.queues))
+	for s, q := range p.queues {
+		stats[s] = QueueStats{
+			Depth:     len(q.jobs),
+			Capacity:  cap(q.jobs),
+			Processed: q.processed.Load(),
+			Failed:    q.failed.Load(),
+			Dropped:   q.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish.
+// When ctx expires first, running jobs see their context cancelled and the
+// jobs still queued are counted as dropped.
+func (p *Pipeline) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, q := range p.queues {
+			close(q.jobs)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		err = ctx.Err()
+	}
+	p.cancel()
+	return err
+}
+
+func (p *Pipeline) worker(s Signal, q *queue) {
+	defer p.wg.Done()
+	for job := range q.jobs {
+		if p.ctx.Err() != nil {
+			q.dropped.Add(1)
+			continue
+		}
+		if err := job(p.ctx); err != nil {
+			q.failed.Add(1)
+			log.Printf("Ingestion of %s failed: %v", s, err)
+			continue
+		}
+		q.processed.Add(1)
+	}
+}
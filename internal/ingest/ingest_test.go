@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipeline_RejectsWhenFullAndDrainsOnShutdown(t *testing.T) {
+	p := New(Config{QueueSize: 2, Workers: 1})
+
+	release := make(chan struct{})
+	var processed atomic.Int32
+	blocking := func(ctx context.Context) error {
+		<-release
+		processed.Add(1)
+		return nil
+	}
+
+	// One job occupies the worker, two more fill the queue.
+	if err := p.Submit(context.Background(), SignalMetrics, blocking); err != nil {
+		t.Fatalf("first submit: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Stats()[SignalMetrics].Depth != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker did not pick up the first job")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.Submit(context.Background(), SignalMetrics, blocking); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+	if err := p.Submit(context.Background(), SignalMetrics, blocking); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("submit to full queue: err = %v, want ErrQueueFull", err)
+	}
+	// Other signals have their own queue.
+	if err := p.Submit(context.Background(), SignalLogs, func(context.Context) error { return errors.New("boom") }); err != nil {
+		t.Fatalf("logs submit: %v", err)
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if n := processed.Load(); n != 3 {
+		t.Errorf("processed %d jobs, want 3", n)
+	}
+
+	stats := p.Stats()
+	if got := stats[SignalMetrics]; got.Processed != 3 || got.Dropped != 1 || got.Capacity != 2 {
+		t.Errorf("metrics stats = %+v", got)
+	}
+	if got := stats[SignalLogs]; got.Failed != 1 {
+		t.Errorf("logs stats = %+v, want one failure", got)
+	}
+	if err := p.Submit(context.Background(), SignalMetrics, blocking); !errors.Is(err, ErrClosed) {
+		t.Errorf("submit after shutdown: err = %v, want ErrClosed", err)
+	}
+}
+
+func TestPipeline_NilRunsSynchronously(t *testing.T) {
+	var p *Pipeline
+	want := errors.New("store failed")
+	if err := p.Submit(context.Background(), SignalTraces, func(context.Context) error { return want }); err != want {
+		t.Errorf("nil pipeline returned %v, want the job's error", err)
+	}
+	if p.Stats() != nil {
+		t.Error("nil pipeline reports stats")
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
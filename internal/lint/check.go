@@ -0,0 +1,210 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// subject is one stored name a rule can check. Label and attribute keys
+// appear once per metric, span or log that carries them.
+type subject struct {
+	target   string
+	name     string
+	owner    string
+	metric   *models.MetricMetadata
+	services map[string]int64
+}
+
+// Check runs every rule against the stored names. Each name or key is
+// reported once per rule, with the services and owners it was seen with.
+func (s *RuleSet) Check(metrics []*models.MetricMetadata, spans []*models.SpanMetadata, logs []*models.LogMetadata) *models.LintResponse {
+	if s.Len() == 0 {
+		return models.NewLintResponse(0, nil)
+	}
+	subjects := collect(metrics, spans, logs)
+
+	type key struct{ rule, target, name string }
+	type agg struct {
+		v        models.LintViolation
+		owners   map[string]bool
+		services map[string]bool
+	}
+	found := make(map[key]*agg)
+	var order []key
+
+	for _, r := range s.rules {
+		for _, subj := range subjects {
+			if !r.targets[subj.target] || !r.applies(subj) {
+				continue
+			}
+			msg := r.check(subj)
+			if msg == "" {
+				continue
+			}
+			k := key{r.Name, subj.target, subj.name}
+			a, ok := found[k]
+			if !ok {
+				a = &agg{
+					v: models.LintViolation{
+						Rule:     r.Name,
+						Target:   subj.target,
+						Name:     subj.name,
+						Severity: r.Severity,
+						Message:  msg,
+					},
+					owners:   make(map[string]bool),
+					services: make(map[string]bool),
+				}
+				found[k] = a
+				order = append(order, k)
+			}
+			if subj.owner != "" {
+				a.owners[subj.owner] = true
+			}
+			for svc := range subj.services {
+				a.services[svc] = true
+			}
+		}
+	}
+
+	violations := make([]models.LintViolation, 0, len(order))
+	for _, k := range order {
+		a := found[k]
+		a.v.FoundIn = sortedSet(a.owners)
+		a.v.Services = sortedSet(a.services)
+		violations = append(violations, a.v)
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Severity != b.Severity {
+			return a.Severity == "critical"
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Name < b.Name
+	})
+	return models.NewLintResponse(s.Len(), violations)
+}
+
+// collect lists the names and keys of every target.
+func collect(metrics []*models.MetricMetadata, spans []*models.SpanMetadata, logs []*models.LogMetadata) []subject {
+	var out []subject
+	attrKeys := func(keys map[string]*models.KeyMetadata, owner string, services map[string]int64) {
+		for k := range keys {
+			out = append(out, subject{target: models.LintTargetAttributeKey, name: k, owner: owner, services: services})
+		}
+	}
+
+	for _, m := range metrics {
+		out = append(out, subject{target: models.LintTargetMetricName, name: m.Name, metric: m, services: m.Services})
+		for k := range m.LabelKeys {
+			out = append(out, subject{target: models.LintTargetMetricLabel, name: k, owner: m.Name, metric: m, services: m.Services})
+		}
+		attrKeys(m.ResourceKeys, m.Name, m.Services)
+	}
+	for _, sp := range spans {
+		out = append(out, subject{target: models.LintTargetSpanName, name: sp.Name, services: sp.Services})
+		attrKeys(sp.AttributeKeys, sp.Name, sp.Services)
+		attrKeys(sp.ResourceKeys, sp.Name, sp.Services)
+	}
+	for _, l := range logs {
+		owner := "logs:" + l.Severity
+		for _, name := range l.EventNames {
+			out = append(out, subject{target: models.LintTargetLogEventName, name: name, owner: owner, services: l.Services})
+		}
+		attrKeys(l.AttributeKeys, owner, l.Services)
+		attrKeys(l.ResourceKeys, owner, l.Services)
+	}
+	return out
+}
+
+// applies reports whether the rule's conditions hold for subj.
+func (r *compiledRule) applies(subj subject) bool {
+	if r.when != nil && !r.when.MatchString(subj.name) {
+		return false
+	}
+	if len(r.MetricTypes) > 0 && !contains(r.MetricTypes, metricType(subj.metric)) {
+		return false
+	}
+	if len(r.Units) > 0 && (subj.metric == nil || !contains(r.Units, subj.metric.Unit)) {
+		return false
+	}
+	return true
+}
+
+// check returns why subj breaks the rule, or "" if it does not.
+func (r *compiledRule) check(subj subject) string {
+	var reasons []string
+	if r.match != nil && !r.match.MatchString(subj.name) {
+		reasons = append(reasons, fmt.Sprintf("does not match %s", r.Match))
+	}
+	if r.notMatch != nil && r.notMatch.MatchString(subj.name) {
+		reasons = append(reasons, fmt.Sprintf("matches %s", r.NotMatch))
+	}
+	n := utf8.RuneCountInString(subj.name)
+	if r.MinLength > 0 && n < r.MinLength {
+		reasons = append(reasons, fmt.Sprintf("is %d characters, minimum %d", n, r.MinLength))
+	}
+	if r.MaxLength > 0 && n > r.MaxLength {
+		reasons = append(reasons, fmt.Sprintf("is %d characters, maximum %d", n, r.MaxLength))
+	}
+	if len(r.AllowedTypes) > 0 {
+		if t := metricType(subj.metric); !contains(r.AllowedTypes, t) {
+			reasons = append(reasons, fmt.Sprintf("is a %s, allowed: %s", t, strings.Join(r.AllowedTypes, ", ")))
+		}
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	msg := strings.Join(reasons, "; ")
+	if r.Description != "" {
+		msg = r.Description + ": " + msg
+	}
+	return msg
+}
+
+// metricType maps metric data to the type names used in rules.
+func metricType(m *models.MetricMetadata) string {
+	if m == nil {
+		return ""
+	}
+	switch d := m.Data.(type) {
+	case *models.SumMetric:
+		if d.IsMonotonic {
+			return "counter"
+		}
+		return "updowncounter"
+	case *models.GaugeMetric:
+		return "gauge"
+	case *models.HistogramMetric:
+		return "histogram"
+	case *models.ExponentialHistogramMetric:
+		return "exponential_histogram"
+	case *models.SummaryMetric:
+		return "summary"
+	}
+	return "unknown"
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for s := range set {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+const testRules = `
+rules:
+  - name: snake_case_labels
+    targets: [metric_label, attribute_key]
+    match: '^[a-z_][a-z0-9_.]*$'
+  - name: seconds_suffix
+    targets: [metric_name]
+    units: [s]
+    match: '_seconds$'
+  - name: total_only_on_counters
+    targets: [metric_name]
+    when: '_total$'
+    allowed_types: [counter]
+    severity: critical
+  - name: lowercase_span_names
+    targets: [span_name]
+    not_match: '[A-Z]'
+  - name: short_event_names
+    targets: [log_event_name]
+    max_length: 10
+`
+
+func newMetric(name, unit string, data models.MetricData, service string, labels ...string) *models.MetricMetadata {
+	m := models.NewMetricMetadata(name, data)
+	m.Unit = unit
+	m.Services[service] = 1
+	for _, l := range labels {
+		m.LabelKeys[l] = models.NewKeyMetadata()
+	}
+	return m
+}
+
+func findViolation(resp *models.LintResponse, rule, name string) *models.LintViolation {
+	for i := range resp.Violations {
+		if resp.Violations[i].Rule == rule && resp.Violations[i].Name == name {
+			return &resp.Violations[i]
+		}
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	rs, err := Parse([]byte(testRules))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	metrics := []*models.MetricMetadata{
+		newMetric("http_request_duration", "s", &models.HistogramMetric{}, "checkout", "httpMethod", "route"),
+		newMetric("queue_wait_seconds", "s", &models.HistogramMetric{}, "search", "httpMethod"),
+		newMetric("requests_total", "1", &models.SumMetric{IsMonotonic: true}, "checkout"),
+		newMetric("inflight_total", "1", &models.GaugeMetric{}, "search"),
+	}
+	span := models.NewSpanMetadata("GET /orders", 2, "Server")
+	span.Services["checkout"] = 1
+	span.AttributeKeys["http.route"] = models.NewKeyMetadata()
+	lowerSpan := models.NewSpanMetadata("process order", 1, "Internal")
+	lowerSpan.Services["worker"] = 1
+	logMeta := &models.LogMetadata{
+		Severity:   "INFO",
+		EventNames: []string{"login", "user.session.refreshed"},
+		Services:   map[string]int64{"auth": 1},
+	}
+
+	resp := rs.Check(metrics, []*models.SpanMetadata{span, lowerSpan}, []*models.LogMetadata{logMeta})
+
+	if resp.Rules != 5 {
+		t.Errorf("rules = %d, want 5", resp.Rules)
+	}
+	if resp.Total != 5 {
+		t.Errorf("violations = %+v, want 5", resp.Violations)
+	}
+	if resp.Violations[0].Rule != "total_only_on_counters" {
+		t.Errorf("first violation = %+v, want the critical one", resp.Violations[0])
+	}
+
+	label := findViolation(resp, "snake_case_labels", "httpMethod")
+	if label == nil {
+		t.Fatalf("httpMethod label not reported: %+v", resp.Violations)
+	}
+	if len(label.FoundIn) != 2 || len(label.Services) != 2 || label.Services[0] != "checkout" {
+		t.Errorf("httpMethod found in %v, services %v", label.FoundIn, label.Services)
+	}
+	if findViolation(resp, "seconds_suffix", "http_request_duration") == nil {
+		t.Error("missing _seconds suffix not reported")
+	}
+	if findViolation(resp, "seconds_suffix", "queue_wait_seconds") != nil {
+		t.Error("queue_wait_seconds reported although it has the suffix")
+	}
+	if findViolation(resp, "total_only_on_counters", "requests_total") != nil {
+		t.Error("monotonic sum ending in _total reported")
+	}
+	if v := findViolation(resp, "total_only_on_counters", "inflight_total"); v == nil || v.Severity != "critical" {
+		t.Errorf("inflight_total violation = %+v", v)
+	}
+	if findViolation(resp, "lowercase_span_names", "GET /orders") == nil {
+		t.Error("uppercase span name not reported")
+	}
+	if findViolation(resp, "short_event_names", "user.session.refreshed") == nil {
+		t.Error("long event name not reported")
+	}
+
+	if resp.ByService["checkout"] != 3 {
+		t.Errorf("checkout violations = %d, want 3 (%v)", resp.ByService["checkout"], resp.ByService)
+	}
+	search := resp.Filter("search", "")
+	if search.Total != 2 {
+		t.Errorf("search violations = %+v, want 2", search.Violations)
+	}
+	if got := resp.Filter("", "snake_case_labels").Total; got != 1 {
+		t.Errorf("snake_case_labels violations = %d, want 1", got)
+	}
+}
@@ -0,0 +1,180 @@
+// Package lint checks metric names, label and attribute keys, span names and
+// log event names against naming rules loaded from a YAML file.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// Rule is one naming rule as written in the rules file.
+//
+// A rule applies to every name of its targets for which all conditions hold
+// (When, MetricTypes, Units) and reports the names failing any check
+// (Match, NotMatch, MinLength, MaxLength, AllowedTypes).
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Targets     []string `yaml:"targets"`
+	Severity    string   `yaml:"severity"` // warning (default) or critical
+
+	// When limits the rule to names matching this regex.
+	When string `yaml:"when"`
+	// MetricTypes limits metric rules to these types.
+	MetricTypes []string `yaml:"metric_types"`
+	// Units limits metric rules to metrics with one of these units.
+	Units []string `yaml:"units"`
+
+	Match        string   `yaml:"match"`
+	NotMatch     string   `yaml:"not_match"`
+	MinLength    int      `yaml:"min_length"`
+	MaxLength    int      `yaml:"max_length"`
+	AllowedTypes []string `yaml:"allowed_types"`
+}
+
+// RulesFile is the layout of a rules file.
+type RulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// metricTypes are the type names used by metric_types and allowed_types.
+var metricTypes = map[string]bool{
+	"counter":               true,
+	"updowncounter":         true,
+	"gauge":                 true,
+	"histogram":             true,
+	"exponential_histogram": true,
+	"summary":               true,
+}
+
+var targets = map[string]bool{
+	models.LintTargetMetricName:   true,
+	models.LintTargetMetricLabel:  true,
+	models.LintTargetAttributeKey: true,
+	models.LintTargetSpanName:     true,
+	models.LintTargetLogEventName: true,
+}
+
+type compiledRule struct {
+	Rule
+	targets  map[string]bool
+	when     *regexp.Regexp
+	match    *regexp.Regexp
+	notMatch *regexp.Regexp
+}
+
+// RuleSet is a validated, compiled set of rules. A nil RuleSet has no rules.
+type RuleSet struct {
+	rules []*compiledRule
+}
+
+// Load reads and compiles a rules file.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading lint rules: %w", err)
+	}
+	rs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// Parse compiles rules in the rules file format.
+func Parse(data []byte) (*RuleSet, error) {
+	var f RulesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing lint rules: %w", err)
+	}
+
+	rs := &RuleSet{}
+	seen := make(map[string]bool, len(f.Rules))
+	for i, r := range f.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+
+		c, err := compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		rs.rules = append(rs.rules, c)
+	}
+	return rs, nil
+}
+
+func compile(r Rule) (*compiledRule, error) {
+	c := &compiledRule{Rule: r, targets: make(map[string]bool)}
+	if len(r.Targets) == 0 {
+		return nil, fmt.Errorf("at least one target is required")
+	}
+	for _, t := range r.Targets {
+		if !targets[t] {
+			return nil, fmt.Errorf("unknown target %q", t)
+		}
+		c.targets[t] = true
+	}
+
+	switch r.Severity {
+	case "":
+		c.Severity = "warning"
+	case "warning", "critical":
+	default:
+		return nil, fmt.Errorf("severity must be 'warning' or 'critical', got %q", r.Severity)
+	}
+
+	if r.Match == "" && r.NotMatch == "" && r.MinLength == 0 && r.MaxLength == 0 && len(r.AllowedTypes) == 0 {
+		return nil, fmt.Errorf("no check: set match, not_match, min_length, max_length or allowed_types")
+	}
+	if r.MinLength < 0 || r.MaxLength < 0 || (r.MaxLength > 0 && r.MinLength > r.MaxLength) {
+		return nil, fmt.Errorf("invalid length bounds %d-%d", r.MinLength, r.MaxLength)
+	}
+
+	metricOnly := len(r.MetricTypes) > 0 || len(r.Units) > 0 || len(r.AllowedTypes) > 0
+	if metricOnly {
+		for t := range c.targets {
+			if t != models.LintTargetMetricName && t != models.LintTargetMetricLabel {
+				return nil, fmt.Errorf("metric_types, units and allowed_types only apply to metric targets, not %q", t)
+			}
+		}
+	}
+	for _, list := range [][]string{r.MetricTypes, r.AllowedTypes} {
+		for _, t := range list {
+			if !metricTypes[t] {
+				return nil, fmt.Errorf("unknown metric type %q", t)
+			}
+		}
+	}
+
+	var err error
+	for _, re := range []struct {
+		expr string
+		dst  **regexp.Regexp
+	}{{r.When, &c.when}, {r.Match, &c.match}, {r.NotMatch, &c.notMatch}} {
+		if re.expr == "" {
+			continue
+		}
+		if *re.dst, err = regexp.Compile(re.expr); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Len returns the number of rules.
+func (s *RuleSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
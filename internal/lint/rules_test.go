@@ -0,0 +1,61 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad_BundledRules(t *testing.T) {
+	rs, err := Load("../../config/lint-rules.yaml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rs.Len() == 0 {
+		t.Fatal("bundled rules file has no rules")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"missing name", "rules:\n  - targets: [span_name]\n    max_length: 10\n", "name is required"},
+		{"duplicate", "rules:\n  - {name: a, targets: [span_name], max_length: 10}\n  - {name: a, targets: [span_name], max_length: 10}\n", "duplicate rule"},
+		{"no target", "rules:\n  - {name: a, max_length: 10}\n", "target is required"},
+		{"unknown target", "rules:\n  - {name: a, targets: [trace_id], max_length: 10}\n", "unknown target"},
+		{"no check", "rules:\n  - {name: a, targets: [span_name]}\n", "no check"},
+		{"bad severity", "rules:\n  - {name: a, targets: [span_name], max_length: 10, severity: error}\n", "severity"},
+		{"bad regex", "rules:\n  - {name: a, targets: [span_name], match: '('}\n", "missing closing"},
+		{"unknown type", "rules:\n  - {name: a, targets: [metric_name], allowed_types: [sum]}\n", "unknown metric type"},
+		{"type on span", "rules:\n  - {name: a, targets: [span_name], units: [s], max_length: 10}\n", "only apply to metric targets"},
+		{"length bounds", "rules:\n  - {name: a, targets: [span_name], min_length: 10, max_length: 5}\n", "invalid length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_DefaultSeverity(t *testing.T) {
+	rs, err := Parse([]byte("rules:\n  - {name: a, targets: [span_name], max_length: 10}\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := rs.rules[0].Severity; got != "warning" {
+		t.Errorf("severity = %q, want warning", got)
+	}
+}
+
+func TestRuleSet_Nil(t *testing.T) {
+	var rs *RuleSet
+	resp := rs.Check(nil, nil, nil)
+	if rs.Len() != 0 || resp.Total != 0 || resp.Violations == nil {
+		t.Errorf("nil rule set = %+v", resp)
+	}
+}
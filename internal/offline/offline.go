@@ -0,0 +1,403 @@
+// Package offline analyzes OTLP files recorded by the OpenTelemetry
+// Collector file exporter, so cardinality reviews can be reproduced from
+// archived data without running the receivers.
+//
+// Two encodings are understood, matching the exporter's "format" option:
+//
+//   - json: one ExportXServiceRequest per line (OTLP/JSON)
+//   - proto: each request prefixed with its 4-byte big-endian length
+//
+// Whole files may be gzip or zstd compressed (rotated archives), and
+// individual proto records may be zstd compressed, which is what the file
+// exporter writes with "compression: zstd".
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
+	"github.com/fidde/otlp_cardinality_checker/internal/patterns"
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	"github.com/klauspost/compress/zstd"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxRecordBytes caps a single JSON line or proto record.
+const maxRecordBytes = 64 << 20
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ParseSignal maps a user-supplied signal name (metrics, traces, spans, logs,
+// profiles, singular or plural) to its models.SignalType* value.
+func ParseSignal(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case "metric", "metrics":
+		return models.SignalTypeMetric, nil
+	case "span", "spans", "trace", "traces":
+		return models.SignalTypeSpan, nil
+	case "log", "logs":
+		return models.SignalTypeLog, nil
+	case "profile", "profiles":
+		return models.SignalTypeProfile, nil
+	}
+	return "", fmt.Errorf("unknown signal %q (want metrics, traces, logs or profiles)", s)
+}
+
+// signalFromFilename infers the signal from names like "metrics.proto" or
+// "otlp-traces-2024.json.zst", as written by per-signal file exporters. Only
+// whole words count: "catalog.json" has no signal and "blog-metrics.json"
+// holds metrics.
+func signalFromFilename(path string) string {
+	words := strings.FieldsFunc(filepath.Base(path), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		if signal, err := ParseSignal(w); err == nil {
+			return signal
+		}
+	}
+	return ""
+}
+
+// jsonSignalKeys maps the top-level OTLP/JSON field to its signal.
+var jsonSignalKeys = map[string]string{
+	"resourceMetrics":   models.SignalTypeMetric,
+	"resource_metrics":  models.SignalTypeMetric,
+	"resourceSpans":     models.SignalTypeSpan,
+	"resource_spans":    models.SignalTypeSpan,
+	"resourceLogs":      models.SignalTypeLog,
+	"resource_logs":     models.SignalTypeLog,
+	"resourceProfiles":  models.SignalTypeProfile,
+	"resource_profiles": models.SignalTypeProfile,
+}
+
+// FileStats summarizes one analyzed file.
+type FileStats struct {
+	Path     string         `json:"path"`
+	Format   string         `json:"format"`
+	Requests map[string]int `json:"requests"`
+	Skipped  int            `json:"skipped"`
+}
+
+// Analyzer feeds recorded export requests through the signal analyzers into
+// a store.
+type Analyzer struct {
+	store            storage.Storage
+	metricsAnalyzer  *analyzer.MetricsAnalyzer
+	tracesAnalyzer   *analyzer.TracesAnalyzer
+	logsAnalyzer     *analyzer.LogsAnalyzer
+	profilesAnalyzer *analyzer.ProfilesAnalyzer
+	spanMetrics      *spanmetrics.Estimator
+	zstdDecoder      *zstd.Decoder
+}
+
+// New creates an analyzer writing into store. Log analysis follows the
+// store's template configuration, as the receivers do.
+func New(store storage.Storage) *Analyzer {
+	pats, err := patterns.LoadPatterns("config/patterns.yaml")
+	if err != nil {
+		pats = nil
+	}
+
+	var logsAnalyzer *analyzer.LogsAnalyzer
+	if store.UseAutoTemplate() {
+		logsAnalyzer = analyzer.NewLogsAnalyzerWithAutoTemplateAndCatalog(store.AutoTemplateCfg(), pats, store)
+	} else {
+		logsAnalyzer = analyzer.NewLogsAnalyzerWithCatalog(store)
+	}
+	if store.PodLogEnrichment() {
+		logsAnalyzer.SetPodLogEnrichment(true, store.PodLogServiceLabels())
+	}
+
+	// A nil reader yields a decoder usable only through DecodeAll.
+	dec, _ := zstd.NewReader(nil)
+
+	return &Analyzer{
+		store:            store,
+		metricsAnalyzer:  analyzer.NewMetricsAnalyzerWithCatalog(store),
+		tracesAnalyzer:   analyzer.NewTracesAnalyzerWithCatalog(store),
+		logsAnalyzer:     logsAnalyzer,
+		profilesAnalyzer: analyzer.NewProfilesAnalyzerWithCatalog(store),
+		zstdDecoder:      dec,
+	}
+}
+
+// SetSpanMetrics feeds the analyzed spans to the spanmetrics estimator e.
+func (a *Analyzer) SetSpanMetrics(e *spanmetrics.Estimator) {
+	a.spanMetrics = e
+}
+
+// Close releases the decoder resources.
+func (a *Analyzer) Close() {
+	a.zstdDecoder.Close()
+}
+
+// AnalyzeFile analyzes one file. signal is a models.SignalType* value and is
+// only required for proto files whose name does not reveal the signal.
+func (a *Analyzer) AnalyzeFile(ctx context.Context, path, signal string) (*FileStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if signal == "" {
+		signal = signalFromFilename(path)
+	}
+	stats, err := a.AnalyzeReader(ctx, f, signal)
+	if stats != nil {
+		stats.Path = path
+	}
+	if err != nil {
+		return stats, fmt.Errorf("%s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// AnalyzeReader detects compression and format and analyzes every record.
+func (a *Analyzer) AnalyzeReader(ctx context.Context, r io.Reader, signal string) (*FileStats, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	magic, _ := br.Peek(4)
+
+	var src io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		defer gz.Close()
+		src = gz
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	br = bufio.NewReaderSize(src, 64<<10)
+	stats := &FileStats{Requests: make(map[string]int)}
+
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	if first == '{' {
+		stats.Format = "json"
+		return stats, a.readJSONLines(ctx, br, signal, stats)
+	}
+
+	stats.Format = "proto"
+	if signal == "" {
+		return stats, errors.New("cannot infer the signal of a protobuf file; name it after the signal or pass --signal")
+	}
+	return stats, a.readProtoRecords(ctx, br, signal, stats)
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+func (a *Analyzer) readJSONLines(ctx context.Context, r io.Reader, signal string, stats *FileStats) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1<<20), maxRecordBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		lineSignal := jsonSignal(data)
+		if lineSignal == "" {
+			lineSignal = signal
+		}
+		if lineSignal == "" {
+			log.Printf("Skipping line %d: no resourceMetrics, resourceSpans, resourceLogs or resourceProfiles", line)
+			stats.Skipped++
+			continue
+		}
+		if err := a.analyze(ctx, lineSignal, data, true); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		stats.Requests[lineSignal]++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
+
+// jsonSignal returns the signal named by the first top-level key of an
+// OTLP/JSON request, or "" when it is not recognized.
+func jsonSignal(data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return ""
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		key, _ := tok.(string)
+		if sig, ok := jsonSignalKeys[key]; ok {
+			return sig
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+func (a *Analyzer) readProtoRecords(ctx context.Context, r io.Reader, signal string, stats *FileStats) error {
+	var lenBuf [4]byte
+	for record := 1; ; record++ {
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("record %d: read length: %w", record, err)
+		}
+		n := binary.BigEndian.Uint32(lenBuf[:])
+		if n > maxRecordBytes {
+			return fmt.Errorf("record %d: length %d exceeds %d bytes", record, n, maxRecordBytes)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("record %d: %w", record, err)
+		}
+
+		if bytes.HasPrefix(data, zstdMagic) {
+			decoded, err := a.zstdDecoder.DecodeAll(data, nil)
+			if err != nil {
+				return fmt.Errorf("record %d: zstd: %w", record, err)
+			}
+			data = decoded
+		}
+
+		if err := a.analyze(ctx, signal, data, false); err != nil {
+			return fmt.Errorf("record %d: %w", record, err)
+		}
+		stats.Requests[signal]++
+	}
+}
+
+// analyze decodes one export request and stores the extracted metadata.
+func (a *Analyzer) analyze(ctx context.Context, signal string, data []byte, isJSON bool) error {
+	unmarshal := func(m proto.Message) error {
+		if isJSON {
+			return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+		}
+		return proto.Unmarshal(data, m)
+	}
+
+	switch signal {
+	case models.SignalTypeMetric:
+		var req colmetricspb.ExportMetricsServiceRequest
+		if err := unmarshal(&req); err != nil {
+			return fmt.Errorf("parse metrics: %w", err)
+		}
+		metadata, err := a.metricsAnalyzer.AnalyzeWithContext(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("analyze metrics: %w", err)
+		}
+		for _, m := range metadata {
+			if err := a.store.StoreMetric(ctx, m); err != nil {
+				return err
+			}
+		}
+	case models.SignalTypeSpan:
+		var req coltracepb.ExportTraceServiceRequest
+		if err := unmarshal(&req); err != nil {
+			return fmt.Errorf("parse traces: %w", err)
+		}
+		metadata, err := a.tracesAnalyzer.AnalyzeWithSpanMetrics(ctx, &req, a.spanMetrics.Recorder(ctx))
+		if err != nil {
+			return fmt.Errorf("analyze traces: %w", err)
+		}
+		for _, m := range metadata {
+			if err := a.store.StoreSpan(ctx, m); err != nil {
+				return err
+			}
+		}
+	case models.SignalTypeLog:
+		var req collogspb.ExportLogsServiceRequest
+		if err := unmarshal(&req); err != nil {
+			return fmt.Errorf("parse logs: %w", err)
+		}
+		metadata, err := a.logsAnalyzer.AnalyzeWithContext(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("analyze logs: %w", err)
+		}
+		for _, m := range metadata {
+			if err := a.store.StoreLog(ctx, m); err != nil {
+				return err
+			}
+		}
+	case models.SignalTypeProfile:
+		ps, ok := a.store.(storage.ProfileStore)
+		if !ok {
+			return fmt.Errorf("store does not keep profiles")
+		}
+		var req profilespb.ExportProfilesServiceRequest
+		if err := unmarshal(&req); err != nil {
+			return fmt.Errorf("parse profiles: %w", err)
+		}
+		metadata, err := a.profilesAnalyzer.AnalyzeWithContext(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("analyze profiles: %w", err)
+		}
+		for _, m := range metadata {
+			if err := ps.StoreProfile(ctx, m); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported signal %q", signal)
+	}
+	return nil
+}
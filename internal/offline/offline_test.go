@@ -0,0 +1,217 @@
+package offline
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	"github.com/klauspost/compress/zstd"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const jsonLines = `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scopeMetrics":[{"metrics":[{"name":"http.server.duration","gauge":{"dataPoints":[{"asDouble":1,"attributes":[{"key":"route","value":{"stringValue":"/users"}}]}]}}]}]}]}
+
+{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scopeLogs":[{"logRecords":[{"severityText":"INFO","body":{"stringValue":"user 42 logged in"}}]}]}]}
+{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},"scopeSpans":[{"spans":[{"name":"GET /users","kind":2}]}]}]}
+`
+
+func traceRequest(name string) *coltracepb.ExportTraceServiceRequest {
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+				Key:   "service.name",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "worker"}},
+			}}},
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Spans: []*tracepb.Span{{Name: name}},
+			}},
+		}},
+	}
+}
+
+// protoRecords encodes requests the way the file exporter's proto format
+// does, optionally zstd-compressing each record.
+func protoRecords(t *testing.T, compress bool, msgs ...proto.Message) []byte {
+	t.Helper()
+	enc, _ := zstd.NewWriter(nil)
+	defer enc.Close()
+
+	var buf bytes.Buffer
+	for _, m := range msgs {
+		b, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if compress {
+			b = enc.EncodeAll(b, nil)
+		}
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+		buf.Write(n[:])
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestAnalyzeFile_JSONLinesGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(jsonLines))
+	gz.Close()
+	path := writeFile(t, "dump.json.gz", buf.Bytes())
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	a := New(store)
+	defer a.Close()
+
+	stats, err := a.AnalyzeFile(context.Background(), path, "")
+	if err != nil {
+		t.Fatalf("AnalyzeFile: %v", err)
+	}
+	if stats.Format != "json" {
+		t.Errorf("Format = %q, want json", stats.Format)
+	}
+	for _, sig := range []string{models.SignalTypeMetric, models.SignalTypeSpan, models.SignalTypeLog} {
+		if stats.Requests[sig] != 1 {
+			t.Errorf("Requests[%s] = %d, want 1", sig, stats.Requests[sig])
+		}
+	}
+
+	ctx := context.Background()
+	metric, err := store.GetMetric(ctx, "http.server.duration")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	if _, ok := metric.LabelKeys["route"]; !ok {
+		t.Errorf("LabelKeys = %v, want route", metric.LabelKeys)
+	}
+	if _, err := store.GetSpan(ctx, "GET /users"); err != nil {
+		t.Errorf("GetSpan: %v", err)
+	}
+	logs, _ := store.ListLogs(ctx, "")
+	if len(logs) != 1 {
+		t.Errorf("got %d log patterns, want 1", len(logs))
+	}
+}
+
+func TestAnalyzeFile_ProtoRecords(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		signal       string
+		wholeFileZst bool
+		recordZst    bool
+	}{
+		{name: "plain, signal from file name", file: "traces.proto"},
+		{name: "per-record zstd", file: "otlp-spans.bin", recordZst: true},
+		{name: "zstd archive, explicit signal", file: "archive.zst", signal: models.SignalTypeSpan, wholeFileZst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := protoRecords(t, tt.recordZst, traceRequest("checkout"), traceRequest("payment"))
+			if tt.wholeFileZst {
+				enc, _ := zstd.NewWriter(nil)
+				data = enc.EncodeAll(data, nil)
+				enc.Close()
+			}
+			path := writeFile(t, tt.file, data)
+
+			store := storage.NewStorage(storage.DefaultConfig())
+			a := New(store)
+			defer a.Close()
+
+			stats, err := a.AnalyzeFile(context.Background(), path, tt.signal)
+			if err != nil {
+				t.Fatalf("AnalyzeFile: %v", err)
+			}
+			if stats.Format != "proto" || stats.Requests[models.SignalTypeSpan] != 2 {
+				t.Errorf("stats = %+v, want 2 proto span requests", stats)
+			}
+			spans, _ := store.ListSpans(context.Background(), "worker")
+			if len(spans) != 2 {
+				t.Errorf("got %d spans, want 2", len(spans))
+			}
+		})
+	}
+}
+
+func TestAnalyzeFile_ProtoNeedsSignal(t *testing.T) {
+	path := writeFile(t, "dump.bin", protoRecords(t, false, traceRequest("x")))
+
+	a := New(storage.NewStorage(storage.DefaultConfig()))
+	defer a.Close()
+
+	_, err := a.AnalyzeFile(context.Background(), path, "")
+	if err == nil || !strings.Contains(err.Error(), "--signal") {
+		t.Errorf("expected signal inference error, got %v", err)
+	}
+}
+
+func TestAnalyzeFile_TruncatedRecord(t *testing.T) {
+	data := protoRecords(t, false, traceRequest("x"))
+	path := writeFile(t, "traces.proto", data[:len(data)-2])
+
+	a := New(storage.NewStorage(storage.DefaultConfig()))
+	defer a.Close()
+
+	if _, err := a.AnalyzeFile(context.Background(), path, ""); err == nil {
+		t.Error("expected error for truncated record")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for in, want := range map[string]string{
+		"metrics": models.SignalTypeMetric,
+		"Traces":  models.SignalTypeSpan,
+		"spans":   models.SignalTypeSpan,
+		"log":     models.SignalTypeLog,
+		"":        "",
+	} {
+		got, err := ParseSignal(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSignal(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseSignal("events"); err == nil {
+		t.Error("expected error for unknown signal")
+	}
+}
+
+func TestSignalFromFilename(t *testing.T) {
+	for name, want := range map[string]string{
+		"metrics.proto":                  models.SignalTypeMetric,
+		"/tmp/otlp-traces-2024.json.zst": models.SignalTypeSpan,
+		"spans.jsonl":                    models.SignalTypeSpan,
+		"app_logs.json.gz":               models.SignalTypeLog,
+		"profiles.proto":                 models.SignalTypeProfile,
+		"blog-metrics.json":              models.SignalTypeMetric,
+		"catalog.json":                   "",
+		"dialog.proto":                   "",
+		"dump.bin":                       "",
+	} {
+		if got := signalFromFilename(name); got != want {
+			t.Errorf("signalFromFilename(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,1251 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: opentelemetry/proto/profiles/v1development/profiles.proto
+
+package profilespb
+
+import (
+	v1 "go.opentelemetry.io/proto/otlp/common/v1"
+	v11 "go.opentelemetry.io/proto/otlp/resource/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AggregationTemporality specifies how sample values of a cumulative or
+// delta profile relate to earlier profiles.
+type AggregationTemporality int32
+
+const (
+	AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED AggregationTemporality = 0
+	AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA       AggregationTemporality = 1
+	AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE  AggregationTemporality = 2
+)
+
+// Enum value maps for AggregationTemporality.
+var (
+	AggregationTemporality_name = map[int32]string{
+		0: "AGGREGATION_TEMPORALITY_UNSPECIFIED",
+		1: "AGGREGATION_TEMPORALITY_DELTA",
+		2: "AGGREGATION_TEMPORALITY_CUMULATIVE",
+	}
+	AggregationTemporality_value = map[string]int32{
+		"AGGREGATION_TEMPORALITY_UNSPECIFIED": 0,
+		"AGGREGATION_TEMPORALITY_DELTA":       1,
+		"AGGREGATION_TEMPORALITY_CUMULATIVE":  2,
+	}
+)
+
+func (x AggregationTemporality) Enum() *AggregationTemporality {
+	p := new(AggregationTemporality)
+	*p = x
+	return p
+}
+
+func (x AggregationTemporality) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AggregationTemporality) Descriptor() protoreflect.EnumDescriptor {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_enumTypes[0].Descriptor()
+}
+
+func (AggregationTemporality) Type() protoreflect.EnumType {
+	return &file_opentelemetry_proto_profiles_v1development_profiles_proto_enumTypes[0]
+}
+
+func (x AggregationTemporality) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AggregationTemporality.Descriptor instead.
+func (AggregationTemporality) EnumDescriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{0}
+}
+
+// ProfilesDictionary holds the lookup tables shared by all profiles in a
+// request. Profiles, samples and locations reference entries by index.
+type ProfilesDictionary struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MappingTable   []*Mapping             `protobuf:"bytes,1,rep,name=mapping_table,json=mappingTable,proto3" json:"mapping_table,omitempty"`
+	LocationTable  []*Location            `protobuf:"bytes,2,rep,name=location_table,json=locationTable,proto3" json:"location_table,omitempty"`
+	FunctionTable  []*Function            `protobuf:"bytes,3,rep,name=function_table,json=functionTable,proto3" json:"function_table,omitempty"`
+	LinkTable      []*Link                `protobuf:"bytes,4,rep,name=link_table,json=linkTable,proto3" json:"link_table,omitempty"`
+	StringTable    []string               `protobuf:"bytes,5,rep,name=string_table,json=stringTable,proto3" json:"string_table,omitempty"`
+	AttributeTable []*v1.KeyValue         `protobuf:"bytes,6,rep,name=attribute_table,json=attributeTable,proto3" json:"attribute_table,omitempty"`
+	AttributeUnits []*AttributeUnit       `protobuf:"bytes,7,rep,name=attribute_units,json=attributeUnits,proto3" json:"attribute_units,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ProfilesDictionary) Reset() {
+	*x = ProfilesDictionary{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfilesDictionary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfilesDictionary) ProtoMessage() {}
+
+func (x *ProfilesDictionary) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfilesDictionary.ProtoReflect.Descriptor instead.
+func (*ProfilesDictionary) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProfilesDictionary) GetMappingTable() []*Mapping {
+	if x != nil {
+		return x.MappingTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetLocationTable() []*Location {
+	if x != nil {
+		return x.LocationTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetFunctionTable() []*Function {
+	if x != nil {
+		return x.FunctionTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetLinkTable() []*Link {
+	if x != nil {
+		return x.LinkTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetStringTable() []string {
+	if x != nil {
+		return x.StringTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetAttributeTable() []*v1.KeyValue {
+	if x != nil {
+		return x.AttributeTable
+	}
+	return nil
+}
+
+func (x *ProfilesDictionary) GetAttributeUnits() []*AttributeUnit {
+	if x != nil {
+		return x.AttributeUnits
+	}
+	return nil
+}
+
+// ProfilesData represents the profiles data that can be stored in persistent
+// storage or embedded by other protocols.
+type ProfilesData struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ResourceProfiles []*ResourceProfiles    `protobuf:"bytes,1,rep,name=resource_profiles,json=resourceProfiles,proto3" json:"resource_profiles,omitempty"`
+	Dictionary       *ProfilesDictionary    `protobuf:"bytes,2,opt,name=dictionary,proto3" json:"dictionary,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ProfilesData) Reset() {
+	*x = ProfilesData{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfilesData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfilesData) ProtoMessage() {}
+
+func (x *ProfilesData) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfilesData.ProtoReflect.Descriptor instead.
+func (*ProfilesData) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProfilesData) GetResourceProfiles() []*ResourceProfiles {
+	if x != nil {
+		return x.ResourceProfiles
+	}
+	return nil
+}
+
+func (x *ProfilesData) GetDictionary() *ProfilesDictionary {
+	if x != nil {
+		return x.Dictionary
+	}
+	return nil
+}
+
+// ResourceProfiles is a collection of profiles from a resource.
+type ResourceProfiles struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      *v11.Resource          `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	ScopeProfiles []*ScopeProfiles       `protobuf:"bytes,2,rep,name=scope_profiles,json=scopeProfiles,proto3" json:"scope_profiles,omitempty"`
+	SchemaUrl     string                 `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl,proto3" json:"schema_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceProfiles) Reset() {
+	*x = ResourceProfiles{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceProfiles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceProfiles) ProtoMessage() {}
+
+func (x *ResourceProfiles) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceProfiles.ProtoReflect.Descriptor instead.
+func (*ResourceProfiles) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResourceProfiles) GetResource() *v11.Resource {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *ResourceProfiles) GetScopeProfiles() []*ScopeProfiles {
+	if x != nil {
+		return x.ScopeProfiles
+	}
+	return nil
+}
+
+func (x *ResourceProfiles) GetSchemaUrl() string {
+	if x != nil {
+		return x.SchemaUrl
+	}
+	return ""
+}
+
+// ScopeProfiles is a collection of profiles produced by one instrumentation
+// scope.
+type ScopeProfiles struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Scope         *v1.InstrumentationScope `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Profiles      []*Profile               `protobuf:"bytes,2,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	SchemaUrl     string                   `protobuf:"bytes,3,opt,name=schema_url,json=schemaUrl,proto3" json:"schema_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScopeProfiles) Reset() {
+	*x = ScopeProfiles{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScopeProfiles) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScopeProfiles) ProtoMessage() {}
+
+func (x *ScopeProfiles) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScopeProfiles.ProtoReflect.Descriptor instead.
+func (*ScopeProfiles) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScopeProfiles) GetScope() *v1.InstrumentationScope {
+	if x != nil {
+		return x.Scope
+	}
+	return nil
+}
+
+func (x *ScopeProfiles) GetProfiles() []*Profile {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *ScopeProfiles) GetSchemaUrl() string {
+	if x != nil {
+		return x.SchemaUrl
+	}
+	return ""
+}
+
+// Profile is a collection of stack traces with values for one or more
+// sample types.
+type Profile struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	SampleType             []*ValueType           `protobuf:"bytes,1,rep,name=sample_type,json=sampleType,proto3" json:"sample_type,omitempty"`
+	Sample                 []*Sample              `protobuf:"bytes,2,rep,name=sample,proto3" json:"sample,omitempty"`
+	LocationIndices        []int32                `protobuf:"varint,3,rep,packed,name=location_indices,json=locationIndices,proto3" json:"location_indices,omitempty"`
+	TimeNanos              int64                  `protobuf:"varint,4,opt,name=time_nanos,json=timeNanos,proto3" json:"time_nanos,omitempty"`
+	DurationNanos          int64                  `protobuf:"varint,5,opt,name=duration_nanos,json=durationNanos,proto3" json:"duration_nanos,omitempty"`
+	PeriodType             *ValueType             `protobuf:"bytes,6,opt,name=period_type,json=periodType,proto3" json:"period_type,omitempty"`
+	Period                 int64                  `protobuf:"varint,7,opt,name=period,proto3" json:"period,omitempty"`
+	CommentStrindices      []int32                `protobuf:"varint,8,rep,packed,name=comment_strindices,json=commentStrindices,proto3" json:"comment_strindices,omitempty"`
+	DefaultSampleTypeIndex int32                  `protobuf:"varint,9,opt,name=default_sample_type_index,json=defaultSampleTypeIndex,proto3" json:"default_sample_type_index,omitempty"`
+	ProfileId              []byte                 `protobuf:"bytes,10,opt,name=profile_id,json=profileId,proto3" json:"profile_id,omitempty"`
+	DroppedAttributesCount uint32                 `protobuf:"varint,11,opt,name=dropped_attributes_count,json=droppedAttributesCount,proto3" json:"dropped_attributes_count,omitempty"`
+	OriginalPayloadFormat  string                 `protobuf:"bytes,12,opt,name=original_payload_format,json=originalPayloadFormat,proto3" json:"original_payload_format,omitempty"`
+	OriginalPayload        []byte                 `protobuf:"bytes,13,opt,name=original_payload,json=originalPayload,proto3" json:"original_payload,omitempty"`
+	AttributeIndices       []int32                `protobuf:"varint,14,rep,packed,name=attribute_indices,json=attributeIndices,proto3" json:"attribute_indices,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Profile) Reset() {
+	*x = Profile{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Profile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Profile) ProtoMessage() {}
+
+func (x *Profile) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Profile.ProtoReflect.Descriptor instead.
+func (*Profile) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Profile) GetSampleType() []*ValueType {
+	if x != nil {
+		return x.SampleType
+	}
+	return nil
+}
+
+func (x *Profile) GetSample() []*Sample {
+	if x != nil {
+		return x.Sample
+	}
+	return nil
+}
+
+func (x *Profile) GetLocationIndices() []int32 {
+	if x != nil {
+		return x.LocationIndices
+	}
+	return nil
+}
+
+func (x *Profile) GetTimeNanos() int64 {
+	if x != nil {
+		return x.TimeNanos
+	}
+	return 0
+}
+
+func (x *Profile) GetDurationNanos() int64 {
+	if x != nil {
+		return x.DurationNanos
+	}
+	return 0
+}
+
+func (x *Profile) GetPeriodType() *ValueType {
+	if x != nil {
+		return x.PeriodType
+	}
+	return nil
+}
+
+func (x *Profile) GetPeriod() int64 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *Profile) GetCommentStrindices() []int32 {
+	if x != nil {
+		return x.CommentStrindices
+	}
+	return nil
+}
+
+func (x *Profile) GetDefaultSampleTypeIndex() int32 {
+	if x != nil {
+		return x.DefaultSampleTypeIndex
+	}
+	return 0
+}
+
+func (x *Profile) GetProfileId() []byte {
+	if x != nil {
+		return x.ProfileId
+	}
+	return nil
+}
+
+func (x *Profile) GetDroppedAttributesCount() uint32 {
+	if x != nil {
+		return x.DroppedAttributesCount
+	}
+	return 0
+}
+
+func (x *Profile) GetOriginalPayloadFormat() string {
+	if x != nil {
+		return x.OriginalPayloadFormat
+	}
+	return ""
+}
+
+func (x *Profile) GetOriginalPayload() []byte {
+	if x != nil {
+		return x.OriginalPayload
+	}
+	return nil
+}
+
+func (x *Profile) GetAttributeIndices() []int32 {
+	if x != nil {
+		return x.AttributeIndices
+	}
+	return nil
+}
+
+// AttributeUnit records the unit of an attribute value.
+type AttributeUnit struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	AttributeKeyStrindex int32                  `protobuf:"varint,1,opt,name=attribute_key_strindex,json=attributeKeyStrindex,proto3" json:"attribute_key_strindex,omitempty"`
+	UnitStrindex         int32                  `protobuf:"varint,2,opt,name=unit_strindex,json=unitStrindex,proto3" json:"unit_strindex,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AttributeUnit) Reset() {
+	*x = AttributeUnit{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeUnit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeUnit) ProtoMessage() {}
+
+func (x *AttributeUnit) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeUnit.ProtoReflect.Descriptor instead.
+func (*AttributeUnit) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AttributeUnit) GetAttributeKeyStrindex() int32 {
+	if x != nil {
+		return x.AttributeKeyStrindex
+	}
+	return 0
+}
+
+func (x *AttributeUnit) GetUnitStrindex() int32 {
+	if x != nil {
+		return x.UnitStrindex
+	}
+	return 0
+}
+
+// Link connects a sample to a trace span.
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraceId       []byte                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	SpanId        []byte                 `protobuf:"bytes,2,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Link) GetTraceId() []byte {
+	if x != nil {
+		return x.TraceId
+	}
+	return nil
+}
+
+func (x *Link) GetSpanId() []byte {
+	if x != nil {
+		return x.SpanId
+	}
+	return nil
+}
+
+// ValueType describes the type and unit of a sample value.
+type ValueType struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	TypeStrindex           int32                  `protobuf:"varint,1,opt,name=type_strindex,json=typeStrindex,proto3" json:"type_strindex,omitempty"`
+	UnitStrindex           int32                  `protobuf:"varint,2,opt,name=unit_strindex,json=unitStrindex,proto3" json:"unit_strindex,omitempty"`
+	AggregationTemporality AggregationTemporality `protobuf:"varint,3,opt,name=aggregation_temporality,json=aggregationTemporality,proto3,enum=opentelemetry.proto.profiles.v1development.AggregationTemporality" json:"aggregation_temporality,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ValueType) Reset() {
+	*x = ValueType{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueType) ProtoMessage() {}
+
+func (x *ValueType) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueType.ProtoReflect.Descriptor instead.
+func (*ValueType) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ValueType) GetTypeStrindex() int32 {
+	if x != nil {
+		return x.TypeStrindex
+	}
+	return 0
+}
+
+func (x *ValueType) GetUnitStrindex() int32 {
+	if x != nil {
+		return x.UnitStrindex
+	}
+	return 0
+}
+
+func (x *ValueType) GetAggregationTemporality() AggregationTemporality {
+	if x != nil {
+		return x.AggregationTemporality
+	}
+	return AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+}
+
+// Sample is a single stack trace with its measured values. Its locations are
+// Profile.location_indices[locations_start_index:locations_start_index+locations_length].
+type Sample struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	LocationsStartIndex int32                  `protobuf:"varint,1,opt,name=locations_start_index,json=locationsStartIndex,proto3" json:"locations_start_index,omitempty"`
+	LocationsLength     int32                  `protobuf:"varint,2,opt,name=locations_length,json=locationsLength,proto3" json:"locations_length,omitempty"`
+	Value               []int64                `protobuf:"varint,3,rep,packed,name=value,proto3" json:"value,omitempty"`
+	AttributeIndices    []int32                `protobuf:"varint,4,rep,packed,name=attribute_indices,json=attributeIndices,proto3" json:"attribute_indices,omitempty"`
+	LinkIndex           *int32                 `protobuf:"varint,5,opt,name=link_index,json=linkIndex,proto3,oneof" json:"link_index,omitempty"`
+	TimestampsUnixNano  []uint64               `protobuf:"varint,6,rep,packed,name=timestamps_unix_nano,json=timestampsUnixNano,proto3" json:"timestamps_unix_nano,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Sample) Reset() {
+	*x = Sample{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sample) ProtoMessage() {}
+
+func (x *Sample) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sample.ProtoReflect.Descriptor instead.
+func (*Sample) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Sample) GetLocationsStartIndex() int32 {
+	if x != nil {
+		return x.LocationsStartIndex
+	}
+	return 0
+}
+
+func (x *Sample) GetLocationsLength() int32 {
+	if x != nil {
+		return x.LocationsLength
+	}
+	return 0
+}
+
+func (x *Sample) GetValue() []int64 {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Sample) GetAttributeIndices() []int32 {
+	if x != nil {
+		return x.AttributeIndices
+	}
+	return nil
+}
+
+func (x *Sample) GetLinkIndex() int32 {
+	if x != nil && x.LinkIndex != nil {
+		return *x.LinkIndex
+	}
+	return 0
+}
+
+func (x *Sample) GetTimestampsUnixNano() []uint64 {
+	if x != nil {
+		return x.TimestampsUnixNano
+	}
+	return nil
+}
+
+// Mapping describes a mapped binary or library.
+type Mapping struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MemoryStart      uint64                 `protobuf:"varint,1,opt,name=memory_start,json=memoryStart,proto3" json:"memory_start,omitempty"`
+	MemoryLimit      uint64                 `protobuf:"varint,2,opt,name=memory_limit,json=memoryLimit,proto3" json:"memory_limit,omitempty"`
+	FileOffset       uint64                 `protobuf:"varint,3,opt,name=file_offset,json=fileOffset,proto3" json:"file_offset,omitempty"`
+	FilenameStrindex int32                  `protobuf:"varint,4,opt,name=filename_strindex,json=filenameStrindex,proto3" json:"filename_strindex,omitempty"`
+	AttributeIndices []int32                `protobuf:"varint,5,rep,packed,name=attribute_indices,json=attributeIndices,proto3" json:"attribute_indices,omitempty"`
+	HasFunctions     bool                   `protobuf:"varint,6,opt,name=has_functions,json=hasFunctions,proto3" json:"has_functions,omitempty"`
+	HasFilenames     bool                   `protobuf:"varint,7,opt,name=has_filenames,json=hasFilenames,proto3" json:"has_filenames,omitempty"`
+	HasLineNumbers   bool                   `protobuf:"varint,8,opt,name=has_line_numbers,json=hasLineNumbers,proto3" json:"has_line_numbers,omitempty"`
+	HasInlineFrames  bool                   `protobuf:"varint,9,opt,name=has_inline_frames,json=hasInlineFrames,proto3" json:"has_inline_frames,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Mapping) Reset() {
+	*x = Mapping{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mapping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mapping) ProtoMessage() {}
+
+func (x *Mapping) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mapping.ProtoReflect.Descriptor instead.
+func (*Mapping) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Mapping) GetMemoryStart() uint64 {
+	if x != nil {
+		return x.MemoryStart
+	}
+	return 0
+}
+
+func (x *Mapping) GetMemoryLimit() uint64 {
+	if x != nil {
+		return x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *Mapping) GetFileOffset() uint64 {
+	if x != nil {
+		return x.FileOffset
+	}
+	return 0
+}
+
+func (x *Mapping) GetFilenameStrindex() int32 {
+	if x != nil {
+		return x.FilenameStrindex
+	}
+	return 0
+}
+
+func (x *Mapping) GetAttributeIndices() []int32 {
+	if x != nil {
+		return x.AttributeIndices
+	}
+	return nil
+}
+
+func (x *Mapping) GetHasFunctions() bool {
+	if x != nil {
+		return x.HasFunctions
+	}
+	return false
+}
+
+func (x *Mapping) GetHasFilenames() bool {
+	if x != nil {
+		return x.HasFilenames
+	}
+	return false
+}
+
+func (x *Mapping) GetHasLineNumbers() bool {
+	if x != nil {
+		return x.HasLineNumbers
+	}
+	return false
+}
+
+func (x *Mapping) GetHasInlineFrames() bool {
+	if x != nil {
+		return x.HasInlineFrames
+	}
+	return false
+}
+
+// Location is a program counter, optionally expanded into inlined lines.
+type Location struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	MappingIndex     *int32                 `protobuf:"varint,1,opt,name=mapping_index,json=mappingIndex,proto3,oneof" json:"mapping_index,omitempty"`
+	Address          uint64                 `protobuf:"varint,2,opt,name=address,proto3" json:"address,omitempty"`
+	Line             []*Line                `protobuf:"bytes,3,rep,name=line,proto3" json:"line,omitempty"`
+	IsFolded         bool                   `protobuf:"varint,4,opt,name=is_folded,json=isFolded,proto3" json:"is_folded,omitempty"`
+	AttributeIndices []int32                `protobuf:"varint,5,rep,packed,name=attribute_indices,json=attributeIndices,proto3" json:"attribute_indices,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Location) GetMappingIndex() int32 {
+	if x != nil && x.MappingIndex != nil {
+		return *x.MappingIndex
+	}
+	return 0
+}
+
+func (x *Location) GetAddress() uint64 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *Location) GetLine() []*Line {
+	if x != nil {
+		return x.Line
+	}
+	return nil
+}
+
+func (x *Location) GetIsFolded() bool {
+	if x != nil {
+		return x.IsFolded
+	}
+	return false
+}
+
+func (x *Location) GetAttributeIndices() []int32 {
+	if x != nil {
+		return x.AttributeIndices
+	}
+	return nil
+}
+
+// Line is a source line within a function.
+type Line struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FunctionIndex int32                  `protobuf:"varint,1,opt,name=function_index,json=functionIndex,proto3" json:"function_index,omitempty"`
+	Line          int64                  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column        int64                  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Line) Reset() {
+	*x = Line{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Line) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Line) ProtoMessage() {}
+
+func (x *Line) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Line.ProtoReflect.Descriptor instead.
+func (*Line) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Line) GetFunctionIndex() int32 {
+	if x != nil {
+		return x.FunctionIndex
+	}
+	return 0
+}
+
+func (x *Line) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *Line) GetColumn() int64 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+// Function describes a function in the profiled program.
+type Function struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	NameStrindex       int32                  `protobuf:"varint,1,opt,name=name_strindex,json=nameStrindex,proto3" json:"name_strindex,omitempty"`
+	SystemNameStrindex int32                  `protobuf:"varint,2,opt,name=system_name_strindex,json=systemNameStrindex,proto3" json:"system_name_strindex,omitempty"`
+	FilenameStrindex   int32                  `protobuf:"varint,3,opt,name=filename_strindex,json=filenameStrindex,proto3" json:"filename_strindex,omitempty"`
+	StartLine          int64                  `protobuf:"varint,4,opt,name=start_line,json=startLine,proto3" json:"start_line,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Function) Reset() {
+	*x = Function{}
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Function) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Function) ProtoMessage() {}
+
+func (x *Function) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Function.ProtoReflect.Descriptor instead.
+func (*Function) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Function) GetNameStrindex() int32 {
+	if x != nil {
+		return x.NameStrindex
+	}
+	return 0
+}
+
+func (x *Function) GetSystemNameStrindex() int32 {
+	if x != nil {
+		return x.SystemNameStrindex
+	}
+	return 0
+}
+
+func (x *Function) GetFilenameStrindex() int32 {
+	if x != nil {
+		return x.FilenameStrindex
+	}
+	return 0
+}
+
+func (x *Function) GetStartLine() int64 {
+	if x != nil {
+		return x.StartLine
+	}
+	return 0
+}
+
+var File_opentelemetry_proto_profiles_v1development_profiles_proto protoreflect.FileDescriptor
+
+const file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDesc = "" +
+	"\n" +
+	"9opentelemetry/proto/profiles/v1development/profiles.proto\x12*opentelemetry.proto.profiles.v1development\x1a*opentelemetry/proto/common/v1/common.proto\x1a.opentelemetry/proto/resource/v1/resource.proto\"\xd2\x04\n" +
+	"\x12ProfilesDictionary\x12X\n" +
+	"\rmapping_table\x18\x01 \x03(\v23.opentelemetry.proto.profiles.v1development.MappingR\fmappingTable\x12[\n" +
+	"\x0elocation_table\x18\x02 \x03(\v24.opentelemetry.proto.profiles.v1development.LocationR\rlocationTable\x12[\n" +
+	"\x0efunction_table\x18\x03 \x03(\v24.opentelemetry.proto.profiles.v1development.FunctionR\rfunctionTable\x12O\n" +
+	"\n" +
+	"link_table\x18\x04 \x03(\v20.opentelemetry.proto.profiles.v1development.LinkR\tlinkTable\x12!\n" +
+	"\fstring_table\x18\x05 \x03(\tR\vstringTable\x12P\n" +
+	"\x0fattribute_table\x18\x06 \x03(\v2'.opentelemetry.proto.common.v1.KeyValueR\x0eattributeTable\x12b\n" +
+	"\x0fattribute_units\x18\a \x03(\v29.opentelemetry.proto.profiles.v1development.AttributeUnitR\x0eattributeUnits\"\xd9\x01\n" +
+	"\fProfilesData\x12i\n" +
+	"\x11resource_profiles\x18\x01 \x03(\v2<.opentelemetry.proto.profiles.v1development.ResourceProfilesR\x10resourceProfiles\x12^\n" +
+	"\n" +
+	"dictionary\x18\x02 \x01(\v2>.opentelemetry.proto.profiles.v1development.ProfilesDictionaryR\n" +
+	"dictionary\"\xda\x01\n" +
+	"\x10ResourceProfiles\x12E\n" +
+	"\bresource\x18\x01 \x01(\v2).opentelemetry.proto.resource.v1.ResourceR\bresource\x12`\n" +
+	"\x0escope_profiles\x18\x02 \x03(\v29.opentelemetry.proto.profiles.v1development.ScopeProfilesR\rscopeProfiles\x12\x1d\n" +
+	"\n" +
+	"schema_url\x18\x03 \x01(\tR\tschemaUrl\"\xca\x01\n" +
+	"\rScopeProfiles\x12I\n" +
+	"\x05scope\x18\x01 \x01(\v23.opentelemetry.proto.common.v1.InstrumentationScopeR\x05scope\x12O\n" +
+	"\bprofiles\x18\x02 \x03(\v23.opentelemetry.proto.profiles.v1development.ProfileR\bprofiles\x12\x1d\n" +
+	"\n" +
+	"schema_url\x18\x03 \x01(\tR\tschemaUrl\"\xe1\x05\n" +
+	"\aProfile\x12V\n" +
+	"\vsample_type\x18\x01 \x03(\v25.opentelemetry.proto.profiles.v1development.ValueTypeR\n" +
+	"sampleType\x12J\n" +
+	"\x06sample\x18\x02 \x03(\v22.opentelemetry.proto.profiles.v1development.SampleR\x06sample\x12)\n" +
+	"\x10location_indices\x18\x03 \x03(\x05R\x0flocationIndices\x12\x1d\n" +
+	"\n" +
+	"time_nanos\x18\x04 \x01(\x03R\ttimeNanos\x12%\n" +
+	"\x0eduration_nanos\x18\x05 \x01(\x03R\rdurationNanos\x12V\n" +
+	"\vperiod_type\x18\x06 \x01(\v25.opentelemetry.proto.profiles.v1development.ValueTypeR\n" +
+	"periodType\x12\x16\n" +
+	"\x06period\x18\a \x01(\x03R\x06period\x12-\n" +
+	"\x12comment_strindices\x18\b \x03(\x05R\x11commentStrindices\x129\n" +
+	"\x19default_sample_type_index\x18\t \x01(\x05R\x16defaultSampleTypeIndex\x12\x1d\n" +
+	"\n" +
+	"profile_id\x18\n" +
+	" \x01(\fR\tprofileId\x128\n" +
+	"\x18dropped_attributes_count\x18\v \x01(\rR\x16droppedAttributesCount\x126\n" +
+	"\x17original_payload_format\x18\f \x01(\tR\x15originalPayloadFormat\x12)\n" +
+	"\x10original_payload\x18\r \x01(\fR\x0foriginalPayload\x12+\n" +
+	"\x11attribute_indices\x18\x0e \x03(\x05R\x10attributeIndices\"j\n" +
+	"\rAttributeUnit\x124\n" +
+	"\x16attribute_key_strindex\x18\x01 \x01(\x05R\x14attributeKeyStrindex\x12#\n" +
+	"\runit_strindex\x18\x02 \x01(\x05R\funitStrindex\":\n" +
+	"\x04Link\x12\x19\n" +
+	"\btrace_id\x18\x01 \x01(\fR\atraceId\x12\x17\n" +
+	"\aspan_id\x18\x02 \x01(\fR\x06spanId\"\xd2\x01\n" +
+	"\tValueType\x12#\n" +
+	"\rtype_strindex\x18\x01 \x01(\x05R\ftypeStrindex\x12#\n" +
+	"\runit_strindex\x18\x02 \x01(\x05R\funitStrindex\x12{\n" +
+	"\x17aggregation_temporality\x18\x03 \x01(\x0e2B.opentelemetry.proto.profiles.v1development.AggregationTemporalityR\x16aggregationTemporality\"\x8f\x02\n" +
+	"\x06Sample\x122\n" +
+	"\x15locations_start_index\x18\x01 \x01(\x05R\x13locationsStartIndex\x12)\n" +
+	"\x10locations_length\x18\x02 \x01(\x05R\x0flocationsLength\x12\x14\n" +
+	"\x05value\x18\x03 \x03(\x03R\x05value\x12+\n" +
+	"\x11attribute_indices\x18\x04 \x03(\x05R\x10attributeIndices\x12\"\n" +
+	"\n" +
+	"link_index\x18\x05 \x01(\x05H\x00R\tlinkIndex\x88\x01\x01\x120\n" +
+	"\x14timestamps_unix_nano\x18\x06 \x03(\x04R\x12timestampsUnixNanoB\r\n" +
+	"\v_link_index\"\xea\x02\n" +
+	"\aMapping\x12!\n" +
+	"\fmemory_start\x18\x01 \x01(\x04R\vmemoryStart\x12!\n" +
+	"\fmemory_limit\x18\x02 \x01(\x04R\vmemoryLimit\x12\x1f\n" +
+	"\vfile_offset\x18\x03 \x01(\x04R\n" +
+	"fileOffset\x12+\n" +
+	"\x11filename_strindex\x18\x04 \x01(\x05R\x10filenameStrindex\x12+\n" +
+	"\x11attribute_indices\x18\x05 \x03(\x05R\x10attributeIndices\x12#\n" +
+	"\rhas_functions\x18\x06 \x01(\bR\fhasFunctions\x12#\n" +
+	"\rhas_filenames\x18\a \x01(\bR\fhasFilenames\x12(\n" +
+	"\x10has_line_numbers\x18\b \x01(\bR\x0ehasLineNumbers\x12*\n" +
+	"\x11has_inline_frames\x18\t \x01(\bR\x0fhasInlineFrames\"\xf0\x01\n" +
+	"\bLocation\x12(\n" +
+	"\rmapping_index\x18\x01 \x01(\x05H\x00R\fmappingIndex\x88\x01\x01\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\x04R\aaddress\x12D\n" +
+	"\x04line\x18\x03 \x03(\v20.opentelemetry.proto.profiles.v1development.LineR\x04line\x12\x1b\n" +
+	"\tis_folded\x18\x04 \x01(\bR\bisFolded\x12+\n" +
+	"\x11attribute_indices\x18\x05 \x03(\x05R\x10attributeIndicesB\x10\n" +
+	"\x0e_mapping_index\"Y\n" +
+	"\x04Line\x12%\n" +
+	"\x0efunction_index\x18\x01 \x01(\x05R\rfunctionIndex\x12\x12\n" +
+	"\x04line\x18\x02 \x01(\x03R\x04line\x12\x16\n" +
+	"\x06column\x18\x03 \x01(\x03R\x06column\"\xad\x01\n" +
+	"\bFunction\x12#\n" +
+	"\rname_strindex\x18\x01 \x01(\x05R\fnameStrindex\x120\n" +
+	"\x14system_name_strindex\x18\x02 \x01(\x05R\x12systemNameStrindex\x12+\n" +
+	"\x11filename_strindex\x18\x03 \x01(\x05R\x10filenameStrindex\x12\x1d\n" +
+	"\n" +
+	"start_line\x18\x04 \x01(\x03R\tstartLine*\x8c\x01\n" +
+	"\x16AggregationTemporality\x12'\n" +
+	"#AGGREGATION_TEMPORALITY_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dAGGREGATION_TEMPORALITY_DELTA\x10\x01\x12&\n" +
+	"\"AGGREGATION_TEMPORALITY_CUMULATIVE\x10\x02B?Z=github.com/fidde/otlp_cardinality_checker/internal/profilespbb\x06proto3"
+
+var (
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescOnce sync.Once
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescData []byte
+)
+
+func file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescGZIP() []byte {
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescOnce.Do(func() {
+		file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDesc), len(file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDesc)))
+	})
+	return file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDescData
+}
+
+var file_opentelemetry_proto_profiles_v1development_profiles_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_opentelemetry_proto_profiles_v1development_profiles_proto_goTypes = []any{
+	(AggregationTemporality)(0),     // 0: opentelemetry.proto.profiles.v1development.AggregationTemporality
+	(*ProfilesDictionary)(nil),      // 1: opentelemetry.proto.profiles.v1development.ProfilesDictionary
+	(*ProfilesData)(nil),            // 2: opentelemetry.proto.profiles.v1development.ProfilesData
+	(*ResourceProfiles)(nil),        // 3: opentelemetry.proto.profiles.v1development.ResourceProfiles
+	(*ScopeProfiles)(nil),           // 4: opentelemetry.proto.profiles.v1development.ScopeProfiles
+	(*Profile)(nil),                 // 5: opentelemetry.proto.profiles.v1development.Profile
+	(*AttributeUnit)(nil),           // 6: opentelemetry.proto.profiles.v1development.AttributeUnit
+	(*Link)(nil),                    // 7: opentelemetry.proto.profiles.v1development.Link
+	(*ValueType)(nil),               // 8: opentelemetry.proto.profiles.v1development.ValueType
+	(*Sample)(nil),                  // 9: opentelemetry.proto.profiles.v1development.Sample
+	(*Mapping)(nil),                 // 10: opentelemetry.proto.profiles.v1development.Mapping
+	(*Location)(nil),                // 11: opentelemetry.proto.profiles.v1development.Location
+	(*Line)(nil),                    // 12: opentelemetry.proto.profiles.v1development.Line
+	(*Function)(nil),                // 13: opentelemetry.proto.profiles.v1development.Function
+	(*v1.KeyValue)(nil),             // 14: opentelemetry.proto.common.v1.KeyValue
+	(*v11.Resource)(nil),            // 15: opentelemetry.proto.resource.v1.Resource
+	(*v1.InstrumentationScope)(nil), // 16: opentelemetry.proto.common.v1.InstrumentationScope
+}
+var file_opentelemetry_proto_profiles_v1development_profiles_proto_depIdxs = []int32{
+	10, // 0: opentelemetry.proto.profiles.v1development.ProfilesDictionary.mapping_table:type_name -> opentelemetry.proto.profiles.v1development.Mapping
+	11, // 1: opentelemetry.proto.profiles.v1development.ProfilesDictionary.location_table:type_name -> opentelemetry.proto.profiles.v1development.Location
+	13, // 2: opentelemetry.proto.profiles.v1development.ProfilesDictionary.function_table:type_name -> opentelemetry.proto.profiles.v1development.Function
+	7,  // 3: opentelemetry.proto.profiles.v1development.ProfilesDictionary.link_table:type_name -> opentelemetry.proto.profiles.v1development.Link
+	14, // 4: opentelemetry.proto.profiles.v1development.ProfilesDictionary.attribute_table:type_name -> opentelemetry.proto.common.v1.KeyValue
+	6,  // 5: opentelemetry.proto.profiles.v1development.ProfilesDictionary.attribute_units:type_name -> opentelemetry.proto.profiles.v1development.AttributeUnit
+	3,  // 6: opentelemetry.proto.profiles.v1development.ProfilesData.resource_profiles:type_name -> opentelemetry.proto.profiles.v1development.ResourceProfiles
+	1,  // 7: opentelemetry.proto.profiles.v1development.ProfilesData.dictionary:type_name -> opentelemetry.proto.profiles.v1development.ProfilesDictionary
+	15, // 8: opentelemetry.proto.profiles.v1development.ResourceProfiles.resource:type_name -> opentelemetry.proto.resource.v1.Resource
+	4,  // 9: opentelemetry.proto.profiles.v1development.ResourceProfiles.scope_profiles:type_name -> opentelemetry.proto.profiles.v1development.ScopeProfiles
+	16, // 10: opentelemetry.proto.profiles.v1development.ScopeProfiles.scope:type_name -> opentelemetry.proto.common.v1.InstrumentationScope
+	5,  // 11: opentelemetry.proto.profiles.v1development.ScopeProfiles.profiles:type_name -> opentelemetry.proto.profiles.v1development.Profile
+	8,  // 12: opentelemetry.proto.profiles.v1development.Profile.sample_type:type_name -> opentelemetry.proto.profiles.v1development.ValueType
+	9,  // 13: opentelemetry.proto.profiles.v1development.Profile.sample:type_name -> opentelemetry.proto.profiles.v1development.Sample
+	8,  // 14: opentelemetry.proto.profiles.v1development.Profile.period_type:type_name -> opentelemetry.proto.profiles.v1development.ValueType
+	0,  // 15: opentelemetry.proto.profiles.v1development.ValueType.aggregation_temporality:type_name -> opentelemetry.proto.profiles.v1development.AggregationTemporality
+	12, // 16: opentelemetry.proto.profiles.v1development.Location.line:type_name -> opentelemetry.proto.profiles.v1development.Line
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_opentelemetry_proto_profiles_v1development_profiles_proto_init() }
+func file_opentelemetry_proto_profiles_v1development_profiles_proto_init() {
+	if File_opentelemetry_proto_profiles_v1development_profiles_proto != nil {
+		return
+	}
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[8].OneofWrappers = []any{}
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDesc), len(file_opentelemetry_proto_profiles_v1development_profiles_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_opentelemetry_proto_profiles_v1development_profiles_proto_goTypes,
+		DependencyIndexes: file_opentelemetry_proto_profiles_v1development_profiles_proto_depIdxs,
+		EnumInfos:         file_opentelemetry_proto_profiles_v1development_profiles_proto_enumTypes,
+		MessageInfos:      file_opentelemetry_proto_profiles_v1development_profiles_proto_msgTypes,
+	}.Build()
+	File_opentelemetry_proto_profiles_v1development_profiles_proto = out.File
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_goTypes = nil
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_depIdxs = nil
+}
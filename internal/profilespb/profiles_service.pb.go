@@ -0,0 +1,249 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: opentelemetry/proto/collector/profiles/v1development/profiles_service.proto
+
+package profilespb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ExportProfilesServiceRequest is the payload of the profiles Export RPC.
+type ExportProfilesServiceRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ResourceProfiles []*ResourceProfiles    `protobuf:"bytes,1,rep,name=resource_profiles,json=resourceProfiles,proto3" json:"resource_profiles,omitempty"`
+	Dictionary       *ProfilesDictionary    `protobuf:"bytes,2,opt,name=dictionary,proto3" json:"dictionary,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExportProfilesServiceRequest) Reset() {
+	*x = ExportProfilesServiceRequest{}
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProfilesServiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProfilesServiceRequest) ProtoMessage() {}
+
+func (x *ExportProfilesServiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProfilesServiceRequest.ProtoReflect.Descriptor instead.
+func (*ExportProfilesServiceRequest) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportProfilesServiceRequest) GetResourceProfiles() []*ResourceProfiles {
+	if x != nil {
+		return x.ResourceProfiles
+	}
+	return nil
+}
+
+func (x *ExportProfilesServiceRequest) GetDictionary() *ProfilesDictionary {
+	if x != nil {
+		return x.Dictionary
+	}
+	return nil
+}
+
+// ExportProfilesServiceResponse is the response of the profiles Export RPC.
+type ExportProfilesServiceResponse struct {
+	state          protoimpl.MessageState        `protogen:"open.v1"`
+	PartialSuccess *ExportProfilesPartialSuccess `protobuf:"bytes,1,opt,name=partial_success,json=partialSuccess,proto3" json:"partial_success,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportProfilesServiceResponse) Reset() {
+	*x = ExportProfilesServiceResponse{}
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProfilesServiceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProfilesServiceResponse) ProtoMessage() {}
+
+func (x *ExportProfilesServiceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProfilesServiceResponse.ProtoReflect.Descriptor instead.
+func (*ExportProfilesServiceResponse) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ExportProfilesServiceResponse) GetPartialSuccess() *ExportProfilesPartialSuccess {
+	if x != nil {
+		return x.PartialSuccess
+	}
+	return nil
+}
+
+// ExportProfilesPartialSuccess reports rejected profiles.
+type ExportProfilesPartialSuccess struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RejectedProfiles int64                  `protobuf:"varint,1,opt,name=rejected_profiles,json=rejectedProfiles,proto3" json:"rejected_profiles,omitempty"`
+	ErrorMessage     string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExportProfilesPartialSuccess) Reset() {
+	*x = ExportProfilesPartialSuccess{}
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProfilesPartialSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProfilesPartialSuccess) ProtoMessage() {}
+
+func (x *ExportProfilesPartialSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProfilesPartialSuccess.ProtoReflect.Descriptor instead.
+func (*ExportProfilesPartialSuccess) Descriptor() ([]byte, []int) {
+	return file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExportProfilesPartialSuccess) GetRejectedProfiles() int64 {
+	if x != nil {
+		return x.RejectedProfiles
+	}
+	return 0
+}
+
+func (x *ExportProfilesPartialSuccess) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+var File_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto protoreflect.FileDescriptor
+
+const file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDesc = "" +
+	"\n" +
+	"Kopentelemetry/proto/collector/profiles/v1development/profiles_service.proto\x124opentelemetry.proto.collector.profiles.v1development\x1a9opentelemetry/proto/profiles/v1development/profiles.proto\"\xe9\x01\n" +
+	"\x1cExportProfilesServiceRequest\x12i\n" +
+	"\x11resource_profiles\x18\x01 \x03(\v2<.opentelemetry.proto.profiles.v1development.ResourceProfilesR\x10resourceProfiles\x12^\n" +
+	"\n" +
+	"dictionary\x18\x02 \x01(\v2>.opentelemetry.proto.profiles.v1development.ProfilesDictionaryR\n" +
+	"dictionary\"\x9c\x01\n" +
+	"\x1dExportProfilesServiceResponse\x12{\n" +
+	"\x0fpartial_success\x18\x01 \x01(\v2R.opentelemetry.proto.collector.profiles.v1development.ExportProfilesPartialSuccessR\x0epartialSuccess\"p\n" +
+	"\x1cExportProfilesPartialSuccess\x12+\n" +
+	"\x11rejected_profiles\x18\x01 \x01(\x03R\x10rejectedProfiles\x12#\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage2\xc5\x01\n" +
+	"\x0fProfilesService\x12\xb1\x01\n" +
+	"\x06Export\x12R.opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceRequest\x1aS.opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceResponseB?Z=github.com/fidde/otlp_cardinality_checker/internal/profilespbb\x06proto3"
+
+var (
+	file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescOnce sync.Once
+	file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescData []byte
+)
+
+func file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescGZIP() []byte {
+	file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescOnce.Do(func() {
+		file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDesc), len(file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDesc)))
+	})
+	return file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDescData
+}
+
+var file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_goTypes = []any{
+	(*ExportProfilesServiceRequest)(nil),  // 0: opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceRequest
+	(*ExportProfilesServiceResponse)(nil), // 1: opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceResponse
+	(*ExportProfilesPartialSuccess)(nil),  // 2: opentelemetry.proto.collector.profiles.v1development.ExportProfilesPartialSuccess
+	(*ResourceProfiles)(nil),              // 3: opentelemetry.proto.profiles.v1development.ResourceProfiles
+	(*ProfilesDictionary)(nil),            // 4: opentelemetry.proto.profiles.v1development.ProfilesDictionary
+}
+var file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_depIdxs = []int32{
+	3, // 0: opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceRequest.resource_profiles:type_name -> opentelemetry.proto.profiles.v1development.ResourceProfiles
+	4, // 1: opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceRequest.dictionary:type_name -> opentelemetry.proto.profiles.v1development.ProfilesDictionary
+	2, // 2: opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceResponse.partial_success:type_name -> opentelemetry.proto.collector.profiles.v1development.ExportProfilesPartialSuccess
+	0, // 3: opentelemetry.proto.collector.profiles.v1development.ProfilesService.Export:input_type -> opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceRequest
+	1, // 4: opentelemetry.proto.collector.profiles.v1development.ProfilesService.Export:output_type -> opentelemetry.proto.collector.profiles.v1development.ExportProfilesServiceResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_init() }
+func file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_init() {
+	if File_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto != nil {
+		return
+	}
+	file_opentelemetry_proto_profiles_v1development_profiles_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDesc), len(file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_goTypes,
+		DependencyIndexes: file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_depIdxs,
+		MessageInfos:      file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_msgTypes,
+	}.Build()
+	File_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto = out.File
+	file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_goTypes = nil
+	file_opentelemetry_proto_collector_profiles_v1development_profiles_service_proto_depIdxs = nil
+}
@@ -0,0 +1,49 @@
+// Package profilespb contains the OTLP profiles (v1development) wire types.
+//
+// The profiles signal is still in development and no released
+// go.opentelemetry.io/proto/otlp version ships Go bindings for it, so the
+// messages in profiles.pb.go and profiles_service.pb.go are generated with
+// protoc-gen-go from opentelemetry-proto v1.7.0, where lookup tables live in
+// a request-level ProfilesDictionary. Fields added by later versions are
+// preserved as unknown fields and ignored by the analyzer.
+package profilespb
+
+import (
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// StringAt returns the string table entry at index i, or "" when i is out of
+// range.
+func (x *ProfilesDictionary) StringAt(i int32) string {
+	if x == nil || i < 0 || int(i) >= len(x.StringTable) {
+		return ""
+	}
+	return x.StringTable[i]
+}
+
+// AttributeAt returns the attribute table entry at index i, or nil when i is
+// out of range.
+func (x *ProfilesDictionary) AttributeAt(i int32) *commonpb.KeyValue {
+	if x == nil || i < 0 || int(i) >= len(x.AttributeTable) {
+		return nil
+	}
+	return x.AttributeTable[i]
+}
+
+// LocationAt returns the location table entry at index i, or nil when i is out
+// of range.
+func (x *ProfilesDictionary) LocationAt(i int32) *Location {
+	if x == nil || i < 0 || int(i) >= len(x.LocationTable) {
+		return nil
+	}
+	return x.LocationTable[i]
+}
+
+// MappingAt returns the mapping table entry at index i, or nil when i is out
+// of range.
+func (x *ProfilesDictionary) MappingAt(i int32) *Mapping {
+	if x == nil || i < 0 || int(i) >= len(x.MappingTable) {
+		return nil
+	}
+	return x.MappingTable[i]
+}
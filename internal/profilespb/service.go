@@ -0,0 +1,64 @@
+package profilespb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProfilesService_Export_FullMethodName is the full gRPC method name of the
+// profiles Export RPC.
+const ProfilesService_Export_FullMethodName = "/opentelemetry.proto.collector.profiles.v1development.ProfilesService/Export"
+
+// ProfilesServiceServer is the server API for the OTLP ProfilesService.
+type ProfilesServiceServer interface {
+	Export(context.Context, *ExportProfilesServiceRequest) (*ExportProfilesServiceResponse, error)
+}
+
+// UnimplementedProfilesServiceServer can be embedded to have forward
+// compatible implementations.
+type UnimplementedProfilesServiceServer struct{}
+
+// Export returns codes.Unimplemented.
+func (UnimplementedProfilesServiceServer) Export(context.Context, *ExportProfilesServiceRequest) (*ExportProfilesServiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+
+// RegisterProfilesServiceServer registers srv on s.
+func RegisterProfilesServiceServer(s grpc.ServiceRegistrar, srv ProfilesServiceServer) {
+	s.RegisterService(&ProfilesService_ServiceDesc, srv)
+}
+
+func _ProfilesService_Export_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportProfilesServiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfilesServiceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProfilesService_Export_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfilesServiceServer).Export(ctx, req.(*ExportProfilesServiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProfilesService_ServiceDesc is the grpc.ServiceDesc for ProfilesService.
+var ProfilesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.profiles.v1development.ProfilesService",
+	HandlerType: (*ProfilesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    _ProfilesService_Export_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/profiles/v1development/profiles_service.proto",
+}
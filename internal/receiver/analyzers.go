@@ -0,0 +1,90 @@
+package receiver
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
+	"github.com/fidde/otlp_cardinality_checker/internal/patterns"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+)
+
+// analyzers bundles the analyzers that write into one store.
+type analyzers struct {
+	store    storage.Storage
+	metrics  *analyzer.MetricsAnalyzer
+	traces   *analyzer.TracesAnalyzer
+	logs     *analyzer.LogsAnalyzer
+	profiles *analyzer.ProfilesAnalyzer
+}
+
+func newAnalyzers(store storage.Storage, pats []patterns.CompiledPattern) *analyzers {
+	// Create logs analyzer based on store configuration
+	var logsAnalyzer *analyzer.LogsAnalyzer
+	if store.UseAutoTemplate() {
+		logsAnalyzer = analyzer.NewLogsAnalyzerWithAutoTemplateAndCatalog(store.AutoTemplateCfg(), pats, store)
+	} else {
+		logsAnalyzer = analyzer.NewLogsAnalyzerWithCatalog(store)
+	}
+	if store.PodLogEnrichment() {
+		logsAnalyzer.SetPodLogEnrichment(true, store.PodLogServiceLabels())
+	}
+
+	return &analyzers{
+		store:    store,
+		metrics:  analyzer.NewMetricsAnalyzerWithCatalog(store),
+		traces:   analyzer.NewTracesAnalyzerWithCatalog(store),
+		logs:     logsAnalyzer,
+		profiles: analyzer.NewProfilesAnalyzerWithCatalog(store),
+	}
+}
+
+// analyzerSets hands out the analyzers for a request's tenant. Log template
+// mining keeps state across requests, so every tenant gets its own set.
+type analyzerSets struct {
+	def  *analyzers
+	pats []patterns.CompiledPattern
+
+	mu       sync.Mutex
+	byTenant map[string]*analyzers
+}
+
+func newAnalyzerSets(store storage.Storage) *analyzerSets {
+	// Load patterns from config
+	pats, err := patterns.LoadPatterns("config/patterns.yaml")
+	if err != nil {
+		log.Printf("Warning: Failed to load patterns: %v", err)
+		pats = nil
+	}
+	return &analyzerSets{
+		def:      newAnalyzers(store, pats),
+		pats:     pats,
+		byTenant: make(map[string]*analyzers),
+	}
+}
+
+// forContext returns the analyzers of the tenant carried by ctx, or the
+// default set when multi-tenancy is off.
+func (s *analyzerSets) forContext(ctx context.Context) *analyzers {
+	t := tenant.FromContext(ctx)
+	if t == nil {
+		return s.def
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.byTenant[t.ID]
+	if !ok {
+		a = newAnalyzers(t.Store, s.pats)
+		s.byTenant[t.ID] = a
+	}
+	return a
+}
+
+// drop releases the analyzers of tenant id.
+func (s *analyzerSets) drop(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byTenant, id)
+}
@@ -0,0 +1,41 @@
+package receiver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+func TestHTTPReceiver_RequiresCredentials(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{Tokens: map[string]auth.Role{"ingest": auth.RoleRead}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+	r := NewHTTPReceiver(":0", storage.NewStorage(storage.DefaultConfig()))
+	r.Auth = authenticator
+
+	send := func(path, apiKey string) int {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(minimalMetricsProto(t)))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		r.server.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("/v1/metrics", ""); code != http.StatusUnauthorized {
+		t.Errorf("without credentials: status %d, want 401", code)
+	}
+	if code := send("/v1/metrics", "ingest"); code != http.StatusOK {
+		t.Errorf("with API key: status %d, want 200", code)
+	}
+	if code := send("/health", ""); code != http.StatusOK {
+		t.Errorf("/health: status %d, want 200", code)
+	}
+}
@@ -0,0 +1,205 @@
+package receiver
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the "gzip" gRPC compressor
+)
+
+// snappyFramedMagic starts every stream in the snappy framing format: a
+// stream identifier chunk (type 0xff, length 6) followed by "sNaPpY".
+var snappyFramedMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+// zstdDecoderPool reuses zstd decoders. With a concurrency of one they
+// decode synchronously and start no goroutines, so pooled decoders can be
+// dropped by the GC without Close.
+var zstdDecoderPool = sync.Pool{
+	New: func() any {
+		d, err := zstd.NewReader(nil,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(maxBodyBytes)),
+		)
+		if err != nil {
+			panic(fmt.Sprintf("zstd decoder: %v", err))
+		}
+		return d
+	},
+}
+
+// errUnsupportedEncoding is returned for a Content-Encoding the receiver
+// cannot decode; handlers answer it with 415 Unsupported Media Type.
+var errUnsupportedEncoding = errors.New("unsupported Content-Encoding")
+
+// decompressBody decompresses raw into dst according to a lower-cased
+// Content-Encoding value. The decompressed size is capped at maxBodyBytes;
+// exceeding it yields an *http.MaxBytesError so handlers answer 413 exactly
+// as they do for oversized uncompressed bodies.
+func decompressBody(contentEncoding string, raw []byte, dst *bytes.Buffer) error {
+	switch contentEncoding {
+	case "gzip", "x-gzip":
+		gzr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("gzip reader: %w", err)
+		}
+		defer gzr.Close()
+		return readLimited(dst, gzr)
+
+	case "zstd":
+		d := zstdDecoderPool.Get().(*zstd.Decoder)
+		defer func() {
+			d.Reset(nil)
+			zstdDecoderPool.Put(d)
+		}()
+		if err := d.Reset(bytes.NewReader(raw)); err != nil {
+			return fmt.Errorf("zstd reader: %w", err)
+		}
+		return readLimited(dst, d)
+
+	case "snappy":
+		// The collector sends the block format for "snappy"; older versions
+		// sent the framing format under the same name.
+		if bytes.HasPrefix(raw, snappyFramedMagic) {
+			return readLimited(dst, snappy.NewReader(bytes.NewReader(raw)))
+		}
+		// The block header carries the decoded length, so oversized
+		// payloads are rejected before anything is allocated for them.
+		n, err := snappy.DecodedLen(raw)
+		if err != nil {
+			return fmt.Errorf("snappy: %w", err)
+		}
+		if int64(n) > maxBodyBytes {
+			return &http.MaxBytesError{Limit: maxBodyBytes}
+		}
+		dst.Grow(n)
+		out, err := snappy.Decode(dst.AvailableBuffer()[:n], raw)
+		if err != nil {
+			return fmt.Errorf("snappy: %w", err)
+		}
+		dst.Write(out)
+		return nil
+
+	case "x-snappy-framed":
+		return readLimited(dst, snappy.NewReader(bytes.NewReader(raw)))
+
+	case "deflate":
+		// RFC 9110 defines deflate as zlib-wrapped, but some clients send a
+		// raw deflate stream; accept both.
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			fr := flate.NewReader(bytes.NewReader(raw))
+			defer fr.Close()
+			return readLimited(dst, fr)
+		}
+		defer zr.Close()
+		return readLimited(dst, zr)
+	}
+	return fmt.Errorf("%w %q (supported: gzip, zstd, snappy, x-snappy-framed, deflate)", errUnsupportedEncoding, contentEncoding)
+}
+
+// readLimited copies r into dst, failing with *http.MaxBytesError once more
+// than maxBodyBytes have been produced. This is the decompression-bomb guard
+// shared by all encodings.
+func readLimited(dst *bytes.Buffer, r io.Reader) error {
+	lr := &io.LimitedReader{R: r, N: maxBodyBytes + 1}
+	if _, err := dst.ReadFrom(lr); err != nil {
+		return fmt.Errorf("decompress: %w", err)
+	}
+	if lr.N == 0 {
+		return &http.MaxBytesError{Limit: maxBodyBytes}
+	}
+	return nil
+}
+
+// gRPC compressors. gzip is registered by grpc-go itself (imported above);
+// zstd and snappy match the compressors the OpenTelemetry Collector offers
+// for its otlp exporter, so collectors can keep their default compression.
+// Decompressed message size is bounded by the server's MaxRecvMsgSize.
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+	encoding.RegisterCompressor(snappyCompressor{})
+}
+
+// zstdCompressor implements encoding.Compressor for "zstd".
+type zstdCompressor struct {
+	encoders sync.Pool
+}
+
+func (c *zstdCompressor) Name() string { return "zstd" }
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if e, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		e.Reset(w)
+		return &pooledZstdWriter{Encoder: e, pool: &c.encoders}, nil
+	}
+	e, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &pooledZstdWriter{Encoder: e, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	d := zstdDecoderPool.Get().(*zstd.Decoder)
+	if err := d.Reset(r); err != nil {
+		zstdDecoderPool.Put(d)
+		return nil, err
+	}
+	return &pooledZstdReader{Decoder: d}, nil
+}
+
+// pooledZstdWriter returns its encoder to the pool on Close.
+type pooledZstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *pooledZstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+// pooledZstdReader returns its decoder to the pool once the message has
+// been read to the end. A reader abandoned early (message over the size
+// limit) simply leaves its decoder to the GC.
+type pooledZstdReader struct {
+	*zstd.Decoder
+}
+
+func (r *pooledZstdReader) Read(p []byte) (int, error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+	n, err := r.Decoder.Read(p)
+	if err != nil {
+		r.Decoder.Reset(nil)
+		zstdDecoderPool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+	return n, err
+}
+
+// snappyCompressor implements encoding.Compressor for "snappy" using the
+// framing format, as gRPC messages are streamed through the compressor.
+type snappyCompressor struct{}
+
+func (snappyCompressor) Name() string { return "snappy" }
+
+func (snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
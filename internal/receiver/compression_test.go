@@ -0,0 +1,162 @@
+package receiver
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+)
+
+// compressors produce request bodies for each supported Content-Encoding.
+var compressors = map[string]func(t *testing.T, src []byte) []byte{
+	"gzip": gzipBytes,
+	"zstd": func(t *testing.T, src []byte) []byte {
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer enc.Close()
+		return enc.EncodeAll(src, nil)
+	},
+	"snappy": func(t *testing.T, src []byte) []byte {
+		return snappy.Encode(nil, src)
+	},
+	"x-snappy-framed": func(t *testing.T, src []byte) []byte {
+		return streamCompress(t, snappy.NewBufferedWriter, src)
+	},
+	"deflate": func(t *testing.T, src []byte) []byte {
+		return streamCompress(t, func(w io.Writer) *zlib.Writer { return zlib.NewWriter(w) }, src)
+	},
+}
+
+func streamCompress[W io.WriteCloser](t *testing.T, newWriter func(io.Writer) W, src []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write(src); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHandleMetrics_ContentEncodings(t *testing.T) {
+	payload := minimalMetricsProto(t)
+	for name, compress := range compressors {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReceiver(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(compress(t, payload)))
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			req.Header.Set("Content-Encoding", name)
+
+			w := httptest.NewRecorder()
+			r.handleMetrics(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReadAndDecompressBody_FramedSnappyAndRawDeflate(t *testing.T) {
+	payload := minimalMetricsProto(t)
+	rawDeflate := streamCompress(t, func(w io.Writer) *flate.Writer {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	}, payload)
+
+	for encoding, body := range map[string][]byte{
+		"snappy":  compressors["x-snappy-framed"](t, payload),
+		"deflate": rawDeflate,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+		got, release, err := readAndDecompressBody(req)
+		if err != nil {
+			t.Fatalf("%s: %v", encoding, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Errorf("%s: decompressed body mismatch", encoding)
+		}
+		release()
+	}
+}
+
+// TestDecompressionBombsRejected checks that every encoding enforces the
+// same decompressed-size limit as gzip.
+func TestDecompressionBombsRejected(t *testing.T) {
+	bomb := make([]byte, maxBodyBytes+1)
+	for name, compress := range compressors {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReceiver(t)
+			req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(compress(t, bomb)))
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			req.Header.Set("Content-Encoding", name)
+
+			w := httptest.NewRecorder()
+			r.handleMetrics(w, req)
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("expected 413, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestReadAndDecompressBody_UnsupportedEncoding(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+	req.Header.Set("Content-Encoding", "br")
+	if _, _, err := readAndDecompressBody(req); !errors.Is(err, errUnsupportedEncoding) {
+		t.Fatalf("expected unsupported encoding error, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+	req.Header.Set("Content-Encoding", "br")
+	w := httptest.NewRecorder()
+	newTestReceiver(t).handleMetrics(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %d", w.Code)
+	}
+}
+
+func TestGRPCCompressorsRoundTrip(t *testing.T) {
+	payload := minimalMetricsProto(t)
+	for _, name := range []string{"gzip", "zstd", "snappy"} {
+		c := encoding.GetCompressor(name)
+		if c == nil {
+			t.Fatalf("%s compressor not registered", name)
+		}
+		// Twice, so the second pass runs on pooled encoders and decoders.
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			w, err := c.Compress(&buf)
+			if err != nil {
+				t.Fatalf("%s: Compress: %v", name, err)
+			}
+			w.Write(payload)
+			if err := w.Close(); err != nil {
+				t.Fatalf("%s: Close: %v", name, err)
+			}
+			r, err := c.Decompress(&buf)
+			if err != nil {
+				t.Fatalf("%s: Decompress: %v", name, err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatalf("%s: read: %v", name, err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Fatalf("%s: round trip mismatch", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package receiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fingerprintSize is how many leading bytes identify a file across
+// restarts, like the OTel Collector's filelog receiver.
+const fingerprintSize = 1024
+
+// tailReadChunk is the read size when following a file.
+const tailReadChunk = 64 << 10
+
+// fileFingerprint identifies a file by a hash of its first Size bytes.
+type fileFingerprint struct {
+	Size int    `json:"size"`
+	Hash uint64 `json:"hash"`
+}
+
+// fingerprintFile hashes the first min(size, fingerprintSize) bytes of f.
+func fingerprintFile(f *os.File, size int64) (fileFingerprint, error) {
+	n := fingerprintSize
+	if size < int64(n) {
+		n = int(size)
+	}
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, 0); err != nil && !errors.Is(err, io.EOF) {
+		return fileFingerprint{}, err
+	}
+	h := fnv.New64a()
+	h.Write(buf)
+	return fileFingerprint{Size: n, Hash: h.Sum64()}, nil
+}
+
+// matches reports whether f starts with the fingerprinted bytes.
+func (fp fileFingerprint) matches(f *os.File, size int64) bool {
+	if fp.Size == 0 || size < int64(fp.Size) {
+		return false
+	}
+	cur, err := fingerprintFile(f, int64(fp.Size))
+	return err == nil && cur == fp
+}
+
+// tailedFile follows one file. offset is the end of the last complete line
+// handed out; partial holds bytes read past it that do not end in a newline
+// yet.
+type tailedFile struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	fp      fileFingerprint
+}
+
+// openTailedFile opens path and positions it at offset.
+func openTailedFile(path string, offset int64) (*tailedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fp, err := fingerprintFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &tailedFile{path: path, file: f, info: info, offset: offset, fp: fp}, nil
+}
+
+// readLines hands every complete line past the current offset to fn,
+// together with the offset the line starts at, reading at most limit bytes.
+// Lines longer than maxLine are split.
+func (t *tailedFile) readLines(limit int64, maxLine int, fn func(line []byte, start int64)) error {
+	buf := make([]byte, tailReadChunk)
+	var read int64
+	for read < limit {
+		n, err := t.file.ReadAt(buf, t.offset+int64(len(t.partial)))
+		read += int64(n)
+		data := buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				t.partial = append(t.partial, data...)
+				break
+			}
+			line := data[:i]
+			if len(t.partial) > 0 {
+				line = append(t.partial, line...)
+				t.partial = t.partial[:0]
+			}
+			fn(line, t.offset)
+			t.offset += int64(len(line)) + 1
+			data = data[i+1:]
+		}
+		for len(t.partial) >= maxLine {
+			fn(t.partial[:maxLine], t.offset)
+			t.offset += int64(maxLine)
+			t.partial = append(t.partial[:0], t.partial[maxLine:]...)
+		}
+		if errors.Is(err, io.EOF) || n == 0 {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh a fingerprint taken while the file was still short.
+	if t.fp.Size < fingerprintSize && t.offset > int64(t.fp.Size) {
+		if fp, err := fingerprintFile(t.file, t.offset); err == nil {
+			t.fp = fp
+		}
+	}
+	return nil
+}
+
+func (t *tailedFile) close() {
+	t.file.Close()
+}
+
+// fileCheckpoint is the persisted read position of one file.
+type fileCheckpoint struct {
+	Offset      int64           `json:"offset"`
+	Fingerprint fileFingerprint `json:"fingerprint"`
+}
+
+// loadCheckpoints reads a checkpoint file written by saveCheckpoints. A
+// missing file yields no checkpoints.
+func loadCheckpoints(path string) (map[string]fileCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]fileCheckpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cps := map[string]fileCheckpoint{}
+	if err := json.Unmarshal(data, &cps); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cps, nil
+}
+
+// saveCheckpoints atomically replaces the checkpoint file.
+func saveCheckpoints(path string, cps map[string]fileCheckpoint) error {
+	data, err := json.Marshal(cps)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,145 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/forward"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+)
+
+func TestHandleMetrics_ForwardsOriginalPayload(t *testing.T) {
+	received := make(chan []byte, 1)
+	contentTypes := make(chan string, 2)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		contentTypes <- req.Header.Get("Content-Type")
+		received <- body
+	}))
+	defer upstream.Close()
+
+	cfg := forward.DefaultConfig()
+	cfg.Endpoint = upstream.URL
+	cfg.Protocol = forward.ProtocolHTTP
+	cfg.Signals = []forward.Signal{forward.SignalMetrics}
+	fwd, err := forward.New(cfg)
+	if err != nil {
+		t.Fatalf("forward.New: %v", err)
+	}
+	defer fwd.Shutdown(context.Background())
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+	r.Forwarder = fwd
+
+	payload := minimalMetricsProto(t)
+	req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(gzipBytes(t, payload)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.handleMetrics(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := store.GetMetric(context.Background(), "test.counter"); err != nil {
+		t.Fatalf("metric not analyzed: %v", err)
+	}
+
+	select {
+	case body := <-received:
+		if !bytes.Equal(body, payload) {
+			t.Errorf("forwarded body differs from the decoded request (%d vs %d bytes)", len(body), len(payload))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("payload was not forwarded")
+	}
+	if ct := <-contentTypes; ct != forward.ContentTypeProtobuf {
+		t.Errorf("forwarded Content-Type = %q, want %q", ct, forward.ContentTypeProtobuf)
+	}
+
+	// A protobuf body labelled as JSON parses through the fallback and is
+	// forwarded as protobuf.
+	req = httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.handleMetrics(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("mislabelled request: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	select {
+	case <-received:
+		if ct := <-contentTypes; ct != forward.ContentTypeProtobuf {
+			t.Errorf("mislabelled request forwarded as %q, want %q", ct, forward.ContentTypeProtobuf)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mislabelled payload was not forwarded")
+	}
+
+	// Disabled signals are analyzed but not forwarded.
+	tracesReq := httptest.NewRequest(http.MethodPost, "/v1/traces", bytes.NewReader([]byte{}))
+	r.handleTraces(httptest.NewRecorder(), tracesReq)
+	if stats := fwd.Stats(); stats.Dropped != 0 || stats.Queued != 0 {
+		t.Errorf("unexpected forward stats for disabled signal: %+v", stats)
+	}
+}
+
+func TestHandleMetrics_ForwardsTenantHeader(t *testing.T) {
+	tenants := make(chan string, 2)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tenants <- req.Header.Get(tenant.DefaultHeader)
+	}))
+	defer upstream.Close()
+
+	cfg := forward.DefaultConfig()
+	cfg.Endpoint = upstream.URL
+	cfg.Protocol = forward.ProtocolHTTP
+	cfg.Signals = []forward.Signal{forward.SignalMetrics}
+	fwd, err := forward.New(cfg)
+	if err != nil {
+		t.Fatalf("forward.New: %v", err)
+	}
+	defer fwd.Shutdown(context.Background())
+
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+
+	r := NewHTTPReceiver(":0", reg.Storage())
+	r.Tenants = reg
+	r.Forwarder = fwd
+	handler := r.handler()
+
+	for _, id := range []string{"team-a", ""} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		if id != "" {
+			req.Header.Set(tenant.DefaultHeader, id)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		select {
+		case got := <-tenants:
+			if got != id {
+				t.Errorf("forwarded tenant header = %q, want %q", got, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("payload was not forwarded")
+		}
+	}
+}
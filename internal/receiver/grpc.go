@@ -3,59 +3,52 @@ package receiver
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
 
-	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
-	"github.com/fidde/otlp_cardinality_checker/internal/patterns"
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/forward"
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
 	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 // GRPCReceiver handles OTLP gRPC requests.
 type GRPCReceiver struct {
 	colmetricspb.UnimplementedMetricsServiceServer
-	store           storage.Storage
-	metricsAnalyzer *analyzer.MetricsAnalyzer
-	tracesAnalyzer  *analyzer.TracesAnalyzer
-	logsAnalyzer    *analyzer.LogsAnalyzer
-	server          *grpc.Server
-	listener        net.Listener
-	addr            string
-	OnActivity      func() // called after successful OTLP ingestion
+	analyzers   *analyzerSets
+	server      *grpc.Server
+	listener    net.Listener
+	addr        string
+	OnActivity  func()                 // called after successful OTLP ingestion
+	Forwarder   *forward.Forwarder     // optional; relays accepted requests upstream
+	TLSConfig   *tls.Config            // optional; serve TLS when set
+	Auth        *auth.Authenticator    // optional; require credentials for ingestion
+	Pipeline    *ingest.Pipeline       // optional; analyze and store asynchronously
+	Tenants     *tenant.Registry       // optional; isolate data per tenant metadata
+	Simulations *simulate.Registry     // optional; shadow-run candidate rules
+	SpanMetrics *spanmetrics.Estimator // optional; project spanmetrics connector series
 }
 
 // NewGRPCReceiver creates a new gRPC receiver.
 func NewGRPCReceiver(addr string, store storage.Storage) *GRPCReceiver {
-	// Load patterns from config
-	pats, err := patterns.LoadPatterns("config/patterns.yaml")
-	if err != nil {
-		log.Printf("Warning: Failed to load patterns: %v", err)
-		pats = nil
-	}
-	
-	// Create logs analyzer based on store configuration
-	var logsAnalyzer *analyzer.LogsAnalyzer
-	if store.UseAutoTemplate() {
-		logsAnalyzer = analyzer.NewLogsAnalyzerWithAutoTemplateAndCatalog(store.AutoTemplateCfg(), pats, store)
-	} else {
-		logsAnalyzer = analyzer.NewLogsAnalyzerWithCatalog(store)
-	}
-	if store.PodLogEnrichment() {
-		logsAnalyzer.SetPodLogEnrichment(true, store.PodLogServiceLabels())
-	}
-	
 	return &GRPCReceiver{
-		store:           store,
-		metricsAnalyzer: analyzer.NewMetricsAnalyzerWithCatalog(store),
-		tracesAnalyzer:  analyzer.NewTracesAnalyzerWithCatalog(store),
-		logsAnalyzer:    logsAnalyzer,
-		addr:            addr,
+		analyzers: newAnalyzerSets(store),
+		addr:      addr,
 	}
 }
 
@@ -67,9 +60,25 @@ func (r *GRPCReceiver) Start() error {
 	}
 	r.listener = lis
 
-	r.server = grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(16 * 1024 * 1024), // 16 MB — accommodates large batches
-	)
+	}
+	if r.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(r.TLSConfig)))
+	}
+	if r.Auth.Enabled() {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(r.authUnary),
+			grpc.ChainStreamInterceptor(r.authStream),
+		)
+	}
+	if r.Tenants != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(r.Tenants.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(r.Tenants.StreamServerInterceptor()),
+		)
+	}
+	r.server = grpc.NewServer(opts...)
 
 	// Register OTLP services with wrapper types to avoid method name conflicts
 	colmetricspb.RegisterMetricsServiceServer(r.server, r)
@@ -81,6 +90,10 @@ func (r *GRPCReceiver) Start() error {
 		UnimplementedLogsServiceServer: collogspb.UnimplementedLogsServiceServer{},
 		GRPCReceiver:                   r,
 	})
+	profilespb.RegisterProfilesServiceServer(r.server, &profilesService{
+		UnimplementedProfilesServiceServer: profilespb.UnimplementedProfilesServiceServer{},
+		GRPCReceiver:                       r,
+	})
 
 	// Register reflection service for debugging with grpcurl
 	reflection.Register(r.server)
@@ -89,6 +102,12 @@ func (r *GRPCReceiver) Start() error {
 	return r.server.Serve(lis)
 }
 
+// DropTenant releases the analyzers kept for tenant id. Register it with
+// Tenants.OnDrop.
+func (r *GRPCReceiver) DropTenant(id string) {
+	r.analyzers.drop(id)
+}
+
 // Shutdown gracefully shuts down the gRPC server.
 func (r *GRPCReceiver) Shutdown(ctx context.Context) error {
 	if r.server != nil {
@@ -97,20 +116,49 @@ func (r *GRPCReceiver) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// authorize checks the authorization or x-api-key metadata of a call.
+func (r *GRPCReceiver) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if _, err := r.Auth.Authenticate(first("authorization"), first("x-api-key")); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+func (r *GRPCReceiver) authUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := r.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (r *GRPCReceiver) authStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := r.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
 // MetricsService implementation
 
 // Export implements the MetricsService Export RPC.
 func (r *GRPCReceiver) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
-	// Analyze metrics metadata
-	metadata, err := r.metricsAnalyzer.AnalyzeWithContext(ctx, req)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	err := r.Pipeline.Submit(ctx, ingest.SignalMetrics, func(ctx context.Context) error {
+		return storeMetrics(ctx, a, sims, req)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze metrics: %w", err)
-	}
-
-	// Store metadata
-	for _, m := range metadata {
-		r.store.StoreMetric(ctx, m)
+		return nil, r.ingestError(err)
 	}
+	r.Forwarder.EnqueueMessage(forward.SignalMetrics, req, r.Tenants.ForwardHeaders(ctx))
 
 	// Return success response
 	if r.OnActivity != nil {
@@ -131,16 +179,17 @@ type traceService struct {
 
 // Export implements the TraceService Export RPC.
 func (s *traceService) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
-	// Analyze traces metadata
-	metadata, err := s.tracesAnalyzer.AnalyzeWithContext(ctx, req)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := s.analyzers.forContext(ctx)
+	sims := s.Simulations.Observer(ctx)
+	rec := s.SpanMetrics.Recorder(ctx)
+	err := s.Pipeline.Submit(ctx, ingest.SignalTraces, func(ctx context.Context) error {
+		return storeSpans(ctx, a, sims, rec, req)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze traces: %w", err)
-	}
-
-	// Store metadata
-	for _, m := range metadata {
-		s.store.StoreSpan(ctx, m)
+		return nil, s.ingestError(err)
 	}
+	s.Forwarder.EnqueueMessage(forward.SignalTraces, req, s.Tenants.ForwardHeaders(ctx))
 
 	// Return success response
 	if s.OnActivity != nil {
@@ -161,16 +210,16 @@ type logsService struct {
 
 // Export implements the LogsService Export RPC.
 func (s *logsService) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
-	// Analyze logs metadata
-	metadata, err := s.logsAnalyzer.AnalyzeWithContext(ctx, req)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := s.analyzers.forContext(ctx)
+	sims := s.Simulations.Observer(ctx)
+	err := s.Pipeline.Submit(ctx, ingest.SignalLogs, func(ctx context.Context) error {
+		return storeLogs(ctx, a, sims, req)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze logs: %w", err)
-	}
-
-	// Store metadata
-	for _, m := range metadata {
-		s.store.StoreLog(ctx, m)
+		return nil, s.ingestError(err)
 	}
+	s.Forwarder.EnqueueMessage(forward.SignalLogs, req, s.Tenants.ForwardHeaders(ctx))
 
 	// Return success response
 	if s.OnActivity != nil {
@@ -182,3 +231,32 @@ func (s *logsService) Export(ctx context.Context, req *collogspb.ExportLogsServi
 		},
 	}, nil
 }
+
+// ProfilesService implementation - uses separate type to avoid method name conflicts
+type profilesService struct {
+	profilespb.UnimplementedProfilesServiceServer
+	*GRPCReceiver
+}
+
+// Export implements the ProfilesService Export RPC.
+func (s *profilesService) Export(ctx context.Context, req *profilespb.ExportProfilesServiceRequest) (*profilespb.ExportProfilesServiceResponse, error) {
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := s.analyzers.forContext(ctx)
+	err := s.Pipeline.Submit(ctx, ingest.SignalProfiles, func(ctx context.Context) error {
+		return storeProfiles(ctx, a, req)
+	})
+	if err != nil {
+		return nil, s.ingestError(err)
+	}
+	s.Forwarder.EnqueueMessage(forward.SignalProfiles, req, s.Tenants.ForwardHeaders(ctx))
+
+	// Return success response
+	if s.OnActivity != nil {
+		s.OnActivity()
+	}
+	return &profilespb.ExportProfilesServiceResponse{
+		PartialSuccess: &profilespb.ExportProfilesPartialSuccess{
+			RejectedProfiles: 0,
+		},
+	}, nil
+}
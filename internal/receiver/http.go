@@ -3,8 +3,8 @@ package receiver
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,9 +17,14 @@ import (
 	"time"
 	"unicode/utf8"
 
-	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
-	"github.com/fidde/otlp_cardinality_checker/internal/patterns"
+	"github.com/fidde/otlp_cardinality_checker/internal/auth"
+	"github.com/fidde/otlp_cardinality_checker/internal/forward"
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
 	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
@@ -49,11 +54,13 @@ func min(a, b int) int {
 	return b
 }
 
-// readAndDecompressBody reads the full request body and decompresses it if
-// needed. Decompression is triggered by an explicit "Content-Encoding: gzip"
-// header OR by the gzip magic bytes (0x1f 0x8b) at the start of the body.
-// The latter handles collectors that compress without advertising it, which
-// is the root cause of "cannot parse invalid wire-format data" errors.
+// readAndDecompressBody reads the full request body and decompresses it
+// according to Content-Encoding (gzip, zstd, snappy, x-snappy-framed,
+// deflate). A body carrying the gzip magic bytes (0x1f 0x8b) is decompressed
+// even without the header: this handles collectors that compress without
+// advertising it, which is the root cause of "cannot parse invalid
+// wire-format data" errors. Every encoding is subject to the same
+// maxBodyBytes limit on the decompressed size.
 //
 // The caller MUST call release() after consuming the returned slice (i.e.
 // after proto.Unmarshal / protojson.Unmarshal returns) to return the buffer
@@ -68,38 +75,24 @@ func readAndDecompressBody(req *http.Request) (data []byte, release func(), err
 	}
 
 	raw := buf.Bytes()
-	isGzipHeader := strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip")
-	hasMagicBytes := len(raw) >= 2 && raw[0] == 0x1f && raw[1] == 0x8b
-
-	if isGzipHeader || hasMagicBytes {
-		gzr, gzErr := gzip.NewReader(bytes.NewReader(raw))
-		if gzErr != nil {
-			bodyBufPool.Put(buf)
-			return nil, nil, fmt.Errorf("gzip reader: %w", gzErr)
-		}
-		defer gzr.Close()
-
-		// Decompress into a second pooled buffer.
-		decompBuf := bodyBufPool.Get().(*bytes.Buffer)
-		decompBuf.Reset()
-		// Limit decompressed output to prevent gzip bombs.
-		lr := &io.LimitedReader{R: gzr, N: maxBodyBytes + 1}
-		if _, err = decompBuf.ReadFrom(lr); err != nil {
-			bodyBufPool.Put(buf)
-			bodyBufPool.Put(decompBuf)
-			return nil, nil, fmt.Errorf("decompress: %w", err)
-		}
-		if lr.N == 0 {
-			// Decompressed size exceeds maxBodyBytes — reject as zip bomb.
-			bodyBufPool.Put(buf)
-			bodyBufPool.Put(decompBuf)
-			return nil, nil, &http.MaxBytesError{Limit: maxBodyBytes}
-		}
-		bodyBufPool.Put(buf) // raw (compressed) buffer no longer needed
-		return decompBuf.Bytes(), func() { bodyBufPool.Put(decompBuf) }, nil
+	encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding")))
+	if (encoding == "" || encoding == "identity") && len(raw) >= 2 && raw[0] == 0x1f && raw[1] == 0x8b {
+		encoding = "gzip"
+	}
+	if encoding == "" || encoding == "identity" {
+		return raw, func() { bodyBufPool.Put(buf) }, nil
 	}
 
-	return raw, func() { bodyBufPool.Put(buf) }, nil
+	// Decompress into a second pooled buffer.
+	decompBuf := bodyBufPool.Get().(*bytes.Buffer)
+	decompBuf.Reset()
+	if err = decompressBody(encoding, raw, decompBuf); err != nil {
+		bodyBufPool.Put(buf)
+		bodyBufPool.Put(decompBuf)
+		return nil, nil, err
+	}
+	bodyBufPool.Put(buf) // raw (compressed) buffer no longer needed
+	return decompBuf.Bytes(), func() { bodyBufPool.Put(decompBuf) }, nil
 }
 
 // sanitizeUTF8 replaces invalid UTF-8 byte sequences with the Unicode
@@ -132,50 +125,40 @@ func rejectIfBodyTooLarge(w http.ResponseWriter, req *http.Request) bool {
 
 // HTTPReceiver handles OTLP HTTP requests.
 type HTTPReceiver struct {
-	store           storage.Storage
-	metricsAnalyzer *analyzer.MetricsAnalyzer
-	tracesAnalyzer  *analyzer.TracesAnalyzer
-	logsAnalyzer    *analyzer.LogsAnalyzer
-	server          *http.Server
-	OnActivity      func() // called after successful OTLP ingestion
+	analyzers   *analyzerSets
+	mux         *http.ServeMux
+	server      *http.Server
+	OnActivity  func()                 // called after successful OTLP ingestion
+	Forwarder   *forward.Forwarder     // optional; relays accepted payloads upstream
+	TLSConfig   *tls.Config            // optional; serve HTTPS when set
+	Auth        *auth.Authenticator    // optional; require credentials for ingestion
+	Pipeline    *ingest.Pipeline       // optional; analyze and store asynchronously
+	Tenants     *tenant.Registry       // optional; isolate data per tenant header
+	Simulations *simulate.Registry     // optional; shadow-run candidate rules
+	SpanMetrics *spanmetrics.Estimator // optional; project spanmetrics connector series
 }
 
 // NewHTTPReceiver creates a new HTTP receiver.
 func NewHTTPReceiver(addr string, store storage.Storage) *HTTPReceiver {
-	// Load patterns from config
-	pats, err := patterns.LoadPatterns("config/patterns.yaml")
-	if err != nil {
-		log.Printf("Warning: Failed to load patterns: %v", err)
-		pats = nil
-	}
-	
-	// Create logs analyzer based on store configuration
-	var logsAnalyzer *analyzer.LogsAnalyzer
-	if store.UseAutoTemplate() {
-		logsAnalyzer = analyzer.NewLogsAnalyzerWithAutoTemplateAndCatalog(store.AutoTemplateCfg(), pats, store)
-	} else {
-		logsAnalyzer = analyzer.NewLogsAnalyzerWithCatalog(store)
-	}
-	if store.PodLogEnrichment() {
-		logsAnalyzer.SetPodLogEnrichment(true, store.PodLogServiceLabels())
-	}
-	
 	r := &HTTPReceiver{
-		store:           store,
-		metricsAnalyzer: analyzer.NewMetricsAnalyzerWithCatalog(store),
-		tracesAnalyzer:  analyzer.NewTracesAnalyzerWithCatalog(store),
-		logsAnalyzer:    logsAnalyzer,
+		analyzers: newAnalyzerSets(store),
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/metrics", r.handleMetrics)
 	mux.HandleFunc("/v1/traces", r.handleTraces)
 	mux.HandleFunc("/v1/logs", r.handleLogs)
+	mux.HandleFunc("/v1development/profiles", r.handleProfiles)
+	mux.HandleFunc("/api/v1/write", r.handleRemoteWrite)
+	mux.HandleFunc("/api/v2/spans", r.handleZipkin)
+	mux.HandleFunc("/api/traces", r.handleJaeger)
+	mux.HandleFunc("/loki/api/v1/push", r.handleLokiPush)
 	mux.HandleFunc("/health", r.handleHealth)
+	r.mux = mux
 
 	r.server = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      r.handler(),
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
@@ -184,11 +167,24 @@ func NewHTTPReceiver(addr string, store storage.Storage) *HTTPReceiver {
 	return r
 }
 
-// Start starts the HTTP server.
+// Start starts the HTTP server, with TLS when TLSConfig is set.
 func (r *HTTPReceiver) Start() error {
+	// Tenants is set after construction, so like the gRPC interceptors the
+	// handler chain is rebuilt once here rather than per request.
+	r.server.Handler = r.handler()
+	if r.TLSConfig != nil {
+		r.server.TLSConfig = r.TLSConfig
+		return r.server.ListenAndServeTLS("", "")
+	}
 	return r.server.ListenAndServe()
 }
 
+// DropTenant releases the analyzers kept for tenant id. Register it with
+// Tenants.OnDrop.
+func (r *HTTPReceiver) DropTenant(id string) {
+	r.analyzers.drop(id)
+}
+
 // Shutdown gracefully shuts down the HTTP server.
 func (r *HTTPReceiver) Shutdown(ctx context.Context) error {
 	return r.server.Shutdown(ctx)
@@ -214,6 +210,10 @@ func (r *HTTPReceiver) handleMetrics(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
 			return
 		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -242,6 +242,7 @@ func (r *HTTPReceiver) handleMetrics(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: %v", jsonErr), http.StatusBadRequest)
 				return
 			}
+			contentType = forward.ContentTypeProtobuf
 			if verboseLogging {
 				fmt.Println("Parsed metrics as protobuf (Content-Type mismatch)")
 			}
@@ -260,6 +261,7 @@ func (r *HTTPReceiver) handleMetrics(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: protobuf error: %v, json error: %v", err, jsonErr), http.StatusBadRequest)
 				return
 			}
+			contentType = forward.ContentTypeJSON
 			if verboseLogging {
 				fmt.Println("Parsed metrics as JSON")
 			}
@@ -267,28 +269,21 @@ func (r *HTTPReceiver) handleMetrics(w http.ResponseWriter, req *http.Request) {
 			fmt.Println("Parsed metrics as protobuf")
 		}
 	}
+	fwd := r.forwardPayload(ctx, forward.SignalMetrics, body, contentType)
 	releaseBody()
 
-	// Analyze metrics
-	metricsMetadata, err := r.metricsAnalyzer.AnalyzeWithContext(ctx, &exportReq)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	err = r.Pipeline.Submit(ctx, ingest.SignalMetrics, func(ctx context.Context) error {
+		return storeMetrics(ctx, a, sims, &exportReq)
+	})
 	if err != nil {
-		log.Printf("Metrics analysis error: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to analyze metrics: %v", err), http.StatusInternalServerError)
+		r.writeIngestError(w, ingest.SignalMetrics, err)
 		return
 	}
 
-	if verboseLogging {
-		fmt.Printf("Successfully analyzed %d metrics\n", len(metricsMetadata))
-	}
-
-	// Store metadata
-	for _, metadata := range metricsMetadata {
-		if err := r.store.StoreMetric(ctx, metadata); err != nil {
-			log.Printf("Storage error: %v\n", err)
-			http.Error(w, fmt.Sprintf("Failed to store metric: %v", err), http.StatusInternalServerError)
-			return
-		}
-	}
+	r.Forwarder.Enqueue(fwd)
 
 	// Return success response (always protobuf for OTLP)
 	resp := &colmetricspb.ExportMetricsServiceResponse{}
@@ -318,6 +313,10 @@ func (r *HTTPReceiver) handleTraces(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
 			return
 		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -335,6 +334,7 @@ func (r *HTTPReceiver) handleTraces(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: %v", jsonErr), http.StatusBadRequest)
 				return
 			}
+			traceContentType = forward.ContentTypeProtobuf
 		} else if verboseLogging {
 			fmt.Println("Parsed traces as JSON")
 		}
@@ -349,6 +349,7 @@ func (r *HTTPReceiver) handleTraces(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: protobuf error: %v, json error: %v", err, jsonErr), http.StatusBadRequest)
 				return
 			}
+			traceContentType = forward.ContentTypeJSON
 			if verboseLogging {
 				fmt.Println("Parsed traces as JSON")
 			}
@@ -356,28 +357,22 @@ func (r *HTTPReceiver) handleTraces(w http.ResponseWriter, req *http.Request) {
 			fmt.Println("Parsed traces as protobuf")
 		}
 	}
+	fwd := r.forwardPayload(ctx, forward.SignalTraces, body, traceContentType)
 	releaseBody()
 
-	// Analyze traces
-	spansMetadata, err := r.tracesAnalyzer.AnalyzeWithContext(ctx, &exportReq)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	rec := r.SpanMetrics.Recorder(ctx)
+	err = r.Pipeline.Submit(ctx, ingest.SignalTraces, func(ctx context.Context) error {
+		return storeSpans(ctx, a, sims, rec, &exportReq)
+	})
 	if err != nil {
-		log.Printf("Trace analysis error: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to analyze traces: %v", err), http.StatusInternalServerError)
+		r.writeIngestError(w, ingest.SignalTraces, err)
 		return
 	}
 
-	if verboseLogging {
-		fmt.Printf("Successfully analyzed %d spans\n", len(spansMetadata))
-	}
-
-	// Store metadata
-	for _, metadata := range spansMetadata {
-		if err := r.store.StoreSpan(ctx, metadata); err != nil {
-			log.Printf("Span storage error: %v\n", err)
-			http.Error(w, fmt.Sprintf("Failed to store span: %v", err), http.StatusInternalServerError)
-			return
-		}
-	}
+	r.Forwarder.Enqueue(fwd)
 
 	// Return success response (always protobuf for OTLP)
 	resp := &coltracepb.ExportTraceServiceResponse{}
@@ -407,6 +402,10 @@ func (r *HTTPReceiver) handleLogs(w http.ResponseWriter, req *http.Request) {
 			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
 			return
 		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -424,6 +423,7 @@ func (r *HTTPReceiver) handleLogs(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: %v", jsonErr), http.StatusBadRequest)
 				return
 			}
+			logsContentType = forward.ContentTypeProtobuf
 		} else if verboseLogging {
 			fmt.Println("Parsed logs as JSON")
 		}
@@ -438,6 +438,7 @@ func (r *HTTPReceiver) handleLogs(w http.ResponseWriter, req *http.Request) {
 				http.Error(w, fmt.Sprintf("Failed to parse request: protobuf error: %v, json error: %v", err, jsonErr), http.StatusBadRequest)
 				return
 			}
+			logsContentType = forward.ContentTypeJSON
 			if verboseLogging {
 				fmt.Println("Parsed logs as JSON")
 			}
@@ -445,31 +446,109 @@ func (r *HTTPReceiver) handleLogs(w http.ResponseWriter, req *http.Request) {
 			fmt.Println("Parsed logs as protobuf")
 		}
 	}
+	fwd := r.forwardPayload(ctx, forward.SignalLogs, body, logsContentType)
 	releaseBody()
 
-	// Analyze logs
-	logsMetadata, err := r.logsAnalyzer.AnalyzeWithContext(ctx, &exportReq)
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	err = r.Pipeline.Submit(ctx, ingest.SignalLogs, func(ctx context.Context) error {
+		return storeLogs(ctx, a, sims, &exportReq)
+	})
 	if err != nil {
-		log.Printf("Log analysis error: %v\n", err)
-		http.Error(w, fmt.Sprintf("Failed to analyze logs: %v", err), http.StatusInternalServerError)
+		r.writeIngestError(w, ingest.SignalLogs, err)
 		return
 	}
 
-	if verboseLogging {
-		fmt.Printf("Successfully analyzed %d log severities\n", len(logsMetadata))
+	r.Forwarder.Enqueue(fwd)
+
+	// Return success response (always protobuf for OTLP)
+	resp := &collogspb.ExportLogsServiceResponse{}
+	r.writeResponse(w, resp)
+	if r.OnActivity != nil {
+		r.OnActivity()
+	}
+}
+
+// handleProfiles handles OTLP profiles export requests.
+func (r *HTTPReceiver) handleProfiles(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rejectIfBodyTooLarge(w, req) {
+		return
 	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+
+	ctx := req.Context()
 
-	// Store metadata
-	for _, metadata := range logsMetadata {
-		if err := r.store.StoreLog(ctx, metadata); err != nil {
-			log.Printf("Log storage error: %v\n", err)
-			http.Error(w, fmt.Sprintf("Failed to store log: %v", err), http.StatusInternalServerError)
+	body, releaseBody, err := readAndDecompressBody(req)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
 			return
 		}
+		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer req.Body.Close()
+
+	var exportReq profilespb.ExportProfilesServiceRequest
+	msg := &exportReq
+	profilesUnmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	profilesContentType := req.Header.Get("Content-Type")
+
+	if isJSONContentType(profilesContentType) {
+		if jsonErr := profilesUnmarshaler.Unmarshal(sanitizeUTF8(body), msg); jsonErr != nil {
+			if protoErr := proto.Unmarshal(body, msg); protoErr != nil {
+				releaseBody()
+				log.Printf("Failed to parse profiles request: json error: %v, protobuf error: %v", jsonErr, protoErr)
+				http.Error(w, fmt.Sprintf("Failed to parse request: %v", jsonErr), http.StatusBadRequest)
+				return
+			}
+			profilesContentType = forward.ContentTypeProtobuf
+		} else if verboseLogging {
+			fmt.Println("Parsed profiles as JSON")
+		}
+	} else {
+		if err := proto.Unmarshal(body, msg); err != nil {
+			if jsonErr := profilesUnmarshaler.Unmarshal(sanitizeUTF8(body), msg); jsonErr != nil {
+				releaseBody()
+				log.Printf("Failed to parse profiles request: protobuf error: %v, json error: %v", err, jsonErr)
+				http.Error(w, fmt.Sprintf("Failed to parse request: protobuf error: %v, json error: %v", err, jsonErr), http.StatusBadRequest)
+				return
+			}
+			profilesContentType = forward.ContentTypeJSON
+			if verboseLogging {
+				fmt.Println("Parsed profiles as JSON")
+			}
+		} else if verboseLogging {
+			fmt.Println("Parsed profiles as protobuf")
+		}
 	}
+	fwd := r.forwardPayload(ctx, forward.SignalProfiles, body, profilesContentType)
+	releaseBody()
+
+	// Analyze and store, on the ingestion pipeline when one is configured.
+	a := r.analyzers.forContext(ctx)
+	err = r.Pipeline.Submit(ctx, ingest.SignalProfiles, func(ctx context.Context) error {
+		return storeProfiles(ctx, a, &exportReq)
+	})
+	if err != nil {
+		r.writeIngestError(w, ingest.SignalProfiles, err)
+		return
+	}
+
+	r.Forwarder.Enqueue(fwd)
 
 	// Return success response (always protobuf for OTLP)
-	resp := &collogspb.ExportLogsServiceResponse{}
+	resp := &profilespb.ExportProfilesServiceResponse{}
 	r.writeResponse(w, resp)
 	if r.OnActivity != nil {
 		r.OnActivity()
@@ -478,8 +557,52 @@ func (r *HTTPReceiver) handleLogs(w http.ResponseWriter, req *http.Request) {
 
 // handleHealth handles health check requests.
 func (r *HTTPReceiver) handleHealth(w http.ResponseWriter, req *http.Request) {
+	health := map[string]any{"status": "ok"}
+	if r.Forwarder != nil {
+		health["forward"] = r.Forwarder.Stats()
+	}
+	if r.Pipeline != nil {
+		health["ingest"] = r.Pipeline.Stats()
+	}
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(health)
+}
+
+// handler wraps the routes in authentication and, when Tenants is set,
+// tenant resolution.
+func (r *HTTPReceiver) handler() http.Handler {
+	return r.authenticate(r.Tenants.Middleware(r.mux))
+}
+
+// authenticate rejects ingestion requests without valid credentials when
+// Auth is set. /health stays open for probes.
+func (r *HTTPReceiver) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.Auth.Enabled() && req.URL.Path != "/health" {
+			if _, err := r.Auth.AuthenticateRequest(req); err != nil {
+				r.Auth.Unauthorized(w)
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// forwardPayload copies body for forwarding when the signal is enabled. The
+// copy is needed because body is returned to the pool by releaseBody.
+// contentType is the format body parsed as, which differs from the request
+// header when only the fallback decoder succeeded. The tenant header of the
+// request is passed on, so a multi-tenant upstream keeps tenants apart.
+func (r *HTTPReceiver) forwardPayload(ctx context.Context, signal forward.Signal, body []byte, contentType string) forward.Payload {
+	if !r.Forwarder.Enabled(signal) {
+		return forward.Payload{}
+	}
+	return forward.Payload{
+		Signal:      signal,
+		Body:        bytes.Clone(body),
+		ContentType: contentType,
+		Headers:     r.Tenants.ForwardHeaders(ctx),
+	}
 }
 
 // writeResponse writes a protobuf response.
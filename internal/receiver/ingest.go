@@ -0,0 +1,166 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The store* functions are the analyze-and-store step shared by the HTTP
+// and gRPC receivers. They run either inline or on an ingest.Pipeline
+// worker, so they must not touch the request that delivered the data, and
+// anything tenant-scoped is resolved by the caller beforehand. Accepted data
+// is also fed to the tenant's running simulations and spanmetrics estimate.
+
+func storeMetrics(ctx context.Context, a *analyzers, sims *simulate.Observer, req *colmetricspb.ExportMetricsServiceRequest) error {
+	metadata, err := a.metrics.AnalyzeWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("analyze metrics: %w", err)
+	}
+	if verboseLogging {
+		fmt.Printf("Successfully analyzed %d metrics\n", len(metadata))
+	}
+	for _, m := range metadata {
+		if err := a.store.StoreMetric(ctx, m); err != nil {
+			return fmt.Errorf("store metric: %w", err)
+		}
+	}
+	sims.Metrics(req)
+	return nil
+}
+
+func storeSpans(ctx context.Context, a *analyzers, sims *simulate.Observer, rec *spanmetrics.Recorder, req *coltracepb.ExportTraceServiceRequest) error {
+	metadata, err := a.traces.AnalyzeWithSpanMetrics(ctx, req, rec)
+	if err != nil {
+		return fmt.Errorf("analyze traces: %w", err)
+	}
+	if verboseLogging {
+		fmt.Printf("Successfully analyzed %d spans\n", len(metadata))
+	}
+	for _, m := range metadata {
+		if err := a.store.StoreSpan(ctx, m); err != nil {
+			return fmt.Errorf("store span: %w", err)
+		}
+	}
+	sims.Traces(req)
+	return nil
+}
+
+func storeLogs(ctx context.Context, a *analyzers, sims *simulate.Observer, req *collogspb.ExportLogsServiceRequest) error {
+	metadata, err := a.logs.AnalyzeWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("analyze logs: %w", err)
+	}
+	if verboseLogging {
+		fmt.Printf("Successfully analyzed %d log severities\n", len(metadata))
+	}
+	for _, m := range metadata {
+		if err := a.store.StoreLog(ctx, m); err != nil {
+			return fmt.Errorf("store log: %w", err)
+		}
+	}
+	sims.Logs(req)
+	return nil
+}
+
+func storeProfiles(ctx context.Context, a *analyzers, req *profilespb.ExportProfilesServiceRequest) error {
+	ps, ok := a.store.(storage.ProfileStore)
+	if !ok {
+		return nil
+	}
+	metadata, err := a.profiles.AnalyzeWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("analyze profiles: %w", err)
+	}
+	if verboseLogging {
+		fmt.Printf("Successfully analyzed %d profile sample types\n", len(metadata))
+	}
+	for _, m := range metadata {
+		if err := ps.StoreProfile(ctx, m); err != nil {
+			return fmt.Errorf("store profile: %w", err)
+		}
+	}
+	return nil
+}
+
+// retryAfterSeconds rounds d up to whole seconds, at least one.
+func retryAfterSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
+
+// writeIngestError answers a failed submit. A full queue is throttling
+// (429) and a draining pipeline is unavailability (503); OTLP exporters
+// retry both and honour Retry-After. Anything else is an analysis or
+// storage failure from synchronous ingestion.
+func (r *HTTPReceiver) writeIngestError(w http.ResponseWriter, signal ingest.Signal, err error) {
+	switch {
+	case errors.Is(err, ingest.ErrQueueFull):
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(r.Pipeline.RetryAfter())))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, ingest.ErrClosed):
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(r.Pipeline.RetryAfter())))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		log.Printf("Failed to ingest %s: %v", signal, err)
+		http.Error(w, fmt.Sprintf("Failed to ingest %s: %v", signal, err), http.StatusInternalServerError)
+	}
+}
+
+// acceptTraces submits spans translated from a non-OTLP format and answers
+// 202 Accepted, as the Zipkin and Jaeger collectors do.
+func (r *HTTPReceiver) acceptTraces(ctx context.Context, w http.ResponseWriter, exportReq *coltracepb.ExportTraceServiceRequest) {
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	rec := r.SpanMetrics.Recorder(ctx)
+	err := r.Pipeline.Submit(ctx, ingest.SignalTraces, func(ctx context.Context) error {
+		return storeSpans(ctx, a, sims, rec, exportReq)
+	})
+	if err != nil {
+		r.writeIngestError(w, ingest.SignalTraces, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	if r.OnActivity != nil {
+		r.OnActivity()
+	}
+}
+
+// ingestError converts a failed submit into a gRPC status. OTLP exporters
+// only retry RESOURCE_EXHAUSTED when the status carries RetryInfo.
+func (r *GRPCReceiver) ingestError(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, ingest.ErrQueueFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, ingest.ErrClosed):
+		code = codes.Unavailable
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+	st, detailErr := status.New(code, err.Error()).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(r.Pipeline.RetryAfter()),
+	})
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
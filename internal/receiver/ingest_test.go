@@ -0,0 +1,114 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func postMetrics(t *testing.T, r *HTTPReceiver) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+	r.handleMetrics(w, req)
+	return w
+}
+
+func drain(t *testing.T, p *ingest.Pipeline) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestHandleMetrics_AsyncIngestion(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+	r.Pipeline = ingest.New(ingest.DefaultConfig())
+
+	if w := postMetrics(t, r); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	drain(t, r.Pipeline)
+	if _, err := store.GetMetric(context.Background(), "test.counter"); err != nil {
+		t.Fatalf("metric not stored by the pipeline: %v", err)
+	}
+	if stats := r.Pipeline.Stats()[ingest.SignalMetrics]; stats.Processed != 1 {
+		t.Errorf("processed = %d, want 1", stats.Processed)
+	}
+
+	// A drained pipeline asks clients to come back later.
+	w := postMetrics(t, r)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("after shutdown: status %d, Retry-After %q; want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestBackpressure_QueueFull(t *testing.T) {
+	pipeline := ingest.New(ingest.Config{QueueSize: 1, Workers: 1, RetryAfter: 2 * time.Second})
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		drain(t, pipeline)
+	}()
+
+	// Occupy the single worker, then fill the single queue slot.
+	started := make(chan struct{})
+	pipeline.Submit(context.Background(), ingest.SignalMetrics, func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	pipeline.Submit(context.Background(), ingest.SignalMetrics, func(context.Context) error { return nil })
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	httpReceiver := NewHTTPReceiver(":0", store)
+	httpReceiver.Pipeline = pipeline
+	w := postMetrics(t, httpReceiver)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("HTTP: expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("HTTP: Retry-After = %q, want 2", got)
+	}
+
+	grpcReceiver := NewGRPCReceiver(":0", store)
+	grpcReceiver.Pipeline = pipeline
+	var exportReq colmetricspb.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(minimalMetricsProto(t), &exportReq); err != nil {
+		t.Fatal(err)
+	}
+	_, err := grpcReceiver.Export(context.Background(), &exportReq)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("gRPC: code = %v, want ResourceExhausted", st.Code())
+	}
+	var retryInfo *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retryInfo = ri
+		}
+	}
+	if retryInfo == nil || retryInfo.RetryDelay.AsDuration() != 2*time.Second {
+		t.Errorf("gRPC: RetryInfo = %v, want a 2s retry delay", retryInfo)
+	}
+
+	if dropped := pipeline.Stats()[ingest.SignalMetrics].Dropped; dropped != 2 {
+		t.Errorf("dropped = %d, want 2", dropped)
+	}
+}
@@ -0,0 +1,558 @@
+package receiver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// jaegerScope is the instrumentation scope recorded for spans that arrived
+// via the Jaeger Thrift HTTP API.
+const jaegerScope = "jaeger.thrift"
+
+// Jaeger tag value types (jaeger.thrift TagType).
+const (
+	jaegerTagString = 0
+	jaegerTagDouble = 1
+	jaegerTagBool   = 2
+	jaegerTagLong   = 3
+	jaegerTagBinary = 4
+)
+
+// Jaeger span reference types (jaeger.thrift SpanRefType).
+const (
+	jaegerRefChildOf     = 0
+	jaegerRefFollowsFrom = 1
+)
+
+// jaegerBatch is the subset of jaeger.thrift Batch needed for cardinality
+// analysis.
+type jaegerBatch struct {
+	process jaegerProcess
+	spans   []jaegerSpan
+}
+
+type jaegerProcess struct {
+	serviceName string
+	tags        []jaegerTag
+}
+
+type jaegerTag struct {
+	key     string
+	vType   int32
+	vStr    string
+	vDouble float64
+	vBool   bool
+	vLong   int64
+	vBinary []byte
+}
+
+type jaegerSpan struct {
+	traceIDLow    int64
+	traceIDHigh   int64
+	spanID        int64
+	parentSpanID  int64
+	operationName string
+	references    []jaegerSpanRef
+	startTime     int64 // microseconds
+	duration      int64 // microseconds
+	tags          []jaegerTag
+	logs          []jaegerLog
+}
+
+type jaegerSpanRef struct {
+	refType     int32
+	traceIDLow  int64
+	traceIDHigh int64
+	spanID      int64
+}
+
+type jaegerLog struct {
+	timestamp int64
+	fields    []jaegerTag
+}
+
+// handleJaeger handles Jaeger Thrift-over-HTTP batches (POST /api/traces),
+// as sent by Jaeger clients configured with a collector endpoint.
+func (r *HTTPReceiver) handleJaeger(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "application/x-thrift" && mediaType != "application/vnd.apache.thrift.binary" {
+		http.Error(w, fmt.Sprintf("Unsupported Content-Type %q, expected application/x-thrift", req.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+	if rejectIfBodyTooLarge(w, req) {
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+	defer req.Body.Close()
+
+	body, releaseBody, err := readAndDecompressBody(req)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	batch, err := decodeJaegerBatch(body)
+	releaseBody()
+	if err != nil {
+		log.Printf("Failed to parse Jaeger request: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if verboseLogging {
+		fmt.Printf("Received Jaeger batch: service %q, %d spans\n", batch.process.serviceName, len(batch.spans))
+	}
+
+	r.acceptTraces(req.Context(), w, jaegerToOTLP(batch))
+}
+
+// Thrift binary protocol field types.
+const (
+	thriftStop   = 0
+	thriftBool   = 2
+	thriftByte   = 3
+	thriftDouble = 4
+	thriftI16    = 6
+	thriftI32    = 8
+	thriftI64    = 10
+	thriftString = 11
+	thriftStruct = 12
+	thriftMap    = 13
+	thriftSet    = 14
+	thriftList   = 15
+)
+
+// maxThriftDepth bounds struct nesting when skipping unknown fields.
+const maxThriftDepth = 32
+
+var errThriftTruncated = errors.New("thrift: unexpected end of data")
+
+// thriftReader decodes the Thrift binary protocol. The first error sticks;
+// later reads return zero values.
+type thriftReader struct {
+	b   []byte
+	err error
+}
+
+func (t *thriftReader) next(n int) []byte {
+	if t.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(t.b) {
+		t.err = errThriftTruncated
+		return nil
+	}
+	p := t.b[:n]
+	t.b = t.b[n:]
+	return p
+}
+
+func (t *thriftReader) readByte() byte {
+	if p := t.next(1); p != nil {
+		return p[0]
+	}
+	return 0
+}
+
+func (t *thriftReader) readI16() int16 {
+	if p := t.next(2); p != nil {
+		return int16(binary.BigEndian.Uint16(p))
+	}
+	return 0
+}
+
+func (t *thriftReader) readI32() int32 {
+	if p := t.next(4); p != nil {
+		return int32(binary.BigEndian.Uint32(p))
+	}
+	return 0
+}
+
+func (t *thriftReader) readI64() int64 {
+	if p := t.next(8); p != nil {
+		return int64(binary.BigEndian.Uint64(p))
+	}
+	return 0
+}
+
+func (t *thriftReader) readDouble() float64 {
+	return math.Float64frombits(uint64(t.readI64()))
+}
+
+func (t *thriftReader) readBinary() []byte {
+	n := t.readI32()
+	return t.next(int(n))
+}
+
+func (t *thriftReader) readString() string {
+	return string(t.readBinary())
+}
+
+// readStruct calls fn for every field of a struct. fn must consume the
+// field's value, typically by calling skip for fields it does not know.
+func (t *thriftReader) readStruct(fn func(id int16, typ byte)) {
+	for t.err == nil {
+		typ := t.readByte()
+		if typ == thriftStop {
+			return
+		}
+		id := t.readI16()
+		fn(id, typ)
+	}
+}
+
+// readList calls fn for every element of a list of elemType. Lists of any
+// other element type are skipped.
+func (t *thriftReader) readList(elemType byte, fn func()) {
+	typ := t.readByte()
+	n := int(t.readI32())
+	if n < 0 || n > len(t.b) {
+		// Every element takes at least one byte.
+		t.fail(errThriftTruncated)
+		return
+	}
+	for i := 0; i < n && t.err == nil; i++ {
+		if typ == elemType {
+			fn()
+		} else {
+			t.skipDepth(typ, 1)
+		}
+	}
+}
+
+func (t *thriftReader) fail(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *thriftReader) skip(typ byte) {
+	t.skipDepth(typ, 0)
+}
+
+func (t *thriftReader) skipDepth(typ byte, depth int) {
+	if depth > maxThriftDepth {
+		t.fail(errors.New("thrift: nesting too deep"))
+		return
+	}
+	switch typ {
+	case thriftBool, thriftByte:
+		t.next(1)
+	case thriftI16:
+		t.next(2)
+	case thriftI32:
+		t.next(4)
+	case thriftDouble, thriftI64:
+		t.next(8)
+	case thriftString:
+		t.readBinary()
+	case thriftStruct:
+		t.readStruct(func(_ int16, typ byte) { t.skipDepth(typ, depth+1) })
+	case thriftMap:
+		keyType, valueType := t.readByte(), t.readByte()
+		n := int(t.readI32())
+		if n < 0 || n > len(t.b) {
+			t.fail(errThriftTruncated)
+			return
+		}
+		for i := 0; i < n && t.err == nil; i++ {
+			t.skipDepth(keyType, depth+1)
+			t.skipDepth(valueType, depth+1)
+		}
+	case thriftSet, thriftList:
+		elemType := t.readByte()
+		n := int(t.readI32())
+		if n < 0 || n > len(t.b) {
+			t.fail(errThriftTruncated)
+			return
+		}
+		for i := 0; i < n && t.err == nil; i++ {
+			t.skipDepth(elemType, depth+1)
+		}
+	default:
+		t.fail(fmt.Errorf("thrift: unknown field type %d", typ))
+	}
+}
+
+// decodeJaegerBatch decodes a Thrift binary encoded jaeger.thrift Batch.
+// Unknown fields are skipped so newer clients remain compatible.
+func decodeJaegerBatch(b []byte) (*jaegerBatch, error) {
+	t := &thriftReader{b: b}
+	batch := &jaegerBatch{}
+	t.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			batch.process = t.readJaegerProcess()
+		case id == 2 && typ == thriftList:
+			t.readList(thriftStruct, func() {
+				batch.spans = append(batch.spans, t.readJaegerSpan())
+			})
+		default:
+			t.skip(typ)
+		}
+	})
+	if t.err != nil {
+		return nil, t.err
+	}
+	return batch, nil
+}
+
+func (t *thriftReader) readJaegerProcess() jaegerProcess {
+	var p jaegerProcess
+	t.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftString:
+			p.serviceName = t.readString()
+		case id == 2 && typ == thriftList:
+			p.tags = t.readJaegerTags()
+		default:
+			t.skip(typ)
+		}
+	})
+	return p
+}
+
+func (t *thriftReader) readJaegerTags() []jaegerTag {
+	var tags []jaegerTag
+	t.readList(thriftStruct, func() {
+		var tag jaegerTag
+		t.readStruct(func(id int16, typ byte) {
+			switch {
+			case id == 1 && typ == thriftString:
+				tag.key = t.readString()
+			case id == 2 && typ == thriftI32:
+				tag.vType = t.readI32()
+			case id == 3 && typ == thriftString:
+				tag.vStr = t.readString()
+			case id == 4 && typ == thriftDouble:
+				tag.vDouble = t.readDouble()
+			case id == 5 && typ == thriftBool:
+				tag.vBool = t.readByte() != 0
+			case id == 6 && typ == thriftI64:
+				tag.vLong = t.readI64()
+			case id == 7 && typ == thriftString:
+				tag.vBinary = append([]byte(nil), t.readBinary()...)
+			default:
+				t.skip(typ)
+			}
+		})
+		tags = append(tags, tag)
+	})
+	return tags
+}
+
+func (t *thriftReader) readJaegerSpan() jaegerSpan {
+	var s jaegerSpan
+	t.readStruct(func(id int16, typ byte) {
+		switch {
+		case id == 1 && typ == thriftI64:
+			s.traceIDLow = t.readI64()
+		case id == 2 && typ == thriftI64:
+			s.traceIDHigh = t.readI64()
+		case id == 3 && typ == thriftI64:
+			s.spanID = t.readI64()
+		case id == 4 && typ == thriftI64:
+			s.parentSpanID = t.readI64()
+		case id == 5 && typ == thriftString:
+			s.operationName = t.readString()
+		case id == 6 && typ == thriftList:
+			t.readList(thriftStruct, func() {
+				var ref jaegerSpanRef
+				t.readStruct(func(id int16, typ byte) {
+					switch {
+					case id == 1 && typ == thriftI32:
+						ref.refType = t.readI32()
+					case id == 2 && typ == thriftI64:
+						ref.traceIDLow = t.readI64()
+					case id == 3 && typ == thriftI64:
+						ref.traceIDHigh = t.readI64()
+					case id == 4 && typ == thriftI64:
+						ref.spanID = t.readI64()
+					default:
+						t.skip(typ)
+					}
+				})
+				s.references = append(s.references, ref)
+			})
+		case id == 8 && typ == thriftI64:
+			s.startTime = t.readI64()
+		case id == 9 && typ == thriftI64:
+			s.duration = t.readI64()
+		case id == 10 && typ == thriftList:
+			s.tags = t.readJaegerTags()
+		case id == 11 && typ == thriftList:
+			t.readList(thriftStruct, func() {
+				var l jaegerLog
+				t.readStruct(func(id int16, typ byte) {
+					switch {
+					case id == 1 && typ == thriftI64:
+						l.timestamp = t.readI64()
+					case id == 2 && typ == thriftList:
+						l.fields = t.readJaegerTags()
+					default:
+						t.skip(typ)
+					}
+				})
+				s.logs = append(s.logs, l)
+			})
+		default:
+			t.skip(typ)
+		}
+	})
+	return s
+}
+
+// jaegerToOTLP converts a Jaeger batch into an OTLP export request with a
+// single resource for the batch's process. The mapping follows the Jaeger
+// receiver in the OTel Collector: span.kind and error tags become the span
+// kind and status, logs become events named by their "event" field and
+// references other than the parent become links.
+func jaegerToOTLP(batch *jaegerBatch) *coltracepb.ExportTraceServiceRequest {
+	var resAttrs []*commonpb.KeyValue
+	if batch.process.serviceName != "" {
+		resAttrs = append(resAttrs, stringKeyValue("service.name", batch.process.serviceName))
+	}
+	for _, tag := range batch.process.tags {
+		resAttrs = append(resAttrs, jaegerKeyValue(tag))
+	}
+
+	ss := &tracepb.ScopeSpans{Scope: &commonpb.InstrumentationScope{Name: jaegerScope}}
+	for _, js := range batch.spans {
+		ss.Spans = append(ss.Spans, jaegerSpanToOTLP(js))
+	}
+
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource:   &resourcepb.Resource{Attributes: resAttrs},
+			ScopeSpans: []*tracepb.ScopeSpans{ss},
+		}},
+	}
+}
+
+func jaegerSpanToOTLP(js jaegerSpan) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId:           jaegerTraceID(js.traceIDHigh, js.traceIDLow),
+		SpanId:            jaegerSpanID(js.spanID),
+		Name:              js.operationName,
+		Kind:              tracepb.Span_SPAN_KIND_INTERNAL,
+		StartTimeUnixNano: uint64(max(js.startTime, 0)) * 1e3,
+		EndTimeUnixNano:   uint64(max(js.startTime+js.duration, 0)) * 1e3,
+	}
+	if js.parentSpanID != 0 {
+		span.ParentSpanId = jaegerSpanID(js.parentSpanID)
+	}
+
+	for _, tag := range js.tags {
+		switch tag.key {
+		case "span.kind":
+			span.Kind = jaegerKind(tag.vStr)
+		case "error":
+			if tag.vBool || tag.vStr == "true" {
+				span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR}
+			}
+		default:
+			span.Attributes = append(span.Attributes, jaegerKeyValue(tag))
+		}
+	}
+
+	for _, ref := range js.references {
+		sameTrace := ref.traceIDLow == js.traceIDLow && ref.traceIDHigh == js.traceIDHigh
+		if ref.refType == jaegerRefChildOf && sameTrace {
+			// Older clients only set the parent through a CHILD_OF reference.
+			if span.ParentSpanId == nil {
+				span.ParentSpanId = jaegerSpanID(ref.spanID)
+				continue
+			}
+			if ref.spanID == js.parentSpanID {
+				continue
+			}
+		}
+		link := &tracepb.Span_Link{
+			TraceId: jaegerTraceID(ref.traceIDHigh, ref.traceIDLow),
+			SpanId:  jaegerSpanID(ref.spanID),
+		}
+		if ref.refType == jaegerRefFollowsFrom {
+			link.Attributes = []*commonpb.KeyValue{stringKeyValue("opentracing.ref_type", "follows_from")}
+		}
+		span.Links = append(span.Links, link)
+	}
+
+	for _, l := range js.logs {
+		event := &tracepb.Span_Event{TimeUnixNano: uint64(max(l.timestamp, 0)) * 1e3}
+		for _, field := range l.fields {
+			if field.key == "event" && field.vType == jaegerTagString {
+				event.Name = field.vStr
+				continue
+			}
+			event.Attributes = append(event.Attributes, jaegerKeyValue(field))
+		}
+		span.Events = append(span.Events, event)
+	}
+	return span
+}
+
+func jaegerKind(kind string) tracepb.Span_SpanKind {
+	switch strings.ToLower(kind) {
+	case "client":
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case "server":
+		return tracepb.Span_SPAN_KIND_SERVER
+	case "producer":
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	default:
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	}
+}
+
+// jaegerKeyValue converts a Jaeger tag into an OTLP attribute, keeping its
+// value type.
+func jaegerKeyValue(tag jaegerTag) *commonpb.KeyValue {
+	value := &commonpb.AnyValue{}
+	switch tag.vType {
+	case jaegerTagDouble:
+		value.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: tag.vDouble}
+	case jaegerTagBool:
+		value.Value = &commonpb.AnyValue_BoolValue{BoolValue: tag.vBool}
+	case jaegerTagLong:
+		value.Value = &commonpb.AnyValue_IntValue{IntValue: tag.vLong}
+	case jaegerTagBinary:
+		value.Value = &commonpb.AnyValue_BytesValue{BytesValue: tag.vBinary}
+	default:
+		value.Value = &commonpb.AnyValue_StringValue{StringValue: tag.vStr}
+	}
+	return &commonpb.KeyValue{Key: tag.key, Value: value}
+}
+
+func jaegerTraceID(high, low int64) []byte {
+	id := binary.BigEndian.AppendUint64(make([]byte, 0, 16), uint64(high))
+	return binary.BigEndian.AppendUint64(id, uint64(low))
+}
+
+func jaegerSpanID(id int64) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), uint64(id))
+}
@@ -0,0 +1,177 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+// thriftWriter encodes just enough of the Thrift binary protocol to build
+// jaeger.thrift batches for tests.
+type thriftWriter struct {
+	bytes.Buffer
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id)
+}
+
+func (w *thriftWriter) stop() { w.WriteByte(thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(s)))
+	w.WriteString(s)
+}
+
+func (w *thriftWriter) list(id int16, elemType byte, n int) {
+	w.field(thriftList, id)
+	w.WriteByte(elemType)
+	binary.Write(w, binary.BigEndian, int32(n))
+}
+
+func (w *thriftWriter) stringTag(key, value string) {
+	w.str(1, key)
+	w.i32(2, jaegerTagString)
+	w.str(3, value)
+	w.stop()
+}
+
+func (w *thriftWriter) longTag(key string, value int64) {
+	w.str(1, key)
+	w.i32(2, jaegerTagLong)
+	w.i64(6, value)
+	w.stop()
+}
+
+// testJaegerBatch encodes a batch from service "billing" with one server
+// span carrying tags, a log and an unknown field.
+func testJaegerBatch() []byte {
+	w := &thriftWriter{}
+
+	w.field(thriftStruct, 1) // process
+	w.str(1, "billing")
+	w.list(2, thriftStruct, 1)
+	w.stringTag("hostname", "billing-7f9c")
+	w.stop()
+
+	w.list(2, thriftStruct, 1) // spans
+	w.i64(1, 0x0102030405060708)
+	w.i64(2, 0)
+	w.i64(3, 0x1111)
+	w.i64(4, 0x2222)
+	w.str(5, "POST /invoices")
+	w.i32(7, 1)
+	w.i64(8, 1700000000000000)
+	w.i64(9, 2500)
+	w.list(10, thriftStruct, 3)
+	w.stringTag("span.kind", "server")
+	w.stringTag("http.method", "POST")
+	w.longTag("http.status_code", 201)
+	w.list(11, thriftStruct, 1)
+	w.i64(1, 1700000000000100)
+	w.list(2, thriftStruct, 2)
+	w.stringTag("event", "invoice.created")
+	w.stringTag("invoice.currency", "EUR")
+	w.stop()
+	w.str(99, "field from a newer client")
+	w.stop()
+
+	w.i64(3, 7) // seqNo
+	w.stop()
+	return w.Bytes()
+}
+
+func postJaeger(t *testing.T, r *HTTPReceiver, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	r.handleJaeger(rec, req)
+	return rec
+}
+
+func TestJaeger_StoresThriftBatch(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	rec := postJaeger(t, r, "application/x-thrift", testJaegerBatch())
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	span, err := store.GetSpan(context.Background(), "POST /invoices")
+	if err != nil {
+		t.Fatalf("GetSpan: %v", err)
+	}
+	if span.KindName != "Server" {
+		t.Errorf("kind = %q, want Server", span.KindName)
+	}
+	for _, key := range []string{"http.method", "http.status_code"} {
+		if _, ok := span.AttributeKeys[key]; !ok {
+			t.Errorf("expected attribute key %q, got %v", key, span.AttributeKeys)
+		}
+	}
+	if _, ok := span.AttributeKeys["span.kind"]; ok {
+		t.Error("span.kind tag should become the span kind, not an attribute")
+	}
+	if len(span.EventNames) != 1 || span.EventNames[0] != "invoice.created" {
+		t.Errorf("event names = %v, want [invoice.created]", span.EventNames)
+	}
+	if _, ok := span.EventAttributeKeys["invoice.created"]["invoice.currency"]; !ok {
+		t.Errorf("expected event attribute invoice.currency, got %v", span.EventAttributeKeys)
+	}
+	if span.Services["billing"] != 1 {
+		t.Errorf("services = %v, want billing", span.Services)
+	}
+}
+
+func TestJaeger_RejectsInvalidRequests(t *testing.T) {
+	r := NewHTTPReceiver(":0", storage.NewStorage(storage.DefaultConfig()))
+
+	if rec := postJaeger(t, r, "application/json", testJaegerBatch()); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("JSON content type: expected 415, got %d", rec.Code)
+	}
+	batch := testJaegerBatch()
+	if rec := postJaeger(t, r, "application/x-thrift", batch[:len(batch)/2]); rec.Code != http.StatusBadRequest {
+		t.Errorf("truncated batch: expected 400, got %d", rec.Code)
+	}
+	// A list claiming more elements than the body could hold.
+	huge := []byte{thriftList, 0, 2, thriftStruct, 0x7f, 0xff, 0xff, 0xff}
+	if rec := postJaeger(t, r, "application/x-thrift", huge); rec.Code != http.StatusBadRequest {
+		t.Errorf("oversized list: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestJaegerSpanToOTLP_References(t *testing.T) {
+	span := jaegerSpanToOTLP(jaegerSpan{
+		traceIDLow: 1,
+		spanID:     3,
+		references: []jaegerSpanRef{
+			{refType: jaegerRefChildOf, traceIDLow: 1, spanID: 2},
+			{refType: jaegerRefFollowsFrom, traceIDLow: 9, spanID: 8},
+		},
+	})
+	if !bytes.Equal(span.ParentSpanId, jaegerSpanID(2)) {
+		t.Errorf("parent = %x, want CHILD_OF reference", span.ParentSpanId)
+	}
+	if len(span.Links) != 1 || !bytes.Equal(span.Links[0].SpanId, jaegerSpanID(8)) {
+		t.Errorf("links = %v, want the FOLLOWS_FROM reference", span.Links)
+	}
+}
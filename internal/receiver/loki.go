@@ -0,0 +1,356 @@
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// lokiScope is the instrumentation scope recorded for logs that arrived via
+// the Loki push API.
+const lokiScope = "loki.push"
+
+// lokiLevelKeys are the labels and structured metadata keys Loki uses for a
+// line's level, in order of preference.
+var lokiLevelKeys = []string{"detected_level", "level", "severity", "lvl"}
+
+// lokiPushStream is one stream of a logproto.PushRequest.
+type lokiPushStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+type lokiEntry struct {
+	timestamp          uint64 // unix nanoseconds
+	line               string
+	structuredMetadata []promLabel
+}
+
+// handleLokiPush handles Loki push requests (POST /loki/api/v1/push) as sent
+// by Promtail, Grafana Agent and Alloy: snappy-compressed protobuf, or JSON.
+func (r *HTTPReceiver) handleLokiPush(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rejectIfBodyTooLarge(w, req) {
+		return
+	}
+	isJSON := isJSONContentType(req.Header.Get("Content-Type"))
+	if !isJSON && req.Header.Get("Content-Encoding") == "" {
+		// Protobuf pushes are snappy-compressed without announcing it.
+		req.Header.Set("Content-Encoding", "snappy")
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+	defer req.Body.Close()
+
+	ctx := req.Context()
+
+	body, releaseBody, err := readAndDecompressBody(req)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var streams []lokiPushStream
+	if isJSON {
+		streams, err = decodeLokiJSON(sanitizeUTF8(body))
+	} else {
+		streams, err = decodeLokiProto(body)
+	}
+	releaseBody()
+	if err != nil {
+		log.Printf("Failed to parse Loki push request: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if verboseLogging {
+		fmt.Printf("Received Loki push request: %d streams\n", len(streams))
+	}
+
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	exportReq := lokiToOTLP(streams)
+	lokiStreams := lokiStreamLabels(streams, a.logs.ServiceName)
+	err = r.Pipeline.Submit(ctx, ingest.SignalLogs, func(ctx context.Context) error {
+		if ls, ok := a.store.(storage.LokiStreamStore); ok {
+			if err := ls.StoreLokiStreams(ctx, lokiStreams); err != nil {
+				return fmt.Errorf("store loki streams: %w", err)
+			}
+		}
+		return storeLogs(ctx, a, sims, exportReq)
+	})
+	if err != nil {
+		r.writeIngestError(w, ingest.SignalLogs, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	if r.OnActivity != nil {
+		r.OnActivity()
+	}
+}
+
+// decodeLokiProto decodes a logproto.PushRequest message.
+func decodeLokiProto(b []byte) ([]lokiPushStream, error) {
+	var streams []lokiPushStream
+	err := forEachField(b, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		s, err := decodeLokiProtoStream(f.bytes)
+		if err != nil {
+			return fmt.Errorf("stream: %w", err)
+		}
+		streams = append(streams, s)
+		return nil
+	})
+	return streams, err
+}
+
+func decodeLokiProtoStream(b []byte) (lokiPushStream, error) {
+	var s lokiPushStream
+	err := forEachField(b, func(f protoField) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			labels, err := parseLokiLabels(string(f.bytes))
+			if err != nil {
+				return err
+			}
+			s.labels = labels
+		case 2:
+			e, err := decodeLokiProtoEntry(f.bytes)
+			if err != nil {
+				return fmt.Errorf("entry: %w", err)
+			}
+			s.entries = append(s.entries, e)
+		}
+		return nil
+	})
+	return s, err
+}
+
+func decodeLokiProtoEntry(b []byte) (lokiEntry, error) {
+	var e lokiEntry
+	err := forEachField(b, func(f protoField) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			// google.protobuf.Timestamp
+			var seconds, nanos uint64
+			err := forEachField(f.bytes, func(tf protoField) error {
+				switch {
+				case tf.num == 1 && tf.typ == protowire.VarintType:
+					seconds = tf.scalar
+				case tf.num == 2 && tf.typ == protowire.VarintType:
+					nanos = tf.scalar
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("timestamp: %w", err)
+			}
+			e.timestamp = seconds*1e9 + nanos
+		case 2:
+			e.line = string(f.bytes)
+		case 3:
+			l, err := decodePromLabel(f.bytes)
+			if err != nil {
+				return fmt.Errorf("structured metadata: %w", err)
+			}
+			e.structuredMetadata = append(e.structuredMetadata, l)
+		}
+		return nil
+	})
+	return e, err
+}
+
+// lokiJSONPush is the JSON push format:
+//
+//	{"streams": [{"stream": {"app": "api"}, "values": [["<unix ns>", "<line>", {"trace_id": "..."}]]}]}
+type lokiJSONPush struct {
+	Streams []struct {
+		Stream map[string]string   `json:"stream"`
+		Values [][]json.RawMessage `json:"values"`
+	} `json:"streams"`
+}
+
+func decodeLokiJSON(b []byte) ([]lokiPushStream, error) {
+	var push lokiJSONPush
+	if err := json.Unmarshal(b, &push); err != nil {
+		return nil, err
+	}
+	streams := make([]lokiPushStream, 0, len(push.Streams))
+	for _, js := range push.Streams {
+		s := lokiPushStream{labels: js.Stream}
+		for _, v := range js.Values {
+			if len(v) < 2 {
+				return nil, fmt.Errorf("entry must be [timestamp, line], got %d elements", len(v))
+			}
+			var ts, line string
+			if err := json.Unmarshal(v[0], &ts); err != nil {
+				return nil, fmt.Errorf("timestamp: %w", err)
+			}
+			nanos, err := strconv.ParseUint(ts, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("timestamp %q: %w", ts, err)
+			}
+			if err := json.Unmarshal(v[1], &line); err != nil {
+				return nil, fmt.Errorf("line: %w", err)
+			}
+			e := lokiEntry{timestamp: nanos, line: line}
+			if len(v) > 2 {
+				var metadata map[string]string
+				if err := json.Unmarshal(v[2], &metadata); err != nil {
+					return nil, fmt.Errorf("structured metadata: %w", err)
+				}
+				for name, value := range metadata {
+					e.structuredMetadata = append(e.structuredMetadata, promLabel{name: name, value: value})
+				}
+			}
+			s.entries = append(s.entries, e)
+		}
+		streams = append(streams, s)
+	}
+	return streams, nil
+}
+
+// parseLokiLabels parses a stream selector such as {app="api", env="prod"}.
+// Values are Go-quoted strings, as written by Loki clients.
+func parseLokiLabels(s string) (map[string]string, error) {
+	rest := strings.TrimSpace(s)
+	if !strings.HasPrefix(rest, "{") || !strings.HasSuffix(rest, "}") {
+		return nil, fmt.Errorf("invalid stream labels %q", s)
+	}
+	rest = strings.TrimSpace(rest[1 : len(rest)-1])
+
+	labels := make(map[string]string)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid stream labels %q", s)
+		}
+		name := strings.TrimSpace(rest[:eq])
+		rest = strings.TrimSpace(rest[eq+1:])
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for label %q in %q", name, s)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for label %q in %q", name, s)
+		}
+		labels[name] = value
+
+		rest = strings.TrimSpace(rest[len(quoted):])
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("invalid stream labels %q", s)
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+	return labels, nil
+}
+
+// lokiToOTLP converts Loki streams into an OTLP export request, one
+// resource per stream. Stream labels become resource attributes, so pod log
+// enrichment resolves the service from them; structured metadata becomes
+// log attributes and each line the log body. A level label or metadata
+// entry becomes the severity text.
+func lokiToOTLP(streams []lokiPushStream) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	for _, s := range streams {
+		resAttrs := make([]*commonpb.KeyValue, 0, len(s.labels))
+		for _, name := range slices.Sorted(maps.Keys(s.labels)) {
+			resAttrs = append(resAttrs, stringKeyValue(name, s.labels[name]))
+		}
+		streamLevel := lokiLevel(s.labels, nil)
+
+		sl := &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: lokiScope}}
+		for _, e := range s.entries {
+			record := &logspb.LogRecord{
+				TimeUnixNano: e.timestamp,
+				Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: e.line}},
+				SeverityText: streamLevel,
+			}
+			for _, l := range e.structuredMetadata {
+				record.Attributes = append(record.Attributes, stringKeyValue(l.name, l.value))
+			}
+			if level := lokiLevel(nil, e.structuredMetadata); level != "" {
+				record.SeverityText = level
+			}
+			sl.LogRecords = append(sl.LogRecords, record)
+		}
+
+		req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+			Resource:  &resourcepb.Resource{Attributes: resAttrs},
+			ScopeLogs: []*logspb.ScopeLogs{sl},
+		})
+	}
+	return req
+}
+
+// lokiLevel returns the upper-cased level found in labels or structured
+// metadata, or "" when there is none.
+func lokiLevel(labels map[string]string, metadata []promLabel) string {
+	for _, key := range lokiLevelKeys {
+		if v := labels[key]; v != "" {
+			return strings.ToUpper(v)
+		}
+		for _, l := range metadata {
+			if l.name == key && l.value != "" {
+				return strings.ToUpper(l.value)
+			}
+		}
+	}
+	return ""
+}
+
+// lokiStreamLabels summarizes the pushed streams for the stream label view.
+// serviceName resolves a stream's labels to a service the same way the logs
+// analyzer does for the stored logs, so both views agree.
+func lokiStreamLabels(streams []lokiPushStream, serviceName func(map[string]string) string) []models.LokiStream {
+	out := make([]models.LokiStream, 0, len(streams))
+	for _, s := range streams {
+		service := serviceName(s.labels)
+		out = append(out, models.LokiStream{
+			Labels:  s.labels,
+			Service: service,
+			Entries: int64(len(s.entries)),
+		})
+	}
+	return out
+}
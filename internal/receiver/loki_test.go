@@ -0,0 +1,247 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+const testLokiJSON = `{
+  "streams": [
+    {
+      "stream": {"app": "checkout", "namespace": "prod", "pod": "checkout-7d9f-abcde"},
+      "values": [
+        ["1700000000000000000", "order 1001 placed", {"trace_id": "abc123"}],
+        ["1700000001000000000", "order 1002 placed"]
+      ]
+    },
+    {
+      "stream": {"app": "checkout", "namespace": "prod", "pod": "checkout-7d9f-fghij", "level": "error"},
+      "values": [["1700000002000000000", "payment declined"]]
+    }
+  ]
+}`
+
+// encodeTestLokiEntry encodes a logproto.EntryAdapter with optional
+// structured metadata name/value pairs.
+func encodeTestLokiEntry(seconds uint64, line string, metadata ...string) []byte {
+	var ts []byte
+	ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+	ts = protowire.AppendVarint(ts, seconds)
+
+	var e []byte
+	e = protowire.AppendTag(e, 1, protowire.BytesType)
+	e = protowire.AppendBytes(e, ts)
+	e = protowire.AppendTag(e, 2, protowire.BytesType)
+	e = protowire.AppendString(e, line)
+	for i := 0; i+1 < len(metadata); i += 2 {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendString(l, metadata[i])
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, metadata[i+1])
+		e = protowire.AppendTag(e, 3, protowire.BytesType)
+		e = protowire.AppendBytes(e, l)
+	}
+	return e
+}
+
+// testLokiPushRequest returns a snappy-compressed PushRequest with one
+// stream and two entries.
+func testLokiPushRequest() []byte {
+	var s []byte
+	s = protowire.AppendTag(s, 1, protowire.BytesType)
+	s = protowire.AppendString(s, `{app="checkout", namespace="prod", pod="checkout-7d9f-abcde"}`)
+	for _, e := range [][]byte{
+		encodeTestLokiEntry(1700000000, "order 1001 placed", "trace_id", "abc123"),
+		encodeTestLokiEntry(1700000001, "order 1002 placed", "level", "warn"),
+	} {
+		s = protowire.AppendTag(s, 2, protowire.BytesType)
+		s = protowire.AppendBytes(s, e)
+	}
+
+	var push []byte
+	push = protowire.AppendTag(push, 1, protowire.BytesType)
+	push = protowire.AppendBytes(push, s)
+	return snappy.Encode(nil, push)
+}
+
+func postLoki(t *testing.T, r *HTTPReceiver, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/push", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	r.handleLokiPush(rec, req)
+	return rec
+}
+
+func TestLoki_StoresProtobufPush(t *testing.T) {
+	r, store := newTestReceiverWithEnrichment(t)
+
+	rec := postLoki(t, r, "application/x-protobuf", testLokiPushRequest())
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	logs, err := store.ListLogs(ctx, "")
+	if err != nil {
+		t.Fatalf("ListLogs: %v", err)
+	}
+	var found bool
+	for _, lm := range logs {
+		if _, ok := lm.Services["checkout"]; !ok {
+			continue
+		}
+		found = true
+		if _, ok := lm.ResourceKeys["pod"]; !ok {
+			t.Errorf("expected stream label pod as resource key, got %v", lm.ResourceKeys)
+		}
+		if lm.Severity == "WARN" {
+			if _, ok := lm.AttributeKeys["level"]; !ok {
+				t.Errorf("expected structured metadata as attributes, got %v", lm.AttributeKeys)
+			}
+		}
+		if len(lm.BodyTemplates) == 0 {
+			t.Error("expected lines to be templated")
+		}
+	}
+	if !found {
+		t.Fatal("expected logs resolved to service checkout via stream labels")
+	}
+
+	streams, err := store.(storage.LokiStreamStore).GetLokiStreams(ctx)
+	if err != nil {
+		t.Fatalf("GetLokiStreams: %v", err)
+	}
+	if streams.TotalStreams != 1 || streams.TotalEntries != 2 {
+		t.Errorf("streams = %d, entries = %d, want 1 and 2", streams.TotalStreams, streams.TotalEntries)
+	}
+}
+
+func TestLoki_StoresJSONPush(t *testing.T) {
+	r, store := newTestReceiverWithEnrichment(t)
+
+	rec := postLoki(t, r, "application/json", []byte(testLokiJSON))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	errLog, err := store.GetLog(ctx, "ERROR")
+	if err != nil {
+		t.Fatalf("GetLog(ERROR): %v", err)
+	}
+	if _, ok := errLog.Services["checkout"]; !ok {
+		t.Errorf("services = %v, want checkout", errLog.Services)
+	}
+
+	streams, err := store.(storage.LokiStreamStore).GetLokiStreams(ctx)
+	if err != nil {
+		t.Fatalf("GetLokiStreams: %v", err)
+	}
+	if streams.TotalStreams != 2 || streams.TotalEntries != 3 {
+		t.Errorf("streams = %d, entries = %d, want 2 and 3", streams.TotalStreams, streams.TotalEntries)
+	}
+	if len(streams.Labels) == 0 || streams.Labels[0].Key != "pod" {
+		t.Errorf("expected pod to be the highest-cardinality label, got %+v", streams.Labels)
+	}
+	if len(streams.Services) != 1 || streams.Services[0].Service != "checkout" || streams.Services[0].Streams != 2 {
+		t.Errorf("services = %+v, want checkout with 2 streams", streams.Services)
+	}
+}
+
+func TestLoki_StreamServiceMatchesLogsWithoutEnrichment(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	rec := postLoki(t, r, "application/json", []byte(testLokiJSON))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	logs, err := store.ListLogs(ctx, "")
+	if err != nil {
+		t.Fatalf("ListLogs: %v", err)
+	}
+	logServices := make(map[string]bool)
+	for _, lm := range logs {
+		for svc := range lm.Services {
+			logServices[svc] = true
+		}
+	}
+
+	streams, err := store.(storage.LokiStreamStore).GetLokiStreams(ctx)
+	if err != nil {
+		t.Fatalf("GetLokiStreams: %v", err)
+	}
+	if len(streams.Services) == 0 {
+		t.Fatal("expected stream services")
+	}
+	for _, s := range streams.Services {
+		if s.Service == "checkout" {
+			t.Errorf("stream service resolved from pod log labels with enrichment off")
+		}
+		if !logServices[s.Service] {
+			t.Errorf("stream service %q not among log services %v", s.Service, logServices)
+		}
+	}
+}
+
+func TestLoki_RejectsMalformedPush(t *testing.T) {
+	r := NewHTTPReceiver(":0", storage.NewStorage(storage.DefaultConfig()))
+
+	t.Run("not snappy", func(t *testing.T) {
+		rec := postLoki(t, r, "application/x-protobuf", []byte("plain bytes"))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+	t.Run("bad json", func(t *testing.T) {
+		rec := postLoki(t, r, "application/json", []byte(`{"streams": [{"values": [["not-a-number", "line"]]}]}`))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+	t.Run("method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/push", nil)
+		rec := httptest.NewRecorder()
+		r.handleLokiPush(rec, req)
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", rec.Code)
+		}
+	})
+}
+
+func TestParseLokiLabels(t *testing.T) {
+	got, err := parseLokiLabels(`{app="api", msg="say \"hi\", bye", empty=""}`)
+	if err != nil {
+		t.Fatalf("parseLokiLabels: %v", err)
+	}
+	want := map[string]string{"app": "api", "msg": `say "hi", bye`, "empty": ""}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("label %q = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got, err := parseLokiLabels("{}"); err != nil || len(got) != 0 {
+		t.Errorf("parseLokiLabels({}) = %v, %v", got, err)
+	}
+	for _, bad := range []string{`app="api"`, `{app=api}`, `{app="api" env="x"}`, `{="x"}`} {
+		if _, err := parseLokiLabels(bad); err == nil {
+			t.Errorf("parseLokiLabels(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package receiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// podLogScope is the instrumentation scope recorded for tailed container
+// logs.
+const podLogScope = "podlogs.tail"
+
+const (
+	// maxPodLogLine caps a single (merged) log line.
+	maxPodLogLine = 256 << 10
+	// maxPodLogReadPerPoll caps how much of one file is read per poll so a
+	// large backlog is worked off over several polls.
+	maxPodLogReadPerPoll = 16 << 20
+	// maxPodLogBatch caps how many records are analyzed together.
+	maxPodLogBatch = 1000
+)
+
+// PodLogConfig configures the container log file tailer.
+type PodLogConfig struct {
+	// Include lists glob patterns of files to tail.
+	Include []string
+	// PollInterval is how often the globs are re-evaluated and files read.
+	PollInterval time.Duration
+	// CheckpointFile persists read positions across restarts; empty
+	// disables checkpoints.
+	CheckpointFile string
+	// StartAtBeginning reads files found at startup (and without a
+	// checkpoint) from the start instead of only following new lines.
+	// Files that appear later are always read from the start.
+	StartAtBeginning bool
+}
+
+// DefaultPodLogConfig returns the kubelet's log layout, polled every second.
+func DefaultPodLogConfig() PodLogConfig {
+	return PodLogConfig{
+		Include:      []string{"/var/log/pods/*/*/*.log"},
+		PollInterval: time.Second,
+	}
+}
+
+// PodLogReceiver tails container log files in CRI or Docker JSON format
+// and analyzes them as pod logs. Namespace, pod and container come from the
+// kubelet path layout; service names are resolved with the pod log
+// enrichment labels whether or not POD_LOG_ENRICHMENT is set.
+type PodLogReceiver struct {
+	cfg         PodLogConfig
+	analyzers   *analyzers
+	OnActivity  func()             // called after lines are accepted
+	Pipeline    *ingest.Pipeline   // optional; analyze and store asynchronously
+	Simulations *simulate.Registry // optional; shadow-run candidate rules
+
+	files       map[string]*podLogFile
+	checkpoints map[string]fileCheckpoint // loaded at startup, consumed on open
+	scanned     bool                      // first poll done
+	unsent      []*logspb.ResourceLogs    // read but rejected, retried first
+}
+
+// podLogFile is a tailed file plus its partial-line merge state.
+type podLogFile struct {
+	*tailedFile
+	resource []*commonpb.KeyValue
+
+	// pending collects CRI "P" or unterminated Docker fragments;
+	// pendingStart is the offset of the first one.
+	pending      *podLogEntry
+	pendingStart int64
+}
+
+// podLogEntry is one parsed container log line.
+type podLogEntry struct {
+	time    time.Time
+	stream  string
+	partial bool
+	message string
+}
+
+// NewPodLogReceiver creates a tailer feeding store. Checkpoints are loaded
+// here so a corrupt checkpoint file fails startup.
+func NewPodLogReceiver(cfg PodLogConfig, store storage.Storage) (*PodLogReceiver, error) {
+	def := DefaultPodLogConfig()
+	if len(cfg.Include) == 0 {
+		cfg.Include = def.Include
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = def.PollInterval
+	}
+	for _, pattern := range cfg.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("include pattern %q: %w", pattern, err)
+		}
+	}
+
+	checkpoints := map[string]fileCheckpoint{}
+	if cfg.CheckpointFile != "" {
+		var err error
+		if checkpoints, err = loadCheckpoints(cfg.CheckpointFile); err != nil {
+			return nil, fmt.Errorf("load checkpoints: %w", err)
+		}
+	}
+
+	a := newAnalyzerSets(store).def
+	a.logs.SetPodLogEnrichment(true, store.PodLogServiceLabels())
+
+	return &PodLogReceiver{
+		cfg:         cfg,
+		analyzers:   a,
+		files:       make(map[string]*podLogFile),
+		checkpoints: checkpoints,
+	}, nil
+}
+
+// Run polls until ctx is cancelled, then closes all files. Checkpoints are
+// saved after every poll whose lines were accepted.
+func (r *PodLogReceiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Pod log tailing: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			for _, f := range r.files {
+				f.close()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll re-evaluates the include globs, follows rotations and reads new
+// lines from every file.
+func (r *PodLogReceiver) poll(ctx context.Context) error {
+	// The file offsets are already past lines that a previous poll could
+	// not submit, so those are retried before reading on.
+	if len(r.unsent) > 0 {
+		rest, err := r.submit(ctx, r.unsent)
+		r.unsent = rest
+		if err != nil {
+			return err
+		}
+	}
+
+	var paths []string
+	for _, pattern := range r.cfg.Include {
+		matches, _ := filepath.Glob(pattern) // patterns were validated up front
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	var batch []*logspb.ResourceLogs
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // removed between glob and stat
+		}
+
+		f := r.files[path]
+		if f != nil && !os.SameFile(f.info, info) {
+			// Rotated: finish the old file, then start the new one from
+			// the beginning.
+			batch = r.read(f, batch)
+			r.flushPending(f, &batch)
+			f.close()
+			delete(r.files, path)
+			f = nil
+		}
+		if f == nil {
+			if f, err = r.open(path, info); err != nil {
+				log.Printf("Pod log tailing: open %s: %v", path, err)
+				continue
+			}
+			r.files[path] = f
+		} else if info.Size() < f.offset {
+			// Truncated in place (copytruncate): the old fingerprint no
+			// longer describes the content.
+			f.offset, f.partial, f.pending = 0, nil, nil
+			f.fp, _ = fingerprintFile(f.file, info.Size())
+		}
+		f.info = info
+		batch = r.read(f, batch)
+	}
+
+	for path, f := range r.files {
+		if !seen[path] {
+			batch = r.read(f, batch)
+			r.flushPending(f, &batch)
+			f.close()
+			delete(r.files, path)
+		}
+	}
+	r.scanned = true
+	r.checkpoints = nil
+
+	// The positions cover exactly this batch, so they are only persisted
+	// once it has been accepted; otherwise a restart re-reads it.
+	cps := r.positions()
+	if rest, err := r.submit(ctx, batch); err != nil {
+		r.unsent = rest
+		return err
+	}
+	r.saveCheckpoints(cps)
+	return nil
+}
+
+// open starts tailing path at its checkpoint, at the end for files present
+// at startup unless StartAtBeginning is set, and at the start otherwise.
+func (r *PodLogReceiver) open(path string, info os.FileInfo) (*podLogFile, error) {
+	t, err := openTailedFile(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	if cp, ok := r.checkpoints[path]; ok && cp.Offset <= info.Size() && cp.Fingerprint.matches(t.file, info.Size()) {
+		t.offset = cp.Offset
+	} else if !r.scanned && !r.cfg.StartAtBeginning {
+		t.offset = info.Size()
+	}
+	return &podLogFile{tailedFile: t, resource: podLogResource(path)}, nil
+}
+
+// read appends the new lines of f to batch as one resource.
+func (r *PodLogReceiver) read(f *podLogFile, batch []*logspb.ResourceLogs) []*logspb.ResourceLogs {
+	var records []*logspb.LogRecord
+	err := f.readLines(maxPodLogReadPerPoll, maxPodLogLine, func(line []byte, start int64) {
+		entry, err := parseContainerLogLine(line)
+		if err != nil {
+			if verboseLogging {
+				log.Printf("Pod log tailing: %s: %v", f.path, err)
+			}
+			return
+		}
+		if f.pending == nil {
+			if !entry.partial {
+				records = append(records, podLogRecord(entry))
+				return
+			}
+			f.pending, f.pendingStart = &entry, start
+			return
+		}
+		if len(f.pending.message) < maxPodLogLine {
+			f.pending.message += entry.message
+		}
+		if !entry.partial {
+			records = append(records, podLogRecord(*f.pending))
+			f.pending = nil
+		}
+	})
+	if err != nil {
+		log.Printf("Pod log tailing: read %s: %v", f.path, err)
+	}
+	return appendPodLogRecords(batch, f, records)
+}
+
+// flushPending emits an unfinished partial line of a file that is going
+// away.
+func (r *PodLogReceiver) flushPending(f *podLogFile, batch *[]*logspb.ResourceLogs) {
+	if f.pending != nil {
+		*batch = appendPodLogRecords(*batch, f, []*logspb.LogRecord{podLogRecord(*f.pending)})
+		f.pending = nil
+	}
+}
+
+func appendPodLogRecords(batch []*logspb.ResourceLogs, f *podLogFile, records []*logspb.LogRecord) []*logspb.ResourceLogs {
+	if len(records) == 0 {
+		return batch
+	}
+	return append(batch, &logspb.ResourceLogs{
+		Resource: &resourcepb.Resource{Attributes: f.resource},
+		ScopeLogs: []*logspb.ScopeLogs{
+			{Scope: &commonpb.InstrumentationScope{Name: podLogScope}, LogRecords: records},
+		},
+	})
+}
+
+// submit analyzes the batch in requests of at most maxPodLogBatch records.
+// A full ingestion queue is retried, so tailing slows down instead of
+// dropping lines. On any other error the part of the batch that was not
+// accepted is returned with it.
+func (r *PodLogReceiver) submit(ctx context.Context, batch []*logspb.ResourceLogs) ([]*logspb.ResourceLogs, error) {
+	for len(batch) > 0 {
+		req := &collogspb.ExportLogsServiceRequest{}
+		records, n := 0, 0
+		for n < len(batch) && (records == 0 || records+len(batch[n].ScopeLogs[0].LogRecords) <= maxPodLogBatch) {
+			records += len(batch[n].ScopeLogs[0].LogRecords)
+			req.ResourceLogs = append(req.ResourceLogs, batch[n])
+			n++
+		}
+
+		a := r.analyzers
+		sims := r.Simulations.Observer(ctx)
+		for {
+			err := r.Pipeline.Submit(ctx, ingest.SignalLogs, func(ctx context.Context) error {
+				return storeLogs(ctx, a, sims, req)
+			})
+			if !errors.Is(err, ingest.ErrQueueFull) {
+				if err != nil {
+					return batch, err
+				}
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return batch, ctx.Err()
+			case <-time.After(r.Pipeline.RetryAfter()):
+			}
+		}
+		batch = batch[n:]
+		if r.OnActivity != nil {
+			r.OnActivity()
+		}
+	}
+	return nil, nil
+}
+
+// positions returns the position of every open file, or nil without a
+// checkpoint file. A pending partial line is re-read after a restart rather
+// than lost.
+func (r *PodLogReceiver) positions() map[string]fileCheckpoint {
+	if r.cfg.CheckpointFile == "" {
+		return nil
+	}
+	cps := make(map[string]fileCheckpoint, len(r.files))
+	for path, f := range r.files {
+		offset := f.offset
+		if f.pending != nil {
+			offset = f.pendingStart
+		}
+		cps[path] = fileCheckpoint{Offset: offset, Fingerprint: f.fp}
+	}
+	return cps
+}
+
+// saveCheckpoints persists positions returned by positions.
+func (r *PodLogReceiver) saveCheckpoints(cps map[string]fileCheckpoint) {
+	if cps == nil {
+		return
+	}
+	if err := saveCheckpoints(r.cfg.CheckpointFile, cps); err != nil {
+		log.Printf("Pod log tailing: save checkpoints: %v", err)
+	}
+}
+
+// podLogResource derives resource attributes from the kubelet layout
+// /var/log/pods/<namespace>_<pod>_<uid>/<container>/<restart>.log. Other
+// paths only get log.file.path.
+func podLogResource(path string) []*commonpb.KeyValue {
+	dir := filepath.Dir(path)
+	container := filepath.Base(dir)
+	parts := strings.Split(filepath.Base(filepath.Dir(dir)), "_")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || container == "" {
+		return []*commonpb.KeyValue{stringKeyValue("log.file.path", path)}
+	}
+
+	attrs := []*commonpb.KeyValue{
+		stringKeyValue("k8s.namespace.name", parts[0]),
+		stringKeyValue("k8s.pod.name", parts[1]),
+		stringKeyValue("k8s.pod.uid", parts[2]),
+		stringKeyValue("k8s.container.name", container),
+	}
+	if restart := strings.TrimSuffix(filepath.Base(path), ".log"); restart != "" {
+		if _, err := strconv.Atoi(restart); err == nil {
+			attrs = append(attrs, stringKeyValue("k8s.container.restart_count", restart))
+		}
+	}
+	return attrs
+}
+
+// dockerLogLine is a line of Docker's json-file log driver.
+type dockerLogLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// parseContainerLogLine parses a CRI line ("<time> <stream> <P|F> <msg>")
+// or a Docker JSON line. Docker marks partial lines by leaving off the
+// trailing newline.
+func parseContainerLogLine(line []byte) (podLogEntry, error) {
+	if len(line) > 0 && line[0] == '{' {
+		var d dockerLogLine
+		if err := json.Unmarshal(line, &d); err != nil {
+			return podLogEntry{}, fmt.Errorf("docker log line: %w", err)
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, d.Time)
+		msg, complete := strings.CutSuffix(d.Log, "\n")
+		return podLogEntry{time: ts, stream: d.Stream, partial: !complete, message: msg}, nil
+	}
+
+	fields := strings.SplitN(string(sanitizeUTF8(line)), " ", 4)
+	if len(fields) < 3 {
+		return podLogEntry{}, errors.New("CRI log line: too few fields")
+	}
+	ts, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		return podLogEntry{}, fmt.Errorf("CRI log line: %w", err)
+	}
+	e := podLogEntry{time: ts, stream: fields[1], partial: fields[2] == "P"}
+	if len(fields) == 4 {
+		e.message = fields[3]
+	}
+	return e, nil
+}
+
+func podLogRecord(e podLogEntry) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: e.message}},
+	}
+	if !e.time.IsZero() {
+		record.TimeUnixNano = uint64(e.time.UnixNano())
+	}
+	if e.stream != "" {
+		record.Attributes = []*commonpb.KeyValue{stringKeyValue("log.iostream", e.stream)}
+	}
+	return record
+}
@@ -0,0 +1,331 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+// podLogPath creates the kubelet directory layout under root and returns
+// the path of the container's log file.
+func podLogPath(t *testing.T, root, namespace, pod, container string) string {
+	t.Helper()
+	dir := filepath.Join(root, namespace+"_"+pod+"_0f3a9c2e-1111-2222-3333-444455556666", container)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	return filepath.Join(dir, "0.log")
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func newTestPodLogReceiver(t *testing.T, root, checkpoint string) (*PodLogReceiver, storage.Storage) {
+	t.Helper()
+	store := storage.NewStorage(storage.DefaultConfig())
+	r, err := NewPodLogReceiver(PodLogConfig{
+		Include:          []string{filepath.Join(root, "*", "*", "*.log")},
+		CheckpointFile:   checkpoint,
+		StartAtBeginning: true,
+	}, store)
+	if err != nil {
+		t.Fatalf("NewPodLogReceiver: %v", err)
+	}
+	return r, store
+}
+
+// serviceRecords sums the log records stored for service over all
+// severities.
+func serviceRecords(t *testing.T, store storage.Storage, service string) int64 {
+	t.Helper()
+	logs, err := store.ListLogs(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListLogs: %v", err)
+	}
+	var n int64
+	for _, lm := range logs {
+		n += lm.Services[service]
+	}
+	return n
+}
+
+func TestPodLogReceiver_CRIFormat(t *testing.T) {
+	root := t.TempDir()
+	path := podLogPath(t, root, "shop", "checkout-7d9f-abcde", "checkout")
+	appendFile(t, path,
+		"2024-01-01T00:00:00.000000001Z stdout F order 1001 placed\n"+
+			"2024-01-01T00:00:01.000000000Z stderr P error: payment declined for \n"+
+			"2024-01-01T00:00:01.000000001Z stderr F order 1002\n"+
+			"2024-01-01T00:00:02.000000000Z stdout F order 1003 pla") // unterminated
+
+	r, store := newTestPodLogReceiver(t, root, "")
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "checkout"); got != 2 {
+		t.Fatalf("records = %d, want 2 (the partial line merged, the unterminated one held back)", got)
+	}
+
+	errLog, err := store.GetLog(context.Background(), "ERROR")
+	if err != nil {
+		t.Fatalf("GetLog(ERROR): %v", err)
+	}
+	for _, key := range []string{"k8s.namespace.name", "k8s.pod.name", "k8s.pod.uid", "k8s.container.name", "k8s.container.restart_count"} {
+		if _, ok := errLog.ResourceKeys[key]; !ok {
+			t.Errorf("expected resource key %q, got %v", key, errLog.ResourceKeys)
+		}
+	}
+	if _, ok := errLog.AttributeKeys["log.iostream"]; !ok {
+		t.Errorf("expected log.iostream attribute, got %v", errLog.AttributeKeys)
+	}
+	if len(errLog.BodyTemplates) != 1 || errLog.BodyTemplates[0].Example != "error: payment declined for order 1002" {
+		t.Errorf("body templates = %+v, want the merged line", errLog.BodyTemplates)
+	}
+
+	appendFile(t, path, "ced\n")
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "checkout"); got != 3 {
+		t.Errorf("records = %d, want 3 once the line is complete", got)
+	}
+}
+
+func TestPodLogReceiver_DockerFormat(t *testing.T) {
+	root := t.TempDir()
+	path := podLogPath(t, root, "default", "api-5c4b", "api")
+	appendFile(t, path,
+		`{"log":"GET /users/1 200\n","stream":"stdout","time":"2024-01-01T00:00:00.000000001Z"}`+"\n"+
+			`{"log":"a very long ","stream":"stdout","time":"2024-01-01T00:00:01Z"}`+"\n"+
+			`{"log":"line\n","stream":"stdout","time":"2024-01-01T00:00:01Z"}`+"\n"+
+			"not json and not CRI\n")
+
+	r, store := newTestPodLogReceiver(t, root, "")
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "api"); got != 2 {
+		t.Errorf("records = %d, want 2", got)
+	}
+}
+
+func TestPodLogReceiver_FollowsRotation(t *testing.T) {
+	root := t.TempDir()
+	path := podLogPath(t, root, "shop", "cart-1", "cart")
+	appendFile(t, path, "2024-01-01T00:00:00Z stdout F line 1\n")
+
+	r, store := newTestPodLogReceiver(t, root, "")
+	ctx := context.Background()
+	if err := r.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	// kubelet rotation: lines written just before the rename must still be
+	// read from the old file, then the new file is read from the start.
+	appendFile(t, path, "2024-01-01T00:00:01Z stdout F line 2\n")
+	if err := os.Rename(path, path+".20240101-000002"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	appendFile(t, path, "2024-01-01T00:00:03Z stdout F line 3\n")
+	if err := r.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "cart"); got != 3 {
+		t.Errorf("records = %d, want 3", got)
+	}
+
+	// copytruncate: the file shrinks in place.
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	appendFile(t, path, "2024-01-01T00:00:04Z stdout F 4\n")
+	if err := r.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "cart"); got != 4 {
+		t.Errorf("records = %d, want 4 after truncation", got)
+	}
+}
+
+func TestPodLogReceiver_ResumesFromCheckpoint(t *testing.T) {
+	root := t.TempDir()
+	checkpoint := filepath.Join(t.TempDir(), "checkpoints.json")
+	path := podLogPath(t, root, "shop", "search-1", "search")
+	appendFile(t, path, "2024-01-01T00:00:00Z stdout F query 1\n2024-01-01T00:00:01Z stdout F query 2\n")
+
+	r, _ := newTestPodLogReceiver(t, root, checkpoint)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(checkpoint); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("checkpoint file was not written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	appendFile(t, path, "2024-01-01T00:00:02Z stdout F query 3\n")
+	r2, store := newTestPodLogReceiver(t, root, checkpoint)
+	if err := r2.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "search"); got != 1 {
+		t.Errorf("records after restart = %d, want only the new line", got)
+	}
+
+	// A file replaced while the checkpoint was taken is not resumed.
+	if err := os.WriteFile(path, []byte("2024-01-02T00:00:00Z stdout F fresh file with other content\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	r3, store3 := newTestPodLogReceiver(t, root, checkpoint)
+	if err := r3.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store3, "search"); got != 1 {
+		t.Errorf("records for replaced file = %d, want 1", got)
+	}
+}
+
+func TestPodLogReceiver_CheckpointsOnlyAcceptedLines(t *testing.T) {
+	root := t.TempDir()
+	checkpoint := filepath.Join(t.TempDir(), "checkpoints.json")
+	path := podLogPath(t, root, "shop", "cart-1", "cart")
+	appendFile(t, path, "2024-01-01T00:00:00Z stdout F line 1\n")
+
+	// A rejected batch leaves no checkpoint behind.
+	r, _ := newTestPodLogReceiver(t, root, checkpoint)
+	r.Pipeline = ingest.New(ingest.Config{QueueSize: 1})
+	r.Pipeline.Shutdown(context.Background()) //nolint:errcheck
+	if err := r.poll(context.Background()); !errors.Is(err, ingest.ErrClosed) {
+		t.Fatalf("poll error = %v, want ErrClosed", err)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Fatalf("checkpoint written for a rejected batch: %v", err)
+	}
+
+	r2, store := newTestPodLogReceiver(t, root, checkpoint)
+	if err := r2.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "cart"); got != 1 {
+		t.Errorf("records after the rejected batch = %d, want 1", got)
+	}
+
+	// After copytruncate the checkpoint describes the new content, so a
+	// restart resumes instead of re-reading the file.
+	if err := os.WriteFile(path, []byte("2024-01-02T00:00:00Z stdout F x\n"), 0o644); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if err := r2.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	appendFile(t, path, "2024-01-02T00:00:01Z stdout F y\n")
+	r3, store3 := newTestPodLogReceiver(t, root, checkpoint)
+	if err := r3.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store3, "cart"); got != 1 {
+		t.Errorf("records after copytruncate and restart = %d, want only the new line", got)
+	}
+}
+
+func TestPodLogReceiver_RetriesRejectedBatch(t *testing.T) {
+	root := t.TempDir()
+	path := podLogPath(t, root, "shop", "cart-1", "cart")
+	appendFile(t, path, "2024-01-01T00:00:00Z stdout F line 1\n2024-01-01T00:00:01Z stdout F line 2\n")
+
+	r, store := newTestPodLogReceiver(t, root, "")
+	r.Pipeline = ingest.New(ingest.Config{QueueSize: 1})
+	r.Pipeline.Shutdown(context.Background()) //nolint:errcheck
+	if err := r.poll(context.Background()); !errors.Is(err, ingest.ErrClosed) {
+		t.Fatalf("poll error = %v, want ErrClosed", err)
+	}
+
+	// The offsets are past the rejected lines, so the next poll must
+	// submit them again rather than skip to new ones.
+	r.Pipeline = nil
+	appendFile(t, path, "2024-01-01T00:00:02Z stdout F line 3\n")
+	if err := r.poll(context.Background()); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "cart"); got != 3 {
+		t.Errorf("records after retry = %d, want 3", got)
+	}
+	if len(r.unsent) != 0 {
+		t.Errorf("unsent = %d resources after an accepted poll, want 0", len(r.unsent))
+	}
+}
+
+func TestPodLogReceiver_StartsAtEndByDefault(t *testing.T) {
+	root := t.TempDir()
+	path := podLogPath(t, root, "shop", "cart-1", "cart")
+	appendFile(t, path, "2024-01-01T00:00:00Z stdout F old line\n")
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	r, err := NewPodLogReceiver(PodLogConfig{Include: []string{filepath.Join(root, "*", "*", "*.log")}}, store)
+	if err != nil {
+		t.Fatalf("NewPodLogReceiver: %v", err)
+	}
+	ctx := context.Background()
+	if err := r.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	appendFile(t, path, "2024-01-01T00:00:01Z stdout F new line\n")
+	later := podLogPath(t, root, "shop", "cart-2", "cart")
+	appendFile(t, later, "2024-01-01T00:00:01Z stdout F new pod\n")
+	if err := r.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if got := serviceRecords(t, store, "cart"); got != 2 {
+		t.Errorf("records = %d, want 2 (new line and new pod, not the old line)", got)
+	}
+}
+
+func TestPodLogResource(t *testing.T) {
+	attrs := podLogResource("/var/log/pods/kube-system_coredns-5d78c9869d-x2x9z_3f0c6e1a/coredns/3.log")
+	got := map[string]string{}
+	for _, kv := range attrs {
+		got[kv.Key] = kv.Value.GetStringValue()
+	}
+	want := map[string]string{
+		"k8s.namespace.name":          "kube-system",
+		"k8s.pod.name":                "coredns-5d78c9869d-x2x9z",
+		"k8s.pod.uid":                 "3f0c6e1a",
+		"k8s.container.name":          "coredns",
+		"k8s.container.restart_count": "3",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	other := podLogResource("/var/lib/docker/containers/abc/abc-json.log")
+	if len(other) != 1 || other[0].Key != "log.file.path" {
+		t.Errorf("non-kubelet path attributes = %v, want only log.file.path", other)
+	}
+}
@@ -0,0 +1,113 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/profilespb"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+// minimalProfilesProto returns a serialised ExportProfilesServiceRequest with
+// one CPU profile holding a single sample.
+func minimalProfilesProto(t *testing.T) []byte {
+	t.Helper()
+	req := &profilespb.ExportProfilesServiceRequest{
+		Dictionary: &profilespb.ProfilesDictionary{
+			StringTable: []string{"", "cpu", "nanoseconds"},
+			AttributeTable: []*commonpb.KeyValue{
+				{Key: "thread.name", Value: &commonpb.AnyValue{
+					Value: &commonpb.AnyValue_StringValue{StringValue: "main"},
+				}},
+			},
+		},
+		ResourceProfiles: []*profilespb.ResourceProfiles{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{
+						{Key: "service.name", Value: &commonpb.AnyValue{
+							Value: &commonpb.AnyValue_StringValue{StringValue: "test-service"},
+						}},
+					},
+				},
+				ScopeProfiles: []*profilespb.ScopeProfiles{
+					{
+						Profiles: []*profilespb.Profile{
+							{
+								SampleType: []*profilespb.ValueType{{TypeStrindex: 1, UnitStrindex: 2}},
+								Sample: []*profilespb.Sample{
+									{Value: []int64{10000000}, AttributeIndices: []int32{0}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	b, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
+
+func TestHandleProfiles_Protobuf(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1development/profiles", bytes.NewReader(minimalProfilesProto(t)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	w := httptest.NewRecorder()
+	r.handleProfiles(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected 200, got %d: %s", res.StatusCode, body)
+	}
+
+	profile, err := store.(storage.ProfileStore).GetProfile(context.Background(), "cpu:nanoseconds")
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if profile.SampleCount != 1 || profile.Services["test-service"] != 1 {
+		t.Errorf("unexpected profile metadata: %+v", profile)
+	}
+	if _, ok := profile.AttributeKeys["thread.name"]; !ok {
+		t.Errorf("expected thread.name attribute key, got %v", profile.AttributeKeys)
+	}
+}
+
+func TestHandleProfiles_JSON(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	rawJSON := []byte(`{"dictionary":{"stringTable":["","alloc_space","bytes"]},` +
+		`"resourceProfiles":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"json-svc"}}]},` +
+		`"scopeProfiles":[{"profiles":[{"sampleType":[{"typeStrindex":1,"unitStrindex":2}],"sample":[{"value":["512"]}]}]}]}]}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1development/profiles", bytes.NewReader(rawJSON))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.handleProfiles(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := store.(storage.ProfileStore).GetProfile(context.Background(), "alloc_space:bytes"); err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+}
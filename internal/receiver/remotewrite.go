@@ -0,0 +1,539 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/klauspost/compress/snappy"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriteScope is the instrumentation scope recorded for metrics that
+// arrived via Prometheus remote-write, so they can be told apart from OTLP.
+const remoteWriteScope = "prometheus.remote_write"
+
+// Prometheus metric types as encoded in prompb.MetricMetadata.MetricType.
+const (
+	promTypeUnknown        = 0
+	promTypeCounter        = 1
+	promTypeGauge          = 2
+	promTypeSummary        = 3
+	promTypeHistogram      = 4
+	promTypeGaugeHistogram = 5
+	promTypeInfo           = 6
+	promTypeStateset       = 7
+)
+
+// promWriteRequest is the subset of prompb.WriteRequest needed for
+// cardinality analysis. Sample values are kept only for completeness; the
+// analyzer never looks at them.
+type promWriteRequest struct {
+	series   []promTimeSeries
+	metadata []promMetadata
+}
+
+type promTimeSeries struct {
+	labels     []promLabel
+	samples    []promSample
+	histograms []promHistogram
+}
+
+type promLabel struct {
+	name  string
+	value string
+}
+
+type promSample struct {
+	value     float64
+	timestamp int64
+}
+
+type promHistogram struct {
+	schema    int32
+	timestamp int64
+}
+
+type promMetadata struct {
+	metricType int
+	family     string
+	help       string
+	unit       string
+}
+
+// handleRemoteWrite handles Prometheus remote-write v1 requests
+// (snappy-compressed protobuf WriteRequest).
+func (r *HTTPReceiver) handleRemoteWrite(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rejectIfBodyTooLarge(w, req) {
+		return
+	}
+	if enc := req.Header.Get("Content-Encoding"); enc != "" && !strings.EqualFold(enc, "snappy") {
+		http.Error(w, fmt.Sprintf("Unsupported Content-Encoding %q, remote-write requires snappy", enc), http.StatusUnsupportedMediaType)
+		return
+	}
+	// Remote-write 2.0 announces itself via the proto parameter; only the v1
+	// prometheus.WriteRequest message is understood here.
+	if ct := req.Header.Get("Content-Type"); strings.Contains(ct, "io.prometheus.write.v2") {
+		http.Error(w, "Remote-write 2.0 is not supported, configure protobuf_message: prometheus.WriteRequest", http.StatusUnsupportedMediaType)
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+	defer req.Body.Close()
+
+	ctx := req.Context()
+
+	compressed, err := io.ReadAll(req.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// The snappy block header carries the decoded length, so oversized
+	// payloads are rejected before any memory is allocated for them.
+	decodedLen, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if int64(decodedLen) > maxBodyBytes {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to decode snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeReq, err := decodeWriteRequest(body)
+	if err != nil {
+		log.Printf("Failed to parse remote-write request: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if verboseLogging {
+		fmt.Printf("Received remote-write request: %d series, %d metadata entries\n",
+			len(writeReq.series), len(writeReq.metadata))
+	}
+
+	exportReq := remoteWriteToOTLP(writeReq)
+
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	err = r.Pipeline.Submit(ctx, ingest.SignalMetrics, func(ctx context.Context) error {
+		return storeMetrics(ctx, a, sims, exportReq)
+	})
+	if err != nil {
+		r.writeIngestError(w, ingest.SignalMetrics, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	if r.OnActivity != nil {
+		r.OnActivity()
+	}
+}
+
+// decodeWriteRequest decodes a prometheus.WriteRequest protobuf message.
+// Unknown fields are skipped so newer senders remain compatible.
+func decodeWriteRequest(b []byte) (*promWriteRequest, error) {
+	wr := &promWriteRequest{}
+	err := forEachField(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.typ == protowire.BytesType:
+			ts, err := decodeTimeSeries(f.bytes)
+			if err != nil {
+				return fmt.Errorf("timeseries: %w", err)
+			}
+			wr.series = append(wr.series, ts)
+		case f.num == 3 && f.typ == protowire.BytesType:
+			md, err := decodePromMetadata(f.bytes)
+			if err != nil {
+				return fmt.Errorf("metadata: %w", err)
+			}
+			wr.metadata = append(wr.metadata, md)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wr, nil
+}
+
+func decodeTimeSeries(b []byte) (promTimeSeries, error) {
+	var ts promTimeSeries
+	err := forEachField(b, func(f protoField) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			l, err := decodePromLabel(f.bytes)
+			if err != nil {
+				return fmt.Errorf("label: %w", err)
+			}
+			ts.labels = append(ts.labels, l)
+		case 2:
+			s, err := decodePromSample(f.bytes)
+			if err != nil {
+				return fmt.Errorf("sample: %w", err)
+			}
+			ts.samples = append(ts.samples, s)
+		case 4:
+			h, err := decodePromHistogram(f.bytes)
+			if err != nil {
+				return fmt.Errorf("histogram: %w", err)
+			}
+			ts.histograms = append(ts.histograms, h)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func decodePromLabel(b []byte) (promLabel, error) {
+	var l promLabel
+	err := forEachField(b, func(f protoField) error {
+		if f.typ != protowire.BytesType {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			l.name = string(f.bytes)
+		case 2:
+			l.value = string(f.bytes)
+		}
+		return nil
+	})
+	return l, err
+}
+
+func decodePromSample(b []byte) (promSample, error) {
+	var s promSample
+	err := forEachField(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.typ == protowire.Fixed64Type:
+			s.value = math.Float64frombits(f.scalar)
+		case f.num == 2 && f.typ == protowire.VarintType:
+			s.timestamp = int64(f.scalar)
+		}
+		return nil
+	})
+	return s, err
+}
+
+func decodePromHistogram(b []byte) (promHistogram, error) {
+	var h promHistogram
+	err := forEachField(b, func(f protoField) error {
+		switch {
+		case f.num == 4 && f.typ == protowire.VarintType:
+			h.schema = int32(protowire.DecodeZigZag(f.scalar))
+		case f.num == 15 && f.typ == protowire.VarintType:
+			h.timestamp = int64(f.scalar)
+		}
+		return nil
+	})
+	return h, err
+}
+
+func decodePromMetadata(b []byte) (promMetadata, error) {
+	var md promMetadata
+	err := forEachField(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.typ == protowire.VarintType:
+			md.metricType = int(f.scalar)
+		case f.num == 2 && f.typ == protowire.BytesType:
+			md.family = string(f.bytes)
+		case f.num == 4 && f.typ == protowire.BytesType:
+			md.help = string(f.bytes)
+		case f.num == 5 && f.typ == protowire.BytesType:
+			md.unit = string(f.bytes)
+		}
+		return nil
+	})
+	return md, err
+}
+
+// protoField is a single decoded protobuf field. bytes is set for
+// length-delimited fields, scalar for varint and fixed-width fields.
+type protoField struct {
+	num    protowire.Number
+	typ    protowire.Type
+	bytes  []byte
+	scalar uint64
+}
+
+// forEachField walks the top-level fields of a protobuf message.
+func forEachField(b []byte, fn func(f protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		f := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			f.scalar, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			f.scalar, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.scalar = uint64(v)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// remoteWriteToOTLP converts a remote-write request into an OTLP export
+// request so it can flow through the regular MetricsAnalyzer. job and
+// instance become service.name and service.instance.id resource attributes
+// (mirroring the Prometheus receiver in the OTel Collector); all other labels
+// except __name__ become data point attributes.
+func remoteWriteToOTLP(wr *promWriteRequest) *colmetricspb.ExportMetricsServiceRequest {
+	families := make(map[string]promMetadata, len(wr.metadata))
+	for _, md := range wr.metadata {
+		families[md.family] = md
+	}
+
+	type resourceGroup struct {
+		rm      *metricspb.ResourceMetrics
+		metrics map[string]*metricspb.Metric
+	}
+	groups := make(map[string]*resourceGroup)
+	var order []string
+
+	for _, ts := range wr.series {
+		var name, job, instance string
+		attrs := make([]*commonpb.KeyValue, 0, len(ts.labels))
+		for _, l := range ts.labels {
+			switch l.name {
+			case "__name__":
+				name = l.value
+			case "job":
+				job = l.value
+			case "instance":
+				instance = l.value
+			default:
+				// Prometheus treats empty label values as absent.
+				if l.value != "" {
+					attrs = append(attrs, stringKeyValue(l.name, l.value))
+				}
+			}
+		}
+		if name == "" {
+			continue
+		}
+
+		groupKey := job + "\xff" + instance
+		g, ok := groups[groupKey]
+		if !ok {
+			var resAttrs []*commonpb.KeyValue
+			if job != "" {
+				resAttrs = append(resAttrs, stringKeyValue("service.name", job))
+			}
+			if instance != "" {
+				resAttrs = append(resAttrs, stringKeyValue("service.instance.id", instance))
+			}
+			g = &resourceGroup{
+				rm: &metricspb.ResourceMetrics{
+					Resource: &resourcepb.Resource{Attributes: resAttrs},
+					ScopeMetrics: []*metricspb.ScopeMetrics{
+						{Scope: &commonpb.InstrumentationScope{Name: remoteWriteScope}},
+					},
+				},
+				metrics: make(map[string]*metricspb.Metric),
+			}
+			groups[groupKey] = g
+			order = append(order, groupKey)
+		}
+
+		// Series of one name may carry float samples or native histograms.
+		// Each kind gets its own metric so that no points are dropped; the
+		// store then reports the name's mixed types as a conflict.
+		for _, native := range sampleKinds(ts) {
+			key := name
+			if native {
+				key += "\xffnative"
+			}
+			metric, ok := g.metrics[key]
+			if !ok {
+				metric = newRemoteWriteMetric(name, families, native)
+				g.metrics[key] = metric
+				sm := g.rm.ScopeMetrics[0]
+				sm.Metrics = append(sm.Metrics, metric)
+			}
+			appendRemoteWritePoints(metric, ts, attrs)
+		}
+	}
+
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	for _, key := range order {
+		req.ResourceMetrics = append(req.ResourceMetrics, groups[key].rm)
+	}
+	return req
+}
+
+// sampleKinds lists the kinds of points ts carries: false for float
+// samples, true for native histograms. A series without either counts as
+// float, so its name and labels are still recorded.
+func sampleKinds(ts promTimeSeries) []bool {
+	switch {
+	case len(ts.histograms) == 0:
+		return []bool{false}
+	case len(ts.samples) == 0:
+		return []bool{true}
+	}
+	return []bool{false, true}
+}
+
+// newRemoteWriteMetric creates an empty OTLP metric for a Prometheus series
+// name, choosing the data type from the family metadata when the sender
+// provided it and from naming conventions otherwise.
+func newRemoteWriteMetric(name string, families map[string]promMetadata, native bool) *metricspb.Metric {
+	md, family, found := lookupFamily(name, families)
+	metric := &metricspb.Metric{Name: name}
+	if found {
+		metric.Description = md.help
+		metric.Unit = md.unit
+	}
+
+	if native {
+		metric.Data = &metricspb.Metric_ExponentialHistogram{
+			ExponentialHistogram: &metricspb.ExponentialHistogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			},
+		}
+		return metric
+	}
+
+	monotonic := false
+	if found {
+		switch md.metricType {
+		case promTypeCounter:
+			monotonic = true
+		case promTypeHistogram, promTypeSummary:
+			// Bucket, _sum and _count series of a classic histogram or
+			// summary are counters; the bare summary name carries quantiles.
+			monotonic = name != family
+		}
+	} else {
+		for _, suffix := range []string{"_total", "_bucket", "_count", "_sum"} {
+			if strings.HasSuffix(name, suffix) {
+				monotonic = true
+				break
+			}
+		}
+	}
+
+	if monotonic {
+		metric.Data = &metricspb.Metric_Sum{
+			Sum: &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			},
+		}
+	} else {
+		metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+	}
+	return metric
+}
+
+// lookupFamily finds the metadata for a series name, first by exact name and
+// then by stripping the suffixes Prometheus appends to family names.
+func lookupFamily(name string, families map[string]promMetadata) (promMetadata, string, bool) {
+	if md, ok := families[name]; ok {
+		return md, name, true
+	}
+	for _, suffix := range []string{"_total", "_bucket", "_count", "_sum", "_created", "_info"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			if md, ok := families[base]; ok {
+				return md, base, true
+			}
+		}
+	}
+	return promMetadata{}, "", false
+}
+
+// appendRemoteWritePoints adds one data point per sample (or native
+// histogram) of ts to metric. Attributes are sorted so identical label sets
+// always produce the same series fingerprint.
+func appendRemoteWritePoints(metric *metricspb.Metric, ts promTimeSeries, attrs []*commonpb.KeyValue) {
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+
+	switch data := metric.Data.(type) {
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, h := range ts.histograms {
+			data.ExponentialHistogram.DataPoints = append(data.ExponentialHistogram.DataPoints, &metricspb.ExponentialHistogramDataPoint{
+				Attributes:   attrs,
+				TimeUnixNano: millisToNanos(h.timestamp),
+				Scale:        h.schema,
+			})
+		}
+	case *metricspb.Metric_Sum:
+		data.Sum.DataPoints = append(data.Sum.DataPoints, samplesToPoints(ts.samples, attrs)...)
+	case *metricspb.Metric_Gauge:
+		data.Gauge.DataPoints = append(data.Gauge.DataPoints, samplesToPoints(ts.samples, attrs)...)
+	}
+}
+
+func samplesToPoints(samples []promSample, attrs []*commonpb.KeyValue) []*metricspb.NumberDataPoint {
+	points := make([]*metricspb.NumberDataPoint, 0, len(samples))
+	for _, s := range samples {
+		points = append(points, &metricspb.NumberDataPoint{
+			Attributes:   attrs,
+			TimeUnixNano: millisToNanos(s.timestamp),
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: s.value},
+		})
+	}
+	return points
+}
+
+func millisToNanos(ms int64) uint64 {
+	if ms <= 0 {
+		return 0
+	}
+	return uint64(ms) * 1e6
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
@@ -0,0 +1,238 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// encodeTestSeries encodes a prompb.TimeSeries with the given labels and a
+// single sample.
+func encodeTestSeries(labels ...string) []byte {
+	var ts []byte
+	for i := 0; i+1 < len(labels); i += 2 {
+		var l []byte
+		l = protowire.AppendTag(l, 1, protowire.BytesType)
+		l = protowire.AppendString(l, labels[i])
+		l = protowire.AppendTag(l, 2, protowire.BytesType)
+		l = protowire.AppendString(l, labels[i+1])
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, l)
+	}
+	var s []byte
+	s = protowire.AppendTag(s, 1, protowire.Fixed64Type)
+	s = protowire.AppendFixed64(s, math.Float64bits(42))
+	s = protowire.AppendTag(s, 2, protowire.VarintType)
+	s = protowire.AppendVarint(s, 1700000000000)
+	ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+	ts = protowire.AppendBytes(ts, s)
+	return ts
+}
+
+// encodeTestMetadata encodes a prompb.MetricMetadata entry.
+func encodeTestMetadata(metricType int, family, help, unit string) []byte {
+	var md []byte
+	md = protowire.AppendTag(md, 1, protowire.VarintType)
+	md = protowire.AppendVarint(md, uint64(metricType))
+	md = protowire.AppendTag(md, 2, protowire.BytesType)
+	md = protowire.AppendString(md, family)
+	md = protowire.AppendTag(md, 4, protowire.BytesType)
+	md = protowire.AppendString(md, help)
+	md = protowire.AppendTag(md, 5, protowire.BytesType)
+	md = protowire.AppendString(md, unit)
+	return md
+}
+
+// testWriteRequest returns a snappy-compressed WriteRequest containing two
+// counter series from one target and one gauge series from another.
+func testWriteRequest(t *testing.T) []byte {
+	t.Helper()
+	var wr []byte
+	for _, ts := range [][]byte{
+		encodeTestSeries("__name__", "http_requests_total", "job", "api", "instance", "10.0.0.1:9090", "method", "GET", "code", "200"),
+		encodeTestSeries("__name__", "http_requests_total", "job", "api", "instance", "10.0.0.1:9090", "method", "POST", "code", "500"),
+		encodeTestSeries("__name__", "queue_depth", "job", "worker", "instance", "10.0.0.2:9090", "queue", "emails", "shard", ""),
+	} {
+		wr = protowire.AppendTag(wr, 1, protowire.BytesType)
+		wr = protowire.AppendBytes(wr, ts)
+	}
+	wr = protowire.AppendTag(wr, 3, protowire.BytesType)
+	wr = protowire.AppendBytes(wr, encodeTestMetadata(promTypeCounter, "http_requests", "Total HTTP requests.", ""))
+	return snappy.Encode(nil, wr)
+}
+
+func postRemoteWrite(t *testing.T, r *HTTPReceiver, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	rec := httptest.NewRecorder()
+	r.handleRemoteWrite(rec, req)
+	return rec
+}
+
+func TestRemoteWrite_StoresPrometheusMetrics(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	rec := postRemoteWrite(t, r, testWriteRequest(t))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	counter, err := store.GetMetric(ctx, "http_requests_total")
+	if err != nil {
+		t.Fatalf("GetMetric(http_requests_total): %v", err)
+	}
+	sum, ok := counter.Data.(*models.SumMetric)
+	if !ok || !sum.IsMonotonic {
+		t.Errorf("expected monotonic sum from counter metadata, got %#v", counter.Data)
+	}
+	if counter.Description != "Total HTTP requests." {
+		t.Errorf("description = %q", counter.Description)
+	}
+	for _, key := range []string{"method", "code"} {
+		if _, ok := counter.LabelKeys[key]; !ok {
+			t.Errorf("expected label key %q, got %v", key, counter.LabelKeys)
+		}
+	}
+	for _, key := range []string{"__name__", "job", "instance"} {
+		if _, ok := counter.LabelKeys[key]; ok {
+			t.Errorf("label %q should not be a label key", key)
+		}
+	}
+	if _, ok := counter.ResourceKeys["service.instance.id"]; !ok {
+		t.Errorf("expected instance as service.instance.id resource key, got %v", counter.ResourceKeys)
+	}
+	if counter.Services["api"] != 2 {
+		t.Errorf("expected 2 samples for service api, got %v", counter.Services)
+	}
+	if got := counter.GetActiveSeries(); got != 2 {
+		t.Errorf("active series = %d, want 2", got)
+	}
+
+	gauge, err := store.GetMetric(ctx, "queue_depth")
+	if err != nil {
+		t.Fatalf("GetMetric(queue_depth): %v", err)
+	}
+	if _, ok := gauge.Data.(*models.GaugeMetric); !ok {
+		t.Errorf("expected gauge without metadata, got %T", gauge.Data)
+	}
+	if _, ok := gauge.LabelKeys["shard"]; ok {
+		t.Error("empty label values should be dropped")
+	}
+	if gauge.Services["worker"] != 1 {
+		t.Errorf("expected service worker, got %v", gauge.Services)
+	}
+}
+
+func TestRemoteWrite_RejectsInvalidRequests(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	t.Run("method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/write", nil)
+		rec := httptest.NewRecorder()
+		r.handleRemoteWrite(rec, req)
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", rec.Code)
+		}
+	})
+
+	t.Run("not snappy", func(t *testing.T) {
+		rec := postRemoteWrite(t, r, []byte("definitely not snappy"))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("gzip encoding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(gzipBytes(t, []byte("x"))))
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		r.handleRemoteWrite(rec, req)
+		if rec.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("expected 415, got %d", rec.Code)
+		}
+	})
+
+	t.Run("decoded size limit", func(t *testing.T) {
+		// A snappy block header claiming a payload larger than maxBodyBytes.
+		body := protowire.AppendVarint(nil, uint64(maxBodyBytes+1))
+		rec := postRemoteWrite(t, r, body)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected 413, got %d", rec.Code)
+		}
+	})
+
+	t.Run("truncated protobuf", func(t *testing.T) {
+		rec := postRemoteWrite(t, r, snappy.Encode(nil, []byte{0x0a, 0x10, 0x01}))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+}
+
+func TestRemoteWrite_MixedSampleKinds(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	// The second series of the name sends a native histogram instead of a
+	// float sample.
+	var h []byte
+	h = protowire.AppendTag(h, 4, protowire.VarintType)
+	h = protowire.AppendVarint(h, protowire.EncodeZigZag(3))
+	h = protowire.AppendTag(h, 15, protowire.VarintType)
+	h = protowire.AppendVarint(h, 1700000000000)
+	var native []byte
+	for _, l := range [][2]string{{"__name__", "request_duration_seconds"}, {"job", "api"}, {"route", "/b"}} {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l[0])
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l[1])
+		native = protowire.AppendTag(native, 1, protowire.BytesType)
+		native = protowire.AppendBytes(native, lb)
+	}
+	native = protowire.AppendTag(native, 4, protowire.BytesType)
+	native = protowire.AppendBytes(native, h)
+
+	var wr []byte
+	for _, ts := range [][]byte{
+		encodeTestSeries("__name__", "request_duration_seconds", "job", "api", "route", "/a"),
+		native,
+	} {
+		wr = protowire.AppendTag(wr, 1, protowire.BytesType)
+		wr = protowire.AppendBytes(wr, ts)
+	}
+	if rec := postRemoteWrite(t, r, snappy.Encode(nil, wr)); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	m, err := store.GetMetric(ctx, "request_duration_seconds")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	if m.SampleCount != 2 {
+		t.Errorf("sample count = %d, want both series' points", m.SampleCount)
+	}
+	conflicts, err := store.(storage.MetricConflictStore).GetMetricConflicts(ctx)
+	if err != nil {
+		t.Fatalf("GetMetricConflicts: %v", err)
+	}
+	if conflicts.Total != 1 || conflicts.Conflicts[0].ConflictingFields[0] != "type" {
+		t.Errorf("conflicts = %+v, want a type conflict for request_duration_seconds", conflicts.Conflicts)
+	}
+}
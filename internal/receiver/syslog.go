@@ -0,0 +1,365 @@
+package receiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// syslogScope is the instrumentation scope recorded for logs that arrived
+// over syslog.
+const syslogScope = "syslog"
+
+// maxSyslogMessageBytes caps a single message. Longer newline-framed
+// messages are truncated; longer octet-counted frames close the connection.
+const maxSyslogMessageBytes = 64 << 10
+
+// maxSyslogBatch caps how many messages from one TCP connection are
+// analyzed together.
+const maxSyslogBatch = 512
+
+// SyslogReceiver accepts RFC 5424 and RFC 3164 messages over UDP and TCP on
+// the same address. TCP supports both octet-counting and newline framing
+// (RFC 6587), detected per message.
+type SyslogReceiver struct {
+	analyzers   *analyzerSets
+	addr        string
+	OnActivity  func()             // called after messages are accepted
+	Pipeline    *ingest.Pipeline   // optional; analyze and store asynchronously
+	Simulations *simulate.Registry // optional; shadow-run candidate rules
+
+	mu       sync.Mutex
+	udp      net.PacketConn
+	tcp      net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSyslogReceiver creates a new syslog receiver.
+func NewSyslogReceiver(addr string, store storage.Storage) *SyslogReceiver {
+	return &SyslogReceiver{
+		analyzers: newAnalyzerSets(store),
+		addr:      addr,
+		conns:     make(map[net.Conn]struct{}),
+		shutdown:  make(chan struct{}),
+	}
+}
+
+// Start listens on UDP and TCP and serves until Shutdown.
+func (r *SyslogReceiver) Start() error {
+	udp, err := net.ListenPacket("udp", r.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp: %w", err)
+	}
+	tcp, err := net.Listen("tcp", r.addr)
+	if err != nil {
+		udp.Close()
+		return fmt.Errorf("failed to listen on tcp: %w", err)
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		udp.Close()
+		tcp.Close()
+		return nil
+	}
+	r.udp, r.tcp = udp, tcp
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.serveUDP(udp)
+	}()
+
+	for {
+		conn, err := tcp.Accept()
+		if err != nil {
+			select {
+			case <-r.shutdown:
+				return nil
+			default:
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		if !r.track(conn) {
+			conn.Close()
+			return nil
+		}
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer r.untrack(conn)
+			r.serveConn(conn)
+		}()
+	}
+}
+
+// Shutdown closes the listeners and open connections and waits for
+// in-flight messages to be handed off.
+func (r *SyslogReceiver) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.shutdown)
+		if r.udp != nil {
+			r.udp.Close()
+		}
+		if r.tcp != nil {
+			r.tcp.Close()
+		}
+		for conn := range r.conns {
+			conn.Close()
+		}
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *SyslogReceiver) track(conn net.Conn) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	r.conns[conn] = struct{}{}
+	return true
+}
+
+func (r *SyslogReceiver) untrack(conn net.Conn) {
+	conn.Close()
+	r.mu.Lock()
+	delete(r.conns, conn)
+	r.mu.Unlock()
+}
+
+// serveUDP handles one message per datagram. There is no way to push back
+// on UDP senders, so messages the pipeline cannot take are dropped.
+func (r *SyslogReceiver) serveUDP(conn net.PacketConn) {
+	buf := make([]byte, maxSyslogMessageBytes)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-r.shutdown:
+				return
+			default:
+			}
+			log.Printf("Syslog UDP read error: %v", err)
+			continue
+		}
+		if err := r.ingest(context.Background(), [][]byte{buf[:n]}); err != nil {
+			log.Printf("Failed to ingest syslog message: %v", err)
+		}
+	}
+}
+
+// serveConn reads framed messages from a TCP connection. Messages already
+// buffered are batched together; a full ingestion queue is retried, which
+// pushes back on the sender through TCP flow control.
+func (r *SyslogReceiver) serveConn(conn net.Conn) {
+	br := bufio.NewReaderSize(conn, maxSyslogMessageBytes)
+	var batch [][]byte
+	for {
+		msg, err := readSyslogFrame(br)
+		if len(msg) > 0 {
+			// The frame aliases the reader's buffer; copy before reading on.
+			batch = append(batch, bytes.Clone(msg))
+		}
+		if len(batch) > 0 && (err != nil || br.Buffered() == 0 || len(batch) >= maxSyslogBatch) {
+			r.ingestWithRetry(batch)
+			batch = nil
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("Syslog connection from %s closed: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+func (r *SyslogReceiver) ingestWithRetry(batch [][]byte) {
+	for {
+		err := r.ingest(context.Background(), batch)
+		if !errors.Is(err, ingest.ErrQueueFull) {
+			if err != nil {
+				log.Printf("Failed to ingest syslog messages: %v", err)
+			}
+			return
+		}
+		select {
+		case <-r.shutdown:
+			return
+		case <-time.After(r.Pipeline.RetryAfter()):
+		}
+	}
+}
+
+// readSyslogFrame returns the next message. A frame starting with a digit
+// is octet-counted ("LEN SP MSG"); anything else runs to the next newline.
+func readSyslogFrame(br *bufio.Reader) ([]byte, error) {
+	first, err := br.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		lenStr, err := br.ReadSlice(' ')
+		if err != nil {
+			return nil, fmt.Errorf("octet count: %w", err)
+		}
+		n, err := strconv.Atoi(string(lenStr[:len(lenStr)-1]))
+		if err != nil || n <= 0 || n > maxSyslogMessageBytes {
+			return nil, fmt.Errorf("invalid octet count %q", lenStr[:min(len(lenStr), 16)])
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(br, msg); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	line, err := br.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		// Keep the first maxSyslogMessageBytes and skip to the next frame.
+		line = bytes.Clone(line)
+		for errors.Is(err, bufio.ErrBufferFull) {
+			_, err = br.ReadSlice('\n')
+		}
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if errors.Is(err, io.EOF) && len(line) > 0 {
+		// Unterminated last message; report it now and EOF on the next call.
+		err = nil
+	}
+	return bytes.TrimRight(line, "\r\n"), err
+}
+
+// ingest parses messages and submits them for analysis. Unparseable
+// messages are logged and skipped.
+func (r *SyslogReceiver) ingest(ctx context.Context, msgs [][]byte) error {
+	now := time.Now()
+	parsed := make([]*syslogMessage, 0, len(msgs))
+	for _, b := range msgs {
+		m, err := parseSyslog(sanitizeUTF8(b), now)
+		if err != nil {
+			if verboseLogging {
+				log.Printf("Dropping syslog message: %v", err)
+			}
+			continue
+		}
+		parsed = append(parsed, m)
+	}
+	if len(parsed) == 0 {
+		return nil
+	}
+
+	a := r.analyzers.forContext(ctx)
+	sims := r.Simulations.Observer(ctx)
+	exportReq := syslogToOTLP(parsed, now)
+	err := r.Pipeline.Submit(ctx, ingest.SignalLogs, func(ctx context.Context) error {
+		return storeLogs(ctx, a, sims, exportReq)
+	})
+	if err != nil {
+		return err
+	}
+	if r.OnActivity != nil {
+		r.OnActivity()
+	}
+	return nil
+}
+
+// syslogToOTLP converts parsed messages into an OTLP export request with one
+// resource per hostname and app name. Facility, process ID, message ID and
+// SD-params become log attributes; the severity maps to the OTLP severity.
+func syslogToOTLP(msgs []*syslogMessage, observed time.Time) *collogspb.ExportLogsServiceRequest {
+	type resourceKey struct{ host, app string }
+	resources := make(map[resourceKey]*logspb.ResourceLogs)
+	req := &collogspb.ExportLogsServiceRequest{}
+
+	for _, m := range msgs {
+		key := resourceKey{m.hostname, m.appName}
+		rl, ok := resources[key]
+		if !ok {
+			var resAttrs []*commonpb.KeyValue
+			if m.hostname != "" {
+				resAttrs = append(resAttrs, stringKeyValue("host.name", m.hostname))
+			}
+			if m.appName != "" {
+				resAttrs = append(resAttrs, stringKeyValue("service.name", m.appName))
+			}
+			rl = &logspb.ResourceLogs{
+				Resource: &resourcepb.Resource{Attributes: resAttrs},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{Scope: &commonpb.InstrumentationScope{Name: syslogScope}},
+				},
+			}
+			resources[key] = rl
+			req.ResourceLogs = append(req.ResourceLogs, rl)
+		}
+
+		sev := syslogSeverities[m.severity]
+		record := &logspb.LogRecord{
+			ObservedTimeUnixNano: uint64(observed.UnixNano()),
+			SeverityNumber:       logspb.SeverityNumber(sev.number),
+			SeverityText:         sev.text,
+			Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: m.message}},
+			Attributes: []*commonpb.KeyValue{
+				stringKeyValue("syslog.facility", syslogFacilities[m.facility]),
+				stringKeyValue("syslog.severity", sev.keyword),
+			},
+		}
+		if !m.timestamp.IsZero() {
+			record.TimeUnixNano = uint64(m.timestamp.UnixNano())
+		}
+		if m.procID != "" {
+			record.Attributes = append(record.Attributes, stringKeyValue("syslog.procid", m.procID))
+		}
+		if m.msgID != "" {
+			record.Attributes = append(record.Attributes, stringKeyValue("syslog.msgid", m.msgID))
+		}
+		for _, p := range m.sdParams {
+			record.Attributes = append(record.Attributes, stringKeyValue(p.name, p.value))
+		}
+
+		sl := rl.ScopeLogs[0]
+		sl.LogRecords = append(sl.LogRecords, record)
+	}
+	return req
+}
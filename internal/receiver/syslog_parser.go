@@ -0,0 +1,221 @@
+package receiver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogMessage is a parsed RFC 5424 or RFC 3164 message.
+type syslogMessage struct {
+	facility  int
+	severity  int
+	timestamp time.Time // zero when absent or unparseable
+	hostname  string
+	appName   string
+	procID    string
+	msgID     string
+	sdParams  []promLabel // "<SD-ID>.<PARAM-NAME>" = value
+	message   string
+}
+
+// syslogFacilities are the RFC 5424 facility keywords, by facility code.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogSeverities maps the syslog severity code to its keyword, the OTLP
+// severity number and the severity text logs are grouped under. The numbers
+// follow the OTel Collector's syslog parser.
+var syslogSeverities = [8]struct {
+	keyword string
+	number  int32
+	text    string
+}{
+	{"emerg", 21, "FATAL"},  // FATAL
+	{"alert", 19, "ERROR"},  // ERROR3
+	{"crit", 18, "ERROR"},   // ERROR2
+	{"err", 17, "ERROR"},    // ERROR
+	{"warning", 13, "WARN"}, // WARN
+	{"notice", 10, "INFO"},  // INFO2
+	{"info", 9, "INFO"},     // INFO
+	{"debug", 5, "DEBUG"},   // DEBUG
+}
+
+// syslogNil is the RFC 5424 NILVALUE.
+const syslogNil = "-"
+
+// utf8BOM may prefix the MSG part of an RFC 5424 message.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// parseSyslog parses one message, detecting RFC 5424 by the version digit
+// that follows the PRI part and falling back to RFC 3164 otherwise.
+func parseSyslog(b []byte, now time.Time) (*syslogMessage, error) {
+	b = bytes.TrimRight(b, "\r\n\x00")
+	pri, rest, err := parseSyslogPRI(b)
+	if err != nil {
+		return nil, err
+	}
+	m := &syslogMessage{facility: pri / 8, severity: pri % 8}
+	if len(rest) >= 2 && rest[0] == '1' && rest[1] == ' ' {
+		err = parseRFC5424(m, rest[2:])
+	} else {
+		parseRFC3164(m, rest, now)
+	}
+	return m, err
+}
+
+func parseSyslogPRI(b []byte) (int, []byte, error) {
+	if len(b) < 3 || b[0] != '<' {
+		return 0, nil, errors.New("missing PRI")
+	}
+	end := bytes.IndexByte(b[:min(len(b), 5)], '>')
+	if end < 2 {
+		return 0, nil, errors.New("invalid PRI")
+	}
+	pri, err := strconv.Atoi(string(b[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, fmt.Errorf("invalid PRI %q", b[1:end])
+	}
+	return pri, b[end+1:], nil
+}
+
+// parseRFC5424 parses the part after "<PRI>1 ":
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(m *syslogMessage, b []byte) error {
+	var fields [5]string
+	for i := range fields {
+		sp := bytes.IndexByte(b, ' ')
+		if sp < 0 {
+			return errors.New("truncated RFC 5424 header")
+		}
+		fields[i], b = string(b[:sp]), b[sp+1:]
+	}
+	if fields[0] != syslogNil {
+		if ts, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+			m.timestamp = ts
+		}
+	}
+	m.hostname = nilToEmpty(fields[1])
+	m.appName = nilToEmpty(fields[2])
+	m.procID = nilToEmpty(fields[3])
+	m.msgID = nilToEmpty(fields[4])
+
+	rest, err := parseStructuredData(m, b)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	m.message = string(bytes.TrimPrefix(rest, utf8BOM))
+	return nil
+}
+
+func nilToEmpty(s string) string {
+	if s == syslogNil {
+		return ""
+	}
+	return s
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part, either NILVALUE or
+// one or more [SD-ID PARAM="VALUE" ...] elements, and returns what follows.
+func parseStructuredData(m *syslogMessage, b []byte) ([]byte, error) {
+	if len(b) > 0 && b[0] == '-' {
+		return b[1:], nil
+	}
+	for len(b) > 0 && b[0] == '[' {
+		b = b[1:]
+		end := bytes.IndexAny(b, " ]")
+		if end <= 0 {
+			return nil, errors.New("invalid SD-ID")
+		}
+		id := string(b[:end])
+		b = b[end:]
+		for len(b) > 0 && b[0] == ' ' {
+			b = b[1:]
+			eq := bytes.IndexByte(b, '=')
+			if eq <= 0 || eq+1 >= len(b) || b[eq+1] != '"' {
+				return nil, fmt.Errorf("invalid SD-PARAM in %q", id)
+			}
+			name := string(b[:eq])
+			value, n, err := parseSDValue(b[eq+2:])
+			if err != nil {
+				return nil, fmt.Errorf("SD-PARAM %s.%s: %w", id, name, err)
+			}
+			m.sdParams = append(m.sdParams, promLabel{name: id + "." + name, value: value})
+			b = b[eq+2+n:]
+		}
+		if len(b) == 0 || b[0] != ']' {
+			return nil, fmt.Errorf("unterminated SD-ELEMENT %q", id)
+		}
+		b = b[1:]
+	}
+	return b, nil
+}
+
+// parseSDValue reads a PARAM-VALUE up to its closing quote, resolving the
+// \" \\ and \] escapes, and returns the value and the bytes consumed.
+func parseSDValue(b []byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(b) && (b[i+1] == '"' || b[i+1] == '\\' || b[i+1] == ']') {
+				i++
+				sb.WriteByte(b[i])
+			} else {
+				sb.WriteByte(c)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, errors.New("unterminated value")
+}
+
+// parseRFC3164 parses the BSD syslog part after "<PRI>":
+// "Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG". Real-world senders omit parts
+// freely, so anything that does not fit becomes the message.
+func parseRFC3164(m *syslogMessage, b []byte, now time.Time) {
+	s := string(b)
+	if len(s) >= 16 && s[15] == ' ' {
+		// The year is implied; pick the one that does not land in the future.
+		if ts, err := time.ParseInLocation(time.Stamp, s[:15], now.Location()); err == nil {
+			ts = ts.AddDate(now.Year(), 0, 0)
+			if ts.After(now.Add(24 * time.Hour)) {
+				ts = ts.AddDate(-1, 0, 0)
+			}
+			m.timestamp = ts
+			s = s[16:]
+		}
+	}
+	if !m.timestamp.IsZero() {
+		// HOSTNAME is present unless the next word is already the tag.
+		if sp := strings.IndexByte(s, ' '); sp > 0 && !isSyslogTag(s[:sp]) {
+			m.hostname, s = s[:sp], s[sp+1:]
+		}
+	}
+	if sp := strings.IndexByte(s, ' '); sp > 0 && isSyslogTag(s[:sp]) {
+		tag := strings.TrimSuffix(s[:sp], ":")
+		if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+			m.procID = tag[open+1 : len(tag)-1]
+			tag = tag[:open]
+		}
+		m.appName, s = tag, s[sp+1:]
+	}
+	m.message = s
+}
+
+// isSyslogTag reports whether word looks like "tag:" or "tag[pid]:".
+func isSyslogTag(word string) bool {
+	return len(word) > 1 && len(word) <= 48 && word[len(word)-1] == ':'
+}
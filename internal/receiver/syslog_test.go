@@ -0,0 +1,240 @@
+package receiver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+func TestParseSyslog_RFC5424(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	raw := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication\]" eventID="1011"][examplePriority@32473 class="high"] ` + "\xef\xbb\xbf" + `An application event log entry...`
+
+	m, err := parseSyslog([]byte(raw), now)
+	if err != nil {
+		t.Fatalf("parseSyslog: %v", err)
+	}
+	if m.facility != 20 || m.severity != 5 {
+		t.Errorf("facility/severity = %d/%d, want 20/5", m.facility, m.severity)
+	}
+	if want := time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC); !m.timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", m.timestamp, want)
+	}
+	if m.hostname != "mymachine.example.com" || m.appName != "evntslog" || m.procID != "1234" || m.msgID != "ID47" {
+		t.Errorf("header = %q %q %q %q", m.hostname, m.appName, m.procID, m.msgID)
+	}
+	want := []promLabel{
+		{"exampleSDID@32473.iut", "3"},
+		{"exampleSDID@32473.eventSource", `App"lication]`},
+		{"exampleSDID@32473.eventID", "1011"},
+		{"examplePriority@32473.class", "high"},
+	}
+	if len(m.sdParams) != len(want) {
+		t.Fatalf("sd params = %v, want %v", m.sdParams, want)
+	}
+	for i := range want {
+		if m.sdParams[i] != want[i] {
+			t.Errorf("sd param %d = %v, want %v", i, m.sdParams[i], want[i])
+		}
+	}
+	if m.message != "An application event log entry..." {
+		t.Errorf("message = %q", m.message)
+	}
+}
+
+func TestParseSyslog_RFC5424Nil(t *testing.T) {
+	m, err := parseSyslog([]byte("<14>1 - - - - - -"), time.Now())
+	if err != nil {
+		t.Fatalf("parseSyslog: %v", err)
+	}
+	if !m.timestamp.IsZero() || m.hostname != "" || m.appName != "" || m.message != "" || len(m.sdParams) != 0 {
+		t.Errorf("expected empty message, got %+v", m)
+	}
+}
+
+func TestParseSyslog_RFC3164(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		raw                      string
+		host, app, proc, message string
+		year                     int
+	}{
+		{"<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8", "mymachine", "su", "", "'su root' failed for lonvick on /dev/pts/8", 2023},
+		{"<13>Jan  1 23:59:59 host sshd[4242]: Accepted publickey for admin\n", "host", "sshd", "4242", "Accepted publickey for admin", 2024},
+		{"<13>Jan  1 10:00:00 cron: job started", "", "cron", "", "job started", 2024},
+		{"<13>kernel: eth0 link up", "", "kernel", "", "eth0 link up", 0},
+		{"<13>just a message", "", "", "", "just a message", 0},
+	}
+	for _, tt := range tests {
+		m, err := parseSyslog([]byte(tt.raw), now)
+		if err != nil {
+			t.Errorf("parseSyslog(%q): %v", tt.raw, err)
+			continue
+		}
+		if m.hostname != tt.host || m.appName != tt.app || m.procID != tt.proc || m.message != tt.message {
+			t.Errorf("parseSyslog(%q) = host %q app %q proc %q msg %q", tt.raw, m.hostname, m.appName, m.procID, m.message)
+		}
+		if tt.year == 0 && !m.timestamp.IsZero() {
+			t.Errorf("parseSyslog(%q): unexpected timestamp %v", tt.raw, m.timestamp)
+		}
+		if tt.year != 0 && m.timestamp.Year() != tt.year {
+			t.Errorf("parseSyslog(%q): year = %d, want %d", tt.raw, m.timestamp.Year(), tt.year)
+		}
+	}
+}
+
+func TestParseSyslog_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "no pri", "<>1 x", "<192>1 - - - - - -", "<13>1 2024-01-01T00:00:00Z host", `<13>1 - - - - - [id k="unterminated]`} {
+		if _, err := parseSyslog([]byte(raw), time.Now()); err == nil {
+			t.Errorf("parseSyslog(%q) should fail", raw)
+		}
+	}
+}
+
+func TestReadSyslogFrame(t *testing.T) {
+	msg := "<13>1 - host app - - - octet counted\nwith newline"
+	stream := fmt.Sprintf("%d %s<13>newline framed\r\n<13>last without newline", len(msg), msg)
+	br := bufio.NewReader(strings.NewReader(stream))
+
+	var got []string
+	for {
+		frame, err := readSyslogFrame(br)
+		if err != nil {
+			break
+		}
+		got = append(got, string(frame))
+	}
+	want := []string{msg, "<13>newline framed", "<13>last without newline"}
+	if len(got) != len(want) {
+		t.Fatalf("frames = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("frame %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if _, err := readSyslogFrame(bufio.NewReader(strings.NewReader("99999999 x"))); err == nil {
+		t.Error("expected an error for an oversized octet count")
+	}
+}
+
+func TestSyslogReceiver_StoresTCPMessages(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewSyslogReceiver("127.0.0.1:0", store)
+	var activity int
+	r.OnActivity = func() { activity++ }
+
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		r.serveConn(server)
+		close(done)
+	}()
+
+	msgs := []string{
+		`<11>1 2024-01-01T00:00:00Z web01 nginx 77 - [req@1 path="/api/users/1"] upstream timed out for user 1`,
+		`<11>1 2024-01-01T00:00:01Z web01 nginx 77 - [req@1 path="/api/users/2"] upstream timed out for user 2`,
+		`<14>Jan  1 00:00:02 web02 cron[99]: job finished`,
+	}
+	for _, m := range msgs {
+		if _, err := fmt.Fprintf(client, "%d %s", len(m), m); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	client.Close()
+	<-done
+
+	ctx := context.Background()
+	errLog, err := store.GetLog(ctx, "ERROR")
+	if err != nil {
+		t.Fatalf("GetLog(ERROR): %v", err)
+	}
+	if errLog.SeverityNumber != 17 {
+		t.Errorf("severity number = %d, want 17", errLog.SeverityNumber)
+	}
+	if _, ok := errLog.Services["nginx"]; !ok {
+		t.Errorf("services = %v, want nginx", errLog.Services)
+	}
+	if _, ok := errLog.ResourceKeys["host.name"]; !ok {
+		t.Errorf("expected host.name resource key, got %v", errLog.ResourceKeys)
+	}
+	for _, key := range []string{"req@1.path", "syslog.facility", "syslog.procid"} {
+		if _, ok := errLog.AttributeKeys[key]; !ok {
+			t.Errorf("expected attribute %q, got %v", key, errLog.AttributeKeys)
+		}
+	}
+	if len(errLog.BodyTemplates) != 1 {
+		t.Errorf("expected both lines in one template, got %d", len(errLog.BodyTemplates))
+	}
+
+	info, err := store.GetLog(ctx, "INFO")
+	if err != nil {
+		t.Fatalf("GetLog(INFO): %v", err)
+	}
+	if _, ok := info.Services["cron"]; !ok {
+		t.Errorf("services = %v, want cron", info.Services)
+	}
+	if activity == 0 {
+		t.Error("expected OnActivity to be called")
+	}
+}
+
+func TestSyslogReceiver_StartShutdown(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+
+	// Reserve a port that is free for TCP; UDP on the same port is almost
+	// always free too.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	r := NewSyslogReceiver(addr, store)
+	errc := make(chan error, 1)
+	go func() { errc <- r.Start() }()
+
+	var conn net.Conn
+	for i := 0; i < 50; i++ {
+		if conn, err = net.Dial("udp", addr); err == nil {
+			if _, err = conn.Write([]byte("<13>1 - host app - - - hello over udp")); err == nil {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("udp send: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if lm, err := store.GetLog(context.Background(), "INFO"); err == nil && lm.Services["app"] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("UDP message was not stored")
+		}
+		// The first datagram may race the listener; resend until stored.
+		conn.Write([]byte("<13>1 - host app - - - hello over udp"))
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := r.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
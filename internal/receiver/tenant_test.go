@@ -0,0 +1,147 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/ingest"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+)
+
+func TestHandleMetrics_RoutesByTenantHeader(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+
+	r := NewHTTPReceiver(":0", reg.Storage())
+	r.Tenants = reg
+	handler := r.handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set(tenant.DefaultHeader, "team-a")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	teamA, err := reg.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, err := teamA.Store.GetMetric(context.Background(), "test.counter"); err != nil {
+		t.Errorf("metric not stored for team-a: %v", err)
+	}
+	if _, err := reg.Default().Store.GetMetric(context.Background(), "test.counter"); err == nil {
+		t.Error("metric leaked into the default tenant")
+	}
+}
+
+func TestHandleTraces_SpanMetricsPerTenantOnPipeline(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+	cfg, err := spanmetrics.Parse([]byte("dimensions: [{name: http.route}]"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	est := spanmetrics.New(cfg)
+
+	r := NewHTTPReceiver(":0", reg.Storage())
+	r.Tenants = reg
+	r.Pipeline = ingest.New(ingest.DefaultConfig())
+	r.SpanMetrics = est
+	handler := r.handler()
+
+	body, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{Key: "service.name", Value: &commonpb.AnyValue{
+			Value: &commonpb.AnyValue_StringValue{StringValue: "catalog"},
+		}}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{Name: "GET", Kind: tracepb.Span_SPAN_KIND_SERVER}}}},
+	}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/traces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set(tenant.DefaultHeader, "team-a")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	drain(t, r.Pipeline)
+
+	teamA, err := reg.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got := est.Estimate(tenant.NewContext(context.Background(), teamA)); len(got.Services) != 1 {
+		t.Errorf("team-a estimate = %+v, want the catalog service", got.Services)
+	}
+	if got := est.Estimate(context.Background()); len(got.Services) != 0 {
+		t.Errorf("spans leaked into the default tenant estimate: %+v", got.Services)
+	}
+}
+
+func TestDropTenant_ReleasesAnalyzers(t *testing.T) {
+	reg, err := tenant.New(tenant.Config{
+		Storage:  storage.DefaultConfig(),
+		Sessions: sessions.Config{SessionDir: t.TempDir(), MaxSessions: 10},
+	})
+	if err != nil {
+		t.Fatalf("tenant.New: %v", err)
+	}
+	defer reg.Close()
+
+	r := NewHTTPReceiver(":0", reg.Storage())
+	r.Tenants = reg
+	reg.OnDrop(r.DropTenant)
+	handler := r.handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(minimalMetricsProto(t)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set(tenant.DefaultHeader, "team-a")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(r.analyzers.byTenant) != 1 {
+		t.Fatalf("analyzer sets = %d, want 1", len(r.analyzers.byTenant))
+	}
+
+	teamA, err := reg.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if err := reg.Storage().Clear(tenant.NewContext(context.Background(), teamA)); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if len(r.analyzers.byTenant) != 0 {
+		t.Errorf("analyzer sets after clear = %d, want 0", len(r.analyzers.byTenant))
+	}
+}
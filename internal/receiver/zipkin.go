@@ -0,0 +1,303 @@
+package receiver
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// zipkinScope is the instrumentation scope recorded for spans that arrived
+// via the Zipkin v2 API.
+const zipkinScope = "zipkin.v2"
+
+// zipkinSpan is a Zipkin v2 span. The JSON field names follow the Zipkin v2
+// API; the proto3 encoding (zipkin.proto3.Span) is decoded into the same
+// struct.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ParentID       string             `json:"parentId"`
+	ID             string             `json:"id"`
+	Kind           string             `json:"kind"`
+	Name           string             `json:"name"`
+	Timestamp      uint64             `json:"timestamp"` // microseconds
+	Duration       uint64             `json:"duration"`  // microseconds
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// Span kinds as encoded in zipkin.proto3.Span.Kind.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+// handleZipkin handles Zipkin v2 span uploads (POST /api/v2/spans) in JSON
+// or proto3 encoding.
+func (r *HTTPReceiver) handleZipkin(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if rejectIfBodyTooLarge(w, req) {
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
+	defer req.Body.Close()
+
+	body, releaseBody, err := readAndDecompressBody(req)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, errUnsupportedEncoding) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var spans []zipkinSpan
+	if strings.Contains(strings.ToLower(req.Header.Get("Content-Type")), "protobuf") {
+		spans, err = decodeZipkinProto(body)
+	} else {
+		err = json.Unmarshal(sanitizeUTF8(body), &spans)
+	}
+	releaseBody()
+	if err != nil {
+		log.Printf("Failed to parse Zipkin request: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if verboseLogging {
+		fmt.Printf("Received Zipkin request: %d spans\n", len(spans))
+	}
+
+	r.acceptTraces(req.Context(), w, zipkinToOTLP(spans))
+}
+
+// decodeZipkinProto decodes a zipkin.proto3.ListOfSpans message.
+func decodeZipkinProto(b []byte) ([]zipkinSpan, error) {
+	var spans []zipkinSpan
+	err := forEachField(b, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		s, err := decodeZipkinProtoSpan(f.bytes)
+		if err != nil {
+			return fmt.Errorf("span: %w", err)
+		}
+		spans = append(spans, s)
+		return nil
+	})
+	return spans, err
+}
+
+func decodeZipkinProtoSpan(b []byte) (zipkinSpan, error) {
+	var s zipkinSpan
+	err := forEachField(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.typ == protowire.BytesType:
+			s.TraceID = hex.EncodeToString(f.bytes)
+		case f.num == 2 && f.typ == protowire.BytesType:
+			s.ParentID = hex.EncodeToString(f.bytes)
+		case f.num == 3 && f.typ == protowire.BytesType:
+			s.ID = hex.EncodeToString(f.bytes)
+		case f.num == 4 && f.typ == protowire.VarintType:
+			s.Kind = zipkinProtoKinds[f.scalar]
+		case f.num == 5 && f.typ == protowire.BytesType:
+			s.Name = string(f.bytes)
+		case f.num == 6 && f.typ == protowire.Fixed64Type:
+			s.Timestamp = f.scalar
+		case f.num == 7 && f.typ == protowire.VarintType:
+			s.Duration = f.scalar
+		case (f.num == 8 || f.num == 9) && f.typ == protowire.BytesType:
+			ep, err := decodeZipkinProtoEndpoint(f.bytes)
+			if err != nil {
+				return fmt.Errorf("endpoint: %w", err)
+			}
+			if f.num == 8 {
+				s.LocalEndpoint = ep
+			} else {
+				s.RemoteEndpoint = ep
+			}
+		case f.num == 10 && f.typ == protowire.BytesType:
+			var a zipkinAnnotation
+			err := forEachField(f.bytes, func(af protoField) error {
+				switch {
+				case af.num == 1 && af.typ == protowire.Fixed64Type:
+					a.Timestamp = af.scalar
+				case af.num == 2 && af.typ == protowire.BytesType:
+					a.Value = string(af.bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("annotation: %w", err)
+			}
+			s.Annotations = append(s.Annotations, a)
+		case f.num == 11 && f.typ == protowire.BytesType:
+			// Map entries are messages with key = 1 and value = 2.
+			var key, value string
+			err := forEachField(f.bytes, func(tf protoField) error {
+				switch {
+				case tf.num == 1 && tf.typ == protowire.BytesType:
+					key = string(tf.bytes)
+				case tf.num == 2 && tf.typ == protowire.BytesType:
+					value = string(tf.bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("tag: %w", err)
+			}
+			if s.Tags == nil {
+				s.Tags = make(map[string]string)
+			}
+			s.Tags[key] = value
+		}
+		return nil
+	})
+	return s, err
+}
+
+func decodeZipkinProtoEndpoint(b []byte) (*zipkinEndpoint, error) {
+	ep := &zipkinEndpoint{}
+	err := forEachField(b, func(f protoField) error {
+		if f.num == 1 && f.typ == protowire.BytesType {
+			ep.ServiceName = string(f.bytes)
+		}
+		return nil
+	})
+	return ep, err
+}
+
+// zipkinToOTLP converts Zipkin v2 spans into an OTLP export request, grouped
+// into one resource per local service name. The mapping follows the Zipkin
+// receiver in the OTel Collector: tags become span attributes, the remote
+// service becomes peer.service, annotations become events and an "error"
+// tag sets the span status.
+func zipkinToOTLP(spans []zipkinSpan) *coltracepb.ExportTraceServiceRequest {
+	resources := make(map[string]*tracepb.ResourceSpans)
+	var order []string
+
+	for _, zs := range spans {
+		service := ""
+		if zs.LocalEndpoint != nil {
+			service = zs.LocalEndpoint.ServiceName
+		}
+		rs, ok := resources[service]
+		if !ok {
+			var resAttrs []*commonpb.KeyValue
+			if service != "" {
+				resAttrs = append(resAttrs, stringKeyValue("service.name", service))
+			}
+			rs = &tracepb.ResourceSpans{
+				Resource: &resourcepb.Resource{Attributes: resAttrs},
+				ScopeSpans: []*tracepb.ScopeSpans{
+					{Scope: &commonpb.InstrumentationScope{Name: zipkinScope}},
+				},
+			}
+			resources[service] = rs
+			order = append(order, service)
+		}
+
+		span := &tracepb.Span{
+			TraceId:           zipkinID(zs.TraceID, 16),
+			SpanId:            zipkinID(zs.ID, 8),
+			ParentSpanId:      zipkinID(zs.ParentID, 8),
+			Name:              zs.Name,
+			Kind:              zipkinKind(zs.Kind),
+			StartTimeUnixNano: zs.Timestamp * 1e3,
+			EndTimeUnixNano:   (zs.Timestamp + zs.Duration) * 1e3,
+		}
+		for _, key := range slices.Sorted(maps.Keys(zs.Tags)) {
+			if key == "error" {
+				span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: zs.Tags[key]}
+				continue
+			}
+			span.Attributes = append(span.Attributes, stringKeyValue(key, zs.Tags[key]))
+		}
+		if zs.RemoteEndpoint != nil && zs.RemoteEndpoint.ServiceName != "" {
+			span.Attributes = append(span.Attributes, stringKeyValue("peer.service", zs.RemoteEndpoint.ServiceName))
+		}
+		for _, a := range zs.Annotations {
+			span.Events = append(span.Events, &tracepb.Span_Event{
+				Name:         a.Value,
+				TimeUnixNano: a.Timestamp * 1e3,
+			})
+		}
+
+		ss := rs.ScopeSpans[0]
+		ss.Spans = append(ss.Spans, span)
+	}
+
+	req := &coltracepb.ExportTraceServiceRequest{}
+	for _, service := range order {
+		req.ResourceSpans = append(req.ResourceSpans, resources[service])
+	}
+	return req
+}
+
+func zipkinKind(kind string) tracepb.Span_SpanKind {
+	switch strings.ToUpper(kind) {
+	case "CLIENT":
+		return tracepb.Span_SPAN_KIND_CLIENT
+	case "SERVER":
+		return tracepb.Span_SPAN_KIND_SERVER
+	case "PRODUCER":
+		return tracepb.Span_SPAN_KIND_PRODUCER
+	case "CONSUMER":
+		return tracepb.Span_SPAN_KIND_CONSUMER
+	default:
+		// Zipkin spans without a kind are local (in-process) operations.
+		return tracepb.Span_SPAN_KIND_INTERNAL
+	}
+}
+
+// zipkinID decodes a hex trace or span ID into a big-endian ID of size
+// bytes, left-padding 64-bit trace IDs. Malformed IDs are dropped; the
+// analyzer does not depend on them.
+func zipkinID(id string, size int) []byte {
+	if id == "" || len(id) > size*2 {
+		return nil
+	}
+	if len(id)%2 == 1 {
+		id = "0" + id
+	}
+	b, err := hex.DecodeString(id)
+	if err != nil {
+		return nil
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
@@ -0,0 +1,177 @@
+package receiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+)
+
+const testZipkinJSON = `[
+  {
+    "traceId": "5af7183fb1d4cf5f",
+    "id": "352bff9a74ca9ad2",
+    "name": "get /api/users/{id}",
+    "kind": "SERVER",
+    "timestamp": 1700000000000000,
+    "duration": 1500,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1"},
+    "remoteEndpoint": {"serviceName": "browser"},
+    "annotations": [{"timestamp": 1700000000000100, "value": "wire.send"}],
+    "tags": {"http.method": "GET", "http.path": "/api/users/42", "error": "timeout"}
+  },
+  {
+    "traceId": "5af7183fb1d4cf5f",
+    "parentId": "352bff9a74ca9ad2",
+    "id": "4c6cd1e8a5d2a4b1",
+    "name": "select users",
+    "timestamp": 1700000000000200,
+    "duration": 300,
+    "localEndpoint": {"serviceName": "frontend"}
+  }
+]`
+
+func postZipkin(t *testing.T, r *HTTPReceiver, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	r.handleZipkin(rec, req)
+	return rec
+}
+
+func TestZipkin_StoresJSONSpans(t *testing.T) {
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+
+	rec := postZipkin(t, r, "application/json", []byte(testZipkinJSON))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	span, err := store.GetSpan(ctx, "get /api/users/{id}")
+	if err != nil {
+		t.Fatalf("GetSpan: %v", err)
+	}
+	if span.KindName != "Server" {
+		t.Errorf("kind = %q, want Server", span.KindName)
+	}
+	for _, key := range []string{"http.method", "http.path", "peer.service"} {
+		if _, ok := span.AttributeKeys[key]; !ok {
+			t.Errorf("expected attribute key %q, got %v", key, span.AttributeKeys)
+		}
+	}
+	if _, ok := span.AttributeKeys["error"]; ok {
+		t.Error("error tag should become the span status, not an attribute")
+	}
+	if len(span.EventNames) != 1 || span.EventNames[0] != "wire.send" {
+		t.Errorf("event names = %v, want [wire.send]", span.EventNames)
+	}
+	if span.Services["frontend"] != 1 {
+		t.Errorf("services = %v, want frontend", span.Services)
+	}
+
+	local, err := store.GetSpan(ctx, "select users")
+	if err != nil {
+		t.Fatalf("GetSpan(select users): %v", err)
+	}
+	if local.KindName != "Internal" {
+		t.Errorf("span without kind: kind = %q, want Internal", local.KindName)
+	}
+}
+
+func TestZipkin_StoresProtoSpans(t *testing.T) {
+	var ep []byte
+	ep = protowire.AppendTag(ep, 1, protowire.BytesType)
+	ep = protowire.AppendString(ep, "checkout")
+
+	var tag []byte
+	tag = protowire.AppendTag(tag, 1, protowire.BytesType)
+	tag = protowire.AppendString(tag, "order.id")
+	tag = protowire.AppendTag(tag, 2, protowire.BytesType)
+	tag = protowire.AppendString(tag, "1234")
+
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span = protowire.AppendTag(span, 4, protowire.VarintType)
+	span = protowire.AppendVarint(span, 3) // PRODUCER
+	span = protowire.AppendTag(span, 5, protowire.BytesType)
+	span = protowire.AppendString(span, "publish orders")
+	span = protowire.AppendTag(span, 6, protowire.Fixed64Type)
+	span = protowire.AppendFixed64(span, 1700000000000000)
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, ep)
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag)
+
+	var list []byte
+	list = protowire.AppendTag(list, 1, protowire.BytesType)
+	list = protowire.AppendBytes(list, span)
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	r := NewHTTPReceiver(":0", store)
+	rec := postZipkin(t, r, "application/x-protobuf", list)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	got, err := store.GetSpan(context.Background(), "publish orders")
+	if err != nil {
+		t.Fatalf("GetSpan: %v", err)
+	}
+	if got.KindName != "Producer" {
+		t.Errorf("kind = %q, want Producer", got.KindName)
+	}
+	if _, ok := got.AttributeKeys["order.id"]; !ok {
+		t.Errorf("expected attribute key order.id, got %v", got.AttributeKeys)
+	}
+	if got.Services["checkout"] != 1 {
+		t.Errorf("services = %v, want checkout", got.Services)
+	}
+}
+
+func TestZipkin_RejectsInvalidRequests(t *testing.T) {
+	r := NewHTTPReceiver(":0", storage.NewStorage(storage.DefaultConfig()))
+
+	if rec := postZipkin(t, r, "application/json", []byte(`{"not": "a list"}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed JSON: expected 400, got %d", rec.Code)
+	}
+	if rec := postZipkin(t, r, "application/x-protobuf", []byte{0x0a, 0xff}); rec.Code != http.StatusBadRequest {
+		t.Errorf("truncated proto: expected 400, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/spans", nil)
+	rec := httptest.NewRecorder()
+	r.handleZipkin(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", rec.Code)
+	}
+}
+
+func TestZipkinID(t *testing.T) {
+	tests := []struct {
+		id   string
+		size int
+		want []byte
+	}{
+		{"5af7183fb1d4cf5f", 16, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f}},
+		{"abc", 8, []byte{0, 0, 0, 0, 0, 0, 0x0a, 0xbc}},
+		{"", 8, nil},
+		{"zz", 8, nil},
+		{"0123456789abcdef0", 8, nil},
+	}
+	for _, tt := range tests {
+		if got := zipkinID(tt.id, tt.size); !bytes.Equal(got, tt.want) {
+			t.Errorf("zipkinID(%q, %d) = %x, want %x", tt.id, tt.size, got, tt.want)
+		}
+	}
+}
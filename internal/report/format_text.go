@@ -3,6 +3,8 @@ package report
 import (
 	"fmt"
 	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 )
 
 // FormatText formats a report as human-readable plain text.
@@ -22,8 +24,12 @@ func FormatText(r *Report) ([]byte, error) {
 	fmt.Fprintf(&b, "Metrics:       %d\n", r.Summary.TotalMetrics)
 	fmt.Fprintf(&b, "Spans:         %d\n", r.Summary.TotalSpanNames)
 	fmt.Fprintf(&b, "Log patterns:  %d\n", r.Summary.TotalLogPatterns)
+	fmt.Fprintf(&b, "Profile types: %d\n", r.Summary.TotalProfileTypes)
 	fmt.Fprintf(&b, "Attributes:    %d\n", r.Summary.TotalAttributes)
 	fmt.Fprintf(&b, "High cardinality: %d\n", r.Summary.HighCardinalityCount)
+	fmt.Fprintf(&b, "Metric conflicts: %d\n", r.Summary.MetricConflictCount)
+	fmt.Fprintf(&b, "Semconv warnings: %d\n", r.Summary.SemconvWarningCount)
+	fmt.Fprintf(&b, "Lint violations:  %d\n", r.Summary.LintViolationCount)
 
 	if len(r.Metrics) > 0 {
 		b.WriteString("\nMetrics (sorted by cardinality)\n")
@@ -38,10 +44,57 @@ func FormatText(r *Report) ([]byte, error) {
 			fmt.Fprintf(&b, "          Labels: %s\n", strings.Join(m.LabelKeys, ", "))
 			fmt.Fprintf(&b, "          Cardinality: %s | Samples: %s\n",
 				formatNumber(m.EstimatedCardinality), formatNumber(m.SampleCount))
+			if len(m.Windows) > 0 {
+				parts := make([]string, len(m.Windows))
+				for i, wc := range m.Windows {
+					parts[i] = wc.Window + ": " + formatNumber(wc.ActiveSeries)
+				}
+				fmt.Fprintf(&b, "          Active series: %s\n", strings.Join(parts, " | "))
+			}
 			b.WriteString("\n")
 		}
 	}
 
+	if len(r.MetricConflicts) > 0 {
+		b.WriteString("Metric conflicts (same name, different definition)\n")
+		b.WriteString("--------------------------------------------------\n")
+		for _, c := range r.MetricConflicts {
+			fmt.Fprintf(&b, "%-9s %s — differs in %s\n", "CONFLICT", c.Name, strings.Join(c.ConflictingFields, ", "))
+			for _, v := range c.Variants {
+				fmt.Fprintf(&b, "          %s: %s | Samples: %s\n",
+					formatVariant(v), strings.Join(v.Services, ", "), formatNumber(v.SampleCount))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(r.SemconvFindings) > 0 {
+		title := fmt.Sprintf("Semantic conventions (registry %s)", r.SemconvVersion)
+		b.WriteString(title + "\n")
+		b.WriteString(strings.Repeat("-", len(title)) + "\n")
+		for _, f := range r.SemconvFindings {
+			name := f.Name
+			if f.Key != "" {
+				name += " / " + f.Key
+			}
+			fmt.Fprintf(&b, "%-9s %s %s — %s\n", strings.ToUpper(f.Severity), f.Signal, name, f.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.LintViolations) > 0 {
+		b.WriteString("Naming lint violations\n")
+		b.WriteString("----------------------\n")
+		for _, v := range r.LintViolations {
+			fmt.Fprintf(&b, "%-9s [%s] %s %s — %s\n", severityTag(v.Severity), v.Rule, v.Target, v.Name, v.Message)
+			fmt.Fprintf(&b, "          Services: %s\n", strings.Join(v.Services, ", "))
+			if len(v.FoundIn) > 0 {
+				fmt.Fprintf(&b, "          Found in: %s\n", strings.Join(v.FoundIn, ", "))
+			}
+		}
+		b.WriteString("\n")
+	}
+
 	if len(r.Spans) > 0 {
 		b.WriteString("Spans (sorted by cardinality)\n")
 		b.WriteString("-----------------------------\n")
@@ -55,6 +108,26 @@ func FormatText(r *Report) ([]byte, error) {
 		}
 	}
 
+	if sm := r.SpanMetrics; sm != nil {
+		b.WriteString("Spanmetrics connector projection\n")
+		b.WriteString("--------------------------------\n")
+		fmt.Fprintf(&b, "Dimensions: %s\n", strings.Join(sm.Dimensions, ", "))
+		histogram := sm.Histogram
+		if sm.Buckets > 0 {
+			histogram = fmt.Sprintf("%s, %d buckets", histogram, sm.Buckets)
+		}
+		fmt.Fprintf(&b, "Histogram:  %s | Per combination: %d OTLP / %d Prometheus series\n",
+			histogram, sm.SeriesPerCombinationOTLP, sm.SeriesPerCombinationPrometheus)
+		fmt.Fprintf(&b, "%-9s %s\n", "TOTAL", formatSpanMetricsProjection(&sm.Total))
+		fmt.Fprintf(&b, "          Values: %s\n", formatDimensionValues(sm.Dimensions, sm.Total.DimensionValues))
+		for _, s := range sm.Services {
+			fmt.Fprintf(&b, "%-9s %s\n", "SERVICE", s.Service)
+			fmt.Fprintf(&b, "          %s\n", formatSpanMetricsProjection(&s.SpanMetricsProjection))
+			fmt.Fprintf(&b, "          Values: %s\n", formatDimensionValues(sm.Dimensions[1:], s.DimensionValues))
+		}
+		b.WriteString("\n")
+	}
+
 	if len(r.Logs) > 0 {
 		b.WriteString("Logs (sorted by cardinality)\n")
 		b.WriteString("----------------------------\n")
@@ -68,6 +141,19 @@ func FormatText(r *Report) ([]byte, error) {
 		}
 	}
 
+	if len(r.Profiles) > 0 {
+		b.WriteString("Profiles (sorted by cardinality)\n")
+		b.WriteString("--------------------------------\n")
+		for _, p := range r.Profiles {
+			tag := severityTag(p.Severity)
+			fmt.Fprintf(&b, "%-9s %s\n", tag, p.ID)
+			fmt.Fprintf(&b, "          Attributes: %s\n", strings.Join(p.AttributeKeys, ", "))
+			fmt.Fprintf(&b, "          Cardinality: %s | Profiles: %s | Samples: %s\n",
+				formatNumber(p.EstimatedCardinality), formatNumber(p.ProfileCount), formatNumber(p.SampleCount))
+			b.WriteString("\n")
+		}
+	}
+
 	if len(r.Attributes) > 0 {
 		b.WriteString("Attributes (cross-signal)\n")
 		b.WriteString("-------------------------\n")
@@ -82,6 +168,23 @@ func FormatText(r *Report) ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// formatVariant renders a metric definition as e.g.
+// `Sum CUMULATIVE monotonic unit="ms"`.
+func formatVariant(v MetricConflictVariant) string {
+	parts := []string{v.Type}
+	if v.AggregationTemporality != "" {
+		parts = append(parts, v.AggregationTemporality)
+	}
+	if v.IsMonotonic {
+		parts = append(parts, "monotonic")
+	}
+	parts = append(parts, fmt.Sprintf("unit=%q", v.Unit))
+	if v.Description != "" {
+		parts = append(parts, fmt.Sprintf("description=%q", v.Description))
+	}
+	return strings.Join(parts, " ")
+}
+
 func severityTag(sev string) string {
 	switch sev {
 	case SeverityCritical:
@@ -94,6 +197,24 @@ func severityTag(sev string) string {
 }
 
 // formatNumber formats an int64 with thousand separators.
+func formatSpanMetricsProjection(p *models.SpanMetricsProjection) string {
+	s := fmt.Sprintf("Combinations: %s | Series: %s OTLP / %s Prometheus | Spans: %s",
+		formatNumber(p.Combinations), formatNumber(p.SeriesOTLP), formatNumber(p.SeriesPrometheus), formatNumber(p.Spans))
+	if p.MaxExemplars > 0 {
+		s += " | Exemplars: up to " + formatNumber(p.MaxExemplars)
+	}
+	return s
+}
+
+// formatDimensionValues lists the unique values of each dimension in dims.
+func formatDimensionValues(dims []string, values map[string]int64) string {
+	parts := make([]string, len(dims))
+	for i, d := range dims {
+		parts[i] = d + " " + formatNumber(values[d])
+	}
+	return strings.Join(parts, " | ")
+}
+
 func formatNumber(n int64) string {
 	s := fmt.Sprintf("%d", n)
 	if len(s) <= 3 {
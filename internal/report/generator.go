@@ -5,6 +5,9 @@ import (
 	"sort"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/lint"
+	"github.com/fidde/otlp_cardinality_checker/internal/semconv"
+	"github.com/fidde/otlp_cardinality_checker/internal/spanmetrics"
 	"github.com/fidde/otlp_cardinality_checker/internal/storage"
 	"github.com/fidde/otlp_cardinality_checker/internal/version"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
@@ -13,6 +16,11 @@ import (
 // Generator builds a Report from storage data.
 type Generator struct {
 	store storage.Storage
+
+	// Lint holds the naming rules to check; nil skips the lint section.
+	Lint *lint.RuleSet
+	// SpanMetrics projects the spanmetrics connector output; nil skips it.
+	SpanMetrics *spanmetrics.Estimator
 }
 
 // NewGenerator creates a new report generator.
@@ -34,10 +42,24 @@ func (g *Generator) Generate(ctx context.Context, duration time.Duration) (*Repo
 	if err != nil {
 		return nil, err
 	}
+	var profiles []*models.ProfileMetadata
+	if ps, ok := g.store.(storage.ProfileStore); ok {
+		profiles, err = ps.ListProfiles(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+	}
 	attrs, err := g.store.ListAttributes(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	var conflicts *models.MetricConflictsResponse
+	if cs, ok := g.store.(storage.MetricConflictStore); ok {
+		conflicts, err = cs.GetMetricConflicts(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	rpt := &Report{
 		Version:     "1.0",
@@ -51,7 +73,16 @@ func (g *Generator) Generate(ctx context.Context, duration time.Duration) (*Repo
 	rpt.Metrics = buildMetricItems(metrics)
 	rpt.Spans = buildSpanItems(spans)
 	rpt.Logs = buildLogItems(logs)
+	rpt.Profiles = buildProfileItems(profiles)
 	rpt.Attributes = buildAttrItems(attrs)
+	rpt.MetricConflicts = buildMetricConflictItems(conflicts)
+	findings := semconv.Default().Validate(metrics, spans, attrs)
+	rpt.SemconvVersion = findings.RegistryVersion
+	rpt.SemconvFindings = buildSemconvItems(findings)
+	rpt.LintViolations = buildLintItems(g.Lint.Check(metrics, spans, logs))
+	if g.SpanMetrics != nil {
+		rpt.SpanMetrics = g.SpanMetrics.Estimate(ctx)
+	}
 
 	rpt.Summary = buildSummary(rpt)
 
@@ -59,6 +90,7 @@ func (g *Generator) Generate(ctx context.Context, duration time.Duration) (*Repo
 }
 
 func buildMetricItems(metrics []*models.MetricMetadata) []MetricItem {
+	windows := models.StandardWindows()
 	items := make([]MetricItem, 0, len(metrics))
 	for _, m := range metrics {
 		cardinality := m.GetActiveSeries()
@@ -74,6 +106,7 @@ func buildMetricItems(metrics []*models.MetricMetadata) []MetricItem {
 			SampleCount:          m.SampleCount,
 			EstimatedCardinality: cardinality,
 			Severity:             CardinalitySeverity(cardinality),
+			Windows:              windowCounts(m, windows),
 		})
 	}
 	sort.Slice(items, func(i, j int) bool {
@@ -82,6 +115,37 @@ func buildMetricItems(metrics []*models.MetricMetadata) []MetricItem {
 	return items
 }
 
+func buildMetricConflictItems(conflicts *models.MetricConflictsResponse) []MetricConflictItem {
+	if conflicts == nil {
+		return nil
+	}
+	items := make([]MetricConflictItem, 0, len(conflicts.Conflicts))
+	for _, c := range conflicts.Conflicts {
+		item := MetricConflictItem{
+			Name:              c.Name,
+			ConflictingFields: c.ConflictingFields,
+		}
+		for _, v := range c.Variants {
+			services := make([]string, 0, len(v.Services))
+			for service := range v.Services {
+				services = append(services, service)
+			}
+			sort.Strings(services)
+			item.Variants = append(item.Variants, MetricConflictVariant{
+				Type:                   v.Type,
+				Unit:                   v.Unit,
+				AggregationTemporality: v.AggregationTemporality,
+				IsMonotonic:            v.IsMonotonic,
+				Description:            v.Description,
+				Services:               services,
+				SampleCount:            v.SampleCount,
+			})
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
 func buildSpanItems(spans []*models.SpanMetadata) []SpanItem {
 	items := make([]SpanItem, 0, len(spans))
 	for _, s := range spans {
@@ -120,6 +184,26 @@ func buildLogItems(logs []*models.LogMetadata) []LogItem {
 	return items
 }
 
+func buildProfileItems(profiles []*models.ProfileMetadata) []ProfileItem {
+	items := make([]ProfileItem, 0, len(profiles))
+	for _, p := range profiles {
+		cardinality := maxKeyCardinality(p.AttributeKeys)
+		keys := sortedKeys(p.AttributeKeys)
+		items = append(items, ProfileItem{
+			ID:                   p.ID,
+			AttributeKeys:        keys,
+			ProfileCount:         p.ProfileCount,
+			SampleCount:          p.SampleCount,
+			EstimatedCardinality: cardinality,
+			Severity:             CardinalitySeverity(cardinality),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].EstimatedCardinality > items[j].EstimatedCardinality
+	})
+	return items
+}
+
 func buildAttrItems(attrs []*models.AttributeMetadata) []AttrItem {
 	items := make([]AttrItem, 0, len(attrs))
 	for _, a := range attrs {
@@ -136,12 +220,55 @@ func buildAttrItems(attrs []*models.AttributeMetadata) []AttrItem {
 	return items
 }
 
+func buildSemconvItems(findings *models.SemconvFindingsResponse) []SemconvItem {
+	items := make([]SemconvItem, 0, len(findings.Findings))
+	for _, f := range findings.Findings {
+		items = append(items, SemconvItem{
+			Signal:      f.Signal,
+			Name:        f.Name,
+			Key:         f.Key,
+			Rule:        f.Rule,
+			Severity:    f.Severity,
+			Message:     f.Message,
+			Replacement: f.Replacement,
+		})
+	}
+	return items
+}
+
+func buildLintItems(resp *models.LintResponse) []LintItem {
+	if resp.Total == 0 {
+		return nil
+	}
+	items := make([]LintItem, 0, resp.Total)
+	for _, v := range resp.Violations {
+		items = append(items, LintItem{
+			Rule:     v.Rule,
+			Target:   v.Target,
+			Name:     v.Name,
+			Severity: v.Severity,
+			Message:  v.Message,
+			FoundIn:  v.FoundIn,
+			Services: v.Services,
+		})
+	}
+	return items
+}
+
 func buildSummary(rpt *Report) Summary {
 	s := Summary{
-		TotalMetrics:     len(rpt.Metrics),
-		TotalSpanNames:   len(rpt.Spans),
-		TotalLogPatterns: len(rpt.Logs),
-		TotalAttributes:  len(rpt.Attributes),
+		TotalMetrics:        len(rpt.Metrics),
+		TotalSpanNames:      len(rpt.Spans),
+		TotalLogPatterns:    len(rpt.Logs),
+		TotalProfileTypes:   len(rpt.Profiles),
+		TotalAttributes:     len(rpt.Attributes),
+		MetricConflictCount: len(rpt.MetricConflicts),
+		LintViolationCount:  len(rpt.LintViolations),
+	}
+	for _, f := range rpt.SemconvFindings {
+		if f.Severity == models.SemconvSeverityWarning {
+			s.SemconvWarningCount++
+		}
 	}
 	for _, m := range rpt.Metrics {
 		s.Samples.Metrics += m.SampleCount
@@ -161,9 +288,24 @@ func buildSummary(rpt *Report) Summary {
 			s.HighCardinalityCount++
 		}
 	}
+	for _, p := range rpt.Profiles {
+		s.Samples.Profiles += p.SampleCount
+		if p.Severity == SeverityWarning || p.Severity == SeverityCritical {
+			s.HighCardinalityCount++
+		}
+	}
 	return s
 }
 
+// windowCounts returns the metric's active series within each window.
+func windowCounts(m *models.MetricMetadata, windows []time.Duration) []WindowCount {
+	counts := make([]WindowCount, 0, len(windows))
+	for _, d := range windows {
+		counts = append(counts, WindowCount{Window: models.FormatWindow(d), ActiveSeries: m.ActiveSeriesIn(d)})
+	}
+	return counts
+}
+
 // maxKeyCardinality returns the maximum EstimatedCardinality across all keys.
 func maxKeyCardinality(keys map[string]*models.KeyMetadata) int64 {
 	var max int64
@@ -2,9 +2,12 @@ package report
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/fidde/otlp_cardinality_checker/internal/lint"
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
 )
 
@@ -20,6 +23,9 @@ func TestGenerator_Generate(t *testing.T) {
 		logs: []*models.LogMetadata{
 			newTestLog("INFO", 2000, "source"),
 		},
+		profiles: []*models.ProfileMetadata{
+			newTestProfile("cpu", "nanoseconds", 300, "thread.name"),
+		},
 		attrs: []*models.AttributeMetadata{
 			{Key: "user_id", EstimatedCardinality: 5000, SignalTypes: []string{"metric", "span"}},
 		},
@@ -46,6 +52,15 @@ func TestGenerator_Generate(t *testing.T) {
 	if rpt.Summary.TotalLogPatterns != 1 {
 		t.Errorf("total_logs = %d, want 1", rpt.Summary.TotalLogPatterns)
 	}
+	if rpt.Summary.TotalProfileTypes != 1 {
+		t.Errorf("total_profile_types = %d, want 1", rpt.Summary.TotalProfileTypes)
+	}
+	if rpt.Summary.Samples.Profiles != 300 {
+		t.Errorf("profile samples = %d, want 300", rpt.Summary.Samples.Profiles)
+	}
+	if len(rpt.Profiles) != 1 || rpt.Profiles[0].ID != "cpu:nanoseconds" {
+		t.Errorf("profiles = %+v", rpt.Profiles)
+	}
 	if rpt.Summary.TotalAttributes != 1 {
 		t.Errorf("total_attrs = %d, want 1", rpt.Summary.TotalAttributes)
 	}
@@ -81,6 +96,132 @@ func TestGenerator_EmptyStorage(t *testing.T) {
 	}
 }
 
+func TestGenerator_ActiveSeriesWindows(t *testing.T) {
+	store := &mockStorage{metrics: []*models.MetricMetadata{newTestMetric("http_requests_total", 500, "method")}}
+	rpt, err := NewGenerator(store).Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := len(rpt.Metrics[0].Windows); got != 0 {
+		t.Errorf("active series windows = %d, want none while windows are disabled", got)
+	}
+
+	models.SetWindowConfig(hyperloglog.DefaultWindowConfig())
+	defer models.SetWindowConfig(hyperloglog.WindowConfig{})
+	rpt, err = NewGenerator(store).Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := len(rpt.Metrics[0].Windows); got != 3 {
+		t.Errorf("active series windows = %d, want 15m, 1h and 24h", got)
+	}
+}
+
+func TestGenerator_MetricConflicts(t *testing.T) {
+	tracker := models.NewMetricIdentityTracker()
+	for _, svc := range []struct {
+		name string
+		unit string
+	}{{"checkout", "ms"}, {"search", "s"}, {"cart", "ms"}} {
+		m := models.NewMetricMetadata("http.server.duration", &models.HistogramMetric{})
+		m.Unit = svc.unit
+		m.SampleCount = 10
+		m.Services[svc.name] = 10
+		tracker.Observe(m)
+	}
+
+	rpt, err := NewGenerator(&mockStorage{conflicts: tracker.Conflicts()}).Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if rpt.Summary.MetricConflictCount != 1 || len(rpt.MetricConflicts) != 1 {
+		t.Fatalf("metric conflicts = %+v", rpt.MetricConflicts)
+	}
+	c := rpt.MetricConflicts[0]
+	if len(c.Variants) != 2 || c.Variants[0].Unit != "ms" {
+		t.Fatalf("variants = %+v, want ms first", c.Variants)
+	}
+	if got := c.Variants[0].Services; len(got) != 2 || got[0] != "cart" || got[1] != "checkout" {
+		t.Errorf("ms services = %v, want [cart checkout]", got)
+	}
+
+	text, err := FormatText(rpt)
+	if err != nil {
+		t.Fatalf("FormatText: %v", err)
+	}
+	if !strings.Contains(string(text), "http.server.duration — differs in unit") {
+		t.Errorf("text report missing the conflict:\n%s", text)
+	}
+}
+
+func TestGenerator_SemconvFindings(t *testing.T) {
+	m := models.NewMetricMetadata("http.server.duration", &models.HistogramMetric{})
+	m.Unit = "ms"
+	store := &mockStorage{
+		metrics: []*models.MetricMetadata{m},
+		attrs:   []*models.AttributeMetadata{models.NewAttributeMetadata("http.method")},
+	}
+
+	rpt, err := NewGenerator(store).Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if rpt.Summary.SemconvWarningCount != 2 || rpt.SemconvVersion == "" {
+		t.Fatalf("semconv findings = %+v (version %q)", rpt.SemconvFindings, rpt.SemconvVersion)
+	}
+	for _, f := range rpt.SemconvFindings {
+		if f.Name == "http.method" && f.Replacement != "http.request.method" {
+			t.Errorf("http.method replacement = %q", f.Replacement)
+		}
+	}
+
+	text, err := FormatText(rpt)
+	if err != nil {
+		t.Fatalf("FormatText: %v", err)
+	}
+	if !strings.Contains(string(text), "WARNING   attribute http.method — http.method is deprecated, use http.request.method") {
+		t.Errorf("text report missing the deprecated attribute:\n%s", text)
+	}
+}
+
+func TestGenerator_LintViolations(t *testing.T) {
+	rules, err := lint.Parse([]byte("rules:\n  - {name: lowercase_span_names, targets: [span_name], not_match: '[A-Z]'}\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	span := models.NewSpanMetadata("GET /orders", 2, "Server")
+	span.Services["checkout"] = 3
+	gen := NewGenerator(&mockStorage{spans: []*models.SpanMetadata{span}})
+
+	rpt, err := gen.Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(rpt.LintViolations) != 0 {
+		t.Fatalf("violations without rules: %+v", rpt.LintViolations)
+	}
+
+	gen.Lint = rules
+	rpt, err = gen.Generate(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if rpt.Summary.LintViolationCount != 1 || rpt.MaxExitCode() != 1 {
+		t.Fatalf("violations = %+v, exit code %d", rpt.LintViolations, rpt.MaxExitCode())
+	}
+	if v := rpt.LintViolations[0]; v.Name != "GET /orders" || len(v.Services) != 1 || v.Services[0] != "checkout" {
+		t.Errorf("violation = %+v", v)
+	}
+
+	text, err := FormatText(rpt)
+	if err != nil {
+		t.Fatalf("FormatText: %v", err)
+	}
+	if !strings.Contains(string(text), "[lowercase_span_names] span_name GET /orders") {
+		t.Errorf("text report missing the violation:\n%s", text)
+	}
+}
+
 func newTestMetric(name string, sampleCount int64, labelKeys ...string) *models.MetricMetadata {
 	m := models.NewMetricMetadata(name, nil)
 	m.SampleCount = sampleCount
@@ -119,3 +260,17 @@ func newTestLog(severity string, sampleCount int64, attrKeys ...string) *models.
 	}
 	return l
 }
+
+func newTestProfile(sampleType, unit string, sampleCount int64, attrKeys ...string) *models.ProfileMetadata {
+	p := models.NewProfileMetadata(sampleType, unit)
+	p.ProfileCount = 1
+	p.SampleCount = sampleCount
+	for _, k := range attrKeys {
+		km := models.NewKeyMetadata()
+		for i := 0; i < 4; i++ {
+			km.AddValue(k + string(rune('a'+i)))
+		}
+		p.AttributeKeys[k] = km
+	}
+	return p
+}
@@ -8,10 +8,12 @@ import (
 )
 
 type mockStorage struct {
-	metrics []*models.MetricMetadata
-	spans   []*models.SpanMetadata
-	logs    []*models.LogMetadata
-	attrs   []*models.AttributeMetadata
+	metrics   []*models.MetricMetadata
+	spans     []*models.SpanMetadata
+	logs      []*models.LogMetadata
+	profiles  []*models.ProfileMetadata
+	attrs     []*models.AttributeMetadata
+	conflicts *models.MetricConflictsResponse
 }
 
 func (m *mockStorage) StoreMetric(_ context.Context, _ *models.MetricMetadata) error {
@@ -26,6 +28,17 @@ func (m *mockStorage) ListMetrics(_ context.Context, _ string) ([]*models.Metric
 	return m.metrics, nil
 }
 
+func (m *mockStorage) GetMetricConflicts(_ context.Context) (*models.MetricConflictsResponse, error) {
+	if m.conflicts == nil {
+		return &models.MetricConflictsResponse{}, nil
+	}
+	return m.conflicts, nil
+}
+
+func (m *mockStorage) ConflictingMetricIdentities(_ context.Context) map[string][]*models.MetricVariant {
+	return nil
+}
+
 func (m *mockStorage) StoreSpan(_ context.Context, _ *models.SpanMetadata) error {
 	return nil
 }
@@ -50,6 +63,18 @@ func (m *mockStorage) ListLogs(_ context.Context, _ string) ([]*models.LogMetada
 	return m.logs, nil
 }
 
+func (m *mockStorage) StoreProfile(_ context.Context, _ *models.ProfileMetadata) error {
+	return nil
+}
+
+func (m *mockStorage) GetProfile(_ context.Context, _ string) (*models.ProfileMetadata, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) ListProfiles(_ context.Context, _ string) ([]*models.ProfileMetadata, error) {
+	return m.profiles, nil
+}
+
 func (m *mockStorage) GetLogPatterns(_ context.Context, _ int64, _ int) (*models.PatternExplorerResponse, error) {
 	return nil, nil
 }
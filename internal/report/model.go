@@ -1,19 +1,33 @@
 // Package report provides cardinality report generation for CI/CD mode.
 package report
 
-import "time"
+import (
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
 
 // Report is the top-level cardinality report.
 type Report struct {
-	Version     string       `json:"version"`
-	GeneratedAt time.Time    `json:"generated_at"`
-	Duration    string       `json:"duration,omitempty"`
-	OCCVersion  string       `json:"occ_version"`
-	Summary     Summary      `json:"summary"`
-	Metrics     []MetricItem `json:"metrics"`
-	Spans       []SpanItem   `json:"spans"`
-	Logs        []LogItem    `json:"logs"`
-	Attributes  []AttrItem   `json:"attributes"`
+	Version     string        `json:"version"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Duration    string        `json:"duration,omitempty"`
+	OCCVersion  string        `json:"occ_version"`
+	Summary     Summary       `json:"summary"`
+	Metrics     []MetricItem  `json:"metrics"`
+	Spans       []SpanItem    `json:"spans"`
+	Logs        []LogItem     `json:"logs"`
+	Profiles    []ProfileItem `json:"profiles"`
+	Attributes  []AttrItem    `json:"attributes"`
+
+	MetricConflicts []MetricConflictItem `json:"metric_conflicts,omitempty"`
+	SemconvFindings []SemconvItem        `json:"semconv_findings,omitempty"`
+	SemconvVersion  string               `json:"semconv_version,omitempty"`
+	LintViolations  []LintItem           `json:"lint_violations,omitempty"`
+
+	// SpanMetrics projects the spanmetrics connector output for the spans
+	// seen; absent unless an estimator was configured.
+	SpanMetrics *models.SpanMetricsEstimate `json:"spanmetrics_estimate,omitempty"`
 }
 
 // Summary provides aggregate counts.
@@ -21,16 +35,21 @@ type Summary struct {
 	TotalMetrics         int          `json:"total_metrics"`
 	TotalSpanNames       int          `json:"total_span_names"`
 	TotalLogPatterns     int          `json:"total_log_patterns"`
+	TotalProfileTypes    int          `json:"total_profile_types"`
 	TotalAttributes      int          `json:"total_attributes"`
 	HighCardinalityCount int          `json:"high_cardinality_count"`
+	MetricConflictCount  int          `json:"metric_conflict_count"`
+	SemconvWarningCount  int          `json:"semconv_warning_count"`
+	LintViolationCount   int          `json:"lint_violation_count"`
 	Samples              SampleCounts `json:"samples"`
 }
 
 // SampleCounts tracks total samples per signal type.
 type SampleCounts struct {
-	Metrics int64 `json:"metrics"`
-	Spans   int64 `json:"spans"`
-	Logs    int64 `json:"logs"`
+	Metrics  int64 `json:"metrics"`
+	Spans    int64 `json:"spans"`
+	Logs     int64 `json:"logs"`
+	Profiles int64 `json:"profiles"`
 }
 
 // MetricItem represents one metric in the report.
@@ -41,6 +60,57 @@ type MetricItem struct {
 	SampleCount          int64    `json:"sample_count"`
 	EstimatedCardinality int64    `json:"estimated_cardinality"`
 	Severity             string   `json:"severity"`
+	// Windows are the series active within recent windows; the estimated
+	// cardinality above counts every series since the start.
+	Windows []WindowCount `json:"active_series_windows,omitempty"`
+}
+
+// WindowCount is the number of series active within one window.
+type WindowCount struct {
+	Window       string `json:"window"`
+	ActiveSeries int64  `json:"active_series"`
+}
+
+// MetricConflictItem represents one metric name sent with disagreeing
+// definitions.
+type MetricConflictItem struct {
+	Name              string                  `json:"name"`
+	ConflictingFields []string                `json:"conflicting_fields"`
+	Variants          []MetricConflictVariant `json:"variants"`
+}
+
+// MetricConflictVariant is one definition of a conflicting metric and the
+// services sending it.
+type MetricConflictVariant struct {
+	Type                   string   `json:"type"`
+	Unit                   string   `json:"unit"`
+	AggregationTemporality string   `json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool     `json:"is_monotonic"`
+	Description            string   `json:"description"`
+	Services               []string `json:"services"`
+	SampleCount            int64    `json:"sample_count"`
+}
+
+// SemconvItem is one semantic convention finding.
+type SemconvItem struct {
+	Signal      string `json:"signal"`
+	Name        string `json:"name"`
+	Key         string `json:"key,omitempty"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// LintItem is one naming rule violation.
+type LintItem struct {
+	Rule     string   `json:"rule"`
+	Target   string   `json:"target"`
+	Name     string   `json:"name"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	FoundIn  []string `json:"found_in,omitempty"`
+	Services []string `json:"services"`
 }
 
 // SpanItem represents one span name in the report.
@@ -61,6 +131,16 @@ type LogItem struct {
 	SeverityLevel        string   `json:"severity"`
 }
 
+// ProfileItem represents one profile sample type in the report.
+type ProfileItem struct {
+	ID                   string   `json:"id"`
+	AttributeKeys        []string `json:"attribute_keys"`
+	ProfileCount         int64    `json:"profile_count"`
+	SampleCount          int64    `json:"sample_count"`
+	EstimatedCardinality int64    `json:"estimated_cardinality"`
+	Severity             string   `json:"severity"`
+}
+
 // AttrItem represents one attribute in the cross-signal report.
 type AttrItem struct {
 	Key                   string   `json:"key"`
@@ -114,8 +194,14 @@ func (r *Report) MaxExitCode() int {
 	for _, l := range r.Logs {
 		check(l.SeverityLevel)
 	}
+	for _, p := range r.Profiles {
+		check(p.Severity)
+	}
 	for _, a := range r.Attributes {
 		check(a.Severity)
 	}
+	for _, v := range r.LintViolations {
+		check(v.Severity)
+	}
 	return code
 }
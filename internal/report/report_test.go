@@ -76,6 +76,14 @@ func TestMaxExitCode(t *testing.T) {
 			},
 			want: 2,
 		},
+		{
+			name: "lint violation",
+			rpt: Report{
+				Metrics:        []MetricItem{{Severity: SeverityOK}},
+				LintViolations: []LintItem{{Severity: SeverityWarning}},
+			},
+			want: 1,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
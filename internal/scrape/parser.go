@@ -0,0 +1,506 @@
+// Package scrape collects metrics from Prometheus text and OpenMetrics
+// exposition endpoints (or saved exposition files) and feeds them through
+// the regular metrics analyzer.
+package scrape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// maxLineBytes bounds a single exposition line. Real exporters stay far
+// below this; the limit protects against unterminated garbage.
+const maxLineBytes = 1 << 20
+
+// Family types as written in "# TYPE" lines. Prometheus text "untyped" is
+// normalised to OpenMetrics "unknown".
+const (
+	typeCounter        = "counter"
+	typeGauge          = "gauge"
+	typeHistogram      = "histogram"
+	typeGaugeHistogram = "gaugehistogram"
+	typeSummary        = "summary"
+	typeInfo           = "info"
+	typeStateset       = "stateset"
+	typeUnknown        = "unknown"
+)
+
+// family is one metric family of an exposition with all of its samples.
+type family struct {
+	name    string
+	help    string
+	unit    string
+	typ     string
+	samples []sample
+}
+
+// sample is a single exposition line. Timestamps and exemplars are parsed
+// past but not kept.
+type sample struct {
+	name   string
+	labels []label
+	value  float64
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+// familySuffixes lists the sample name suffixes that belong to a family of
+// the given type, in addition to the bare family name.
+var familySuffixes = map[string][]string{
+	typeCounter:        {"_total", "_created"},
+	typeHistogram:      {"_bucket", "_sum", "_count", "_created"},
+	typeGaugeHistogram: {"_bucket", "_gsum", "_gcount"},
+	typeSummary:        {"_sum", "_count", "_created"},
+	typeInfo:           {"_info"},
+}
+
+func (f *family) owns(sampleName string) bool {
+	if sampleName == f.name {
+		return true
+	}
+	for _, suffix := range familySuffixes[f.typ] {
+		if sampleName == f.name+suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExposition parses Prometheus text format 0.0.4 or OpenMetrics 1.0.
+// Families are returned in the order they first appear.
+func parseExposition(r io.Reader) ([]*family, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	var (
+		families []*family
+		byName   = make(map[string]*family)
+		current  *family
+		lineNo   int
+	)
+
+	getFamily := func(name string) *family {
+		f, ok := byName[name]
+		if !ok {
+			f = &family{name: name, typ: typeUnknown}
+			byName[name] = f
+			families = append(families, f)
+		}
+		return f
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(strings.TrimSpace(line[1:]), " ", 3)
+			if fields[0] == "EOF" {
+				break
+			}
+			if len(fields) < 2 {
+				continue // plain comment
+			}
+			var rest string
+			if len(fields) == 3 {
+				rest = strings.TrimSpace(fields[2])
+			}
+			switch fields[0] {
+			case "HELP":
+				current = getFamily(fields[1])
+				current.help = unescapeHelp(rest)
+			case "TYPE":
+				current = getFamily(fields[1])
+				current.typ = normaliseType(rest)
+			case "UNIT":
+				current = getFamily(fields[1])
+				current.unit = rest
+			}
+			continue
+		}
+
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if current == nil || !current.owns(s.name) {
+			current = getFamily(s.name)
+		}
+		current.samples = append(current.samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+	}
+
+	return families, nil
+}
+
+func normaliseType(t string) string {
+	switch t = strings.ToLower(t); t {
+	case typeCounter, typeGauge, typeHistogram, typeGaugeHistogram, typeSummary, typeInfo, typeStateset:
+		return t
+	default:
+		return typeUnknown
+	}
+}
+
+func unescapeHelp(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\"`, `"`).Replace(s)
+}
+
+// parseSample parses `name{label="value",...} value [timestamp] [# exemplar]`.
+func parseSample(line string) (sample, error) {
+	var s sample
+
+	end := strings.IndexAny(line, "{ \t")
+	if end == 0 {
+		return s, fmt.Errorf("missing metric name")
+	}
+	if end < 0 {
+		return s, fmt.Errorf("missing value for %q", line)
+	}
+	s.name = line[:end]
+	rest := line[end:]
+
+	if rest[0] == '{' {
+		labels, n, err := parseLabels(rest)
+		if err != nil {
+			return s, fmt.Errorf("metric %s: %w", s.name, err)
+		}
+		s.labels = labels
+		rest = rest[n:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return s, fmt.Errorf("metric %s: missing value", s.name)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, fmt.Errorf("metric %s: invalid value %q", s.name, fields[0])
+	}
+	s.value = v
+	return s, nil
+}
+
+// parseLabels parses a `{...}` label set at the start of s and returns the
+// labels sorted by name together with the number of bytes consumed.
+func parseLabels(s string) ([]label, int, error) {
+	var labels []label
+	i := 1 // skip '{'
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("unterminated label set")
+		}
+		if s[i] == '}' {
+			i++
+			break
+		}
+
+		eq := strings.IndexByte(s[i:], '=')
+		if eq <= 0 {
+			return nil, 0, fmt.Errorf("invalid label at %q", s[i:])
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) || s[i] != '"' {
+			return nil, 0, fmt.Errorf("label %s: value must be quoted", name)
+		}
+		i++
+
+		var value strings.Builder
+		closed := false
+		for i < len(s) {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) {
+				switch s[i+1] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i+1])
+				}
+				i += 2
+				continue
+			}
+			i++
+			if c == '"' {
+				closed = true
+				break
+			}
+			value.WriteByte(c)
+		}
+		if !closed {
+			return nil, 0, fmt.Errorf("label %s: unterminated value", name)
+		}
+		labels = append(labels, label{name: name, value: value.String()})
+	}
+
+	sort.Slice(labels, func(a, b int) bool { return labels[a].name < labels[b].name })
+	return labels, i, nil
+}
+
+// toMetrics converts parsed families into OTLP metrics. Classic histogram
+// and summary samples are regrouped into one data point per label set (minus
+// le/quantile) so the analyzer sees the same shape an OTLP exporter would
+// send. Counters keep their _total sample name to match what remote-write
+// and Prometheus itself store.
+func toMetrics(families []*family) []*metricspb.Metric {
+	var metrics []*metricspb.Metric
+	for _, f := range families {
+		if len(f.samples) == 0 {
+			continue
+		}
+		switch f.typ {
+		case typeCounter:
+			metrics = append(metrics, counterMetric(f))
+		case typeHistogram, typeGaugeHistogram:
+			metrics = append(metrics, histogramMetric(f))
+		case typeSummary:
+			metrics = append(metrics, summaryMetric(f))
+		default:
+			metrics = append(metrics, gaugeMetrics(f)...)
+		}
+	}
+	return metrics
+}
+
+func counterMetric(f *family) *metricspb.Metric {
+	name := f.name
+	for _, s := range f.samples {
+		if s.name == f.name+"_total" {
+			name = s.name
+			break
+		}
+	}
+
+	sum := &metricspb.Sum{
+		AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		IsMonotonic:            true,
+	}
+	for _, s := range f.samples {
+		if s.name == f.name+"_created" {
+			continue
+		}
+		sum.DataPoints = append(sum.DataPoints, &metricspb.NumberDataPoint{
+			Attributes: toKeyValues(s.labels, ""),
+			Value:      &metricspb.NumberDataPoint_AsDouble{AsDouble: s.value},
+		})
+	}
+	return &metricspb.Metric{
+		Name:        name,
+		Description: f.help,
+		Unit:        f.unit,
+		Data:        &metricspb.Metric_Sum{Sum: sum},
+	}
+}
+
+// gaugeMetrics handles gauge, info, stateset and untyped families. Each
+// distinct sample name becomes its own gauge, which keeps OpenMetrics
+// "foo_info" samples under their exposed name.
+func gaugeMetrics(f *family) []*metricspb.Metric {
+	var metrics []*metricspb.Metric
+	byName := make(map[string]*metricspb.Gauge)
+	for _, s := range f.samples {
+		if strings.HasSuffix(s.name, "_created") && s.name != f.name {
+			continue
+		}
+		g, ok := byName[s.name]
+		if !ok {
+			g = &metricspb.Gauge{}
+			byName[s.name] = g
+			metrics = append(metrics, &metricspb.Metric{
+				Name:        s.name,
+				Description: f.help,
+				Unit:        f.unit,
+				Data:        &metricspb.Metric_Gauge{Gauge: g},
+			})
+		}
+		g.DataPoints = append(g.DataPoints, &metricspb.NumberDataPoint{
+			Attributes: toKeyValues(s.labels, ""),
+			Value:      &metricspb.NumberDataPoint_AsDouble{AsDouble: s.value},
+		})
+	}
+	return metrics
+}
+
+func histogramMetric(f *family) *metricspb.Metric {
+	type bucket struct {
+		bound float64
+		count float64
+	}
+	type group struct {
+		attrs   []*commonpb.KeyValue
+		buckets []bucket
+		count   float64
+		sum     float64
+	}
+	groups := make(map[string]*group)
+	var order []string
+
+	sumSuffix, countSuffix := "_sum", "_count"
+	if f.typ == typeGaugeHistogram {
+		sumSuffix, countSuffix = "_gsum", "_gcount"
+	}
+
+	for _, s := range f.samples {
+		key := labelsKey(s.labels, "le")
+		g, ok := groups[key]
+		if !ok {
+			g = &group{attrs: toKeyValues(s.labels, "le")}
+			groups[key] = g
+			order = append(order, key)
+		}
+		switch s.name {
+		case f.name + "_bucket":
+			le := labelValue(s.labels, "le")
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				continue
+			}
+			g.buckets = append(g.buckets, bucket{bound: bound, count: s.value})
+		case f.name + sumSuffix:
+			g.sum = s.value
+		case f.name + countSuffix:
+			g.count = s.value
+		}
+	}
+
+	temporality := metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	if f.typ == typeGaugeHistogram {
+		temporality = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+	}
+	hist := &metricspb.Histogram{AggregationTemporality: temporality}
+
+	for _, key := range order {
+		g := groups[key]
+		sort.Slice(g.buckets, func(a, b int) bool { return g.buckets[a].bound < g.buckets[b].bound })
+
+		// Exposition buckets are cumulative; OTLP bucket counts are not.
+		// The +Inf bucket is implied by the last OTLP bucket.
+		var bounds []float64
+		var counts []uint64
+		var prev float64
+		for _, b := range g.buckets {
+			if !math.IsInf(b.bound, 1) {
+				bounds = append(bounds, b.bound)
+			}
+			counts = append(counts, uint64(math.Max(b.count-prev, 0)))
+			prev = b.count
+		}
+		if len(g.buckets) == 0 || !math.IsInf(g.buckets[len(g.buckets)-1].bound, 1) {
+			counts = append(counts, uint64(math.Max(g.count-prev, 0)))
+		}
+
+		sum := g.sum
+		hist.DataPoints = append(hist.DataPoints, &metricspb.HistogramDataPoint{
+			Attributes:     g.attrs,
+			Count:          uint64(g.count),
+			Sum:            &sum,
+			ExplicitBounds: bounds,
+			BucketCounts:   counts,
+		})
+	}
+
+	return &metricspb.Metric{
+		Name:        f.name,
+		Description: f.help,
+		Unit:        f.unit,
+		Data:        &metricspb.Metric_Histogram{Histogram: hist},
+	}
+}
+
+func summaryMetric(f *family) *metricspb.Metric {
+	groups := make(map[string]*metricspb.SummaryDataPoint)
+	summary := &metricspb.Summary{}
+
+	for _, s := range f.samples {
+		key := labelsKey(s.labels, "quantile")
+		dp, ok := groups[key]
+		if !ok {
+			dp = &metricspb.SummaryDataPoint{Attributes: toKeyValues(s.labels, "quantile")}
+			groups[key] = dp
+			summary.DataPoints = append(summary.DataPoints, dp)
+		}
+		switch s.name {
+		case f.name:
+			q, err := strconv.ParseFloat(labelValue(s.labels, "quantile"), 64)
+			if err != nil {
+				continue
+			}
+			dp.QuantileValues = append(dp.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+				Quantile: q,
+				Value:    s.value,
+			})
+		case f.name + "_sum":
+			dp.Sum = s.value
+		case f.name + "_count":
+			dp.Count = uint64(s.value)
+		}
+	}
+
+	return &metricspb.Metric{
+		Name:        f.name,
+		Description: f.help,
+		Unit:        f.unit,
+		Data:        &metricspb.Metric_Summary{Summary: summary},
+	}
+}
+
+// toKeyValues converts sorted labels to OTLP attributes, leaving out skip.
+func toKeyValues(labels []label, skip string) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		if l.name == skip || l.value == "" {
+			continue
+		}
+		kvs = append(kvs, stringKeyValue(l.name, l.value))
+	}
+	return kvs
+}
+
+// labelsKey builds a grouping key from sorted labels, leaving out skip.
+func labelsKey(labels []label, skip string) string {
+	var b strings.Builder
+	for _, l := range labels {
+		if l.name == skip {
+			continue
+		}
+		b.WriteString(l.name)
+		b.WriteByte('=')
+		b.WriteString(l.value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
+
+func labelValue(labels []label, name string) string {
+	for _, l := range labels {
+		if l.name == name {
+			return l.value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package scrape
+
+import (
+	"strings"
+	"testing"
+
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+const promText = `# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 1027 1395066363000
+http_requests_total{method="POST",code="500"} 3
+# HELP request_duration_seconds Request latency.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{path="/",le="0.1"} 5
+request_duration_seconds_bucket{path="/",le="0.5"} 8
+request_duration_seconds_bucket{path="/",le="+Inf"} 10
+request_duration_seconds_sum{path="/"} 2.5
+request_duration_seconds_count{path="/"} 10
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.01
+rpc_duration_seconds{quantile="0.99"} 0.2
+rpc_duration_seconds_sum 17
+rpc_duration_seconds_count 100
+# A plain comment
+go_goroutines 42
+`
+
+const openMetricsText = `# TYPE process_cpu_seconds counter
+# UNIT process_cpu_seconds seconds
+# HELP process_cpu_seconds Total CPU time.
+process_cpu_seconds_total 4.2 # {trace_id="abc"} 1.0
+process_cpu_seconds_created 1.6e9
+# TYPE build info
+build_info{version="1.2.3",path="a\"b\\c"} 1
+# EOF
+ignored_after_eof 1
+`
+
+func metricsByName(t *testing.T, text string) map[string]*metricspb.Metric {
+	t.Helper()
+	families, err := parseExposition(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("parseExposition: %v", err)
+	}
+	out := make(map[string]*metricspb.Metric)
+	for _, m := range toMetrics(families) {
+		out[m.Name] = m
+	}
+	return out
+}
+
+func TestParseExposition_PrometheusText(t *testing.T) {
+	metrics := metricsByName(t, promText)
+	if len(metrics) != 4 {
+		t.Fatalf("expected 4 metrics, got %d: %v", len(metrics), metrics)
+	}
+
+	counter := metrics["http_requests_total"].GetSum()
+	if counter == nil || !counter.IsMonotonic || len(counter.DataPoints) != 2 {
+		t.Errorf("unexpected counter: %v", metrics["http_requests_total"])
+	}
+	if got := metrics["http_requests_total"].Description; got != "Total HTTP requests." {
+		t.Errorf("description = %q", got)
+	}
+
+	hist := metrics["request_duration_seconds"].GetHistogram()
+	if hist == nil || len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one histogram data point, got %v", metrics["request_duration_seconds"])
+	}
+	dp := hist.DataPoints[0]
+	if len(dp.ExplicitBounds) != 2 || dp.ExplicitBounds[0] != 0.1 || dp.ExplicitBounds[1] != 0.5 {
+		t.Errorf("bounds = %v", dp.ExplicitBounds)
+	}
+	if want := []uint64{5, 3, 2}; len(dp.BucketCounts) != 3 || dp.BucketCounts[0] != want[0] || dp.BucketCounts[1] != want[1] || dp.BucketCounts[2] != want[2] {
+		t.Errorf("bucket counts = %v, want %v", dp.BucketCounts, want)
+	}
+	if dp.Count != 10 || dp.GetSum() != 2.5 {
+		t.Errorf("count/sum = %d/%v", dp.Count, dp.GetSum())
+	}
+	if len(dp.Attributes) != 1 || dp.Attributes[0].Key != "path" {
+		t.Errorf("le must not be an attribute: %v", dp.Attributes)
+	}
+
+	summary := metrics["rpc_duration_seconds"].GetSummary()
+	if summary == nil || len(summary.DataPoints) != 1 || len(summary.DataPoints[0].QuantileValues) != 2 {
+		t.Errorf("unexpected summary: %v", metrics["rpc_duration_seconds"])
+	}
+
+	if metrics["go_goroutines"].GetGauge() == nil {
+		t.Errorf("untyped sample should become a gauge: %v", metrics["go_goroutines"])
+	}
+}
+
+func TestParseExposition_OpenMetrics(t *testing.T) {
+	metrics := metricsByName(t, openMetricsText)
+
+	cpu, ok := metrics["process_cpu_seconds_total"]
+	if !ok {
+		t.Fatalf("expected counter under its _total name, got %v", metrics)
+	}
+	if cpu.Unit != "seconds" {
+		t.Errorf("unit = %q", cpu.Unit)
+	}
+	if n := len(cpu.GetSum().GetDataPoints()); n != 1 {
+		t.Errorf("_created must not be a data point, got %d points", n)
+	}
+
+	info, ok := metrics["build_info"]
+	if !ok {
+		t.Fatalf("expected build_info gauge, got %v", metrics)
+	}
+	attrs := info.GetGauge().GetDataPoints()[0].Attributes
+	if len(attrs) != 2 || attrs[0].Key != "path" || attrs[0].Value.GetStringValue() != `a"b\c` {
+		t.Errorf("unexpected info attributes: %v", attrs)
+	}
+
+	if _, ok := metrics["ignored_after_eof"]; ok {
+		t.Error("samples after # EOF must be ignored")
+	}
+}
+
+func TestParseExposition_Errors(t *testing.T) {
+	for name, text := range map[string]string{
+		"missing value":      "foo\n",
+		"invalid value":      "foo abc\n",
+		"unterminated label": "foo{a=\"b 1\n",
+		"unquoted label":     "foo{a=b} 1\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseExposition(strings.NewReader(text)); err == nil {
+				t.Errorf("expected error for %q", text)
+			}
+		})
+	}
+}
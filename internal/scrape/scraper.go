@@ -0,0 +1,272 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
+	"github.com/fidde/otlp_cardinality_checker/internal/simulate"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// scopeName is the instrumentation scope recorded for scraped metrics.
+const scopeName = "prometheus.scrape"
+
+// maxResponseBytes caps how much of a scrape response is read, matching the
+// OTLP receiver body limit. A larger response fails the scrape. Variable so
+// tests can lower it.
+var maxResponseBytes int64 = 32 << 20
+
+// acceptHeader prefers OpenMetrics (which carries # UNIT) and falls back to
+// the classic text format.
+const acceptHeader = "application/openmetrics-text;version=1.0.0;q=0.9,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+
+// Target is a single scrape target. URL is either http(s):// for a live
+// endpoint or file:// for a saved exposition that is read once.
+type Target struct {
+	Job string
+	URL string
+}
+
+// Config configures the scraper.
+type Config struct {
+	Targets  []Target
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// DefaultConfig returns a configuration with Prometheus' default interval
+// and timeout and no targets.
+func DefaultConfig() Config {
+	return Config{
+		Interval: 60 * time.Second,
+		Timeout:  10 * time.Second,
+	}
+}
+
+// ParseTargets parses a comma-separated target list. Each entry is either a
+// bare URL or "job=URL"; without an explicit job the host name (or the file
+// name for file:// targets) is used.
+func ParseTargets(spec string) ([]Target, error) {
+	var targets []Target
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var job string
+		if i := strings.Index(entry, "="); i > 0 && !strings.Contains(entry[:i], "://") {
+			job, entry = entry[:i], entry[i+1:]
+		}
+
+		u, err := url.Parse(entry)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", entry, err)
+		}
+		switch u.Scheme {
+		case "http", "https":
+			if u.Host == "" {
+				return nil, fmt.Errorf("target %q: missing host", entry)
+			}
+			if job == "" {
+				job = u.Hostname()
+			}
+		case "file":
+			if u.Path == "" {
+				return nil, fmt.Errorf("target %q: missing path", entry)
+			}
+			if job == "" {
+				base := filepath.Base(u.Path)
+				job = strings.TrimSuffix(base, filepath.Ext(base))
+			}
+		default:
+			return nil, fmt.Errorf("target %q: unsupported scheme %q (want http, https or file)", entry, u.Scheme)
+		}
+
+		targets = append(targets, Target{Job: job, URL: entry})
+	}
+	return targets, nil
+}
+
+// Scraper periodically collects exposition-format metrics from its targets
+// and stores the analyzed metadata.
+type Scraper struct {
+	cfg         Config
+	store       storage.Storage
+	analyzer    *analyzer.MetricsAnalyzer
+	client      *http.Client
+	OnActivity  func()             // called after each successful scrape
+	Simulations *simulate.Registry // optional; shadow-run candidate rules
+}
+
+// New creates a scraper feeding store.
+func New(cfg Config, store storage.Storage) *Scraper {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig().Timeout
+	}
+	return &Scraper{
+		cfg:      cfg,
+		store:    store,
+		analyzer: analyzer.NewMetricsAnalyzerWithCatalog(store),
+		client:   &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Run scrapes file:// targets once and http(s) targets every interval until
+// ctx is cancelled. Errors are logged per target and never stop the loop.
+func (s *Scraper) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range s.cfg.Targets {
+		if strings.HasPrefix(t.URL, "file://") {
+			if err := s.ScrapeTarget(ctx, t); err != nil {
+				log.Printf("Scrape of %s failed: %v", t.URL, err)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			s.runTarget(ctx, t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (s *Scraper) runTarget(ctx context.Context, t Target) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ScrapeTarget(ctx, t); err != nil && ctx.Err() == nil {
+			log.Printf("Scrape of %s failed: %v", t.URL, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScrapeTarget fetches, parses, analyzes and stores a single target.
+func (s *Scraper) ScrapeTarget(ctx context.Context, t Target) error {
+	body, instance, err := s.fetch(ctx, t)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// Read one byte past the limit so an oversized response is rejected
+	// rather than analyzed half-way.
+	limited := &io.LimitedReader{R: body, N: maxResponseBytes + 1}
+	families, err := parseExposition(limited)
+	if limited.N == 0 {
+		return fmt.Errorf("response exceeds %d bytes", maxResponseBytes)
+	}
+	if err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+
+	req := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{
+						stringKeyValue("service.name", t.Job),
+						stringKeyValue("service.instance.id", instance),
+					},
+				},
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope:   &commonpb.InstrumentationScope{Name: scopeName},
+						Metrics: toMetrics(families),
+					},
+				},
+			},
+		},
+	}
+
+	metrics, err := s.analyzer.AnalyzeWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("analyze: %w", err)
+	}
+	for _, m := range metrics {
+		if err := s.store.StoreMetric(ctx, m); err != nil {
+			return fmt.Errorf("store metric %s: %w", m.Name, err)
+		}
+	}
+	s.Simulations.Observer(ctx).Metrics(req)
+
+	if s.OnActivity != nil {
+		s.OnActivity()
+	}
+	return nil
+}
+
+// fetch opens the exposition for t and returns it together with the
+// instance identity (host:port, or the file path).
+func (s *Scraper) fetch(ctx context.Context, t Target) (io.ReadCloser, string, error) {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if u.Scheme == "file" {
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		return f, u.Path, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", fmt.Sprintf("%g", s.cfg.Timeout.Seconds()))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	instance := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		instance = net.JoinHostPort(u.Hostname(), port)
+	}
+	return resp.Body, instance, nil
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
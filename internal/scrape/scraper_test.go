@@ -0,0 +1,124 @@
+package scrape
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets("http://node:9100/metrics, api=https://api.example.com/metrics,file:///tmp/saved.prom")
+	if err != nil {
+		t.Fatalf("ParseTargets: %v", err)
+	}
+	want := []Target{
+		{Job: "node", URL: "http://node:9100/metrics"},
+		{Job: "api", URL: "https://api.example.com/metrics"},
+		{Job: "saved", URL: "file:///tmp/saved.prom"},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("got %v, want %v", targets, want)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+
+	if _, err := ParseTargets("ftp://example.com/metrics"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func TestScrapeTarget_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "" {
+			t.Error("expected Accept header")
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(promText))
+	}))
+	defer srv.Close()
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	s := New(DefaultConfig(), store)
+	var activity int
+	s.OnActivity = func() { activity++ }
+
+	ctx := context.Background()
+	if err := s.ScrapeTarget(ctx, Target{Job: "web", URL: srv.URL + "/metrics"}); err != nil {
+		t.Fatalf("ScrapeTarget: %v", err)
+	}
+	if activity != 1 {
+		t.Errorf("OnActivity called %d times, want 1", activity)
+	}
+
+	hist, err := store.GetMetric(ctx, "request_duration_seconds")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	data, ok := hist.Data.(*models.HistogramMetric)
+	if !ok {
+		t.Fatalf("expected histogram, got %T", hist.Data)
+	}
+	if len(data.ExplicitBounds) != 2 {
+		t.Errorf("bounds = %v", data.ExplicitBounds)
+	}
+	if hist.Services["web"] == 0 {
+		t.Errorf("expected service web, got %v", hist.Services)
+	}
+
+	counter, err := store.GetMetric(ctx, "http_requests_total")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	if got := counter.GetActiveSeries(); got != 2 {
+		t.Errorf("active series = %d, want 2", got)
+	}
+
+	if _, err := store.GetMetric(ctx, "rpc_duration_seconds"); err != nil {
+		t.Errorf("expected summary to be stored: %v", err)
+	}
+}
+
+func TestScrapeTarget_FileAndErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.prom")
+	if err := os.WriteFile(path, []byte(openMetricsText), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := storage.NewStorage(storage.DefaultConfig())
+	s := New(Config{Targets: []Target{{Job: "saved", URL: "file://" + path}}}, store)
+
+	// Run returns once file targets are read since there are no HTTP targets.
+	s.Run(context.Background())
+
+	m, err := store.GetMetric(context.Background(), "process_cpu_seconds_total")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	if _, ok := m.Data.(*models.SumMetric); !ok {
+		t.Errorf("expected sum, got %T", m.Data)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	if err := s.ScrapeTarget(context.Background(), Target{Job: "down", URL: srv.URL}); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+
+	maxResponseBytes = int64(len(openMetricsText)) - 1
+	defer func() { maxResponseBytes = 32 << 20 }()
+	if err := s.ScrapeTarget(context.Background(), Target{Job: "saved", URL: "file://" + path}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("oversized response: err = %v, want size limit error", err)
+	}
+}
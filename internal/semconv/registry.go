@@ -0,0 +1,149 @@
+// Package semconv validates observed telemetry metadata against a bundled
+// snapshot of the OpenTelemetry semantic conventions registry.
+package semconv
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed registry.yaml
+var registryYAML []byte
+
+// Attribute is a registered attribute key. Template keys match any key
+// below them, e.g. http.request.header.<name>.
+type Attribute struct {
+	Key        string `yaml:"key"`
+	Template   bool   `yaml:"template"`
+	Deprecated bool   `yaml:"deprecated"`
+	ReplacedBy string `yaml:"replaced_by"`
+}
+
+// Metric is a registered metric with its instrument and unit.
+type Metric struct {
+	Name       string `yaml:"name"`
+	Instrument string `yaml:"instrument"`
+	Unit       string `yaml:"unit"`
+	Deprecated bool   `yaml:"deprecated"`
+	ReplacedBy string `yaml:"replaced_by"`
+}
+
+// SpanConvention lists attribute requirement levels for spans of the
+// given kinds that carry an attribute in Namespace.
+type SpanConvention struct {
+	Name                  string   `yaml:"name"`
+	Kinds                 []string `yaml:"kinds"`
+	Namespace             string   `yaml:"namespace"`
+	Required              []string `yaml:"required"`
+	ConditionallyRequired []string `yaml:"conditionally_required"`
+	Recommended           []string `yaml:"recommended"`
+}
+
+// Registry is a parsed semantic conventions snapshot.
+type Registry struct {
+	Version string
+
+	attributes       map[string]*Attribute
+	templates        []*Attribute
+	metrics          map[string]*Metric
+	spans            []*SpanConvention
+	closedAttributes map[string]bool
+	closedMetrics    map[string]bool
+}
+
+type registryFile struct {
+	Version                   string            `yaml:"version"`
+	ClosedAttributeNamespaces []string          `yaml:"closed_attribute_namespaces"`
+	ClosedMetricNamespaces    []string          `yaml:"closed_metric_namespaces"`
+	Attributes                []*Attribute      `yaml:"attributes"`
+	Metrics                   []*Metric         `yaml:"metrics"`
+	Spans                     []*SpanConvention `yaml:"spans"`
+}
+
+// Parse reads a registry snapshot in the format of the bundled
+// registry.yaml.
+func Parse(data []byte) (*Registry, error) {
+	var f registryFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing semconv registry: %w", err)
+	}
+
+	r := &Registry{
+		Version:          f.Version,
+		attributes:       make(map[string]*Attribute, len(f.Attributes)),
+		metrics:          make(map[string]*Metric, len(f.Metrics)),
+		spans:            f.Spans,
+		closedAttributes: make(map[string]bool),
+		closedMetrics:    make(map[string]bool),
+	}
+	for _, a := range f.Attributes {
+		if _, dup := r.attributes[a.Key]; dup {
+			return nil, fmt.Errorf("semconv registry: duplicate attribute %q", a.Key)
+		}
+		r.attributes[a.Key] = a
+		if a.Template {
+			r.templates = append(r.templates, a)
+		}
+	}
+	for _, m := range f.Metrics {
+		if _, dup := r.metrics[m.Name]; dup {
+			return nil, fmt.Errorf("semconv registry: duplicate metric %q", m.Name)
+		}
+		r.metrics[m.Name] = m
+	}
+	for _, ns := range f.ClosedAttributeNamespaces {
+		r.closedAttributes[ns] = true
+	}
+	for _, ns := range f.ClosedMetricNamespaces {
+		r.closedMetrics[ns] = true
+	}
+	return r, nil
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+)
+
+// Default returns the bundled registry snapshot.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		r, err := Parse(registryYAML)
+		if err != nil {
+			panic(err) // the snapshot is compiled in and covered by tests
+		}
+		defaultRegistry = r
+	})
+	return defaultRegistry
+}
+
+// Attribute looks up a key, including keys below template attributes.
+func (r *Registry) Attribute(key string) (*Attribute, bool) {
+	if a, ok := r.attributes[key]; ok {
+		return a, true
+	}
+	for _, t := range r.templates {
+		if strings.HasPrefix(key, t.Key+".") {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Metric looks up a metric name.
+func (r *Registry) Metric(name string) (*Metric, bool) {
+	m, ok := r.metrics[name]
+	return m, ok
+}
+
+// namespace returns the part of a dotted name before the first dot.
+func namespace(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
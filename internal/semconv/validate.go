@@ -0,0 +1,243 @@
+package semconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// spanKinds maps OTLP SpanKind values to the names used in the registry.
+var spanKinds = map[int32]string{
+	1: "internal",
+	2: "server",
+	3: "client",
+	4: "producer",
+	5: "consumer",
+}
+
+// Validate checks metric names and units, span attribute requirements and
+// attribute catalog keys. Warnings come first.
+func (r *Registry) Validate(metrics []*models.MetricMetadata, spans []*models.SpanMetadata, attrs []*models.AttributeMetadata) *models.SemconvFindingsResponse {
+	var findings []models.SemconvFinding
+	findings = append(findings, r.ValidateAttributes(attrs)...)
+	findings = append(findings, r.ValidateMetrics(metrics)...)
+	findings = append(findings, r.ValidateSpans(spans)...)
+
+	severityRank := map[string]int{models.SemconvSeverityWarning: 0, models.SemconvSeverityInfo: 1}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.Signal != b.Signal {
+			return a.Signal < b.Signal
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Key < b.Key
+	})
+
+	return models.NewSemconvFindingsResponse(r.Version, findings)
+}
+
+// ValidateAttributes reports deprecated keys and unknown keys in
+// namespaces the registry lists completely.
+func (r *Registry) ValidateAttributes(attrs []*models.AttributeMetadata) []models.SemconvFinding {
+	var findings []models.SemconvFinding
+	for _, attr := range attrs {
+		f, ok := r.checkKey(attr.Key)
+		if !ok {
+			continue
+		}
+		f.Signal = "attribute"
+		f.Name = attr.Key
+		f.SignalTypes = attr.SignalTypes
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// checkKey returns the finding for an attribute key, if any.
+func (r *Registry) checkKey(key string) (models.SemconvFinding, bool) {
+	a, registered := r.Attribute(key)
+	switch {
+	case registered && a.Deprecated:
+		f := models.SemconvFinding{
+			Rule:        models.SemconvRuleDeprecated,
+			Severity:    models.SemconvSeverityWarning,
+			Replacement: a.ReplacedBy,
+		}
+		if a.ReplacedBy != "" {
+			f.Message = fmt.Sprintf("%s is deprecated, use %s", key, a.ReplacedBy)
+		} else {
+			f.Message = fmt.Sprintf("%s is deprecated without a replacement", key)
+		}
+		return f, true
+	case !registered && r.closedAttributes[namespace(key)]:
+		return models.SemconvFinding{
+			Rule:     models.SemconvRuleUnknown,
+			Severity: models.SemconvSeverityInfo,
+			Message:  fmt.Sprintf("%s is not defined in the %s namespace of semantic conventions %s", key, namespace(key), r.Version),
+		}, true
+	}
+	return models.SemconvFinding{}, false
+}
+
+// ValidateMetrics reports deprecated metric names, unit and instrument
+// mismatches, and unknown names in namespaces the registry lists
+// completely.
+func (r *Registry) ValidateMetrics(metrics []*models.MetricMetadata) []models.SemconvFinding {
+	var findings []models.SemconvFinding
+	for _, m := range metrics {
+		add := func(rule, severity, message, replacement string) {
+			findings = append(findings, models.SemconvFinding{
+				Signal:      "metric",
+				Name:        m.Name,
+				Rule:        rule,
+				Severity:    severity,
+				Message:     message,
+				Replacement: replacement,
+				Services:    sortedServices(m.Services),
+			})
+		}
+
+		def, registered := r.Metric(m.Name)
+		if !registered {
+			if r.closedMetrics[namespace(m.Name)] {
+				add(models.SemconvRuleUnknown, models.SemconvSeverityInfo,
+					fmt.Sprintf("%s is not defined in the %s namespace of semantic conventions %s", m.Name, namespace(m.Name), r.Version), "")
+			}
+			continue
+		}
+		if def.Deprecated {
+			msg := fmt.Sprintf("%s is deprecated", m.Name)
+			if def.ReplacedBy != "" {
+				replacement := r.metrics[def.ReplacedBy]
+				msg = fmt.Sprintf("%s is deprecated, use %s", m.Name, def.ReplacedBy)
+				if replacement != nil && replacement.Unit != def.Unit {
+					msg += fmt.Sprintf(" (unit %q instead of %q)", replacement.Unit, def.Unit)
+				}
+			}
+			add(models.SemconvRuleDeprecated, models.SemconvSeverityWarning, msg, def.ReplacedBy)
+			continue
+		}
+		if m.Unit != def.Unit {
+			add(models.SemconvRuleUnitMismatch, models.SemconvSeverityWarning,
+				fmt.Sprintf("unit is %q, semantic conventions use %q", m.Unit, def.Unit), "")
+		}
+		if got := instrument(m.Data); got != "" && got != def.Instrument {
+			add(models.SemconvRuleInstrumentMismatch, models.SemconvSeverityWarning,
+				fmt.Sprintf("sent as a %s, semantic conventions define a %s", got, def.Instrument), "")
+		}
+	}
+	return findings
+}
+
+// instrument maps metric data to the registry's instrument names.
+func instrument(data models.MetricData) string {
+	switch d := data.(type) {
+	case *models.SumMetric:
+		if d.IsMonotonic {
+			return "counter"
+		}
+		return "updowncounter"
+	case *models.GaugeMetric:
+		return "gauge"
+	case *models.HistogramMetric, *models.ExponentialHistogramMetric:
+		return "histogram"
+	case *models.SummaryMetric:
+		return "summary"
+	}
+	return ""
+}
+
+// ValidateSpans reports attributes that span conventions require or
+// recommend and that a span following the convention never carried.
+func (r *Registry) ValidateSpans(spans []*models.SpanMetadata) []models.SemconvFinding {
+	var findings []models.SemconvFinding
+	for _, span := range spans {
+		kind := spanKinds[span.Kind]
+		for _, conv := range r.spans {
+			if !conv.appliesTo(kind, span.AttributeKeys) {
+				continue
+			}
+			levels := []struct {
+				keys     []string
+				rule     string
+				severity string
+				level    string
+			}{
+				{conv.Required, models.SemconvRuleMissingRequired, models.SemconvSeverityWarning, "required"},
+				{conv.ConditionallyRequired, models.SemconvRuleMissingConditionally, models.SemconvSeverityInfo, "conditionally required"},
+				{conv.Recommended, models.SemconvRuleMissingRecommended, models.SemconvSeverityInfo, "recommended"},
+			}
+			for _, level := range levels {
+				for _, key := range level.keys {
+					if _, ok := span.AttributeKeys[key]; ok {
+						continue
+					}
+					msg := fmt.Sprintf("%s is %s for %s spans", key, level.level, conv.Name)
+					if old := r.deprecatedFor(key, span.AttributeKeys); old != "" {
+						msg += fmt.Sprintf("; the span has the deprecated %s instead", old)
+					}
+					findings = append(findings, models.SemconvFinding{
+						Signal:   "span",
+						Name:     span.Name,
+						Key:      key,
+						Rule:     level.rule,
+						Severity: level.severity,
+						Message:  msg,
+						Services: sortedServices(span.Services),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// appliesTo reports whether a span of kind with the given attribute keys
+// follows the convention.
+func (c *SpanConvention) appliesTo(kind string, keys map[string]*models.KeyMetadata) bool {
+	kindMatches := false
+	for _, k := range c.Kinds {
+		if k == kind {
+			kindMatches = true
+			break
+		}
+	}
+	if !kindMatches {
+		return false
+	}
+	prefix := c.Namespace + "."
+	for key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedFor returns a deprecated key among keys that key replaces.
+func (r *Registry) deprecatedFor(key string, keys map[string]*models.KeyMetadata) string {
+	var found []string
+	for k := range keys {
+		if a, ok := r.attributes[k]; ok && a.Deprecated && a.ReplacedBy == key {
+			found = append(found, k)
+		}
+	}
+	sort.Strings(found)
+	return strings.Join(found, ", ")
+}
+
+func sortedServices(services map[string]int64) []string {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
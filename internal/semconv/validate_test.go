@@ -0,0 +1,148 @@
+package semconv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func findRule(findings []models.SemconvFinding, name, key, rule string) *models.SemconvFinding {
+	for i := range findings {
+		f := &findings[i]
+		if f.Name == name && f.Key == key && f.Rule == rule {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	r := Default()
+	if r.Version == "" {
+		t.Fatal("bundled registry has no version")
+	}
+	if _, ok := r.Attribute("http.request.method"); !ok {
+		t.Error("http.request.method not registered")
+	}
+	if _, ok := r.Attribute("http.request.header.x-request-id"); !ok {
+		t.Error("template key http.request.header.<name> not matched")
+	}
+	if _, ok := r.Attribute("http.request.headers"); ok {
+		t.Error("template matched a key that only shares its prefix")
+	}
+}
+
+func TestParse_Duplicate(t *testing.T) {
+	data := []byte("version: x\nattributes:\n  - {key: a.b}\n  - {key: a.b}\n")
+	if _, err := Parse(data); err == nil {
+		t.Fatal("expected an error for a duplicate attribute")
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	attrs := []*models.AttributeMetadata{
+		models.NewAttributeMetadata("http.method"),
+		models.NewAttributeMetadata("http.foo"),
+		models.NewAttributeMetadata("http.request.method"),
+		models.NewAttributeMetadata("tenant.id"),
+	}
+	findings := Default().ValidateAttributes(attrs)
+	if len(findings) != 2 {
+		t.Fatalf("findings = %+v, want 2", findings)
+	}
+
+	f := findRule(findings, "http.method", "", models.SemconvRuleDeprecated)
+	if f == nil {
+		t.Fatalf("http.method not reported as deprecated: %+v", findings)
+	}
+	if f.Replacement != "http.request.method" || f.Severity != models.SemconvSeverityWarning {
+		t.Errorf("http.method finding = %+v", f)
+	}
+	if f := findRule(findings, "http.foo", "", models.SemconvRuleUnknown); f == nil || f.Severity != models.SemconvSeverityInfo {
+		t.Errorf("http.foo finding = %+v, want unknown info", f)
+	}
+}
+
+func TestValidateMetrics(t *testing.T) {
+	deprecated := models.NewMetricMetadata("http.server.duration", &models.HistogramMetric{})
+	deprecated.Unit = "ms"
+	wrongUnit := models.NewMetricMetadata("http.server.request.duration", &models.HistogramMetric{})
+	wrongUnit.Unit = "ms"
+	wrongInstrument := models.NewMetricMetadata("http.client.request.duration", &models.GaugeMetric{})
+	wrongInstrument.Unit = "s"
+	custom := models.NewMetricMetadata("orders.placed", &models.SumMetric{IsMonotonic: true})
+
+	findings := Default().ValidateMetrics([]*models.MetricMetadata{deprecated, wrongUnit, wrongInstrument, custom})
+
+	f := findRule(findings, "http.server.duration", "", models.SemconvRuleDeprecated)
+	if f == nil || f.Replacement != "http.server.request.duration" {
+		t.Fatalf("deprecated finding = %+v", f)
+	}
+	if !strings.Contains(f.Message, `unit "s"`) {
+		t.Errorf("deprecated message %q does not mention the unit change", f.Message)
+	}
+	if findRule(findings, "http.server.request.duration", "", models.SemconvRuleUnitMismatch) == nil {
+		t.Error("unit mismatch not reported")
+	}
+	if findRule(findings, "http.client.request.duration", "", models.SemconvRuleInstrumentMismatch) == nil {
+		t.Error("instrument mismatch not reported")
+	}
+	for _, f := range findings {
+		if f.Name == "orders.placed" {
+			t.Errorf("custom metric reported: %+v", f)
+		}
+	}
+}
+
+func TestValidateSpans(t *testing.T) {
+	span := models.NewSpanMetadata("GET /orders", 2, "Server")
+	for _, key := range []string{"http.method", "url.path", "url.scheme"} {
+		span.AttributeKeys[key] = models.NewKeyMetadata()
+	}
+	internal := models.NewSpanMetadata("compute", 1, "Internal")
+	internal.AttributeKeys["http.method"] = models.NewKeyMetadata()
+
+	findings := Default().ValidateSpans([]*models.SpanMetadata{span, internal})
+
+	f := findRule(findings, "GET /orders", "http.request.method", models.SemconvRuleMissingRequired)
+	if f == nil {
+		t.Fatalf("missing http.request.method not reported: %+v", findings)
+	}
+	if f.Severity != models.SemconvSeverityWarning || !strings.Contains(f.Message, "deprecated http.method") {
+		t.Errorf("missing required finding = %+v", f)
+	}
+	if findRule(findings, "GET /orders", "url.path", models.SemconvRuleMissingRequired) != nil {
+		t.Error("url.path reported although present")
+	}
+	if findRule(findings, "GET /orders", "http.route", models.SemconvRuleMissingConditionally) == nil {
+		t.Error("missing conditionally required http.route not reported")
+	}
+	for _, f := range findings {
+		if f.Name == "compute" {
+			t.Errorf("internal span matched the HTTP server convention: %+v", f)
+		}
+	}
+}
+
+func TestValidate_OrderAndFilter(t *testing.T) {
+	attrs := []*models.AttributeMetadata{
+		models.NewAttributeMetadata("http.foo"),
+		models.NewAttributeMetadata("http.method"),
+	}
+	resp := Default().Validate(nil, nil, attrs)
+	if resp.Total != 2 || resp.Findings[0].Severity != models.SemconvSeverityWarning {
+		t.Fatalf("findings = %+v, want the warning first", resp.Findings)
+	}
+	if resp.ByRule[models.SemconvRuleDeprecated] != 1 || resp.ByRule[models.SemconvRuleUnknown] != 1 {
+		t.Errorf("by_rule = %v", resp.ByRule)
+	}
+
+	warnings := resp.Filter("", models.SemconvSeverityWarning)
+	if warnings.Total != 1 || warnings.ByRule[models.SemconvRuleUnknown] != 0 {
+		t.Errorf("warnings = %+v", warnings)
+	}
+	if metrics := resp.Filter("metric", ""); metrics.Total != 0 || metrics.Findings == nil {
+		t.Errorf("metric findings = %+v, want an empty list", metrics)
+	}
+}
@@ -0,0 +1,256 @@
+// Package simulate runs candidate drop, keep and relabel rules in shadow
+// against incoming telemetry. Each simulation sketches the data as received
+// and as its rules would have left it, without changing what is stored.
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/analyzer"
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// MaxSimulations caps the simulations running per tenant, since every
+// incoming record is transformed once per simulation.
+const MaxSimulations = 10
+
+// ErrNotFound is returned for an unknown simulation ID.
+var ErrNotFound = errors.New("simulation not found")
+
+// ErrTooMany is returned when a tenant already runs MaxSimulations.
+var ErrTooMany = fmt.Errorf("at most %d simulations can run at once", MaxSimulations)
+
+// Registry holds the running simulations of every tenant. A nil Registry
+// has none and observes nothing.
+type Registry struct {
+	mu       sync.RWMutex
+	next     int
+	byTenant map[string][]*Simulation
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byTenant: make(map[string][]*Simulation)}
+}
+
+// tenantID keys simulations by the tenant carried by ctx; "" when
+// multi-tenancy is off.
+func tenantID(ctx context.Context) string {
+	if t := tenant.FromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// Create validates spec and starts a simulation for the tenant of ctx.
+func (r *Registry) Create(ctx context.Context, spec models.SimulationSpec) (*Simulation, error) {
+	if len(spec.Rules) == 0 {
+		return nil, fmt.Errorf("at least one rule is required")
+	}
+	rules := make([]*rule, 0, len(spec.Rules))
+	for i, sr := range spec.Rules {
+		c, err := compileRule(sr)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules = append(rules, c)
+	}
+
+	id := tenantID(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.byTenant[id]) >= MaxSimulations {
+		return nil, ErrTooMany
+	}
+	r.next++
+	sim := newSimulation(models.SimulationSummary{
+		ID:        fmt.Sprintf("sim-%d", r.next),
+		Name:      spec.Name,
+		Rules:     spec.Rules,
+		CreatedAt: time.Now().UTC(),
+	}, rules)
+	r.byTenant[id] = append(r.byTenant[id], sim)
+	return sim, nil
+}
+
+// Get returns a simulation of the tenant of ctx.
+func (r *Registry) Get(ctx context.Context, id string) (*Simulation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, sim := range r.byTenant[tenantID(ctx)] {
+		if sim.summary.ID == id {
+			return sim, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// List describes the simulations of the tenant of ctx, oldest first.
+func (r *Registry) List(ctx context.Context) []models.SimulationSummary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sims := r.byTenant[tenantID(ctx)]
+	out := make([]models.SimulationSummary, 0, len(sims))
+	for _, sim := range sims {
+		out = append(out, sim.summary)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Delete stops a simulation of the tenant of ctx and frees its sketches.
+func (r *Registry) Delete(ctx context.Context, id string) error {
+	tid := tenantID(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sims := r.byTenant[tid]
+	for i, sim := range sims {
+		if sim.summary.ID != id {
+			continue
+		}
+		r.byTenant[tid] = append(sims[:i:i], sims[i+1:]...)
+		if len(r.byTenant[tid]) == 0 {
+			delete(r.byTenant, tid)
+		}
+		sim.release()
+		return nil
+	}
+	return ErrNotFound
+}
+
+// Observer feeds the data of one tenant to its simulations. A nil Observer
+// discards everything.
+type Observer struct {
+	sims []*Simulation
+}
+
+// Observer returns the observer for the tenant of ctx, or nil if it runs no
+// simulations. Ingestion takes it before queueing a request, as the tenant
+// is not carried past the pipeline.
+func (r *Registry) Observer(ctx context.Context) *Observer {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sims := r.byTenant[tenantID(ctx)]
+	if len(sims) == 0 {
+		return nil
+	}
+	return &Observer{sims: append([]*Simulation(nil), sims...)}
+}
+
+func (o *Observer) observe(service string, rec record) {
+	for _, sim := range o.sims {
+		sim.observe(service, rec)
+	}
+}
+
+// Metrics observes every data point of req.
+func (o *Observer) Metrics(req *colmetricspb.ExportMetricsServiceRequest) {
+	if o == nil {
+		return
+	}
+	for _, rm := range req.GetResourceMetrics() {
+		resource := analyzer.Attributes(rm.GetResource().GetAttributes())
+		service := analyzer.ServiceName(resource)
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				for _, attrs := range dataPointAttributes(m) {
+					o.observe(service, record{
+						signal:   signalMetrics,
+						name:     m.GetName(),
+						resource: resource,
+						attrs:    analyzer.Attributes(attrs),
+					})
+				}
+			}
+		}
+	}
+}
+
+// Traces observes every span of req.
+func (o *Observer) Traces(req *coltracepb.ExportTraceServiceRequest) {
+	if o == nil {
+		return
+	}
+	for _, rs := range req.GetResourceSpans() {
+		resource := analyzer.Attributes(rs.GetResource().GetAttributes())
+		service := analyzer.ServiceName(resource)
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				o.observe(service, record{
+					signal:   signalTraces,
+					name:     span.GetName(),
+					resource: resource,
+					attrs:    analyzer.Attributes(span.GetAttributes()),
+				})
+			}
+		}
+	}
+}
+
+// Logs observes every log record of req. Rules match logs by severity
+// text, UNSET when absent, as the log analysis groups them.
+func (o *Observer) Logs(req *collogspb.ExportLogsServiceRequest) {
+	if o == nil {
+		return
+	}
+	for _, rl := range req.GetResourceLogs() {
+		resource := analyzer.Attributes(rl.GetResource().GetAttributes())
+		service := analyzer.ServiceName(resource)
+		for _, sl := range rl.GetScopeLogs() {
+			for _, lr := range sl.GetLogRecords() {
+				severity := lr.GetSeverityText()
+				if severity == "" {
+					severity = "UNSET"
+				}
+				o.observe(service, record{
+					signal:   signalLogs,
+					name:     severity,
+					resource: resource,
+					attrs:    analyzer.Attributes(lr.GetAttributes()),
+				})
+			}
+		}
+	}
+}
+
+// dataPointAttributes lists the attributes of each data point of m.
+func dataPointAttributes(m *metricspb.Metric) [][]*commonpb.KeyValue {
+	var out [][]*commonpb.KeyValue
+	switch data := m.Data.(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range data.Gauge.DataPoints {
+			out = append(out, dp.Attributes)
+		}
+	case *metricspb.Metric_Sum:
+		for _, dp := range data.Sum.DataPoints {
+			out = append(out, dp.Attributes)
+		}
+	case *metricspb.Metric_Histogram:
+		for _, dp := range data.Histogram.DataPoints {
+			out = append(out, dp.Attributes)
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, dp := range data.ExponentialHistogram.DataPoints {
+			out = append(out, dp.Attributes)
+		}
+	case *metricspb.Metric_Summary:
+		for _, dp := range data.Summary.DataPoints {
+			out = append(out, dp.Attributes)
+		}
+	}
+	return out
+}
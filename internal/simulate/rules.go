@@ -0,0 +1,165 @@
+package simulate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// Signals a rule can be limited to.
+const (
+	signalMetrics = "metrics"
+	signalTraces  = "traces"
+	signalLogs    = "logs"
+)
+
+type rule struct {
+	models.SimulationRule
+	signals    map[string]bool
+	match      *regexp.Regexp
+	whereValue *regexp.Regexp
+	regex      *regexp.Regexp
+	labels     map[string]bool
+}
+
+func compileRule(r models.SimulationRule) (*rule, error) {
+	c := &rule{SimulationRule: r, signals: make(map[string]bool)}
+	for _, s := range r.Signals {
+		switch s {
+		case signalMetrics, signalTraces, signalLogs:
+			c.signals[s] = true
+		default:
+			return nil, fmt.Errorf("unknown signal %q: must be metrics, traces or logs", s)
+		}
+	}
+
+	var err error
+	if r.Match != "" {
+		if c.match, err = regexp.Compile("^(?:" + r.Match + ")$"); err != nil {
+			return nil, fmt.Errorf("match: %w", err)
+		}
+	}
+	if r.WhereValue != "" {
+		if r.WhereLabel == "" {
+			return nil, fmt.Errorf("where_value needs where_label")
+		}
+		if c.whereValue, err = regexp.Compile("^(?:" + r.WhereValue + ")$"); err != nil {
+			return nil, fmt.Errorf("where_value: %w", err)
+		}
+	}
+
+	switch r.Action {
+	case models.SimulationDropLabels, models.SimulationKeepLabels:
+		if len(r.Labels) == 0 {
+			return nil, fmt.Errorf("%s needs labels", r.Action)
+		}
+		c.labels = make(map[string]bool, len(r.Labels))
+		for _, l := range r.Labels {
+			c.labels[l] = true
+		}
+	case models.SimulationRelabel:
+		if r.SourceLabel == "" || r.Regex == "" {
+			return nil, fmt.Errorf("relabel needs source_label and regex")
+		}
+		if c.regex, err = regexp.Compile("^(?:" + r.Regex + ")$"); err != nil {
+			return nil, fmt.Errorf("regex: %w", err)
+		}
+		if c.TargetLabel == "" {
+			c.TargetLabel = r.SourceLabel
+		}
+	case models.SimulationDrop:
+		if r.Match == "" && r.WhereLabel == "" {
+			return nil, fmt.Errorf("drop needs match or where_label")
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q: must be drop_labels, keep_labels, relabel or drop", r.Action)
+	}
+	return c, nil
+}
+
+// record is one data point, span or log record with its attributes. The
+// rules rewrite resource and attrs in place.
+type record struct {
+	signal   string
+	name     string
+	resource map[string]string
+	attrs    map[string]string
+}
+
+func (r *record) label(key string) (string, bool) {
+	if v, ok := r.attrs[key]; ok {
+		return v, true
+	}
+	v, ok := r.resource[key]
+	return v, ok
+}
+
+func (c *rule) appliesTo(rec *record) bool {
+	if len(c.signals) > 0 && !c.signals[rec.signal] {
+		return false
+	}
+	if c.match != nil && !c.match.MatchString(rec.name) {
+		return false
+	}
+	if c.WhereLabel != "" {
+		v, ok := rec.label(c.WhereLabel)
+		if !ok || (c.whereValue != nil && !c.whereValue.MatchString(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply rewrites rec and reports whether it is kept.
+func (c *rule) apply(rec *record) bool {
+	if !c.appliesTo(rec) {
+		return true
+	}
+	switch c.Action {
+	case models.SimulationDrop:
+		return false
+	case models.SimulationDropLabels:
+		for _, m := range []map[string]string{rec.resource, rec.attrs} {
+			for k := range m {
+				if c.labels[k] {
+					delete(m, k)
+				}
+			}
+		}
+	case models.SimulationKeepLabels:
+		for _, m := range []map[string]string{rec.resource, rec.attrs} {
+			for k := range m {
+				if !c.labels[k] {
+					delete(m, k)
+				}
+			}
+		}
+	case models.SimulationRelabel:
+		c.relabel(rec)
+	}
+	return true
+}
+
+func (c *rule) relabel(rec *record) {
+	v, ok := rec.label(c.SourceLabel)
+	if !ok {
+		return
+	}
+	idx := c.regex.FindStringSubmatchIndex(v)
+	if idx == nil {
+		return
+	}
+	value := string(c.regex.ExpandString(nil, c.Replacement, v, idx))
+
+	// The target keeps the level of the source label.
+	target := rec.attrs
+	if _, inAttrs := rec.attrs[c.SourceLabel]; !inAttrs {
+		target = rec.resource
+	}
+	delete(rec.attrs, c.TargetLabel)
+	delete(rec.resource, c.TargetLabel)
+	if value != "" {
+		target[c.TargetLabel] = value
+	}
+}
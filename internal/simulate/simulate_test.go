@@ -0,0 +1,276 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func kv(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func resource(service string) *resourcepb.Resource {
+	return &resourcepb.Resource{Attributes: []*commonpb.KeyValue{kv("service.name", service)}}
+}
+
+// gaugeRequest sends one gauge data point per user ID, each also labelled
+// with a method out of two.
+func gaugeRequest(service, name string, users int) *colmetricspb.ExportMetricsServiceRequest {
+	var dps []*metricspb.NumberDataPoint
+	for i := 0; i < users; i++ {
+		dps = append(dps, &metricspb.NumberDataPoint{Attributes: []*commonpb.KeyValue{
+			kv("user_id", fmt.Sprintf("u%d", i)),
+			kv("method", []string{"GET", "POST"}[i%2]),
+		}})
+	}
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: resource(service),
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{{
+					Name: name,
+					Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: dps}},
+				}},
+			}},
+		}},
+	}
+}
+
+func service(res *models.SimulationResult, name string) *models.SimulationService {
+	for _, s := range res.Services {
+		if s.Service == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func attribute(res *models.SimulationResult, key string) *models.SimulationAttribute {
+	for _, a := range res.Attributes {
+		if a.Key == key {
+			return a
+		}
+	}
+	return nil
+}
+
+// near reports whether an HLL estimate is within 10% of want, about three
+// standard errors at precision 10.
+func near(got, want int64) bool {
+	return math.Abs(float64(got-want)) <= 0.10*float64(want)
+}
+
+func TestDropLabels(t *testing.T) {
+	reg := NewRegistry()
+	ctx := context.Background()
+	sim, err := reg.Create(ctx, models.SimulationSpec{
+		Name:  "drop user_id",
+		Rules: []models.SimulationRule{{Action: models.SimulationDropLabels, Labels: []string{"user_id"}}},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	reg.Observer(ctx).Metrics(gaugeRequest("checkout", "cart_size", 1000))
+	res := sim.Result()
+
+	svc := service(res, "checkout")
+	if svc == nil {
+		t.Fatalf("no checkout service in %+v", res.Services)
+	}
+	if !near(svc.Before.ActiveSeries, 1000) {
+		t.Errorf("before series = %d, want ~1000", svc.Before.ActiveSeries)
+	}
+	if svc.After.ActiveSeries != 2 {
+		t.Errorf("after series = %d, want 2", svc.After.ActiveSeries)
+	}
+	if svc.Before.MetricSamples != 1000 || svc.After.MetricSamples != 1000 {
+		t.Errorf("samples = %d/%d, want 1000/1000", svc.Before.MetricSamples, svc.After.MetricSamples)
+	}
+	if svc.SeriesReductionPercent < 99 {
+		t.Errorf("reduction = %.1f%%, want > 99%%", svc.SeriesReductionPercent)
+	}
+
+	user := attribute(res, "user_id")
+	if user == nil || !near(user.Before, 1000) || user.After != 0 {
+		t.Errorf("user_id = %+v, want ~1000 before and 0 after", user)
+	}
+	if method := attribute(res, "method"); method == nil || method.Before != 2 || method.After != 2 {
+		t.Errorf("method = %+v, want 2 before and after", method)
+	}
+	if res.Attributes[0].Key != "user_id" {
+		t.Errorf("first attribute = %q, want the largest reduction first", res.Attributes[0].Key)
+	}
+}
+
+func TestKeepLabelsAndRelabel(t *testing.T) {
+	reg := NewRegistry()
+	ctx := context.Background()
+	keep, err := reg.Create(ctx, models.SimulationSpec{Rules: []models.SimulationRule{
+		{Action: models.SimulationKeepLabels, Labels: []string{"service.name", "method"}},
+	}})
+	if err != nil {
+		t.Fatalf("Create keep: %v", err)
+	}
+	relabel, err := reg.Create(ctx, models.SimulationSpec{Rules: []models.SimulationRule{
+		// Bucket users by the first digit of their ID.
+		{Action: models.SimulationRelabel, SourceLabel: "user_id", Regex: `u(\d)\d*`, TargetLabel: "user_id", Replacement: "bucket-$1"},
+	}})
+	if err != nil {
+		t.Fatalf("Create relabel: %v", err)
+	}
+
+	reg.Observer(ctx).Metrics(gaugeRequest("checkout", "cart_size", 1000))
+
+	if got := keep.Result().Totals.After.ActiveSeries; got != 2 {
+		t.Errorf("keep_labels after series = %d, want 2", got)
+	}
+	res := relabel.Result()
+	// Ten buckets with both methods, except bucket-0 which only holds u0.
+	if got := res.Totals.After.ActiveSeries; got != 19 {
+		t.Errorf("relabel after series = %d, want 19", got)
+	}
+	if user := attribute(res, "user_id"); user == nil || user.After != 10 {
+		t.Errorf("user_id = %+v, want 10 values after", user)
+	}
+}
+
+func TestDropByMatcher(t *testing.T) {
+	reg := NewRegistry()
+	ctx := context.Background()
+	sim, err := reg.Create(ctx, models.SimulationSpec{Rules: []models.SimulationRule{
+		{Action: models.SimulationDrop, Signals: []string{"metrics"}, Match: "debug_.*"},
+		{Action: models.SimulationDrop, Signals: []string{"traces"}, WhereLabel: "http.route", WhereValue: "/health.*"},
+		{Action: models.SimulationDrop, Signals: []string{"logs"}, Match: "DEBUG"},
+	}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	obs := reg.Observer(ctx)
+	obs.Metrics(gaugeRequest("api", "debug_queue_depth", 10))
+	obs.Metrics(gaugeRequest("api", "requests_debug_total", 10)) // match is anchored
+	obs.Traces(&coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource: resource("api"),
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{
+			{Name: "GET", Attributes: []*commonpb.KeyValue{kv("http.route", "/healthz")}},
+			{Name: "GET", Attributes: []*commonpb.KeyValue{kv("http.route", "/orders")}},
+			{Name: "GET"},
+		}}},
+	}}})
+	obs.Logs(&collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: resource("worker"),
+		ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{
+			{SeverityText: "DEBUG"}, {SeverityText: "DEBUG"}, {SeverityText: "INFO"}, {},
+		}}},
+	}}})
+
+	res := sim.Result()
+	api := service(res, "api")
+	if api == nil {
+		t.Fatal("no api service")
+	}
+	if api.Before.ActiveSeries != 20 || api.After.ActiveSeries != 10 {
+		t.Errorf("api series = %d → %d, want 20 → 10", api.Before.ActiveSeries, api.After.ActiveSeries)
+	}
+	if api.Before.MetricSamples != 20 || api.After.MetricSamples != 10 {
+		t.Errorf("api samples = %d → %d, want 20 → 10", api.Before.MetricSamples, api.After.MetricSamples)
+	}
+	if api.Before.Spans != 3 || api.After.Spans != 2 {
+		t.Errorf("api spans = %d → %d, want 3 → 2", api.Before.Spans, api.After.Spans)
+	}
+
+	worker := service(res, "worker")
+	if worker == nil || worker.Before.LogRecords != 4 || worker.After.LogRecords != 2 {
+		t.Errorf("worker = %+v, want 4 → 2 log records", worker)
+	}
+	if res.Totals.Before.LogRecords != 4 || res.Totals.After.Spans != 2 {
+		t.Errorf("totals = %+v", res.Totals)
+	}
+}
+
+func TestCreateValidation(t *testing.T) {
+	reg := NewRegistry()
+	ctx := context.Background()
+	bad := []models.SimulationSpec{
+		{},
+		{Rules: []models.SimulationRule{{Action: "rename"}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationDropLabels}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationRelabel, SourceLabel: "a"}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationRelabel, SourceLabel: "a", Regex: "("}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationDrop}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationDrop, Match: "x", Signals: []string{"profiles"}}}},
+		{Rules: []models.SimulationRule{{Action: models.SimulationDrop, WhereValue: "x"}}},
+	}
+	for i, spec := range bad {
+		if _, err := reg.Create(ctx, spec); err == nil {
+			t.Errorf("spec %d: Create succeeded, want error", i)
+		}
+	}
+	if n := len(reg.List(ctx)); n != 0 {
+		t.Errorf("List = %d simulations, want 0", n)
+	}
+}
+
+func TestRegistryPerTenant(t *testing.T) {
+	reg := NewRegistry()
+	acme := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "acme"})
+	globex := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "globex"})
+	spec := models.SimulationSpec{Rules: []models.SimulationRule{{Action: models.SimulationDropLabels, Labels: []string{"user_id"}}}}
+
+	sim, err := reg.Create(acme, spec)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := reg.Get(globex, sim.Summary().ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get from other tenant: err = %v, want ErrNotFound", err)
+	}
+	if reg.Observer(globex) != nil {
+		t.Error("Observer for a tenant without simulations should be nil")
+	}
+
+	reg.Observer(globex).Metrics(gaugeRequest("checkout", "cart_size", 10))
+	reg.Observer(acme).Metrics(gaugeRequest("checkout", "cart_size", 5))
+	if got := sim.Result().Totals.Before.MetricSamples; got != 5 {
+		t.Errorf("samples = %d, want only acme's 5", got)
+	}
+
+	for i := 1; i < MaxSimulations; i++ {
+		if _, err := reg.Create(acme, spec); err != nil {
+			t.Fatalf("Create %d: %v", i, err)
+		}
+	}
+	if _, err := reg.Create(acme, spec); !errors.Is(err, ErrTooMany) {
+		t.Errorf("Create over the cap: err = %v, want ErrTooMany", err)
+	}
+	if _, err := reg.Create(globex, spec); err != nil {
+		t.Errorf("Create for other tenant: %v", err)
+	}
+
+	if err := reg.Delete(acme, sim.Summary().ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := reg.Delete(acme, sim.Summary().ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrNotFound", err)
+	}
+	if n := len(reg.List(acme)); n != MaxSimulations-1 {
+		t.Errorf("List = %d, want %d", n, MaxSimulations-1)
+	}
+
+	var nilReg *Registry
+	nilReg.Observer(acme).Metrics(gaugeRequest("checkout", "cart_size", 1))
+}
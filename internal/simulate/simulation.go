@@ -0,0 +1,237 @@
+package simulate
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// maxAttributeKeys caps the attribute keys whose value cardinality a
+// simulation tracks. Each key costs two 1 KB sketches.
+const maxAttributeKeys = 256
+
+// side holds the sketches of one view of the data: as received, or as the
+// rules would have left it.
+type side struct {
+	series map[string]*hyperloglog.HyperLogLog // by service
+	counts map[string]*models.SimulationCounts // by service
+	values map[string]*hyperloglog.HyperLogLog // by attribute key
+}
+
+func newSide() *side {
+	return &side{
+		series: make(map[string]*hyperloglog.HyperLogLog),
+		counts: make(map[string]*models.SimulationCounts),
+		values: make(map[string]*hyperloglog.HyperLogLog),
+	}
+}
+
+func (s *side) service(name string) *models.SimulationCounts {
+	c, ok := s.counts[name]
+	if !ok {
+		c = &models.SimulationCounts{}
+		s.counts[name] = c
+	}
+	return c
+}
+
+// Simulation applies a rule set in shadow to the data received since it was
+// created and keeps sketches of the data with and without the rules.
+type Simulation struct {
+	summary models.SimulationSummary
+	rules   []*rule
+
+	mu        sync.Mutex
+	before    *side
+	after     *side
+	keys      map[string]bool
+	truncated bool
+}
+
+func newSimulation(summary models.SimulationSummary, rules []*rule) *Simulation {
+	return &Simulation{
+		summary: summary,
+		rules:   rules,
+		before:  newSide(),
+		after:   newSide(),
+		keys:    make(map[string]bool),
+	}
+}
+
+// Summary describes the simulation.
+func (s *Simulation) Summary() models.SimulationSummary {
+	return s.summary
+}
+
+// observe records rec as received and as transformed by the rules. Counts
+// stay with the service that sent the record even if the rules rewrite
+// service.name, so both sides compare the same sender.
+func (s *Simulation) observe(service string, rec record) {
+	transformed := record{
+		signal:   rec.signal,
+		name:     rec.name,
+		resource: cloneMap(rec.resource),
+		attrs:    cloneMap(rec.attrs),
+	}
+	kept := true
+	for _, r := range s.rules {
+		if kept = r.apply(&transformed); !kept {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(s.before, service, &rec)
+	if kept {
+		s.add(s.after, service, &transformed)
+	}
+}
+
+func (s *Simulation) add(sd *side, service string, rec *record) {
+	c := sd.service(service)
+	switch rec.signal {
+	case signalMetrics:
+		c.MetricSamples++
+		h, ok := sd.series[service]
+		if !ok {
+			h = hyperloglog.New(10)
+			sd.series[service] = h
+		}
+		h.Add(rec.name + "\x00" + models.CreateSeriesFingerprintWithResource(rec.resource, rec.attrs))
+	case signalTraces:
+		c.Spans++
+	case signalLogs:
+		c.LogRecords++
+	}
+
+	for _, m := range []map[string]string{rec.resource, rec.attrs} {
+		for k, v := range m {
+			h, ok := sd.values[k]
+			if !ok {
+				if !s.trackKey(k) {
+					continue
+				}
+				h = hyperloglog.New(10)
+				sd.values[k] = h
+			}
+			h.Add(v)
+		}
+	}
+}
+
+// trackKey reports whether key is tracked, tracking it if it is new and
+// fits under the cap. Both sides share the cap so they track the same keys.
+func (s *Simulation) trackKey(key string) bool {
+	if s.keys[key] {
+		return true
+	}
+	if len(s.keys) >= maxAttributeKeys {
+		s.truncated = true
+		return false
+	}
+	s.keys[key] = true
+	return true
+}
+
+// Result compares the data seen so far with and without the rules.
+func (s *Simulation) Result() *models.SimulationResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := &models.SimulationResult{
+		SimulationSummary:   s.summary,
+		Services:            make([]*models.SimulationService, 0, len(s.before.counts)),
+		Attributes:          make([]*models.SimulationAttribute, 0, len(s.before.values)),
+		AttributesTruncated: s.truncated,
+	}
+
+	totalBefore, totalAfter := hyperloglog.New(10), hyperloglog.New(10)
+	defer totalBefore.Release()
+	defer totalAfter.Release()
+	for name, before := range s.before.counts {
+		svc := &models.SimulationService{Service: name}
+		svc.Before = *before
+		svc.After = *s.after.service(name)
+		if h := s.before.series[name]; h != nil {
+			svc.Before.ActiveSeries = int64(h.Count())
+			totalBefore.Merge(h)
+		}
+		if h := s.after.series[name]; h != nil {
+			svc.After.ActiveSeries = int64(h.Count())
+			totalAfter.Merge(h)
+		}
+		svc.SeriesReductionPercent = reductionPercent(svc.Before.ActiveSeries, svc.After.ActiveSeries)
+		res.Services = append(res.Services, svc)
+
+		res.Totals.Before.MetricSamples += svc.Before.MetricSamples
+		res.Totals.Before.Spans += svc.Before.Spans
+		res.Totals.Before.LogRecords += svc.Before.LogRecords
+		res.Totals.After.MetricSamples += svc.After.MetricSamples
+		res.Totals.After.Spans += svc.After.Spans
+		res.Totals.After.LogRecords += svc.After.LogRecords
+	}
+	res.Totals.Before.ActiveSeries = int64(totalBefore.Count())
+	res.Totals.After.ActiveSeries = int64(totalAfter.Count())
+	res.Totals.SeriesReductionPercent = reductionPercent(res.Totals.Before.ActiveSeries, res.Totals.After.ActiveSeries)
+
+	for k := range s.keys {
+		a := &models.SimulationAttribute{Key: k}
+		if h := s.before.values[k]; h != nil {
+			a.Before = int64(h.Count())
+		}
+		if h := s.after.values[k]; h != nil {
+			a.After = int64(h.Count())
+		}
+		res.Attributes = append(res.Attributes, a)
+	}
+
+	sort.Slice(res.Services, func(i, j int) bool {
+		a, b := res.Services[i], res.Services[j]
+		if ra, rb := a.Before.ActiveSeries-a.After.ActiveSeries, b.Before.ActiveSeries-b.After.ActiveSeries; ra != rb {
+			return ra > rb
+		}
+		return a.Service < b.Service
+	})
+	sort.Slice(res.Attributes, func(i, j int) bool {
+		a, b := res.Attributes[i], res.Attributes[j]
+		if ra, rb := a.Before-a.After, b.Before-b.After; ra != rb {
+			return ra > rb
+		}
+		return a.Key < b.Key
+	})
+	return res
+}
+
+// release returns the simulation's sketches to the pool.
+func (s *Simulation) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sd := range []*side{s.before, s.after} {
+		for _, h := range sd.series {
+			h.Release()
+		}
+		for _, h := range sd.values {
+			h.Release()
+		}
+	}
+	s.before, s.after = newSide(), newSide()
+	s.keys = make(map[string]bool)
+}
+
+func reductionPercent(before, after int64) float64 {
+	if before <= 0 || after >= before {
+		return 0
+	}
+	return float64(before-after) / float64(before) * 100
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
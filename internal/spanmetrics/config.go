@@ -0,0 +1,169 @@
+// Package spanmetrics estimates how many series the collector's spanmetrics
+// connector would generate from the spans received, for a given dimension
+// list, histogram and exemplar configuration.
+package spanmetrics
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBucketCount is the number of explicit bounds of the connector's
+// default duration histogram (2ms to 15s).
+const DefaultBucketCount = 16
+
+// Default dimensions of every connector metric. service.name is always
+// present; the others can be removed with exclude_dimensions.
+const (
+	DimensionService = "service.name"
+	DimensionName    = "span.name"
+	DimensionKind    = "span.kind"
+	DimensionStatus  = "status.code"
+)
+
+// Dimension is an attribute added to the series identity. Spans without it
+// fall back to Default, or leave it out when Default is unset.
+type Dimension struct {
+	Name    string  `yaml:"name"`
+	Default *string `yaml:"default"`
+}
+
+// ExplicitHistogram configures the duration histogram with fixed bounds.
+// Buckets is the connector's list of bounds; BucketCount can be given
+// instead when only the number matters.
+type ExplicitHistogram struct {
+	Buckets     []time.Duration `yaml:"buckets"`
+	BucketCount int             `yaml:"bucket_count"`
+}
+
+// ExponentialHistogram configures an exponential duration histogram.
+type ExponentialHistogram struct {
+	MaxSize int `yaml:"max_size"`
+}
+
+// Histogram holds at most one of Explicit and Exponential. Neither means
+// the connector default: explicit with DefaultBucketCount bounds.
+type Histogram struct {
+	Explicit    *ExplicitHistogram    `yaml:"explicit"`
+	Exponential *ExponentialHistogram `yaml:"exponential"`
+}
+
+// Exemplars configures exemplars on the duration histogram. A zero
+// MaxPerDataPoint means no limit.
+type Exemplars struct {
+	Enabled         bool `yaml:"enabled"`
+	MaxPerDataPoint int  `yaml:"max_per_data_point"`
+}
+
+// Config follows the layout of the connector's configuration, so its
+// spanmetrics section can be used as is. Options that do not change the
+// series count are ignored.
+type Config struct {
+	Dimensions        []Dimension `yaml:"dimensions"`
+	ExcludeDimensions []string    `yaml:"exclude_dimensions"`
+	Histogram         Histogram   `yaml:"histogram"`
+	Exemplars         Exemplars   `yaml:"exemplars"`
+}
+
+// Load reads and validates a spanmetrics configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading spanmetrics config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse validates a spanmetrics configuration.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing spanmetrics config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	seen := map[string]bool{DimensionService: true}
+	for _, d := range c.defaultDimensions() {
+		seen[d] = true
+	}
+	for i, d := range c.Dimensions {
+		if d.Name == "" {
+			return fmt.Errorf("dimension %d: name is required", i+1)
+		}
+		if seen[d.Name] {
+			return fmt.Errorf("dimension %q: duplicate of a default or earlier dimension", d.Name)
+		}
+		seen[d.Name] = true
+	}
+	for _, name := range c.ExcludeDimensions {
+		switch name {
+		case DimensionName, DimensionKind, DimensionStatus:
+		default:
+			return fmt.Errorf("exclude_dimensions: %q is not one of %s, %s or %s", name, DimensionName, DimensionKind, DimensionStatus)
+		}
+	}
+
+	h := c.Histogram
+	if h.Explicit != nil && h.Exponential != nil {
+		return fmt.Errorf("histogram: set explicit or exponential, not both")
+	}
+	if e := h.Explicit; e != nil {
+		if len(e.Buckets) > 0 && e.BucketCount > 0 && len(e.Buckets) != e.BucketCount {
+			return fmt.Errorf("histogram: bucket_count %d does not match the %d buckets listed", e.BucketCount, len(e.Buckets))
+		}
+		if e.BucketCount < 0 {
+			return fmt.Errorf("histogram: bucket_count must not be negative")
+		}
+	}
+	if h.Exponential != nil && h.Exponential.MaxSize < 0 {
+		return fmt.Errorf("histogram: max_size must not be negative")
+	}
+	if c.Exemplars.MaxPerDataPoint < 0 {
+		return fmt.Errorf("exemplars: max_per_data_point must not be negative")
+	}
+	return nil
+}
+
+// defaultDimensions returns the default dimensions after service.name that
+// are not excluded.
+func (c *Config) defaultDimensions() []string {
+	var out []string
+	for _, d := range []string{DimensionName, DimensionKind, DimensionStatus} {
+		excluded := false
+		for _, x := range c.ExcludeDimensions {
+			if x == d {
+				excluded = true
+			}
+		}
+		if !excluded {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// bucketCount returns the explicit histogram's number of bounds, or 0 for
+// an exponential histogram.
+func (c *Config) bucketCount() int {
+	switch h := c.Histogram; {
+	case h.Exponential != nil:
+		return 0
+	case h.Explicit != nil && len(h.Explicit.Buckets) > 0:
+		return len(h.Explicit.Buckets)
+	case h.Explicit != nil && h.Explicit.BucketCount > 0:
+		return h.Explicit.BucketCount
+	}
+	return DefaultBucketCount
+}
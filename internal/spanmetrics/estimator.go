@@ -0,0 +1,252 @@
+package spanmetrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Sketch precisions: combinations decide the estimate and get the 16 KB
+// sketch, per-dimension value counts are only a breakdown and get 1 KB.
+const (
+	combinationPrecision = 14
+	valuePrecision       = 10
+)
+
+// absent marks a dimension a span does not carry. The connector leaves the
+// attribute out, which is a value of its own for the series identity.
+const absent = "\x01"
+
+// serviceSketches holds the sketches of one service's spans.
+type serviceSketches struct {
+	spans        int64
+	combinations *hyperloglog.HyperLogLog
+	values       []*hyperloglog.HyperLogLog // per dimension after service.name
+}
+
+// tenantSketches holds the sketches of one tenant.
+type tenantSketches struct {
+	mu       sync.Mutex
+	services map[string]*serviceSketches
+}
+
+// Estimator keeps, per tenant and service, sketches of the dimension
+// combinations of the spans it is fed. A nil Estimator records nothing.
+type Estimator struct {
+	cfg      *Config
+	defaults []string // default dimensions after service.name
+
+	mu       sync.Mutex
+	byTenant map[string]*tenantSketches
+}
+
+// New creates an estimator for cfg, which must have passed Parse.
+func New(cfg *Config) *Estimator {
+	return &Estimator{
+		cfg:      cfg,
+		defaults: cfg.defaultDimensions(),
+		byTenant: make(map[string]*tenantSketches),
+	}
+}
+
+// tenantID keys sketches by the tenant carried by ctx; "" when
+// multi-tenancy is off.
+func tenantID(ctx context.Context) string {
+	if t := tenant.FromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// Dimensions returns the full dimension list in series identity order.
+func (e *Estimator) Dimensions() []string {
+	dims := append([]string{DimensionService}, e.defaults...)
+	for _, d := range e.cfg.Dimensions {
+		dims = append(dims, d.Name)
+	}
+	return dims
+}
+
+func (e *Estimator) tenant(ctx context.Context) *tenantSketches {
+	id := tenantID(ctx)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ts, ok := e.byTenant[id]
+	if !ok {
+		ts = &tenantSketches{services: make(map[string]*serviceSketches)}
+		e.byTenant[id] = ts
+	}
+	return ts
+}
+
+// Recorder feeds the spans of one request into the sketches of its tenant.
+// A nil Recorder records nothing.
+type Recorder struct {
+	e      *Estimator
+	ts     *tenantSketches
+	key    []byte
+	values []string
+}
+
+// Recorder returns a recorder for the tenant of ctx, or nil for a nil
+// Estimator.
+func (e *Estimator) Recorder(ctx context.Context) *Recorder {
+	if e == nil {
+		return nil
+	}
+	return &Recorder{e: e, ts: e.tenant(ctx)}
+}
+
+// Span records span of service. attrs are the span attributes and resource
+// the resource attributes; like the connector, dimensions are looked up in
+// attrs first.
+func (r *Recorder) Span(service string, resource, attrs map[string]string, span *tracepb.Span) {
+	if r == nil {
+		return
+	}
+
+	r.values = r.values[:0]
+	for _, d := range r.e.defaults {
+		switch d {
+		case DimensionName:
+			r.values = append(r.values, span.Name)
+		case DimensionKind:
+			r.values = append(r.values, span.Kind.String())
+		case DimensionStatus:
+			r.values = append(r.values, span.Status.GetCode().String())
+		}
+	}
+	for _, d := range r.e.cfg.Dimensions {
+		v, ok := attrs[d.Name]
+		if !ok {
+			v, ok = resource[d.Name]
+		}
+		if !ok && d.Default != nil {
+			v, ok = *d.Default, true
+		}
+		if !ok {
+			v = absent
+		}
+		r.values = append(r.values, v)
+	}
+
+	r.key = append(r.key[:0], service...)
+	for _, v := range r.values {
+		r.key = append(r.key, 0)
+		r.key = append(r.key, v...)
+	}
+
+	r.ts.mu.Lock()
+	defer r.ts.mu.Unlock()
+	s, ok := r.ts.services[service]
+	if !ok {
+		s = &serviceSketches{
+			combinations: hyperloglog.New(combinationPrecision),
+			values:       make([]*hyperloglog.HyperLogLog, len(r.values)),
+		}
+		for i := range s.values {
+			s.values[i] = hyperloglog.New(valuePrecision)
+		}
+		r.ts.services[service] = s
+	}
+	s.spans++
+	s.combinations.Add(string(r.key))
+	for i, v := range r.values {
+		s.values[i].Add(v)
+	}
+}
+
+// DropTenant releases the sketches of tenant id. Register it with the
+// tenant registry's OnDrop.
+func (e *Estimator) DropTenant(id string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.byTenant, id)
+}
+
+// Estimate projects the connector output for the spans recorded for the
+// tenant of ctx, services with the most Prometheus series first.
+func (e *Estimator) Estimate(ctx context.Context) *models.SpanMetricsEstimate {
+	dims := e.Dimensions()
+	out := &models.SpanMetricsEstimate{
+		Dimensions:               dims,
+		SeriesPerCombinationOTLP: 2, // calls and duration
+		Exemplars:                e.cfg.Exemplars.Enabled,
+		Services:                 []*models.SpanMetricsService{},
+		Total:                    models.SpanMetricsProjection{DimensionValues: make(map[string]int64, len(dims))},
+	}
+	if e.cfg.Histogram.Exponential != nil {
+		// A native histogram is a single Prometheus series.
+		out.Histogram = "exponential"
+		out.SeriesPerCombinationPrometheus = 2
+	} else {
+		out.Histogram = "explicit"
+		out.Buckets = e.cfg.bucketCount() + 1
+		out.SeriesPerCombinationPrometheus = 1 + out.Buckets + 2
+	}
+	if out.Exemplars {
+		out.MaxExemplarsPerDataPoint = e.cfg.Exemplars.MaxPerDataPoint
+		out.ExemplarsUnbounded = out.MaxExemplarsPerDataPoint == 0
+	}
+
+	ts := e.tenant(ctx)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	merged := make([]*hyperloglog.HyperLogLog, len(dims)-1)
+	for i := range merged {
+		merged[i] = hyperloglog.New(valuePrecision)
+	}
+	for name, s := range ts.services {
+		svc := &models.SpanMetricsService{
+			Service: name,
+			SpanMetricsProjection: models.SpanMetricsProjection{
+				Spans:           s.spans,
+				Combinations:    min(int64(s.combinations.Count()), s.spans),
+				DimensionValues: make(map[string]int64, len(dims)),
+			},
+		}
+		svc.DimensionValues[DimensionService] = 1
+		for i, h := range s.values {
+			svc.DimensionValues[dims[i+1]] = int64(h.Count())
+			merged[i].Merge(h) //nolint:errcheck // same precision
+		}
+		project(out, &svc.SpanMetricsProjection)
+		out.Services = append(out.Services, svc)
+
+		// service.name is a dimension, so services never share a
+		// combination and their counts add up.
+		out.Total.Spans += svc.Spans
+		out.Total.Combinations += svc.Combinations
+	}
+	out.Total.DimensionValues[DimensionService] = int64(len(ts.services))
+	for i, h := range merged {
+		out.Total.DimensionValues[dims[i+1]] = int64(h.Count())
+	}
+	project(out, &out.Total)
+
+	sort.Slice(out.Services, func(i, j int) bool {
+		if out.Services[i].SeriesPrometheus != out.Services[j].SeriesPrometheus {
+			return out.Services[i].SeriesPrometheus > out.Services[j].SeriesPrometheus
+		}
+		return out.Services[i].Service < out.Services[j].Service
+	})
+	return out
+}
+
+// project fills in the series and exemplars of p from its combinations.
+func project(est *models.SpanMetricsEstimate, p *models.SpanMetricsProjection) {
+	p.SeriesOTLP = p.Combinations * int64(est.SeriesPerCombinationOTLP)
+	p.SeriesPrometheus = p.Combinations * int64(est.SeriesPerCombinationPrometheus)
+	if est.Exemplars {
+		p.MaxExemplars = p.Combinations * int64(est.MaxExemplarsPerDataPoint)
+	}
+}
@@ -0,0 +1,130 @@
+package spanmetrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/tenant"
+)
+
+func TestParse(t *testing.T) {
+	if _, err := Load("../../config/spanmetrics.yaml"); err != nil {
+		t.Fatalf("example config: %v", err)
+	}
+
+	cfg, err := Parse([]byte(`
+histogram:
+  explicit:
+    buckets: [5ms, 10ms, 100ms, 1s]
+dimensions:
+  - name: http.route
+exclude_dimensions: [span.kind]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := cfg.bucketCount(); got != 4 {
+		t.Errorf("bucket count = %d, want 4", got)
+	}
+	if got := fmt.Sprint(New(cfg).Dimensions()); got != "[service.name span.name status.code http.route]" {
+		t.Errorf("dimensions = %s", got)
+	}
+
+	for name, data := range map[string]string{
+		"no name":          "dimensions: [{default: x}]",
+		"duplicate":        "dimensions: [{name: span.name}]",
+		"bad exclude":      "exclude_dimensions: [service.name]",
+		"both histograms":  "histogram: {explicit: {bucket_count: 4}, exponential: {max_size: 160}}",
+		"count mismatch":   "histogram: {explicit: {buckets: [1ms, 2ms], bucket_count: 3}}",
+		"negative max":     "exemplars: {enabled: true, max_per_data_point: -1}",
+		"invalid duration": "histogram: {explicit: {buckets: [fast]}}",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse succeeded, want error", name)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	ctx := context.Background()
+	cfg, err := Parse([]byte(`
+histogram:
+  explicit:
+    bucket_count: 10
+dimensions:
+  - name: http.route
+  - name: deployment.environment
+exemplars:
+  enabled: true
+  max_per_data_point: 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(cfg)
+
+	// checkout: 5 routes x {OK, ERROR} server spans, the environment from
+	// the resource. search: one span name without a route.
+	rec := e.Recorder(ctx)
+	resource := map[string]string{"deployment.environment": "prod"}
+	for i := 0; i < 100; i++ {
+		span := &tracepb.Span{Name: "HTTP GET", Kind: tracepb.Span_SPAN_KIND_SERVER}
+		if i%2 == 0 {
+			span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR}
+		}
+		rec.Span("checkout", resource, map[string]string{"http.route": fmt.Sprintf("/r%d", i%5)}, span)
+		rec.Span("search", nil, nil, &tracepb.Span{Name: "query"})
+	}
+
+	est := e.Estimate(ctx)
+	if est.Buckets != 11 || est.SeriesPerCombinationPrometheus != 14 || est.SeriesPerCombinationOTLP != 2 {
+		t.Errorf("series per combination = %d OTLP / %d Prometheus with %d buckets, want 2 / 14 with 11",
+			est.SeriesPerCombinationOTLP, est.SeriesPerCombinationPrometheus, est.Buckets)
+	}
+	if len(est.Services) != 2 || est.Services[0].Service != "checkout" {
+		t.Fatalf("services = %+v, want checkout first", est.Services)
+	}
+	checkout := est.Services[0]
+	if checkout.Combinations != 10 || checkout.SeriesPrometheus != 140 || checkout.MaxExemplars != 20 {
+		t.Errorf("checkout = %+v, want 10 combinations, 140 series, 20 exemplars", checkout.SpanMetricsProjection)
+	}
+	if checkout.DimensionValues["http.route"] != 5 || checkout.DimensionValues["status.code"] != 2 {
+		t.Errorf("checkout dimension values = %v", checkout.DimensionValues)
+	}
+	if est.Total.Combinations != 11 || est.Total.Spans != 200 || est.Total.SeriesOTLP != 22 {
+		t.Errorf("total = %+v, want 11 combinations, 200 spans, 22 OTLP series", est.Total)
+	}
+	if est.Total.DimensionValues["service.name"] != 2 {
+		t.Errorf("total services = %d, want 2", est.Total.DimensionValues["service.name"])
+	}
+
+	// A nil estimator records nothing.
+	var none *Estimator
+	none.Recorder(ctx).Span("checkout", nil, nil, &tracepb.Span{})
+}
+
+func TestDropTenant(t *testing.T) {
+	cfg, err := Parse(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(cfg)
+	teamA := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "team-a"})
+	teamB := tenant.NewContext(context.Background(), &tenant.Tenant{ID: "team-b"})
+	e.Recorder(teamA).Span("checkout", nil, nil, &tracepb.Span{Name: "GET"})
+	e.Recorder(teamB).Span("search", nil, nil, &tracepb.Span{Name: "GET"})
+
+	e.DropTenant("team-a")
+	if est := e.Estimate(teamA); len(est.Services) != 0 {
+		t.Errorf("team-a services after drop = %+v, want none", est.Services)
+	}
+	if est := e.Estimate(teamB); len(est.Services) != 1 {
+		t.Errorf("team-b services = %+v, want search", est.Services)
+	}
+
+	var none *Estimator
+	none.DropTenant("team-a")
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultHistoryInterval is how often the cardinality history is sampled.
+const DefaultHistoryInterval = 30 * time.Second
+
+// SampleHistory calls record every interval until ctx is done.
+func SampleHistory(ctx context.Context, interval time.Duration, record func(at time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case at := <-ticker.C:
+			record(at)
+		}
+	}
+}
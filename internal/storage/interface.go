@@ -3,6 +3,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/fidde/otlp_cardinality_checker/pkg/autotemplate"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
@@ -25,6 +26,7 @@ type Storage interface {
 	StoreLog(ctx context.Context, log *models.LogMetadata) error
 	GetLog(ctx context.Context, severityText string) (*models.LogMetadata, error)
 	ListLogs(ctx context.Context, serviceName string) ([]*models.LogMetadata, error)
+
 	
 	// Pattern explorer - advanced log pattern analysis
 	GetLogPatterns(ctx context.Context, minCount int64, minServices int) (*models.PatternExplorerResponse, error)
@@ -69,3 +71,41 @@ type Storage interface {
 	// Close the storage (for cleanup, e.g., DB connections)
 	Close() error
 }
+
+// HistoryStore is implemented by stores that sample their cardinality over
+// time.
+type HistoryStore interface {
+	// RecordHistory takes a sample of every metric, attribute and service.
+	RecordHistory(ctx context.Context, at time.Time)
+	// History returns the samples taken so far.
+	History(ctx context.Context) *models.History
+	// MergeHistory merges samples restored from a session.
+	MergeHistory(ctx context.Context, h *models.SerializedHistory) error
+}
+
+// ProfileStore is implemented by stores that keep OTLP profile metadata.
+type ProfileStore interface {
+	StoreProfile(ctx context.Context, profile *models.ProfileMetadata) error
+	GetProfile(ctx context.Context, id string) (*models.ProfileMetadata, error)
+	ListProfiles(ctx context.Context, serviceName string) ([]*models.ProfileMetadata, error)
+}
+
+// MetricConflictStore is implemented by stores that track the identities
+// each metric name is received with.
+type MetricConflictStore interface {
+	// GetMetricConflicts returns the metric names received with more than
+	// one identity.
+	GetMetricConflicts(ctx context.Context) (*models.MetricConflictsResponse, error)
+	// ConflictingMetricIdentities returns the identity variants of every
+	// conflicting metric name, for saving with a session.
+	ConflictingMetricIdentities(ctx context.Context) map[string][]*models.MetricVariant
+}
+
+// LokiStreamStore is implemented by stores that keep the label sets of
+// streams pushed through the Loki API.
+type LokiStreamStore interface {
+	// StoreLokiStreams records the label sets of pushed streams.
+	StoreLokiStreams(ctx context.Context, streams []models.LokiStream) error
+	// GetLokiStreams returns the stream label view.
+	GetLokiStreams(ctx context.Context) (*models.LokiStreamsResponse, error)
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fidde/otlp_cardinality_checker/pkg/autotemplate"
 	"github.com/fidde/otlp_cardinality_checker/pkg/models"
@@ -19,6 +20,9 @@ type Store struct {
 	metrics map[string]*models.MetricMetadata
 	metricsmu sync.RWMutex
 
+	// Identities seen per metric name, guarded by metricsmu
+	metricIdentities *models.MetricIdentityTracker
+
 	// Spans storage: span name -> metadata
 	spans map[string]*models.SpanMetadata
 	spansmu sync.RWMutex
@@ -27,6 +31,10 @@ type Store struct {
 	logs map[string]*models.LogMetadata
 	logsmu sync.RWMutex
 
+	// Profiles storage: sample type ID -> metadata
+	profiles map[string]*models.ProfileMetadata
+	profilesmu sync.RWMutex
+
 	// Attributes storage: attribute key -> metadata
 	attributes map[string]*models.AttributeMetadata
 	attributesmu sync.RWMutex
@@ -35,6 +43,13 @@ type Store struct {
 	services map[string]struct{}
 	servicesmu sync.RWMutex
 
+	// Loki stream labels pushed through the Loki API
+	lokiStreams   *models.LokiStreamMetadata
+	lokiStreamsmu sync.RWMutex
+
+	// Cardinality over time, sampled by RecordHistory
+	history *models.History
+
 	// Deep watch: key -> watched attribute
 	watched       map[string]*models.WatchedAttribute
 	watchedmu     sync.RWMutex
@@ -59,11 +74,15 @@ func NewWithConfig(useAutoTemplate bool, maxWatchedFields int, podLogEnrichment
 
 	return &Store{
 		metrics:             make(map[string]*models.MetricMetadata),
+		metricIdentities:    models.NewMetricIdentityTracker(),
 		spans:               make(map[string]*models.SpanMetadata),
 		logs:                make(map[string]*models.LogMetadata),
+		profiles:            make(map[string]*models.ProfileMetadata),
 		attributes:          make(map[string]*models.AttributeMetadata),
 		services:            make(map[string]struct{}),
 		watched:             make(map[string]*models.WatchedAttribute),
+		lokiStreams:         models.NewLokiStreamMetadata(),
+		history:             models.NewHistory(models.DefaultHistorySize),
 		maxWatchedFields:    maxWatchedFields,
 		useAutoTemplate:     useAutoTemplate,
 		autoTemplateCfg:     cfg,
@@ -112,6 +131,9 @@ func (s *Store) StoreMetric(ctx context.Context, metric *models.MetricMetadata)
 	// Track services
 	s.trackServices(metric.Services)
 
+	// Record the identity before merging, the merge keeps only the first one
+	s.metricIdentities.Observe(metric)
+
 	// If metric exists, merge with existing
 	if existing, exists := s.metrics[metric.Name]; exists {
 		existing.MergeMetricMetadata(metric)
@@ -160,6 +182,24 @@ func (s *Store) ListMetrics(ctx context.Context, serviceName string) ([]*models.
 	return metrics, nil
 }
 
+// GetMetricConflicts returns the metric names received with more than one
+// type, unit, temporality, monotonicity or description.
+func (s *Store) GetMetricConflicts(ctx context.Context) (*models.MetricConflictsResponse, error) {
+	s.metricsmu.RLock()
+	defer s.metricsmu.RUnlock()
+
+	return s.metricIdentities.Conflicts(), nil
+}
+
+// ConflictingMetricIdentities returns the identity variants of every
+// conflicting metric name, for saving with a session.
+func (s *Store) ConflictingMetricIdentities(ctx context.Context) map[string][]*models.MetricVariant {
+	s.metricsmu.RLock()
+	defer s.metricsmu.RUnlock()
+
+	return s.metricIdentities.Conflicting()
+}
+
 // StoreSpan stores or updates span metadata.
 func (s *Store) StoreSpan(ctx context.Context, span *models.SpanMetadata) error {
 	if span == nil {
@@ -467,6 +507,69 @@ func (s *Store) ListLogs(ctx context.Context, serviceName string) ([]*models.Log
 	return logs, nil
 }
 
+// StoreProfile stores or updates profile metadata.
+func (s *Store) StoreProfile(ctx context.Context, profile *models.ProfileMetadata) error {
+	if profile == nil {
+		return errors.New("profile cannot be nil")
+	}
+	if profile.ID == "" {
+		return errors.New("profile ID cannot be empty")
+	}
+
+	s.profilesmu.Lock()
+	defer s.profilesmu.Unlock()
+
+	// Track services
+	s.trackServices(profile.Services)
+
+	// If the sample type exists, merge with existing
+	if existing, exists := s.profiles[profile.ID]; exists {
+		existing.MergeProfileMetadata(profile)
+		return nil
+	}
+
+	// Store new profile
+	s.profiles[profile.ID] = profile
+	return nil
+}
+
+// GetProfile retrieves profile metadata by sample type ID.
+func (s *Store) GetProfile(ctx context.Context, id string) (*models.ProfileMetadata, error) {
+	s.profilesmu.RLock()
+	defer s.profilesmu.RUnlock()
+
+	profile, exists := s.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile %s: %w", id, models.ErrNotFound)
+	}
+
+	return profile, nil
+}
+
+// ListProfiles returns all profile sample types, optionally filtered by service name.
+func (s *Store) ListProfiles(ctx context.Context, serviceName string) ([]*models.ProfileMetadata, error) {
+	s.profilesmu.RLock()
+	defer s.profilesmu.RUnlock()
+
+	profiles := make([]*models.ProfileMetadata, 0, len(s.profiles))
+	for _, profile := range s.profiles {
+		// Filter by service if specified
+		if serviceName != "" {
+			if _, hasService := profile.Services[serviceName]; !hasService {
+				continue
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+
+	// Sort by ID for consistency
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+
+	return profiles, nil
+}
+
 // CountLogPatterns returns the number of unique log templates without building the full pattern response.
 func (s *Store) CountLogPatterns(ctx context.Context) (int, error) {
 	s.logsmu.RLock()
@@ -481,6 +584,30 @@ func (s *Store) CountLogPatterns(ctx context.Context) (int, error) {
 	return len(seen), nil
 }
 
+// CountLogTemplatesByService returns the number of unique log templates of
+// each service.
+func (s *Store) CountLogTemplatesByService(ctx context.Context) (map[string]int64, error) {
+	s.logsmu.RLock()
+	defer s.logsmu.RUnlock()
+
+	seen := make(map[string]map[string]struct{})
+	for _, logMeta := range s.logs {
+		for svc := range logMeta.Services {
+			if seen[svc] == nil {
+				seen[svc] = make(map[string]struct{})
+			}
+			for _, tmpl := range logMeta.BodyTemplates {
+				seen[svc][tmpl.Template] = struct{}{}
+			}
+		}
+	}
+	counts := make(map[string]int64, len(seen))
+	for svc, templates := range seen {
+		counts[svc] = int64(len(templates))
+	}
+	return counts, nil
+}
+
 // GetLogPatterns returns an advanced pattern analysis view.
 // Note: In-memory store has limited pattern analysis capabilities compared to SQLite.
 func (s *Store) GetLogPatterns(ctx context.Context, minCount int64, minServices int) (*models.PatternExplorerResponse, error) {
@@ -606,19 +733,26 @@ func (s *Store) GetServiceOverview(ctx context.Context, serviceName string) (*mo
 		return nil, fmt.Errorf("listing logs: %w", err)
 	}
 
+	profiles, err := s.ListProfiles(ctx, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("listing profiles: %w", err)
+	}
+
 	return &models.ServiceOverview{
-		ServiceName: serviceName,
-		MetricCount: len(metrics),
-		SpanCount:   len(spans),
-		LogCount:    len(logs),
-		Metrics:     metrics,
-		Spans:       spans,
-		Logs:        logs,
+		ServiceName:  serviceName,
+		MetricCount:  len(metrics),
+		SpanCount:    len(spans),
+		LogCount:     len(logs),
+		ProfileCount: len(profiles),
+		Metrics:      metrics,
+		Spans:        spans,
+		Logs:         logs,
+		Profiles:     profiles,
 	}, nil
 }
 
 // GetHighCardinalityKeys returns high-cardinality keys across all signal types.
-// For in-memory store, we aggregate keys from metrics, spans, and logs.
+// For in-memory store, we aggregate keys from metrics, spans, logs, and profiles.
 func (s *Store) GetHighCardinalityKeys(ctx context.Context, threshold int, limit int) (*models.CrossSignalCardinalityResponse, error) {
 	if limit <= 0 {
 		limit = 100
@@ -723,6 +857,35 @@ func (s *Store) GetHighCardinalityKeys(ctx context.Context, threshold int, limit
 	}
 	s.logsmu.RUnlock()
 
+	// Collect profile keys
+	s.profilesmu.RLock()
+	for profileID, profile := range s.profiles {
+		for _, group := range []struct {
+			scope string
+			keys  map[string]*models.KeyMetadata
+		}{
+			{"attribute", profile.AttributeKeys},
+			{"resource", profile.ResourceKeys},
+			{"location", profile.LocationAttributeKeys},
+			{"mapping", profile.MappingAttributeKeys},
+		} {
+			for keyName, keyMeta := range group.keys {
+				if keyMeta.EstimatedCardinality >= threshold64 {
+					allKeys = append(allKeys, models.SignalKey{
+						SignalType:           "profile",
+						SignalName:           profileID,
+						KeyScope:             group.scope,
+						KeyName:              keyName,
+						EstimatedCardinality: int(keyMeta.EstimatedCardinality),
+						KeyCount:             keyMeta.Count,
+						ValueSamples:         keyMeta.ValueSamples,
+					})
+				}
+			}
+		}
+	}
+	s.profilesmu.RUnlock()
+
 	// Sort by cardinality descending
 	sort.Slice(allKeys, func(i, j int) bool {
 		return allKeys[i].EstimatedCardinality > allKeys[j].EstimatedCardinality
@@ -895,6 +1058,43 @@ func (s *Store) GetMetadataComplexity(ctx context.Context, threshold int, limit
 	}
 	s.logsmu.RUnlock()
 
+	// Analyze profiles
+	s.profilesmu.RLock()
+	for profileID, profile := range s.profiles {
+		totalKeys := len(profile.AttributeKeys) + len(profile.ResourceKeys) +
+			len(profile.LocationAttributeKeys) + len(profile.MappingAttributeKeys)
+		if totalKeys < threshold {
+			continue
+		}
+
+		sig := models.SignalComplexity{
+			SignalType:        "profile",
+			SignalName:        profileID,
+			TotalKeys:         totalKeys,
+			AttributeKeyCount: len(profile.AttributeKeys),
+			ResourceKeyCount:  len(profile.ResourceKeys),
+		}
+
+		// Find max cardinality
+		for _, keys := range []map[string]*models.KeyMetadata{
+			profile.AttributeKeys, profile.ResourceKeys,
+			profile.LocationAttributeKeys, profile.MappingAttributeKeys,
+		} {
+			for _, keyMeta := range keys {
+				if int(keyMeta.EstimatedCardinality) > sig.MaxCardinality {
+					sig.MaxCardinality = int(keyMeta.EstimatedCardinality)
+				}
+				if keyMeta.EstimatedCardinality > 100 {
+					sig.HighCardinalityCount++
+				}
+			}
+		}
+
+		sig.ComplexityScore = sig.TotalKeys * sig.MaxCardinality
+		signals = append(signals, sig)
+	}
+	s.profilesmu.RUnlock()
+
 	// Sort by complexity score descending
 	sort.Slice(signals, func(i, j int) bool {
 		if signals[i].TotalKeys == signals[j].TotalKeys {
@@ -920,26 +1120,53 @@ func (s *Store) Clear(ctx context.Context) error {
 	s.metricsmu.Lock()
 	s.spansmu.Lock()
 	s.logsmu.Lock()
+	s.profilesmu.Lock()
 	s.attributesmu.Lock()
 	s.servicesmu.Lock()
 	s.watchedmu.Lock()
+	s.lokiStreamsmu.Lock()
 	defer s.metricsmu.Unlock()
 	defer s.spansmu.Unlock()
 	defer s.logsmu.Unlock()
+	defer s.profilesmu.Unlock()
 	defer s.attributesmu.Unlock()
 	defer s.servicesmu.Unlock()
 	defer s.watchedmu.Unlock()
+	defer s.lokiStreamsmu.Unlock()
 
 	s.metrics = make(map[string]*models.MetricMetadata)
+	s.metricIdentities = models.NewMetricIdentityTracker()
 	s.spans = make(map[string]*models.SpanMetadata)
 	s.logs = make(map[string]*models.LogMetadata)
+	s.profiles = make(map[string]*models.ProfileMetadata)
 	s.attributes = make(map[string]*models.AttributeMetadata)
 	s.services = make(map[string]struct{})
 	s.watched = make(map[string]*models.WatchedAttribute)
+	s.lokiStreams = models.NewLokiStreamMetadata()
+	s.history.Reset()
+
+	return nil
+}
+
+// StoreLokiStreams records the label sets of pushed Loki streams.
+func (s *Store) StoreLokiStreams(ctx context.Context, streams []models.LokiStream) error {
+	s.lokiStreamsmu.RLock()
+	defer s.lokiStreamsmu.RUnlock()
 
+	for _, stream := range streams {
+		s.lokiStreams.Add(stream)
+	}
 	return nil
 }
 
+// GetLokiStreams returns the Loki stream label view.
+func (s *Store) GetLokiStreams(ctx context.Context) (*models.LokiStreamsResponse, error) {
+	s.lokiStreamsmu.RLock()
+	defer s.lokiStreamsmu.RUnlock()
+
+	return s.lokiStreams.Summary(), nil
+}
+
 // StoreAttributeValue stores or updates an attribute key-value observation.
 func (s *Store) StoreAttributeValue(ctx context.Context, key, value, signalType, scope string) error {
 	if key == "" {
@@ -1189,12 +1416,37 @@ func (s *Store) MergeWatchedAttribute(ctx context.Context, w *models.WatchedAttr
 	return nil
 }
 
+// GetAllProfiles returns all profile metadata for session saving.
+// Implements api.StoreAccessor extended interface.
+func (s *Store) GetAllProfiles(ctx context.Context) ([]*models.ProfileMetadata, error) {
+	return s.ListProfiles(ctx, "")
+}
+
 // GetWatchedAll returns all watched attributes for session saving.
 // Implements api.StoreAccessor extended interface.
 func (s *Store) GetWatchedAll(ctx context.Context) ([]*models.WatchedAttribute, error) {
 	return s.ListWatchedAttributes(ctx)
 }
 
+// RecordHistory samples the current cardinality of every metric, attribute
+// and service into the history.
+func (s *Store) RecordHistory(ctx context.Context, at time.Time) {
+	metrics, _ := s.ListMetrics(ctx, "")
+	attrs, _ := s.ListAttributes(ctx, nil)
+	s.history.Record(at, models.NewHistorySample(metrics, attrs))
+}
+
+// History returns the cardinality samples taken so far.
+func (s *Store) History(ctx context.Context) *models.History {
+	return s.history
+}
+
+// MergeHistory merges the history of a saved session into the store.
+func (s *Store) MergeHistory(ctx context.Context, h *models.SerializedHistory) error {
+	s.history.Merge(h)
+	return nil
+}
+
 // trackServices adds services to the global service set.
 // Must be called with appropriate lock held.
 func (s *Store) trackServices(services map[string]int64) {
@@ -1226,6 +1478,36 @@ func spanKindToString(kind int32) string {
 	}
 }
 
+// Counts is the number of entries held by a Store.
+type Counts struct {
+	Metrics    int `json:"metrics"`
+	Spans      int `json:"spans"`
+	Logs       int `json:"logs"`
+	Profiles   int `json:"profiles"`
+	Attributes int `json:"attributes"`
+}
+
+// Counts returns the number of stored entries per kind without copying them.
+func (s *Store) Counts() Counts {
+	var c Counts
+	s.metricsmu.RLock()
+	c.Metrics = len(s.metrics)
+	s.metricsmu.RUnlock()
+	s.spansmu.RLock()
+	c.Spans = len(s.spans)
+	s.spansmu.RUnlock()
+	s.logsmu.RLock()
+	c.Logs = len(s.logs)
+	s.logsmu.RUnlock()
+	s.profilesmu.RLock()
+	c.Profiles = len(s.profiles)
+	s.profilesmu.RUnlock()
+	s.attributesmu.RLock()
+	c.Attributes = len(s.attributes)
+	s.attributesmu.RUnlock()
+	return c
+}
+
 // Close cleans up resources (no-op for in-memory store).
 func (s *Store) Close() error {
 	return nil
@@ -1377,6 +1659,12 @@ func (s *Store) MergeLog(ctx context.Context, log *models.LogMetadata) error {
 	return s.StoreLog(ctx, log)
 }
 
+// MergeProfile merges profile metadata into the store.
+// Implements api.StoreAccessor interface.
+func (s *Store) MergeProfile(ctx context.Context, profile *models.ProfileMetadata) error {
+	return s.StoreProfile(ctx, profile)
+}
+
 // MergeAttribute merges an attribute into the store.
 // Implements api.StoreAccessor interface.
 func (s *Store) MergeAttribute(ctx context.Context, attr *models.AttributeMetadata) error {
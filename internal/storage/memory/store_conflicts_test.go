@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+func newConflictTestMetric(service string, samples int64, unit string, data models.MetricData) *models.MetricMetadata {
+	m := models.NewMetricMetadata("http.server.duration", data)
+	m.Unit = unit
+	m.SampleCount = samples
+	m.Services[service] = samples
+	return m
+}
+
+func TestStoreMetric_RecordsIdentityConflicts(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(5)
+
+	cumulative := func() models.MetricData {
+		return &models.HistogramMetric{AggregationTemporality: models.AggregationTemporalityCumulative}
+	}
+	metrics := []*models.MetricMetadata{
+		newConflictTestMetric("checkout", 10, "ms", cumulative()),
+		newConflictTestMetric("cart", 5, "ms", cumulative()),
+		newConflictTestMetric("search", 20, "s", &models.HistogramMetric{AggregationTemporality: models.AggregationTemporalityDelta}),
+		newConflictTestMetric("checkout", 10, "ms", cumulative()),
+	}
+	for _, m := range metrics {
+		if err := s.StoreMetric(ctx, m); err != nil {
+			t.Fatalf("StoreMetric: %v", err)
+		}
+	}
+	other := models.NewMetricMetadata("queue.depth", &models.GaugeMetric{})
+	if err := s.StoreMetric(ctx, other); err != nil {
+		t.Fatalf("StoreMetric: %v", err)
+	}
+
+	resp, err := s.GetMetricConflicts(ctx)
+	if err != nil {
+		t.Fatalf("GetMetricConflicts: %v", err)
+	}
+	if resp.Total != 1 || len(resp.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want only http.server.duration", resp.Conflicts)
+	}
+	c := resp.Conflicts[0]
+	if c.Name != "http.server.duration" {
+		t.Errorf("name = %q", c.Name)
+	}
+	if len(c.ConflictingFields) != 2 || c.ConflictingFields[0] != "unit" || c.ConflictingFields[1] != "aggregation_temporality" {
+		t.Errorf("conflicting fields = %v, want [unit aggregation_temporality]", c.ConflictingFields)
+	}
+	if len(c.Variants) != 2 {
+		t.Fatalf("variants = %d, want 2", len(c.Variants))
+	}
+	ms := c.Variants[0]
+	if ms.Unit != "ms" || ms.SampleCount != 25 || ms.Services["checkout"] != 20 || ms.Services["cart"] != 5 {
+		t.Errorf("ms variant = %+v", ms)
+	}
+	if sec := c.Variants[1]; sec.Unit != "s" || sec.AggregationTemporality != "DELTA" || sec.Services["search"] != 20 {
+		t.Errorf("s variant = %+v", sec)
+	}
+
+	// The merged metric itself is unchanged: the first definition wins.
+	merged, err := s.GetMetric(ctx, "http.server.duration")
+	if err != nil {
+		t.Fatalf("GetMetric: %v", err)
+	}
+	if merged.Unit != "ms" || merged.SampleCount != 45 {
+		t.Errorf("merged metric unit = %q, samples = %d", merged.Unit, merged.SampleCount)
+	}
+
+	if err := s.Clear(ctx); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if resp, _ := s.GetMetricConflicts(ctx); resp.Total != 0 {
+		t.Errorf("conflicts after Clear = %d, want 0", resp.Total)
+	}
+}
+
+func TestStoreMetric_SumMonotonicityConflict(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(5)
+
+	counter := newConflictTestMetric("api", 1, "1", &models.SumMetric{AggregationTemporality: models.AggregationTemporalityCumulative, IsMonotonic: true})
+	counter.Description = "Requests"
+	upDown := newConflictTestMetric("worker", 1, "1", &models.SumMetric{AggregationTemporality: models.AggregationTemporalityCumulative})
+	upDown.Description = "Requests"
+	gauge := newConflictTestMetric("legacy", 1, "1", &models.GaugeMetric{})
+	gauge.Description = "Requests in flight"
+	for _, m := range []*models.MetricMetadata{counter, upDown, gauge} {
+		if err := s.StoreMetric(ctx, m); err != nil {
+			t.Fatalf("StoreMetric: %v", err)
+		}
+	}
+
+	resp, _ := s.GetMetricConflicts(ctx)
+	if resp.Total != 1 {
+		t.Fatalf("conflicts = %d, want 1", resp.Total)
+	}
+	want := []string{"type", "aggregation_temporality", "is_monotonic", "description"}
+	got := resp.Conflicts[0].ConflictingFields
+	if len(got) != len(want) {
+		t.Fatalf("conflicting fields = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("conflicting fields = %v, want %v", got, want)
+			break
+		}
+	}
+}
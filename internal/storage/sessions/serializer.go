@@ -80,12 +80,32 @@ func (s *Serializer) marshalMetric(m *models.MetricMetadata) (*models.Serialized
 		case *models.HistogramMetric:
 			sm.AggregationTemporality = int32(d.AggregationTemporality)
 			sm.ExplicitBounds = d.ExplicitBounds
+			sm.BucketLayouts = d.BucketLayouts
 		case *models.ExponentialHistogramMetric:
 			sm.AggregationTemporality = int32(d.AggregationTemporality)
 			sm.Scales = d.Scales
 		}
 	}
 
+	// Serialize exemplar statistics with HLL
+	if m.Exemplars != nil {
+		se := &models.SerializedExemplars{
+			DataPointsWithExemplars: m.Exemplars.DataPointsWithExemplars,
+			ExemplarCount:           m.Exemplars.ExemplarCount,
+			WithTraceID:             m.Exemplars.WithTraceID,
+			WithSpanID:              m.Exemplars.WithSpanID,
+			FilteredAttributeKeys:   make(map[string]*models.SerializedKey),
+		}
+		for name, key := range m.Exemplars.FilteredAttributeKeys {
+			sk, err := models.SerializeKeyMetadata(key)
+			if err != nil {
+				return nil, err
+			}
+			se.FilteredAttributeKeys[name] = sk
+		}
+		sm.Exemplars = se
+	}
+
 	// Serialize series HLL if present
 	seriesHLL := m.GetSeriesHLL()
 	if seriesHLL != nil {
@@ -96,6 +116,18 @@ func (s *Serializer) marshalMetric(m *models.MetricMetadata) (*models.Serialized
 		sm.SeriesHLL = hll
 	}
 
+	// Serialize per-label contribution sketches if present
+	contribution, err := m.MarshalContribution()
+	if err != nil {
+		return nil, err
+	}
+	sm.Contribution = contribution
+
+	// Serialize the series window if windows are enabled
+	if sm.SeriesWindow, err = m.MarshalSeriesWindow(); err != nil {
+		return nil, err
+	}
+
 	return sm, nil
 }
 
@@ -137,6 +169,7 @@ func (s *Serializer) unmarshalMetric(sm *models.SerializedMetric) (*models.Metri
 			DataPointCount:         sm.DataPointCount,
 			AggregationTemporality: models.AggregationTemporality(sm.AggregationTemporality),
 			ExplicitBounds:         sm.ExplicitBounds,
+			BucketLayouts:          sm.BucketLayouts,
 		}
 	case "ExponentialHistogram":
 		metricData = &models.ExponentialHistogramMetric{
@@ -177,6 +210,23 @@ func (s *Serializer) unmarshalMetric(sm *models.SerializedMetric) (*models.Metri
 		m.ResourceKeys[name] = km
 	}
 
+	// Deserialize exemplar statistics
+	if se := sm.Exemplars; se != nil {
+		m.Exemplars = models.NewExemplarStats()
+		m.Exemplars.DataPointsWithExemplars = se.DataPointsWithExemplars
+		m.Exemplars.ExemplarCount = se.ExemplarCount
+		m.Exemplars.WithTraceID = se.WithTraceID
+		m.Exemplars.WithSpanID = se.WithSpanID
+		for name, sk := range se.FilteredAttributeKeys {
+			km, err := models.DeserializeKeyMetadata(sk)
+			if err != nil {
+				return nil, err
+			}
+			m.Exemplars.FilteredAttributeKeys[name] = km
+		}
+		m.Exemplars.Update(m.SampleCount)
+	}
+
 	// Deserialize series HLL if present
 	if sm.SeriesHLL != nil {
 		hll, err := models.UnmarshalHLL(sm.SeriesHLL)
@@ -185,6 +235,15 @@ func (s *Serializer) unmarshalMetric(sm *models.SerializedMetric) (*models.Metri
 		}
 		m.SetSeriesHLL(hll)
 	}
+	if err := m.UnmarshalContribution(sm.Contribution); err != nil {
+		return nil, err
+	}
+	if err := m.UnmarshalSeriesWindow(sm.SeriesWindow); err != nil {
+		return nil, err
+	}
+	if len(sm.Identities) > 0 {
+		m.RestoreIdentities(sm.Identities)
+	}
 	return m, nil
 }
 
@@ -442,6 +501,131 @@ func (s *Serializer) unmarshalLog(sl *models.SerializedLog) (*models.LogMetadata
 	return l, nil
 }
 
+// MarshalProfiles converts ProfileMetadata slice to SerializedProfile slice.
+func (s *Serializer) MarshalProfiles(profiles []*models.ProfileMetadata) ([]*models.SerializedProfile, error) {
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	result := make([]*models.SerializedProfile, 0, len(profiles))
+	for _, p := range profiles {
+		sp, err := s.marshalProfile(p)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling profile %s: %w", p.ID, err)
+		}
+		result = append(result, sp)
+	}
+	return result, nil
+}
+
+// marshalProfile converts a single ProfileMetadata to SerializedProfile.
+func (s *Serializer) marshalProfile(p *models.ProfileMetadata) (*models.SerializedProfile, error) {
+	sp := &models.SerializedProfile{
+		ID:            p.ID,
+		SampleType:    p.SampleType,
+		SampleUnit:    p.SampleUnit,
+		PeriodType:    p.PeriodType,
+		ProfileCount:  p.ProfileCount,
+		SampleCount:   p.SampleCount,
+		MaxStackDepth: p.MaxStackDepth,
+		Services:      p.Services,
+	}
+
+	var err error
+	if sp.AttributeKeys, err = serializeKeyMap(p.AttributeKeys); err != nil {
+		return nil, err
+	}
+	if sp.LocationAttributeKeys, err = serializeKeyMap(p.LocationAttributeKeys); err != nil {
+		return nil, err
+	}
+	if sp.MappingAttributeKeys, err = serializeKeyMap(p.MappingAttributeKeys); err != nil {
+		return nil, err
+	}
+	if sp.ResourceKeys, err = serializeKeyMap(p.ResourceKeys); err != nil {
+		return nil, err
+	}
+	if sp.MappingFiles, err = models.SerializeKeyMetadata(p.MappingFiles); err != nil {
+		return nil, err
+	}
+
+	return sp, nil
+}
+
+// UnmarshalProfiles converts SerializedProfile slice to ProfileMetadata slice.
+func (s *Serializer) UnmarshalProfiles(profiles []*models.SerializedProfile) ([]*models.ProfileMetadata, error) {
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	result := make([]*models.ProfileMetadata, 0, len(profiles))
+	for _, sp := range profiles {
+		p, err := s.unmarshalProfile(sp)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshaling profile %s: %w", sp.ID, err)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// unmarshalProfile converts a single SerializedProfile to ProfileMetadata.
+func (s *Serializer) unmarshalProfile(sp *models.SerializedProfile) (*models.ProfileMetadata, error) {
+	p := models.NewProfileMetadata(sp.SampleType, sp.SampleUnit)
+	p.ID = sp.ID
+	p.PeriodType = sp.PeriodType
+	p.ProfileCount = sp.ProfileCount
+	p.SampleCount = sp.SampleCount
+	p.MaxStackDepth = sp.MaxStackDepth
+	if sp.Services != nil {
+		p.Services = sp.Services
+	}
+
+	var err error
+	if p.AttributeKeys, err = deserializeKeyMap(sp.AttributeKeys); err != nil {
+		return nil, err
+	}
+	if p.LocationAttributeKeys, err = deserializeKeyMap(sp.LocationAttributeKeys); err != nil {
+		return nil, err
+	}
+	if p.MappingAttributeKeys, err = deserializeKeyMap(sp.MappingAttributeKeys); err != nil {
+		return nil, err
+	}
+	if p.ResourceKeys, err = deserializeKeyMap(sp.ResourceKeys); err != nil {
+		return nil, err
+	}
+	if p.MappingFiles, err = models.DeserializeKeyMetadata(sp.MappingFiles); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// deserializeKeyMap is the inverse of serializeKeyMap.
+func deserializeKeyMap(keys map[string]*models.SerializedKey) (map[string]*models.KeyMetadata, error) {
+	result := make(map[string]*models.KeyMetadata, len(keys))
+	for name, sk := range keys {
+		km, err := models.DeserializeKeyMetadata(sk)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = km
+	}
+	return result, nil
+}
+
+// serializeKeyMap serializes every KeyMetadata in keys.
+func serializeKeyMap(keys map[string]*models.KeyMetadata) (map[string]*models.SerializedKey, error) {
+	result := make(map[string]*models.SerializedKey, len(keys))
+	for name, key := range keys {
+		sk, err := models.SerializeKeyMetadata(key)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = sk
+	}
+	return result, nil
+}
+
 // MarshalAttributes converts AttributeMetadata slice to SerializedAttribute slice.
 func (s *Serializer) MarshalAttributes(attrs []*models.AttributeMetadata) ([]*models.SerializedAttribute, error) {
 	if len(attrs) == 0 {
@@ -483,6 +667,9 @@ func (s *Serializer) marshalAttribute(a *models.AttributeMetadata) (*models.Seri
 			Registers: base64.StdEncoding.EncodeToString(hllBytes[1:]),
 		}
 	}
+	if sa.Window, err = a.MarshalWindow(); err != nil {
+		return nil, err
+	}
 
 	return sa, nil
 }
@@ -529,6 +716,9 @@ func (s *Serializer) unmarshalAttribute(sa *models.SerializedAttribute) (*models
 			return nil, err
 		}
 	}
+	if err := a.UnmarshalWindow(sa.Window); err != nil {
+		return nil, err
+	}
 
 	return a, nil
 }
@@ -579,10 +769,12 @@ func (s *Serializer) UnmarshalWatchedAttributes(watched []*models.SerializedWatc
 
 // CreateSessionOptions defines what to include when creating a session.
 type CreateSessionOptions struct {
-	Name        string
-	Description string
-	Signals     []string // empty = all
-	Services    []string // empty = all
+	Name             string
+	Description      string
+	Signals          []string // empty = all
+	Services         []string // empty = all
+	History          *models.History
+	MetricIdentities map[string][]*models.MetricVariant // conflicting metric names only
 }
 
 // CreateSession creates a new session from the current store state.
@@ -593,6 +785,7 @@ func (s *Serializer) CreateSession(
 	metrics []*models.MetricMetadata,
 	spans []*models.SpanMetadata,
 	logs []*models.LogMetadata,
+	profiles []*models.ProfileMetadata,
 	attributes []*models.AttributeMetadata,
 	services []string,
 	watched []*models.WatchedAttribute,
@@ -611,6 +804,7 @@ func (s *Serializer) CreateSession(
 	includeMetrics := len(opts.Signals) == 0 || containsString(opts.Signals, "metrics")
 	includeSpans := len(opts.Signals) == 0 || containsString(opts.Signals, "spans")
 	includeLogs := len(opts.Signals) == 0 || containsString(opts.Signals, "logs")
+	includeProfiles := len(opts.Signals) == 0 || containsString(opts.Signals, "profiles")
 	includeAttributes := len(opts.Signals) == 0 || containsString(opts.Signals, "attributes")
 
 	// Set signals list if it wasn't specified
@@ -625,6 +819,9 @@ func (s *Serializer) CreateSession(
 		if includeLogs {
 			session.Signals = append(session.Signals, "logs")
 		}
+		if includeProfiles {
+			session.Signals = append(session.Signals, "profiles")
+		}
 		if includeAttributes {
 			session.Signals = append(session.Signals, "attributes")
 		}
@@ -637,6 +834,7 @@ func (s *Serializer) CreateSession(
 		if err != nil {
 			return nil, fmt.Errorf("marshaling metrics: %w", err)
 		}
+		SetMetricIdentities(serialized, opts.MetricIdentities)
 		session.Data.Metrics = serialized
 		// Count total data points, not just unique metric names
 		for _, m := range filteredMetrics {
@@ -672,6 +870,20 @@ func (s *Serializer) CreateSession(
 		}
 	}
 
+	// Filter and serialize profiles
+	if includeProfiles && len(profiles) > 0 {
+		filteredProfiles := filterProfilesByService(profiles, opts.Services)
+		serialized, err := s.MarshalProfiles(filteredProfiles)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling profiles: %w", err)
+		}
+		session.Data.Profiles = serialized
+		// Count total samples, not just unique sample types
+		for _, p := range filteredProfiles {
+			session.Stats.ProfilesCount += int(p.SampleCount)
+		}
+	}
+
 	// Serialize attributes
 	if includeAttributes && len(attributes) > 0 {
 		serialized, err := s.MarshalAttributes(attributes)
@@ -682,6 +894,25 @@ func (s *Serializer) CreateSession(
 		session.Stats.AttributesCount = len(serialized)
 	}
 
+	// The history follows the signal and service filters: metrics only
+	// if included, services only if selected.
+	if opts.History != nil {
+		metricNames := make(map[string]bool, len(session.Data.Metrics))
+		for _, m := range session.Data.Metrics {
+			metricNames[m.Name] = true
+		}
+		session.Data.History = opts.History.Serialize(func(kind models.HistoryKind, name string) bool {
+			switch kind {
+			case models.HistoryMetric:
+				return metricNames[name]
+			case models.HistoryAttribute:
+				return includeAttributes
+			default:
+				return len(opts.Services) == 0 || containsString(opts.Services, name)
+			}
+		})
+	}
+
 	// Always serialize watched attributes (independent of signals filter).
 	if len(watched) > 0 {
 		serializedWatched, err := s.MarshalWatchedAttributes(watched)
@@ -694,6 +925,15 @@ func (s *Serializer) CreateSession(
 	return session, nil
 }
 
+// SetMetricIdentities saves the identity variants of conflicting metric
+// names with their serialized metrics, so that a loaded session still
+// reports the conflicts.
+func SetMetricIdentities(metrics []*models.SerializedMetric, identities map[string][]*models.MetricVariant) {
+	for _, sm := range metrics {
+		sm.Identities = identities[sm.Name]
+	}
+}
+
 // Helper functions
 
 func containsString(slice []string, s string) bool {
@@ -743,3 +983,16 @@ func filterLogsByService(logs []*models.LogMetadata, services []string) []*model
 	}
 	return result
 }
+
+func filterProfilesByService(profiles []*models.ProfileMetadata, services []string) []*models.ProfileMetadata {
+	if len(services) == 0 {
+		return profiles
+	}
+	result := make([]*models.ProfileMetadata, 0)
+	for _, p := range profiles {
+		if models.FilterByService(p.Services, services) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -144,6 +144,39 @@ func TestSerializer_HistogramBucketsPreservedOnRoundTrip(t *testing.T) {
 	}
 }
 
+func TestSerializer_BucketLayoutsPreservedOnRoundTrip(t *testing.T) {
+	serializer := NewSerializer()
+
+	explicitBounds := []float64{0.1, 0.5, 1.0, 5.0, 10.0}
+	histMetric := &models.HistogramMetric{
+		DataPointCount: 200,
+		ExplicitBounds: explicitBounds,
+	}
+	histMetric.AddBucketLayout(explicitBounds, "api", 150)
+	histMetric.AddBucketLayout([]float64{1.0, 10.0}, "worker", 50)
+	hist := models.NewMetricMetadata("request_duration_seconds", histMetric)
+	hist.SampleCount = 200
+
+	serialized, err := serializer.MarshalMetrics([]*models.MetricMetadata{hist})
+	if err != nil {
+		t.Fatalf("MarshalMetrics failed: %v", err)
+	}
+	restored, err := serializer.UnmarshalMetrics(serialized)
+	if err != nil {
+		t.Fatalf("UnmarshalMetrics failed: %v", err)
+	}
+	histData, ok := restored[0].Data.(*models.HistogramMetric)
+	if !ok {
+		t.Fatalf("Expected *HistogramMetric, got %T", restored[0].Data)
+	}
+	if len(histData.BucketLayouts) != 2 {
+		t.Fatalf("BucketLayouts lost after round-trip: got %d, want 2", len(histData.BucketLayouts))
+	}
+	if l := histData.BucketLayouts[1]; len(l.Bounds) != 2 || l.DataPoints != 50 || l.Services["worker"] != 50 {
+		t.Errorf("BucketLayouts[1] after round-trip: got %+v", l)
+	}
+}
+
 func TestSerializer_ExponentialHistogramScalesPreservedOnRoundTrip(t *testing.T) {
 	serializer := NewSerializer()
 
@@ -187,6 +220,89 @@ func TestSerializer_ExponentialHistogramScalesPreservedOnRoundTrip(t *testing.T)
 	}
 }
 
+func TestSerializer_ExemplarsPreservedOnRoundTrip(t *testing.T) {
+	serializer := NewSerializer()
+
+	metric := models.NewMetricMetadata("http_requests_total", nil)
+	metric.SampleCount = 10
+	metric.Exemplars = models.NewExemplarStats()
+	metric.Exemplars.DataPointsWithExemplars = 4
+	for i := 0; i < 5; i++ {
+		metric.Exemplars.AddExemplar(map[string]string{"user.id": fmt.Sprintf("u%d", i)}, true, i%2 == 0)
+	}
+
+	serialized, err := serializer.MarshalMetrics([]*models.MetricMetadata{metric})
+	if err != nil {
+		t.Fatalf("MarshalMetrics failed: %v", err)
+	}
+	if serialized[0].Exemplars == nil {
+		t.Fatal("Exemplars not serialized")
+	}
+
+	restored, err := serializer.UnmarshalMetrics(serialized)
+	if err != nil {
+		t.Fatalf("UnmarshalMetrics failed: %v", err)
+	}
+	ex := restored[0].Exemplars
+	if ex == nil {
+		t.Fatal("Exemplars lost after round-trip")
+	}
+	if ex.DataPointsWithExemplars != 4 || ex.Fraction != 0.4 {
+		t.Errorf("data points with exemplars = %d (fraction %v), want 4 (0.4)", ex.DataPointsWithExemplars, ex.Fraction)
+	}
+	if ex.ExemplarCount != 5 || ex.WithTraceID != 5 || ex.WithSpanID != 3 {
+		t.Errorf("exemplars = %d, with trace ID = %d, with span ID = %d", ex.ExemplarCount, ex.WithTraceID, ex.WithSpanID)
+	}
+	userID, ok := ex.FilteredAttributeKeys["user.id"]
+	if !ok {
+		t.Fatal("filtered attribute user.id lost after round-trip")
+	}
+	if userID.Cardinality() != 5 || userID.Percentage != 100 {
+		t.Errorf("user.id cardinality = %d, percentage = %v", userID.Cardinality(), userID.Percentage)
+	}
+
+	// Metrics without exemplars stay without them.
+	plain, err := serializer.MarshalMetrics([]*models.MetricMetadata{models.NewMetricMetadata("up", nil)})
+	if err != nil {
+		t.Fatalf("MarshalMetrics failed: %v", err)
+	}
+	if plain[0].Exemplars != nil {
+		t.Errorf("expected no exemplars, got %+v", plain[0].Exemplars)
+	}
+}
+
+func TestSerializer_ContributionPreservedOnRoundTrip(t *testing.T) {
+	serializer := NewSerializer()
+
+	metric := models.NewMetricMetadata("http_requests_total", &models.SumMetric{IsMonotonic: true})
+	for i := 0; i < 100; i++ {
+		metric.AddSeries(map[string]string{"k8s.pod.name": fmt.Sprintf("pod-%d", i)}, map[string]string{"method": "GET"})
+	}
+	want := metric.SeriesContribution()
+
+	serialized, err := serializer.MarshalMetrics([]*models.MetricMetadata{metric})
+	if err != nil {
+		t.Fatalf("MarshalMetrics failed: %v", err)
+	}
+	if serialized[0].Contribution == nil {
+		t.Fatal("Contribution not serialized")
+	}
+	restored, err := serializer.UnmarshalMetrics(serialized)
+	if err != nil {
+		t.Fatalf("UnmarshalMetrics failed: %v", err)
+	}
+
+	got := restored[0].SeriesContribution()
+	if got.ActiveSeries != want.ActiveSeries || len(got.Labels) != 2 {
+		t.Fatalf("restored contribution = %+v, want %+v", got, want)
+	}
+	for i := range want.Labels {
+		if *got.Labels[i] != *want.Labels[i] {
+			t.Errorf("label %d = %+v, want %+v", i, got.Labels[i], want.Labels[i])
+		}
+	}
+}
+
 func TestSerializer_MarshalUnmarshalSpans_RoundTrip(t *testing.T) {
 	serializer := NewSerializer()
 
@@ -275,6 +391,56 @@ func TestSerializer_MarshalUnmarshalLogs_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestSerializer_MarshalUnmarshalProfiles_RoundTrip(t *testing.T) {
+	serializer := NewSerializer()
+
+	profile := models.NewProfileMetadata("cpu", "nanoseconds")
+	profile.PeriodType = "cpu:nanoseconds"
+	profile.ProfileCount = 3
+	profile.SampleCount = 1200
+	profile.MaxStackDepth = 42
+	profile.Services["checkout"] = 1200
+
+	threadKey := models.NewKeyMetadata()
+	for i := 0; i < 50; i++ {
+		threadKey.AddValue(string(rune('a' + i)))
+	}
+	profile.AttributeKeys["thread.name"] = threadKey
+	profile.MappingFiles = models.NewKeyMetadata()
+	profile.MappingFiles.AddValue("libc.so.6")
+
+	serialized, err := serializer.MarshalProfiles([]*models.ProfileMetadata{profile})
+	if err != nil {
+		t.Fatalf("MarshalProfiles failed: %v", err)
+	}
+
+	restored, err := serializer.UnmarshalProfiles(serialized)
+	if err != nil {
+		t.Fatalf("UnmarshalProfiles failed: %v", err)
+	}
+
+	if len(restored) != 1 {
+		t.Fatalf("Expected 1 restored profile, got %d", len(restored))
+	}
+
+	rp := restored[0]
+	if rp.ID != "cpu:nanoseconds" || rp.PeriodType != profile.PeriodType {
+		t.Errorf("ID/PeriodType mismatch: %s/%s", rp.ID, rp.PeriodType)
+	}
+	if rp.SampleCount != 1200 || rp.ProfileCount != 3 || rp.MaxStackDepth != 42 {
+		t.Errorf("counts mismatch: %+v", rp)
+	}
+	if got := rp.AttributeKeys["thread.name"].Cardinality(); got != threadKey.Cardinality() {
+		t.Errorf("thread.name cardinality: got %d, want %d", got, threadKey.Cardinality())
+	}
+	if rp.MappingFiles == nil || rp.MappingFiles.Cardinality() != 1 {
+		t.Errorf("MappingFiles not restored: %+v", rp.MappingFiles)
+	}
+	if rp.LocationAttributeKeys == nil || rp.MappingAttributeKeys == nil {
+		t.Error("key maps must be initialized after restore")
+	}
+}
+
 func TestSerializer_MarshalUnmarshalAttributes_RoundTrip(t *testing.T) {
 	serializer := NewSerializer()
 
@@ -364,7 +530,7 @@ func TestSerializer_CreateSession(t *testing.T) {
 	session, err := serializer.CreateSession(ctx, CreateSessionOptions{
 		Name:        "test-session",
 		Description: "Test session",
-	}, metrics, spans, logs, attributes, services, nil)
+	}, metrics, spans, logs, nil, attributes, services, nil)
 
 	if err != nil {
 		t.Fatalf("CreateSession failed: %v", err)
@@ -400,7 +566,7 @@ func TestSerializer_CreateSession_FilterBySignal(t *testing.T) {
 	session, err := serializer.CreateSession(ctx, CreateSessionOptions{
 		Name:    "metrics-only",
 		Signals: []string{"metrics"},
-	}, metrics, spans, nil, nil, nil, nil)
+	}, metrics, spans, nil, nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("CreateSession failed: %v", err)
 	}
@@ -428,7 +594,7 @@ func TestSerializer_CreateSession_FilterByService(t *testing.T) {
 	session, err := serializer.CreateSession(ctx, CreateSessionOptions{
 		Name:     "svc-a-only",
 		Services: []string{"svc-a"},
-	}, metrics, nil, nil, nil, nil, nil)
+	}, metrics, nil, nil, nil, nil, nil, nil)
 
 	if err != nil {
 		t.Fatalf("CreateSession failed: %v", err)
@@ -484,7 +650,7 @@ func TestSerializer_LargeSession(t *testing.T) {
 	session, err := serializer.CreateSession(ctx, CreateSessionOptions{
 		Name:        "large-session",
 		Description: "Performance test session",
-	}, metrics, nil, nil, nil, nil, nil)
+	}, metrics, nil, nil, nil, nil, nil, nil)
 	createDuration := time.Since(start)
 
 	if err != nil {
@@ -518,3 +684,56 @@ func TestSerializer_LargeSession(t *testing.T) {
 		t.Error("Expected SeriesHLL on first restored metric")
 	}
 }
+
+func TestSerializer_WindowsPreservedOnRoundTrip(t *testing.T) {
+	models.SetWindowConfig(hyperloglog.DefaultWindowConfig())
+	defer models.SetWindowConfig(hyperloglog.WindowConfig{})
+	serializer := NewSerializer()
+
+	metric := models.NewMetricMetadata("http_requests_total", &models.SumMetric{})
+	metric.LabelKeys["method"] = models.NewKeyMetadata()
+	attr := models.NewAttributeMetadata("user_id")
+	for i := 0; i < 50; i++ {
+		metric.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+		attr.AddValue(fmt.Sprintf("u%d", i), "metric", "attribute")
+	}
+	metric.LabelKeys["method"].AddValue("GET")
+	metric.LabelKeys["method"].AddValue("POST")
+
+	sm, err := serializer.MarshalMetrics([]*models.MetricMetadata{metric})
+	if err != nil {
+		t.Fatalf("MarshalMetrics failed: %v", err)
+	}
+	metrics, err := serializer.UnmarshalMetrics(sm)
+	if err != nil {
+		t.Fatalf("UnmarshalMetrics failed: %v", err)
+	}
+	sa, err := serializer.MarshalAttributes([]*models.AttributeMetadata{attr})
+	if err != nil {
+		t.Fatalf("MarshalAttributes failed: %v", err)
+	}
+	attrs, err := serializer.UnmarshalAttributes(sa)
+	if err != nil {
+		t.Fatalf("UnmarshalAttributes failed: %v", err)
+	}
+
+	if got, want := metrics[0].ActiveSeriesIn(time.Hour), metric.ActiveSeriesIn(time.Hour); got != want || got == 0 {
+		t.Errorf("ActiveSeriesIn(1h) = %d after round trip, want %d", got, want)
+	}
+	if got := metrics[0].LabelKeys["method"].CardinalityIn(time.Hour); got != 2 {
+		t.Errorf("method CardinalityIn(1h) = %d after round trip, want 2", got)
+	}
+	if got, want := attrs[0].CardinalityIn(time.Hour), attr.CardinalityIn(time.Hour); got != want || got == 0 {
+		t.Errorf("attribute CardinalityIn(1h) = %d after round trip, want %d", got, want)
+	}
+
+	// Windows saved with another layout are dropped rather than misread.
+	models.SetWindowConfig(hyperloglog.WindowConfig{Resolution: time.Minute, Horizon: time.Hour})
+	metrics, err = serializer.UnmarshalMetrics(sm)
+	if err != nil {
+		t.Fatalf("UnmarshalMetrics failed: %v", err)
+	}
+	if got := metrics[0].ActiveSeriesIn(time.Hour); got != 0 {
+		t.Errorf("ActiveSeriesIn(1h) from another layout = %d, want 0", got)
+	}
+}
@@ -0,0 +1,112 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Middleware resolves the tenant from the tenant header, creating it on
+// first use, and stores it in the request context. It guards ingestion
+// endpoints. Invalid IDs get 400 and new tenants beyond MaxTenants 403. A
+// nil Registry passes requests through untouched.
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	return r.middleware(next, r.Resolve)
+}
+
+// APIMiddleware is Middleware for the REST API: it only looks tenants up,
+// so unknown tenants get 404 instead of being created by a read.
+func (r *Registry) APIMiddleware(next http.Handler) http.Handler {
+	return r.middleware(next, r.Lookup)
+}
+
+func (r *Registry) middleware(next http.Handler, resolve func(string) (*Tenant, error)) http.Handler {
+	if r == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t, err := resolve(strings.TrimSpace(req.Header.Get(r.cfg.Header)))
+		if err != nil {
+			code := http.StatusBadRequest
+			switch {
+			case errors.Is(err, ErrTooManyTenants):
+				code = http.StatusForbidden
+			case errors.Is(err, ErrUnknownTenant):
+				code = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), t)))
+	})
+}
+
+// ForwardHeaders returns the tenant header to send upstream with data
+// forwarded for the tenant of ctx. It is nil for the default tenant, whose
+// requests carried no header, and for a nil Registry.
+func (r *Registry) ForwardHeaders(ctx context.Context) map[string]string {
+	if r == nil {
+		return nil
+	}
+	t := FromContext(ctx)
+	if t == nil || t == r.def {
+		return nil
+	}
+	return map[string]string{r.cfg.Header: t.ID}
+}
+
+// fromMetadata resolves the tenant of a gRPC call from its metadata.
+func (r *Registry) fromMetadata(ctx context.Context) (context.Context, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(strings.ToLower(r.cfg.Header)); len(v) > 0 {
+			id = strings.TrimSpace(v[0])
+		}
+	}
+	t, err := r.Resolve(id)
+	if err != nil {
+		code := codes.InvalidArgument
+		if errors.Is(err, ErrTooManyTenants) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+	return NewContext(ctx, t), nil
+}
+
+// UnaryServerInterceptor resolves the tenant of unary gRPC calls.
+func (r *Registry) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := r.fromMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor resolves the tenant of streaming gRPC calls.
+func (r *Registry) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := r.fromMetadata(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,348 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/memory"
+	"github.com/fidde/otlp_cardinality_checker/pkg/autotemplate"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+)
+
+// limitedStore enforces a tenant's limits on new entries. Entries beyond a
+// limit are dropped and counted instead of failing the whole export, the
+// same way a cardinality limiter in a collector would behave.
+type limitedStore struct {
+	*memory.Store
+	tenant *Tenant
+
+	// mu serializes the limit check and insert of new entries so that
+	// concurrent exports cannot push a tenant past its limits.
+	mu sync.Mutex
+}
+
+// insert runs store unless it would add an entry beyond max. Updates to
+// existing entries skip the lock since they cannot grow the count.
+func (s *limitedStore) insert(max int, exists func() bool, count func(memory.Counts) int, dropped *atomic.Uint64, store func() error) error {
+	if max <= 0 || exists() {
+		return store()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !exists() && count(s.Store.Counts()) >= max {
+		dropped.Add(1)
+		return nil
+	}
+	return store()
+}
+
+func (s *limitedStore) StoreMetric(ctx context.Context, metric *models.MetricMetadata) error {
+	if metric == nil {
+		return s.Store.StoreMetric(ctx, metric)
+	}
+	return s.insert(s.tenant.Limits.MaxMetrics,
+		func() bool { _, err := s.Store.GetMetric(ctx, metric.Name); return err == nil },
+		func(c memory.Counts) int { return c.Metrics },
+		&s.tenant.droppedMetrics,
+		func() error { return s.Store.StoreMetric(ctx, metric) })
+}
+
+func (s *limitedStore) StoreSpan(ctx context.Context, span *models.SpanMetadata) error {
+	if span == nil {
+		return s.Store.StoreSpan(ctx, span)
+	}
+	return s.insert(s.tenant.Limits.MaxSpans,
+		func() bool { _, err := s.Store.GetSpan(ctx, span.Name); return err == nil },
+		func(c memory.Counts) int { return c.Spans },
+		&s.tenant.droppedSpans,
+		func() error { return s.Store.StoreSpan(ctx, span) })
+}
+
+func (s *limitedStore) StoreAttributeValue(ctx context.Context, key, value, signalType, scope string) error {
+	return s.insert(s.tenant.Limits.MaxAttributes,
+		func() bool { _, err := s.Store.GetAttribute(ctx, key); return err == nil },
+		func(c memory.Counts) int { return c.Attributes },
+		&s.tenant.droppedAttributes,
+		func() error { return s.Store.StoreAttributeValue(ctx, key, value, signalType, scope) })
+}
+
+// Merges from a loaded or merged session go through the same limits, so a
+// session cannot push a tenant past them either. Logs and profiles have no
+// limits and merge directly.
+
+func (s *limitedStore) MergeMetric(ctx context.Context, metric *models.MetricMetadata) error {
+	if metric == nil {
+		return s.Store.MergeMetric(ctx, metric)
+	}
+	return s.insert(s.tenant.Limits.MaxMetrics,
+		func() bool { _, err := s.Store.GetMetric(ctx, metric.Name); return err == nil },
+		func(c memory.Counts) int { return c.Metrics },
+		&s.tenant.droppedMetrics,
+		func() error { return s.Store.MergeMetric(ctx, metric) })
+}
+
+func (s *limitedStore) MergeSpan(ctx context.Context, span *models.SpanMetadata) error {
+	if span == nil {
+		return s.Store.MergeSpan(ctx, span)
+	}
+	return s.insert(s.tenant.Limits.MaxSpans,
+		func() bool { _, err := s.Store.GetSpan(ctx, span.Name); return err == nil },
+		func(c memory.Counts) int { return c.Spans },
+		&s.tenant.droppedSpans,
+		func() error { return s.Store.MergeSpan(ctx, span) })
+}
+
+func (s *limitedStore) MergeAttribute(ctx context.Context, attr *models.AttributeMetadata) error {
+	if attr == nil {
+		return s.Store.MergeAttribute(ctx, attr)
+	}
+	return s.insert(s.tenant.Limits.MaxAttributes,
+		func() bool { _, err := s.Store.GetAttribute(ctx, attr.Key); return err == nil },
+		func(c memory.Counts) int { return c.Attributes },
+		&s.tenant.droppedAttributes,
+		func() error { return s.Store.MergeAttribute(ctx, attr) })
+}
+
+// Storage returns a storage.Storage that serves each call from the tenant
+// carried by its context, or from the default tenant. It also provides the
+// snapshot and merge methods the session API needs, so the REST API can
+// run unchanged on top of it.
+func (r *Registry) Storage() storage.Storage {
+	return &router{reg: r}
+}
+
+type router struct {
+	reg *Registry
+}
+
+func (r *router) tenant(ctx context.Context) *Tenant {
+	if t := FromContext(ctx); t != nil {
+		return t
+	}
+	return r.reg.def
+}
+
+func (r *router) StoreMetric(ctx context.Context, metric *models.MetricMetadata) error {
+	return r.tenant(ctx).Store.StoreMetric(ctx, metric)
+}
+
+func (r *router) GetMetric(ctx context.Context, name string) (*models.MetricMetadata, error) {
+	return r.tenant(ctx).Store.GetMetric(ctx, name)
+}
+
+func (r *router) ListMetrics(ctx context.Context, serviceName string) ([]*models.MetricMetadata, error) {
+	return r.tenant(ctx).Store.ListMetrics(ctx, serviceName)
+}
+
+func (r *router) StoreSpan(ctx context.Context, span *models.SpanMetadata) error {
+	return r.tenant(ctx).Store.StoreSpan(ctx, span)
+}
+
+func (r *router) GetSpan(ctx context.Context, name string) (*models.SpanMetadata, error) {
+	return r.tenant(ctx).Store.GetSpan(ctx, name)
+}
+
+func (r *router) ListSpans(ctx context.Context, serviceName string) ([]*models.SpanMetadata, error) {
+	return r.tenant(ctx).Store.ListSpans(ctx, serviceName)
+}
+
+func (r *router) StoreLog(ctx context.Context, log *models.LogMetadata) error {
+	return r.tenant(ctx).Store.StoreLog(ctx, log)
+}
+
+func (r *router) GetLog(ctx context.Context, severityText string) (*models.LogMetadata, error) {
+	return r.tenant(ctx).Store.GetLog(ctx, severityText)
+}
+
+func (r *router) ListLogs(ctx context.Context, serviceName string) ([]*models.LogMetadata, error) {
+	return r.tenant(ctx).Store.ListLogs(ctx, serviceName)
+}
+
+func (r *router) GetLogByServiceAndSeverity(ctx context.Context, serviceName, severityText string) (*models.LogMetadata, error) {
+	return r.tenant(ctx).mem.GetLogByServiceAndSeverity(ctx, serviceName, severityText)
+}
+
+func (r *router) GetLogPatterns(ctx context.Context, minCount int64, minServices int) (*models.PatternExplorerResponse, error) {
+	return r.tenant(ctx).Store.GetLogPatterns(ctx, minCount, minServices)
+}
+
+func (r *router) CountLogPatterns(ctx context.Context) (int, error) {
+	return r.tenant(ctx).Store.CountLogPatterns(ctx)
+}
+
+func (r *router) CountLogTemplatesByService(ctx context.Context) (map[string]int64, error) {
+	return r.tenant(ctx).mem.CountLogTemplatesByService(ctx)
+}
+
+func (r *router) GetSpanPatterns(ctx context.Context) (*models.SpanPatternResponse, error) {
+	return r.tenant(ctx).Store.GetSpanPatterns(ctx)
+}
+
+func (r *router) GetHighCardinalityKeys(ctx context.Context, threshold int, limit int) (*models.CrossSignalCardinalityResponse, error) {
+	return r.tenant(ctx).Store.GetHighCardinalityKeys(ctx, threshold, limit)
+}
+
+func (r *router) GetMetadataComplexity(ctx context.Context, threshold int, limit int) (*models.MetadataComplexityResponse, error) {
+	return r.tenant(ctx).Store.GetMetadataComplexity(ctx, threshold, limit)
+}
+
+func (r *router) StoreAttributeValue(ctx context.Context, key, value, signalType, scope string) error {
+	return r.tenant(ctx).Store.StoreAttributeValue(ctx, key, value, signalType, scope)
+}
+
+func (r *router) GetAttribute(ctx context.Context, key string) (*models.AttributeMetadata, error) {
+	return r.tenant(ctx).Store.GetAttribute(ctx, key)
+}
+
+func (r *router) ListAttributes(ctx context.Context, filter *models.AttributeFilter) ([]*models.AttributeMetadata, error) {
+	return r.tenant(ctx).Store.ListAttributes(ctx, filter)
+}
+
+func (r *router) WatchAttribute(ctx context.Context, key string) error {
+	return r.tenant(ctx).Store.WatchAttribute(ctx, key)
+}
+
+func (r *router) UnwatchAttribute(ctx context.Context, key string) error {
+	return r.tenant(ctx).Store.UnwatchAttribute(ctx, key)
+}
+
+func (r *router) GetWatchedAttribute(ctx context.Context, key string) (*models.WatchedAttribute, error) {
+	return r.tenant(ctx).Store.GetWatchedAttribute(ctx, key)
+}
+
+func (r *router) ListWatchedAttributes(ctx context.Context) ([]*models.WatchedAttribute, error) {
+	return r.tenant(ctx).Store.ListWatchedAttributes(ctx)
+}
+
+func (r *router) ListServices(ctx context.Context) ([]string, error) {
+	return r.tenant(ctx).Store.ListServices(ctx)
+}
+
+func (r *router) GetServiceOverview(ctx context.Context, serviceName string) (*models.ServiceOverview, error) {
+	return r.tenant(ctx).Store.GetServiceOverview(ctx, serviceName)
+}
+
+func (r *router) UseAutoTemplate() bool {
+	return r.reg.def.Store.UseAutoTemplate()
+}
+
+func (r *router) AutoTemplateCfg() autotemplate.Config {
+	return r.reg.def.Store.AutoTemplateCfg()
+}
+
+func (r *router) PodLogEnrichment() bool {
+	return r.reg.def.Store.PodLogEnrichment()
+}
+
+func (r *router) PodLogServiceLabels() []string {
+	return r.reg.def.Store.PodLogServiceLabels()
+}
+
+func (r *router) Clear(ctx context.Context) error {
+	t := r.tenant(ctx)
+	if err := t.Store.Clear(ctx); err != nil {
+		return err
+	}
+	r.reg.dropped(t.ID)
+	return nil
+}
+
+func (r *router) Close() error {
+	return r.reg.Close()
+}
+
+// Session snapshot and merge support.
+
+func (r *router) GetAll(ctx context.Context) (
+	metrics []*models.MetricMetadata,
+	spans []*models.SpanMetadata,
+	logs []*models.LogMetadata,
+	attrs []*models.AttributeMetadata,
+	services []string,
+	err error,
+) {
+	return r.tenant(ctx).mem.GetAll(ctx)
+}
+
+func (r *router) MergeMetric(ctx context.Context, metric *models.MetricMetadata) error {
+	return r.tenant(ctx).limited.MergeMetric(ctx, metric)
+}
+
+func (r *router) MergeSpan(ctx context.Context, span *models.SpanMetadata) error {
+	return r.tenant(ctx).limited.MergeSpan(ctx, span)
+}
+
+func (r *router) MergeLog(ctx context.Context, log *models.LogMetadata) error {
+	return r.tenant(ctx).mem.MergeLog(ctx, log)
+}
+
+func (r *router) GetAllProfiles(ctx context.Context) ([]*models.ProfileMetadata, error) {
+	return r.tenant(ctx).mem.GetAllProfiles(ctx)
+}
+
+func (r *router) MergeProfile(ctx context.Context, profile *models.ProfileMetadata) error {
+	return r.tenant(ctx).mem.MergeProfile(ctx, profile)
+}
+
+func (r *router) MergeAttribute(ctx context.Context, attr *models.AttributeMetadata) error {
+	return r.tenant(ctx).limited.MergeAttribute(ctx, attr)
+}
+
+func (r *router) GetWatchedAll(ctx context.Context) ([]*models.WatchedAttribute, error) {
+	return r.tenant(ctx).mem.GetWatchedAll(ctx)
+}
+
+func (r *router) MergeWatchedAttribute(ctx context.Context, watched *models.WatchedAttribute) error {
+	return r.tenant(ctx).mem.MergeWatchedAttribute(ctx, watched)
+}
+
+// Cardinality history support.
+
+func (r *router) RecordHistory(ctx context.Context, at time.Time) {
+	r.tenant(ctx).mem.RecordHistory(ctx, at)
+}
+
+func (r *router) History(ctx context.Context) *models.History {
+	return r.tenant(ctx).mem.History(ctx)
+}
+
+func (r *router) MergeHistory(ctx context.Context, h *models.SerializedHistory) error {
+	return r.tenant(ctx).mem.MergeHistory(ctx, h)
+}
+
+// Profile support.
+
+func (r *router) StoreProfile(ctx context.Context, profile *models.ProfileMetadata) error {
+	return r.tenant(ctx).mem.StoreProfile(ctx, profile)
+}
+
+func (r *router) GetProfile(ctx context.Context, id string) (*models.ProfileMetadata, error) {
+	return r.tenant(ctx).mem.GetProfile(ctx, id)
+}
+
+func (r *router) ListProfiles(ctx context.Context, serviceName string) ([]*models.ProfileMetadata, error) {
+	return r.tenant(ctx).mem.ListProfiles(ctx, serviceName)
+}
+
+// Metric identity conflict support.
+
+func (r *router) GetMetricConflicts(ctx context.Context) (*models.MetricConflictsResponse, error) {
+	return r.tenant(ctx).mem.GetMetricConflicts(ctx)
+}
+
+func (r *router) ConflictingMetricIdentities(ctx context.Context) map[string][]*models.MetricVariant {
+	return r.tenant(ctx).mem.ConflictingMetricIdentities(ctx)
+}
+
+// Loki stream label support.
+
+func (r *router) StoreLokiStreams(ctx context.Context, streams []models.LokiStream) error {
+	return r.tenant(ctx).mem.StoreLokiStreams(ctx, streams)
+}
+
+func (r *router) GetLokiStreams(ctx context.Context) (*models.LokiStreamsResponse, error) {
+	return r.tenant(ctx).mem.GetLokiStreams(ctx)
+}
@@ -0,0 +1,384 @@
+// Package tenant isolates teams sharing one deployment. The tenant is taken
+// from a request header (X-Scope-OrgID by default) on OTLP HTTP requests,
+// gRPC metadata and REST API calls. Every tenant owns its own in-memory
+// store, watched attributes and session directory, so clearing or loading a
+// session in one tenant leaves the others untouched.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/memory"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+)
+
+// DefaultHeader is the header carrying the tenant ID, as used by Mimir,
+// Loki and Tempo.
+const DefaultHeader = "X-Scope-OrgID"
+
+// DefaultID is the tenant of requests without a tenant header.
+const DefaultID = "default"
+
+// Errors returned by Registry.Resolve and Registry.Lookup.
+var (
+	ErrInvalidID      = errors.New("invalid tenant ID")
+	ErrTooManyTenants = errors.New("tenant limit reached")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrDefaultTenant  = errors.New("the default tenant cannot be removed")
+)
+
+// validID restricts tenant IDs to characters that are safe in a directory
+// name.
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// Limits bounds what a single tenant may store. Zero means unlimited, or
+// the storage default for MaxWatchedFields and MaxSessions.
+type Limits struct {
+	MaxMetrics       int `yaml:"max_metrics" json:"max_metrics,omitempty"`
+	MaxSpans         int `yaml:"max_spans" json:"max_spans,omitempty"`
+	MaxAttributes    int `yaml:"max_attributes" json:"max_attributes,omitempty"`
+	MaxWatchedFields int `yaml:"max_watched_fields" json:"max_watched_fields,omitempty"`
+	MaxSessions      int `yaml:"max_sessions" json:"max_sessions,omitempty"`
+}
+
+// merge returns l with every non-zero field of o applied.
+func (l Limits) merge(o Limits) Limits {
+	if o.MaxMetrics != 0 {
+		l.MaxMetrics = o.MaxMetrics
+	}
+	if o.MaxSpans != 0 {
+		l.MaxSpans = o.MaxSpans
+	}
+	if o.MaxAttributes != 0 {
+		l.MaxAttributes = o.MaxAttributes
+	}
+	if o.MaxWatchedFields != 0 {
+		l.MaxWatchedFields = o.MaxWatchedFields
+	}
+	if o.MaxSessions != 0 {
+		l.MaxSessions = o.MaxSessions
+	}
+	return l
+}
+
+// LimitsFile is the YAML layout of a limits file:
+//
+//	defaults:
+//	  max_metrics: 10000
+//	tenants:
+//	  team-a:
+//	    max_metrics: 50000
+type LimitsFile struct {
+	Defaults Limits            `yaml:"defaults"`
+	Tenants  map[string]Limits `yaml:"tenants"`
+}
+
+// LoadLimits reads a limits file.
+func LoadLimits(path string) (*LimitsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f LimitsFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for id := range f.Tenants {
+		if !validID.MatchString(id) {
+			return nil, fmt.Errorf("%s: %w %q", path, ErrInvalidID, id)
+		}
+	}
+	return &f, nil
+}
+
+// Config configures a Registry.
+type Config struct {
+	// Header carries the tenant ID. Defaults to DefaultHeader.
+	Header string
+	// MaxTenants caps the number of tenants, including the default one.
+	// Zero means unlimited.
+	MaxTenants int
+	// Limits apply to every tenant; Overrides replace them per tenant.
+	Limits    Limits
+	Overrides map[string]Limits
+	// Storage and Sessions configure each tenant's store. Tenants other
+	// than the default keep their sessions in <SessionDir>/tenants/<id>.
+	Storage  storage.Config
+	Sessions sessions.Config
+}
+
+// Dropped counts entries rejected by a tenant's limits.
+type Dropped struct {
+	Metrics    uint64 `json:"metrics"`
+	Spans      uint64 `json:"spans"`
+	Attributes uint64 `json:"attributes"`
+}
+
+// Tenant is one isolated slice of the checker.
+type Tenant struct {
+	ID       string
+	Created  time.Time
+	Limits   Limits
+	Store    storage.Storage
+	Sessions *sessions.Store
+
+	mem               *memory.Store
+	limited           *limitedStore
+	droppedMetrics    atomic.Uint64
+	droppedSpans      atomic.Uint64
+	droppedAttributes atomic.Uint64
+}
+
+// Info summarizes a tenant for the admin listing.
+type Info struct {
+	ID                string        `json:"id"`
+	Created           time.Time     `json:"created"`
+	Counts            memory.Counts `json:"counts"`
+	WatchedAttributes int           `json:"watched_attributes"`
+	Limits            Limits        `json:"limits"`
+	Dropped           Dropped       `json:"dropped"`
+}
+
+// Info returns a snapshot of t.
+func (t *Tenant) Info() Info {
+	watched, _ := t.mem.ListWatchedAttributes(context.Background())
+	return Info{
+		ID:                t.ID,
+		Created:           t.Created,
+		Counts:            t.mem.Counts(),
+		WatchedAttributes: len(watched),
+		Limits:            t.Limits,
+		Dropped: Dropped{
+			Metrics:    t.droppedMetrics.Load(),
+			Spans:      t.droppedSpans.Load(),
+			Attributes: t.droppedAttributes.Load(),
+		},
+	}
+}
+
+// Registry creates tenants on first ingestion and routes requests to them.
+type Registry struct {
+	cfg Config
+	def *Tenant
+
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+	onDrop  []func(id string)
+}
+
+// New creates a registry holding the default tenant.
+func New(cfg Config) (*Registry, error) {
+	if cfg.Header == "" {
+		cfg.Header = DefaultHeader
+	}
+	r := &Registry{cfg: cfg, tenants: make(map[string]*Tenant)}
+	def, err := r.create(DefaultID)
+	if err != nil {
+		return nil, err
+	}
+	r.def = def
+	r.tenants[DefaultID] = def
+	return r, nil
+}
+
+// Header returns the name of the tenant header.
+func (r *Registry) Header() string {
+	return r.cfg.Header
+}
+
+// Default returns the tenant of requests without a tenant header.
+func (r *Registry) Default() *Tenant {
+	return r.def
+}
+
+// Resolve returns the tenant with the given ID, creating it on first use.
+// An empty ID resolves to the default tenant.
+func (r *Registry) Resolve(id string) (*Tenant, error) {
+	if id == "" {
+		return r.def, nil
+	}
+	r.mu.RLock()
+	t, ok := r.tenants[id]
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidID, id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.tenants[id]; ok {
+		return t, nil
+	}
+	if r.cfg.MaxTenants > 0 && len(r.tenants) >= r.cfg.MaxTenants {
+		return nil, fmt.Errorf("%w (%d)", ErrTooManyTenants, r.cfg.MaxTenants)
+	}
+	t, err := r.create(id)
+	if err != nil {
+		return nil, err
+	}
+	r.tenants[id] = t
+	log.Printf("Created tenant %q", id)
+	return t, nil
+}
+
+// Lookup returns the existing tenant with the given ID without creating
+// it. An empty ID resolves to the default tenant.
+func (r *Registry) Lookup(id string) (*Tenant, error) {
+	if id == "" {
+		return r.def, nil
+	}
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("%w %q", ErrInvalidID, id)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+func (r *Registry) create(id string) (*Tenant, error) {
+	limits := r.cfg.Limits.merge(r.cfg.Overrides[id])
+
+	storageCfg := r.cfg.Storage
+	if limits.MaxWatchedFields > 0 {
+		storageCfg.MaxWatchedFields = limits.MaxWatchedFields
+	}
+	sessionsCfg := r.cfg.Sessions
+	if sessionsCfg.SessionDir == "" {
+		sessionsCfg = sessions.DefaultConfig()
+	}
+	if id != DefaultID {
+		sessionsCfg.SessionDir = filepath.Join(sessionsCfg.SessionDir, "tenants", id)
+	}
+	if limits.MaxSessions > 0 {
+		sessionsCfg.MaxSessions = limits.MaxSessions
+	}
+	sessionStore, err := sessions.NewWithConfig(sessionsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", id, err)
+	}
+
+	mem := memory.NewWithConfig(storageCfg.UseAutoTemplate, storageCfg.MaxWatchedFields, storageCfg.PodLogEnrichment, storageCfg.PodLogServiceLabels)
+	t := &Tenant{
+		ID:       id,
+		Created:  time.Now(),
+		Limits:   limits,
+		Sessions: sessionStore,
+		mem:      mem,
+	}
+	t.limited = &limitedStore{Store: mem, tenant: t}
+	t.Store = t.limited
+	return t, nil
+}
+
+// List returns every tenant, sorted by ID.
+func (r *Registry) List() []Info {
+	tenants := r.Tenants()
+	infos := make([]Info, len(tenants))
+	for i, t := range tenants {
+		infos[i] = t.Info()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Tenants returns every tenant, in no particular order.
+func (r *Registry) Tenants() []*Tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenants := make([]*Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		tenants = append(tenants, t)
+	}
+	return tenants
+}
+
+// Remove drops the tenant with the given ID and its data. Its session
+// directory stays on disk and is picked up again if the tenant returns.
+func (r *Registry) Remove(id string) error {
+	if id == "" || id == DefaultID {
+		return ErrDefaultTenant
+	}
+	r.mu.Lock()
+	t, ok := r.tenants[id]
+	delete(r.tenants, id)
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownTenant, id)
+	}
+	err := t.Store.Close()
+	r.dropped(id)
+	log.Printf("Removed tenant %q", id)
+	return err
+}
+
+// OnDrop registers fn to run after a tenant is removed or cleared, so that
+// state kept per tenant outside its store can be released with it. On a
+// nil Registry it does nothing.
+func (r *Registry) OnDrop(fn func(id string)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onDrop = append(r.onDrop, fn)
+}
+
+func (r *Registry) dropped(id string) {
+	r.mu.RLock()
+	fns := r.onDrop
+	r.mu.RUnlock()
+	for _, fn := range fns {
+		fn(id)
+	}
+}
+
+// RecordHistory samples the cardinality history of every tenant.
+func (r *Registry) RecordHistory(at time.Time) {
+	for _, t := range r.Tenants() {
+		t.mem.RecordHistory(context.Background(), at)
+	}
+}
+
+// Close closes every tenant's store.
+func (r *Registry) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var errs []error
+	for _, t := range r.tenants {
+		errs = append(errs, t.Store.Close())
+	}
+	return errors.Join(errs...)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying t.
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant carried by ctx, or nil.
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(contextKey{}).(*Tenant)
+	return t
+}
@@ -0,0 +1,338 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/fidde/otlp_cardinality_checker/internal/storage"
+	"github.com/fidde/otlp_cardinality_checker/internal/storage/sessions"
+	"github.com/fidde/otlp_cardinality_checker/pkg/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestRegistry(t *testing.T, cfg Config) *Registry {
+	t.Helper()
+	cfg.Storage = storage.DefaultConfig()
+	cfg.Sessions = sessions.Config{SessionDir: t.TempDir(), MaxSessionSize: 1 << 20, MaxSessions: 10}
+	r, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRouterIsolatesTenants(t *testing.T) {
+	r := newTestRegistry(t, Config{})
+	store := r.Storage()
+
+	a, err := r.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	ctxA := NewContext(context.Background(), a)
+	if err := store.StoreMetric(ctxA, models.NewMetricMetadata("a.requests", nil)); err != nil {
+		t.Fatalf("StoreMetric: %v", err)
+	}
+	if err := store.StoreMetric(context.Background(), models.NewMetricMetadata("default.requests", nil)); err != nil {
+		t.Fatalf("StoreMetric: %v", err)
+	}
+
+	if _, err := store.GetMetric(ctxA, "default.requests"); err == nil {
+		t.Error("team-a sees the default tenant's metric")
+	}
+	if _, err := store.GetMetric(context.Background(), "a.requests"); err == nil {
+		t.Error("default tenant sees team-a's metric")
+	}
+
+	// Clearing one tenant leaves the other alone.
+	if err := store.Clear(ctxA); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := store.GetMetric(context.Background(), "default.requests"); err != nil {
+		t.Errorf("default tenant lost its metric after team-a cleared: %v", err)
+	}
+}
+
+func TestSessionDirPerTenant(t *testing.T) {
+	r := newTestRegistry(t, Config{})
+	a, err := r.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if err := a.Sessions.Save(context.Background(), &models.Session{ID: "nightly", Version: sessions.CurrentVersion}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if exists, _ := r.Default().Sessions.Exists(context.Background(), "nightly"); exists {
+		t.Error("default tenant sees team-a's session")
+	}
+	if _, err := os.Stat(filepath.Join(r.cfg.Sessions.SessionDir, "tenants", "team-a")); err != nil {
+		t.Errorf("tenant session directory: %v", err)
+	}
+}
+
+func TestLimitsDropNewEntries(t *testing.T) {
+	r := newTestRegistry(t, Config{
+		Limits:    Limits{MaxMetrics: 1},
+		Overrides: map[string]Limits{"big": {MaxMetrics: 5}},
+	})
+	ctx := context.Background()
+	store := r.Default().Store
+
+	for _, name := range []string{"m1", "m2", "m1"} {
+		if err := store.StoreMetric(ctx, models.NewMetricMetadata(name, nil)); err != nil {
+			t.Fatalf("StoreMetric(%s): %v", name, err)
+		}
+	}
+	if _, err := store.GetMetric(ctx, "m2"); err == nil {
+		t.Error("metric beyond the limit was stored")
+	}
+	info := r.Default().Info()
+	if info.Counts.Metrics != 1 || info.Dropped.Metrics != 1 {
+		t.Errorf("counts %d, dropped %d; want 1 and 1", info.Counts.Metrics, info.Dropped.Metrics)
+	}
+
+	big, err := r.Resolve("big")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if big.Limits.MaxMetrics != 5 {
+		t.Errorf("override MaxMetrics = %d, want 5", big.Limits.MaxMetrics)
+	}
+
+	// Concurrent exports must not overshoot the limit.
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			big.Store.StoreMetric(ctx, models.NewMetricMetadata(fmt.Sprintf("c%d", i), nil)) //nolint:errcheck
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if info := big.Info(); info.Counts.Metrics != 5 || info.Dropped.Metrics != 195 {
+		t.Errorf("concurrent: counts %d, dropped %d; want 5 and 195", info.Counts.Metrics, info.Dropped.Metrics)
+	}
+}
+
+func TestLimitsApplyToMerges(t *testing.T) {
+	r := newTestRegistry(t, Config{Limits: Limits{MaxMetrics: 1, MaxAttributes: 1}})
+	store := r.Storage().(interface {
+		MergeMetric(context.Context, *models.MetricMetadata) error
+		MergeAttribute(context.Context, *models.AttributeMetadata) error
+	})
+	ctx := context.Background()
+
+	for _, name := range []string{"m1", "m2", "m1"} {
+		if err := store.MergeMetric(ctx, models.NewMetricMetadata(name, nil)); err != nil {
+			t.Fatalf("MergeMetric(%s): %v", name, err)
+		}
+	}
+	for _, key := range []string{"k1", "k2"} {
+		if err := store.MergeAttribute(ctx, models.NewAttributeMetadata(key)); err != nil {
+			t.Fatalf("MergeAttribute(%s): %v", key, err)
+		}
+	}
+	info := r.Default().Info()
+	if info.Counts.Metrics != 1 || info.Dropped.Metrics != 1 {
+		t.Errorf("metrics: counts %d, dropped %d; want 1 and 1", info.Counts.Metrics, info.Dropped.Metrics)
+	}
+	if info.Counts.Attributes != 1 || info.Dropped.Attributes != 1 {
+		t.Errorf("attributes: counts %d, dropped %d; want 1 and 1", info.Counts.Attributes, info.Dropped.Attributes)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	r := newTestRegistry(t, Config{MaxTenants: 2})
+
+	if _, err := r.Resolve("../etc"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Resolve(../etc) = %v, want ErrInvalidID", err)
+	}
+	if _, err := r.Resolve("team-a"); err != nil {
+		t.Fatalf("Resolve(team-a): %v", err)
+	}
+	if _, err := r.Resolve("team-b"); !errors.Is(err, ErrTooManyTenants) {
+		t.Errorf("Resolve(team-b) = %v, want ErrTooManyTenants", err)
+	}
+	// Known tenants keep resolving once the limit is reached.
+	if _, err := r.Resolve("team-a"); err != nil {
+		t.Errorf("Resolve(team-a) again: %v", err)
+	}
+
+	ids := []string{}
+	for _, info := range r.List() {
+		ids = append(ids, info.ID)
+	}
+	if len(ids) != 2 || ids[0] != DefaultID || ids[1] != "team-a" {
+		t.Errorf("List = %v, want [default team-a]", ids)
+	}
+}
+
+func TestRemoveAndOnDrop(t *testing.T) {
+	r := newTestRegistry(t, Config{MaxTenants: 2})
+	var dropped []string
+	r.OnDrop(func(id string) { dropped = append(dropped, id) })
+
+	a, err := r.Resolve("team-a")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if err := r.Storage().Clear(NewContext(context.Background(), a)); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if err := r.Remove("team-a"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if len(dropped) != 2 || dropped[0] != "team-a" || dropped[1] != "team-a" {
+		t.Errorf("dropped = %v, want team-a cleared then removed", dropped)
+	}
+	if _, err := r.Lookup("team-a"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Lookup after Remove = %v, want ErrUnknownTenant", err)
+	}
+	// The removed tenant frees its slot.
+	if _, err := r.Resolve("team-b"); err != nil {
+		t.Errorf("Resolve(team-b) after Remove: %v", err)
+	}
+
+	if err := r.Remove("team-a"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("Remove(team-a) again = %v, want ErrUnknownTenant", err)
+	}
+	if err := r.Remove(DefaultID); !errors.Is(err, ErrDefaultTenant) {
+		t.Errorf("Remove(default) = %v, want ErrDefaultTenant", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := newTestRegistry(t, Config{Header: "X-Tenant", MaxTenants: 2})
+
+	var got string
+	h := r.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = FromContext(req.Context()).ID
+	}))
+
+	tests := []struct {
+		header string
+		code   int
+		tenant string
+	}{
+		{"", http.StatusOK, DefaultID},
+		{"team-a", http.StatusOK, "team-a"},
+		{"bad/id", http.StatusBadRequest, ""},
+		{"team-b", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("X-Tenant", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.code || got != tt.tenant {
+			t.Errorf("header %q: status %d tenant %q, want %d %q", tt.header, w.Code, got, tt.code, tt.tenant)
+		}
+	}
+
+	// A nil registry leaves requests alone.
+	var nilReg *Registry
+	if h := nilReg.Middleware(http.NotFoundHandler()); h == nil {
+		t.Error("nil registry returned a nil handler")
+	}
+}
+
+func TestAPIMiddleware(t *testing.T) {
+	r := newTestRegistry(t, Config{Header: "X-Tenant"})
+	if _, err := r.Resolve("team-a"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	var got string
+	h := r.APIMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = FromContext(req.Context()).ID
+	}))
+
+	tests := []struct {
+		header string
+		code   int
+		tenant string
+	}{
+		{"", http.StatusOK, DefaultID},
+		{"team-a", http.StatusOK, "team-a"},
+		{"bad/id", http.StatusBadRequest, ""},
+		{"team-b", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("X-Tenant", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.code || got != tt.tenant {
+			t.Errorf("header %q: status %d tenant %q, want %d %q", tt.header, w.Code, got, tt.code, tt.tenant)
+		}
+	}
+
+	// Reads never create tenants or their session directories.
+	if len(r.Tenants()) != 2 {
+		t.Errorf("registry holds %d tenants, want 2", len(r.Tenants()))
+	}
+	if _, err := os.Stat(filepath.Join(r.cfg.Sessions.SessionDir, "tenants", "team-b")); !os.IsNotExist(err) {
+		t.Errorf("session directory of unknown tenant: %v", err)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	r := newTestRegistry(t, Config{})
+	intercept := r.UnaryServerInterceptor()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-scope-orgid", "team-a"))
+	resp, err := intercept(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) {
+		return FromContext(ctx).ID, nil
+	})
+	if err != nil || resp != "team-a" {
+		t.Errorf("got %v, %v; want team-a", resp, err)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-scope-orgid", "no spaces"))
+	_, err = intercept(ctx, nil, nil, func(ctx context.Context, _ any) (any, error) { return nil, nil })
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid tenant: code %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestLoadLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.yaml")
+	data := "defaults:\n  max_metrics: 100\ntenants:\n  team-a:\n    max_spans: 10\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadLimits(path)
+	if err != nil {
+		t.Fatalf("LoadLimits: %v", err)
+	}
+	if f.Defaults.MaxMetrics != 100 || f.Tenants["team-a"].MaxSpans != 10 {
+		t.Errorf("LoadLimits = %+v", f)
+	}
+
+	if err := os.WriteFile(path, []byte("tenants:\n  \"a b\":\n    max_spans: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLimits(path); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("invalid tenant ID: err = %v, want ErrInvalidID", err)
+	}
+}
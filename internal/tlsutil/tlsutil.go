@@ -0,0 +1,174 @@
+// Package tlsutil builds server TLS configurations for the OTLP receivers
+// and the REST API. Certificates and the client CA bundle are re-read when
+// their files change, so rotated certificates (e.g. cert-manager secrets)
+// take effect without a restart.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reloadInterval bounds how often the files are stat'ed during handshakes.
+const reloadInterval = time.Second
+
+// Config describes the TLS settings of a single listener.
+type Config struct {
+	// CertFile and KeyFile hold the PEM server certificate and key.
+	// TLS is disabled when both are empty.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle; when set, clients must present a
+	// certificate signed by one of its CAs (mTLS).
+	ClientCAFile string
+	// MinVersion is "1.0", "1.1", "1.2" or "1.3"; empty means 1.2.
+	MinVersion string
+}
+
+// Enabled reports whether TLS is configured.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// ParseVersion maps "1.0".."1.3" (optionally prefixed with "TLS") to the
+// crypto/tls constant.
+func ParseVersion(v string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(v)), "TLS") {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q (want 1.0, 1.1, 1.2 or 1.3)", v)
+}
+
+// ServerConfig loads the certificate (and CA bundle) and returns a TLS
+// configuration that reloads them when the files change. It returns nil
+// when TLS is not enabled.
+func (c Config) ServerConfig() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("both a certificate and a key file are required")
+	}
+	minVersion, err := ParseVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reloader{cfg: c}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	tlsCfg := base.Clone()
+	tlsCfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := r.current()
+		cfg := base.Clone()
+		cfg.Certificates = []tls.Certificate{*cert}
+		if pool != nil {
+			cfg.ClientCAs = pool
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		return cfg, nil
+	}
+	return tlsCfg, nil
+}
+
+// reloader caches the parsed files and re-reads them when a modification
+// time changes. A failed reload keeps serving the previous material.
+type reloader struct {
+	cfg Config
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  [3]time.Time
+	lastCheck time.Time
+}
+
+func (r *reloader) files() [3]string {
+	return [3]string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile}
+}
+
+func (r *reloader) stat() ([3]time.Time, error) {
+	var times [3]time.Time
+	for i, path := range r.files() {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return times, err
+		}
+		times[i] = info.ModTime()
+	}
+	return times, nil
+}
+
+// load reads all files unconditionally.
+func (r *reloader) load() error {
+	times, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA %s contains no PEM certificates", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTimes, r.lastCheck = &cert, pool, times, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// current returns the certificate and CA pool, reloading them first when
+// any file changed since the last check.
+func (r *reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	due := time.Since(r.lastCheck) >= reloadInterval
+	if due {
+		r.lastCheck = time.Now()
+	}
+	modTimes := r.modTimes
+	r.mu.Unlock()
+
+	if due {
+		if times, err := r.stat(); err == nil && times != modTimes {
+			// Errors are ignored: a half-written rotation is retried on
+			// the next check while the old certificate keeps serving.
+			_ = r.load()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, r.pool
+}
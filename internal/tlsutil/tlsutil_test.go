@@ -0,0 +1,216 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns PEM certificate and key for commonName.
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startServer serves HTTPS with cfg and returns its URL.
+func startServer(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func client(ca *testCA, cert *tls.Certificate, maxVersion uint16) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	tlsCfg := &tls.Config{RootCAs: roots, MaxVersion: maxVersion}
+	if cert != nil {
+		tlsCfg.Certificates = []tls.Certificate{*cert}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+}
+
+func TestServerConfig_Disabled(t *testing.T) {
+	cfg, err := Config{}.ServerConfig()
+	if cfg != nil || err != nil {
+		t.Errorf("ServerConfig() = %v, %v; want nil, nil", cfg, err)
+	}
+	if _, err := (Config{CertFile: "server.crt"}).ServerConfig(); err == nil {
+		t.Error("expected error when the key file is missing")
+	}
+}
+
+func TestServerConfig_MutualTLSAndMinVersion(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "server", 2)
+	writeFile(t, filepath.Join(dir, "server.crt"), certPEM)
+	writeFile(t, filepath.Join(dir, "server.key"), keyPEM)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+
+	tlsCfg, err := Config{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MinVersion:   "1.3",
+	}.ServerConfig()
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+	url := startServer(t, tlsCfg)
+
+	clientPEM, clientKey := ca.issue(t, "client", 3)
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client(ca, &clientCert, 0).Get(url)
+	if err != nil {
+		t.Fatalf("request with client certificate: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client(ca, nil, 0).Get(url); err == nil {
+		t.Error("request without client certificate succeeded")
+	}
+	if _, err := client(ca, &clientCert, tls.VersionTLS12).Get(url); err == nil {
+		t.Error("TLS 1.2 client accepted although the minimum is 1.3")
+	}
+}
+
+func TestServerConfig_ReloadsRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "before", 2)
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+
+	tlsCfg, err := Config{CertFile: certPath, KeyFile: keyPath}.ServerConfig()
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+	url := startServer(t, tlsCfg)
+
+	servedCN := func() string {
+		c := client(ca, nil, 0)
+		defer c.CloseIdleConnections()
+		resp, err := c.Get(url)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		defer resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	if cn := servedCN(); cn != "before" {
+		t.Fatalf("served %q, want before", cn)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "after", 3)
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certPath, future, future)
+	os.Chtimes(keyPath, future, future)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for servedCN() != "after" {
+		if time.Now().After(deadline) {
+			t.Fatal("rotated certificate was not picked up")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for in, want := range map[string]uint16{
+		"":       tls.VersionTLS12,
+		"1.3":    tls.VersionTLS13,
+		"TLS1.1": tls.VersionTLS11,
+	} {
+		got, err := ParseVersion(in)
+		if err != nil || got != want {
+			t.Errorf("ParseVersion(%q) = %x, %v", in, got, err)
+		}
+	}
+	if _, err := ParseVersion("1.4"); err == nil {
+		t.Error("expected error for 1.4")
+	}
+}
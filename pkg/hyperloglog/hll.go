@@ -120,6 +120,17 @@ func (h *HyperLogLog) AddHash(hash uint64) {
 	}
 }
 
+// Mix64 is the splitmix64 finalizer. It spreads poorly distributed hashes,
+// such as FNV of short similar strings or sums of hashes, before AddHash.
+func Mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
 // Count returns the estimated cardinality.
 func (h *HyperLogLog) Count() uint64 {
 	// Calculate raw estimate using harmonic mean
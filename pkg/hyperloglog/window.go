@@ -0,0 +1,324 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"time"
+)
+
+// fineBuckets is the number of buckets at the configured resolution. Older
+// data is kept in coarse buckets as wide as all fine buckets together.
+const fineBuckets = 60
+
+// WindowConfig describes the buckets of a Window.
+type WindowConfig struct {
+	// Resolution is the width of the most recent buckets; windows shorter
+	// than fineBuckets resolutions are rounded to it.
+	Resolution time.Duration
+	// Horizon is the longest window that can be counted.
+	Horizon time.Duration
+	// Precision of the bucket sketches. Every bucket holds its own sketch,
+	// so this is lower than for cumulative counts.
+	Precision uint8
+}
+
+// DefaultWindowConfig keeps one-minute buckets for the last hour and hourly
+// buckets for the last day: at most 84 sketches of 256 bytes each.
+func DefaultWindowConfig() WindowConfig {
+	return WindowConfig{
+		Resolution: time.Minute,
+		Horizon:    24 * time.Hour,
+		Precision:  8,
+	}
+}
+
+// normalize fills in defaults for unset fields.
+func (c WindowConfig) normalize() WindowConfig {
+	def := DefaultWindowConfig()
+	if c.Resolution <= 0 {
+		c.Resolution = def.Resolution
+	}
+	if c.Horizon < c.Resolution {
+		c.Horizon = max(def.Horizon, c.Resolution)
+	}
+	if c.Precision < 4 || c.Precision > 18 {
+		c.Precision = def.Precision
+	}
+	return c
+}
+
+type windowBucket struct {
+	epoch int64 // start time divided by the level's width
+	hll   *HyperLogLog
+}
+
+type windowLevel struct {
+	width   int64 // nanoseconds
+	buckets []windowBucket
+}
+
+func (l *windowLevel) span() time.Duration {
+	return time.Duration(l.width * int64(len(l.buckets)))
+}
+
+// Window estimates the cardinality of values added within a recent time
+// window. Values go into rotating time buckets, each with its own sketch;
+// a count merges the buckets the window covers. Bucket sketches are
+// allocated on first use and reused when their slot rotates. A nil Window
+// records nothing and counts zero.
+//
+// Like HyperLogLog, a Window is not safe for concurrent use.
+type Window struct {
+	precision uint8
+	levels    []windowLevel
+}
+
+// layout returns the width in nanoseconds and bucket count of each level.
+func (c WindowConfig) layout() (widths []int64, counts []int) {
+	c = c.normalize()
+	res := int64(c.Resolution)
+	n := (int64(c.Horizon) + res - 1) / res
+	if n <= fineBuckets {
+		return []int64{res}, []int{int(n)}
+	}
+	coarse := res * fineBuckets
+	return []int64{res, coarse}, []int{fineBuckets, int((int64(c.Horizon) + coarse - 1) / coarse)}
+}
+
+// Span returns the longest window a Window with this configuration can
+// count: the horizon rounded up to whole buckets.
+func (c WindowConfig) Span() time.Duration {
+	widths, counts := c.layout()
+	last := len(widths) - 1
+	return time.Duration(widths[last] * int64(counts[last]))
+}
+
+// NewWindow creates an empty window.
+func NewWindow(cfg WindowConfig) *Window {
+	widths, counts := cfg.layout()
+	w := &Window{precision: cfg.normalize().Precision}
+	for i := range widths {
+		w.levels = append(w.levels, windowLevel{width: widths[i], buckets: make([]windowBucket, counts[i])})
+	}
+	return w
+}
+
+// Horizon returns the longest window that can be counted.
+func (w *Window) Horizon() time.Duration {
+	if w == nil {
+		return 0
+	}
+	return w.levels[len(w.levels)-1].span()
+}
+
+// Add records value as seen at t.
+func (w *Window) Add(value string, t time.Time) {
+	if w == nil {
+		return
+	}
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	w.AddHash(h.Sum64(), t)
+}
+
+// AddHash records a pre-computed hash as seen at t.
+func (w *Window) AddHash(hash uint64, t time.Time) {
+	if w == nil {
+		return
+	}
+	// FNV's low bits, which pick the register, are poorly spread for short
+	// similar strings; at the small bucket precision that skews estimates.
+	hash = Mix64(hash)
+	for i := range w.levels {
+		if b := w.bucket(&w.levels[i], t.UnixNano()/w.levels[i].width); b != nil {
+			b.AddHash(hash)
+		}
+	}
+}
+
+// bucket returns the sketch for epoch, rotating its slot if the slot holds
+// an older epoch. It returns nil if the slot already holds a newer one.
+func (w *Window) bucket(l *windowLevel, epoch int64) *HyperLogLog {
+	b := &l.buckets[epoch%int64(len(l.buckets))]
+	switch {
+	case b.hll == nil:
+		b.hll = New(w.precision)
+	case b.epoch > epoch:
+		return nil
+	case b.epoch < epoch:
+		b.hll.Clear()
+	}
+	b.epoch = epoch
+	return b.hll
+}
+
+// Count estimates the distinct values added within d before now. d is
+// rounded up to whole buckets of the finest level spanning it, the current
+// partial bucket included, and capped at the horizon.
+func (w *Window) Count(d time.Duration, now time.Time) uint64 {
+	if w == nil || d <= 0 {
+		return 0
+	}
+	l := &w.levels[len(w.levels)-1]
+	for i := range w.levels {
+		if w.levels[i].span() >= d {
+			l = &w.levels[i]
+			break
+		}
+	}
+	k := min((int64(d)+l.width-1)/l.width, int64(len(l.buckets)))
+	cur := now.UnixNano() / l.width
+
+	var union *HyperLogLog
+	for _, b := range l.buckets {
+		if b.hll == nil || b.epoch > cur || b.epoch <= cur-k {
+			continue
+		}
+		if union == nil {
+			union = New(w.precision)
+			defer union.Release()
+		}
+		union.Merge(b.hll) //nolint:errcheck
+	}
+	if union == nil {
+		return 0
+	}
+	return union.Count()
+}
+
+// SameLayout reports whether w and other share a configuration and can be
+// merged.
+func (w *Window) SameLayout(other *Window) bool {
+	if w == nil || other == nil || w.precision != other.precision || len(w.levels) != len(other.levels) {
+		return false
+	}
+	for i := range w.levels {
+		if w.levels[i].width != other.levels[i].width || len(w.levels[i].buckets) != len(other.levels[i].buckets) {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge adds the buckets of other into w. Both windows must share a
+// configuration; otherwise other is ignored, as it is when w is nil.
+func (w *Window) Merge(other *Window) {
+	if !w.SameLayout(other) {
+		return
+	}
+	for i := range w.levels {
+		l, ol := &w.levels[i], &other.levels[i]
+		for _, ob := range ol.buckets {
+			if ob.hll == nil {
+				continue
+			}
+			if b := w.bucket(l, ob.epoch); b != nil {
+				b.Merge(ob.hll) //nolint:errcheck
+			}
+		}
+	}
+}
+
+// Release returns the bucket sketches to the pool. The Window must not be
+// used afterwards.
+func (w *Window) Release() {
+	if w == nil {
+		return
+	}
+	for i := range w.levels {
+		for j := range w.levels[i].buckets {
+			if b := &w.levels[i].buckets[j]; b.hll != nil {
+				b.hll.Release()
+				b.hll = nil
+			}
+		}
+	}
+}
+
+// MemorySize returns the approximate memory usage in bytes.
+func (w *Window) MemorySize() int {
+	if w == nil {
+		return 0
+	}
+	size := 32
+	for _, l := range w.levels {
+		size += 16 * len(l.buckets)
+		for _, b := range l.buckets {
+			if b.hll != nil {
+				size += b.hll.MemorySize()
+			}
+		}
+	}
+	return size
+}
+
+// MarshalBinary encodes the window's layout and used buckets.
+// Format: [precision:1][levels:1], then per level [width:8][buckets:4]
+// [used:4] followed by used times [slot:4][epoch:8][registers:m bytes].
+func (w *Window) MarshalBinary() ([]byte, error) {
+	m := 1 << w.precision
+	data := []byte{w.precision, byte(len(w.levels))}
+	for _, l := range w.levels {
+		var used uint32
+		for _, b := range l.buckets {
+			if b.hll != nil {
+				used++
+			}
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(l.width))
+		data = binary.BigEndian.AppendUint32(data, uint32(len(l.buckets)))
+		data = binary.BigEndian.AppendUint32(data, used)
+		for i, b := range l.buckets {
+			if b.hll == nil {
+				continue
+			}
+			data = binary.BigEndian.AppendUint32(data, uint32(i))
+			data = binary.BigEndian.AppendUint64(data, uint64(b.epoch))
+			data = append(data, b.hll.registers[:m]...)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a window encoded by MarshalBinary, replacing the
+// contents of w.
+func (w *Window) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] < 4 || data[0] > 18 || data[1] == 0 {
+		return ErrInvalidData
+	}
+	out := Window{precision: data[0]}
+	m := 1 << out.precision
+	levels := int(data[1])
+	data = data[2:]
+	for range levels {
+		if len(data) < 16 {
+			return ErrInvalidData
+		}
+		width := int64(binary.BigEndian.Uint64(data))
+		n := binary.BigEndian.Uint32(data[8:])
+		used := binary.BigEndian.Uint32(data[12:])
+		data = data[16:]
+		if width <= 0 || n == 0 || used > n || uint64(len(data)) < uint64(used)*uint64(12+m) {
+			return ErrInvalidData
+		}
+		l := windowLevel{width: width, buckets: make([]windowBucket, n)}
+		for range used {
+			slot := binary.BigEndian.Uint32(data)
+			if slot >= n || l.buckets[slot].hll != nil {
+				return ErrInvalidData
+			}
+			b := &l.buckets[slot]
+			b.epoch = int64(binary.BigEndian.Uint64(data[4:]))
+			b.hll = New(out.precision)
+			copy(b.hll.registers, data[12:12+m])
+			data = data[12+m:]
+		}
+		out.levels = append(out.levels, l)
+	}
+	if len(data) != 0 {
+		return ErrInvalidData
+	}
+	w.Release()
+	*w = out
+	return nil
+}
@@ -0,0 +1,166 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWindowLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     WindowConfig
+		widths  []time.Duration
+		buckets []int
+	}{
+		{"default", DefaultWindowConfig(), []time.Duration{time.Minute, time.Hour}, []int{60, 24}},
+		{"fine only", WindowConfig{Resolution: time.Minute, Horizon: 15 * time.Minute}, []time.Duration{time.Minute}, []int{15}},
+		{"week", WindowConfig{Resolution: 5 * time.Minute, Horizon: 7 * 24 * time.Hour}, []time.Duration{5 * time.Minute, 5 * time.Hour}, []int{60, 34}},
+		{"zero", WindowConfig{}, []time.Duration{time.Minute, time.Hour}, []int{60, 24}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWindow(tt.cfg)
+			if len(w.levels) != len(tt.widths) {
+				t.Fatalf("levels = %d, want %d", len(w.levels), len(tt.widths))
+			}
+			for i, l := range w.levels {
+				if time.Duration(l.width) != tt.widths[i] || len(l.buckets) != tt.buckets[i] {
+					t.Errorf("level %d = %d × %s, want %d × %s", i, len(l.buckets), time.Duration(l.width), tt.buckets[i], tt.widths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWindowCount(t *testing.T) {
+	w := NewWindow(DefaultWindowConfig())
+	defer w.Release()
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	// 100 values three hours ago, 50 half an hour ago, 20 in the last five
+	// minutes, each batch with fresh values.
+	add := func(at time.Time, prefix string, n int) {
+		for i := 0; i < n; i++ {
+			w.Add(fmt.Sprintf("%s-%d", prefix, i), at)
+		}
+	}
+	now := start.Add(10 * time.Hour)
+	add(now.Add(-3*time.Hour), "old", 100)
+	add(now.Add(-30*time.Minute), "recent", 50)
+	add(now.Add(-2*time.Minute), "live", 20)
+
+	tests := []struct {
+		window time.Duration
+		want   uint64
+	}{
+		{5 * time.Minute, 20},
+		{15 * time.Minute, 20},
+		{time.Hour, 70},
+		{24 * time.Hour, 170},
+		{48 * time.Hour, 170}, // capped at the horizon
+		{0, 0},
+	}
+	for _, tt := range tests {
+		got := w.Count(tt.window, now)
+		if diff := int64(got) - int64(tt.want); diff < -int64(tt.want)/10 || diff > int64(tt.want)/10 {
+			t.Errorf("Count(%s) = %d, want ~%d", tt.window, got, tt.want)
+		}
+	}
+
+	// A day later everything has aged out.
+	if got := w.Count(24*time.Hour, now.Add(25*time.Hour)); got != 0 {
+		t.Errorf("Count a day later = %d, want 0", got)
+	}
+}
+
+func TestWindowRotation(t *testing.T) {
+	w := NewWindow(WindowConfig{Resolution: time.Minute, Horizon: 10 * time.Minute})
+	defer w.Release()
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	// The same slot is reused every ten minutes; old values must not leak.
+	for i := 0; i < 5; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Minute)
+		w.Add(fmt.Sprintf("v%d", i), at)
+		if got := w.Count(time.Minute, at); got != 1 {
+			t.Errorf("round %d: Count = %d, want 1", i, got)
+		}
+	}
+	// A value older than the slot's current bucket is ignored.
+	w.Add("late", start)
+	if got := w.Count(10*time.Minute, start.Add(40*time.Minute)); got != 1 {
+		t.Errorf("Count after late add = %d, want 1", got)
+	}
+}
+
+func TestWindowMerge(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	a, b := NewWindow(DefaultWindowConfig()), NewWindow(DefaultWindowConfig())
+	defer a.Release()
+	defer b.Release()
+
+	for i := 0; i < 30; i++ {
+		a.Add(fmt.Sprintf("a-%d", i), now.Add(-time.Minute))
+		b.Add(fmt.Sprintf("b-%d", i), now.Add(-2*time.Hour))
+		b.Add(fmt.Sprintf("shared-%d", i), now)
+		a.Add(fmt.Sprintf("shared-%d", i), now)
+	}
+	a.Merge(b)
+
+	if got := a.Count(time.Hour, now); got < 57 || got > 63 {
+		t.Errorf("Count(1h) = %d, want ~60", got)
+	}
+	if got := a.Count(24*time.Hour, now); got < 85 || got > 95 {
+		t.Errorf("Count(24h) = %d, want ~90", got)
+	}
+
+	// Windows with different layouts are not merged.
+	c := NewWindow(WindowConfig{Resolution: time.Minute, Horizon: 10 * time.Minute})
+	defer c.Release()
+	c.Merge(a)
+	if got := c.Count(10*time.Minute, now); got != 0 {
+		t.Errorf("Count after mismatched merge = %d, want 0", got)
+	}
+
+	// A nil window records nothing.
+	var none *Window
+	none.Add("x", now)
+	none.Merge(a)
+	if got := none.Count(time.Hour, now); got != 0 || none.MemorySize() != 0 {
+		t.Errorf("nil window counted %d", got)
+	}
+}
+
+func TestWindowMarshalBinary(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	w := NewWindow(DefaultWindowConfig())
+	defer w.Release()
+	for i := 0; i < 100; i++ {
+		w.Add(fmt.Sprintf("old-%d", i), now.Add(-3*time.Hour))
+	}
+	for i := 0; i < 20; i++ {
+		w.Add(fmt.Sprintf("new-%d", i), now)
+	}
+
+	data, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	var got Window
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	defer got.Release()
+	for _, d := range []time.Duration{15 * time.Minute, 24 * time.Hour} {
+		if a, b := w.Count(d, now), got.Count(d, now); a != b {
+			t.Errorf("Count(%s) = %d after round trip, want %d", d, b, a)
+		}
+	}
+
+	for _, bad := range [][]byte{nil, {8}, {8, 1}, data[:len(data)-1], append(data, 0)} {
+		if err := new(Window).UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary(%d bytes) succeeded, want error", len(bad))
+		}
+	}
+}
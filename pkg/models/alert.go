@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Alert targets: what an alert rule watches.
+const (
+	AlertTargetMetricSeries         = "metric_series"
+	AlertTargetAttributeCardinality = "attribute_cardinality"
+	AlertTargetLogTemplates         = "log_templates"
+)
+
+// Alert states.
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Alert is a cardinality explosion detected by an alert rule for one
+// metric, attribute key or service.
+type Alert struct {
+	Rule     string `json:"rule"`
+	Target   string `json:"target"`
+	Name     string `json:"name"`
+	Tenant   string `json:"tenant,omitempty"`
+	Severity string `json:"severity"`
+	State    string `json:"state"`
+
+	// Value is the latest cardinality; Growth how much it rose within
+	// Window. Peak is the highest value while firing.
+	Value  int64  `json:"value"`
+	Growth int64  `json:"growth"`
+	Window string `json:"window,omitempty"`
+	Peak   int64  `json:"peak"`
+
+	Summary    string     `json:"summary"`
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
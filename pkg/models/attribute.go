@@ -20,6 +20,10 @@ type AttributeMetadata struct {
 	// hll is the HyperLogLog sketch for estimating unique value cardinality
 	hll *hyperloglog.HyperLogLog
 
+	// window counts values by when they were seen; nil until the first one
+	// and while windows are disabled
+	window *hyperloglog.Window
+
 	// Count is the total number of times this attribute key has been observed
 	Count int64 `json:"count"`
 
@@ -31,7 +35,7 @@ type AttributeMetadata struct {
 	// For high-cardinality attributes this is biased toward early observations.
 	ValueSamples []string `json:"value_samples"`
 
-	// SignalTypes tracks which signal types use this attribute (metric, span, log)
+	// SignalTypes tracks which signal types use this attribute (metric, span, log, profile)
 	SignalTypes []string `json:"signal_types"`
 
 	// Scope tracks whether this is a resource or regular attribute
@@ -59,7 +63,7 @@ func NewAttributeMetadata(key string) *AttributeMetadata {
 		Count:                0,
 		EstimatedCardinality: 0,
 		ValueSamples:         make([]string, 0, maxSamples),
-		SignalTypes:          make([]string, 0, 3), // max 3: metric, span, log
+		SignalTypes:          make([]string, 0, 4), // max 4: metric, span, log, profile
 		Scope:                "",
 		FirstSeen:            time.Now(),
 		LastSeen:             time.Now(),
@@ -76,6 +80,11 @@ func (a *AttributeMetadata) AddValue(value, signalType, scope string) {
 	// Add to HLL sketch. EstimatedCardinality is computed lazily in
 	// MarshalJSON to avoid the 16 384-register Count() scan on every write.
 	a.hll.Add(value)
+	now := clock()
+	if a.window == nil {
+		a.window = newWindow()
+	}
+	a.window.Add(value, now)
 
 	// Update count
 	a.Count++
@@ -114,7 +123,7 @@ func (a *AttributeMetadata) AddValue(value, signalType, scope string) {
 	}
 
 	// Update timestamp
-	a.LastSeen = time.Now()
+	a.LastSeen = now
 
 	// Flag if the value contains the Unicode replacement character, which our
 	// sanitizeUTF8 receiver helper inserts in place of invalid UTF-8 bytes.
@@ -123,6 +132,17 @@ func (a *AttributeMetadata) AddValue(value, signalType, scope string) {
 	}
 }
 
+// Cardinality returns the current HLL estimate of unique values. Unlike
+// the EstimatedCardinality field it does not wait for the next MarshalJSON.
+func (a *AttributeMetadata) Cardinality() int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.hll == nil {
+		return a.EstimatedCardinality
+	}
+	return int64(a.hll.Count())
+}
+
 // MarshalHLL serializes the HLL sketch for persistence.
 func (a *AttributeMetadata) MarshalHLL() ([]byte, error) {
 	a.mu.RLock()
@@ -212,6 +232,12 @@ func MergeAttributeMetadata(a1, a2 *AttributeMetadata) *AttributeMetadata {
 		a1.hll.Merge(a2.hll)
 		a1.EstimatedCardinality = int64(a1.hll.Count())
 	}
+	if a2.window != nil {
+		if a1.window == nil {
+			a1.window = newWindow()
+		}
+		a1.window.Merge(a2.window)
+	}
 
 	// Merge counts
 	a1.Count += a2.Count
@@ -9,7 +9,7 @@ type CrossSignalCardinalityResponse struct {
 
 // SignalKey represents a key with its signal type and metadata
 type SignalKey struct {
-	SignalType          string   `json:"signal_type"`          // "metric", "span", "log"
+	SignalType          string   `json:"signal_type"`          // "metric", "span", "log", "profile"
 	SignalName          string   `json:"signal_name"`          // metric name, span name, or severity
 	KeyScope            string   `json:"key_scope"`            // "label", "resource", "attribute", etc.
 	KeyName             string   `json:"key_name"`             // The actual key name
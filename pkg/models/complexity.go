@@ -9,7 +9,7 @@ type MetadataComplexityResponse struct {
 
 // SignalComplexity represents a signal with its metadata complexity metrics
 type SignalComplexity struct {
-	SignalType           string `json:"signal_type"`             // "metric", "span", "log"
+	SignalType           string `json:"signal_type"`             // "metric", "span", "log", "profile"
 	SignalName           string `json:"signal_name"`             // metric name, span name, or severity
 	TotalKeys            int    `json:"total_keys"`              // Total unique keys across all scopes
 	AttributeKeyCount    int    `json:"attribute_key_count"`     // Number of attribute/label keys
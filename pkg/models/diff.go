@@ -20,6 +20,7 @@ const (
 	SignalTypeMetric    = "metric"
 	SignalTypeSpan      = "span"
 	SignalTypeLog       = "log"
+	SignalTypeProfile   = "profile"
 	SignalTypeAttribute = "attribute"
 )
 
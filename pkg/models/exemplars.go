@@ -0,0 +1,79 @@
+package models
+
+// ExemplarStats summarizes the exemplars attached to a metric's data points.
+// Backends bill for exemplar storage, so the share of data points carrying
+// them and the cardinality of their filtered attributes matter as much as
+// the data point labels.
+type ExemplarStats struct {
+	// DataPointsWithExemplars counts data points carrying at least one exemplar
+	DataPointsWithExemplars int64 `json:"data_points_with_exemplars"`
+
+	// Fraction is DataPointsWithExemplars divided by the metric's SampleCount (0-1)
+	Fraction float64 `json:"fraction"`
+
+	// ExemplarCount is the total number of exemplars observed
+	ExemplarCount int64 `json:"exemplar_count"`
+
+	// WithTraceID and WithSpanID count exemplars linked to a trace or span
+	WithTraceID int64 `json:"with_trace_id"`
+	WithSpanID  int64 `json:"with_span_id"`
+
+	// FilteredAttributeKeys maps Exemplar.filtered_attributes keys to their
+	// metadata; percentages are relative to ExemplarCount
+	FilteredAttributeKeys map[string]*KeyMetadata `json:"filtered_attribute_keys"`
+}
+
+// NewExemplarStats creates empty exemplar statistics.
+func NewExemplarStats() *ExemplarStats {
+	return &ExemplarStats{
+		FilteredAttributeKeys: make(map[string]*KeyMetadata),
+	}
+}
+
+// AddExemplar records one exemplar with its filtered attributes.
+func (e *ExemplarStats) AddExemplar(filteredAttrs map[string]string, hasTraceID, hasSpanID bool) {
+	e.ExemplarCount++
+	if hasTraceID {
+		e.WithTraceID++
+	}
+	if hasSpanID {
+		e.WithSpanID++
+	}
+	for key, value := range filteredAttrs {
+		km := e.FilteredAttributeKeys[key]
+		if km == nil {
+			km = NewKeyMetadata()
+			e.FilteredAttributeKeys[key] = km
+		}
+		km.AddValue(value)
+	}
+}
+
+// Update recomputes the fraction and the key percentages after data points
+// or exemplars were added. sampleCount is the metric's data point count.
+func (e *ExemplarStats) Update(sampleCount int64) {
+	if e == nil {
+		return
+	}
+	if sampleCount > 0 {
+		e.Fraction = float64(e.DataPointsWithExemplars) / float64(sampleCount)
+	}
+	for _, km := range e.FilteredAttributeKeys {
+		km.UpdatePercentage(e.ExemplarCount)
+	}
+}
+
+// Merge adds other into e. Percentages are left for the caller to Update.
+func (e *ExemplarStats) Merge(other *ExemplarStats) {
+	e.DataPointsWithExemplars += other.DataPointsWithExemplars
+	e.ExemplarCount += other.ExemplarCount
+	e.WithTraceID += other.WithTraceID
+	e.WithSpanID += other.WithSpanID
+	for key, km := range other.FilteredAttributeKeys {
+		if existing, ok := e.FilteredAttributeKeys[key]; ok {
+			MergeKeyMetadata(existing, km)
+		} else {
+			e.FilteredAttributeKeys[key] = km
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Default thresholds for histogram layout analysis.
+const (
+	// DefaultMaxHistogramBuckets flags layouts with more buckets than this.
+	// The OTel SDK default layout has 16 buckets and the Prometheus client
+	// default 12.
+	DefaultMaxHistogramBuckets = 20
+
+	// DefaultExpensiveHistogramSeries is the Prometheus series estimate at
+	// which an explicit bucket histogram is worth replacing.
+	DefaultExpensiveHistogramSeries = 1000
+)
+
+// BucketLayout is one distinct set of explicit bounds used by a histogram,
+// with the services and data points using it.
+type BucketLayout struct {
+	Bounds     []float64        `json:"bounds"`
+	DataPoints int64            `json:"data_points"`
+	Services   map[string]int64 `json:"services"`
+}
+
+// BucketCount is the number of buckets, including the implicit +Inf bucket.
+func (l *BucketLayout) BucketCount() int {
+	return len(l.Bounds) + 1
+}
+
+// SeriesCost is the number of Prometheus series one OTLP series with this
+// layout turns into: one per bucket plus _sum and _count.
+func (l *BucketLayout) SeriesCost() int {
+	return l.BucketCount() + 2
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// AddBucketLayout records dataPoints data points from service using bounds.
+func (h *HistogramMetric) AddBucketLayout(bounds []float64, service string, dataPoints int64) {
+	layout := h.bucketLayout(bounds)
+	layout.DataPoints += dataPoints
+	if service != "" {
+		layout.Services[service] += dataPoints
+	}
+}
+
+// bucketLayout returns the layout with the given bounds, adding it if new.
+func (h *HistogramMetric) bucketLayout(bounds []float64) *BucketLayout {
+	for _, l := range h.BucketLayouts {
+		if equalBounds(l.Bounds, bounds) {
+			return l
+		}
+	}
+	l := &BucketLayout{
+		Bounds:   append([]float64{}, bounds...),
+		Services: make(map[string]int64),
+	}
+	h.BucketLayouts = append(h.BucketLayouts, l)
+	return l
+}
+
+// mergeBucketLayouts adds the layouts of other into h.
+func (h *HistogramMetric) mergeBucketLayouts(other *HistogramMetric) {
+	for _, l := range other.BucketLayouts {
+		layout := h.bucketLayout(l.Bounds)
+		layout.DataPoints += l.DataPoints
+		for service, count := range l.Services {
+			layout.Services[service] += count
+		}
+	}
+}
+
+// BucketLayoutSummary is a bucket layout with its cost and share.
+type BucketLayoutSummary struct {
+	Bounds      []float64 `json:"bounds"`
+	BucketCount int       `json:"bucket_count"`
+	// SeriesCost is the Prometheus series per OTLP series (buckets + _sum + _count)
+	SeriesCost  int              `json:"series_cost"`
+	DataPoints  int64            `json:"data_points"`
+	SampleShare float64          `json:"sample_share"` // 0-1 of the histogram's data points
+	Services    map[string]int64 `json:"services"`
+	Oversized   bool             `json:"oversized"`
+}
+
+// HistogramLayoutAnalysis describes the bucket layouts of one histogram.
+type HistogramLayoutAnalysis struct {
+	Name                   string                 `json:"name"`
+	Layouts                []*BucketLayoutSummary `json:"layouts"`
+	LayoutCount            int                    `json:"layout_count"`
+	ActiveSeriesOTLP       int64                  `json:"active_series_otlp"`
+	ActiveSeriesPrometheus int64                  `json:"active_series_prometheus"`
+	RecommendExponential   bool                   `json:"recommend_exponential"`
+	Recommendations        []string               `json:"recommendations,omitempty"`
+}
+
+// HistogramLayoutsResponse lists the explicit bucket histograms and their
+// layouts, most expensive first.
+type HistogramLayoutsResponse struct {
+	Histograms      []*HistogramLayoutAnalysis `json:"histograms"`
+	Total           int                        `json:"total"`
+	MaxBuckets      int                        `json:"max_buckets"`
+	ExpensiveSeries int64                      `json:"expensive_series"`
+}
+
+// AnalyzeHistogramLayouts inspects the bucket layouts of every explicit
+// bucket histogram in metrics. Layouts with more than maxBuckets buckets are
+// flagged, and exponential histograms are recommended for histograms whose
+// Prometheus series estimate reaches expensiveSeries.
+func AnalyzeHistogramLayouts(metrics []*MetricMetadata, maxBuckets int, expensiveSeries int64) *HistogramLayoutsResponse {
+	resp := &HistogramLayoutsResponse{
+		Histograms:      []*HistogramLayoutAnalysis{},
+		MaxBuckets:      maxBuckets,
+		ExpensiveSeries: expensiveSeries,
+	}
+
+	for _, m := range metrics {
+		if _, ok := m.Data.(*HistogramMetric); !ok {
+			continue
+		}
+		otlpSeries := m.GetActiveSeries()
+
+		// Layouts grow under the metric lock while batches are merged.
+		m.mu.RLock()
+		a := analyzeHistogramLayout(m.Name, m.Data.(*HistogramMetric), otlpSeries, maxBuckets, expensiveSeries)
+		m.mu.RUnlock()
+		resp.Histograms = append(resp.Histograms, a)
+	}
+
+	sort.Slice(resp.Histograms, func(i, j int) bool {
+		if resp.Histograms[i].ActiveSeriesPrometheus != resp.Histograms[j].ActiveSeriesPrometheus {
+			return resp.Histograms[i].ActiveSeriesPrometheus > resp.Histograms[j].ActiveSeriesPrometheus
+		}
+		return resp.Histograms[i].Name < resp.Histograms[j].Name
+	})
+	resp.Total = len(resp.Histograms)
+	return resp
+}
+
+func analyzeHistogramLayout(name string, hist *HistogramMetric, otlpSeries int64, maxBuckets int, expensiveSeries int64) *HistogramLayoutAnalysis {
+	a := &HistogramLayoutAnalysis{
+		Name:                   name,
+		Layouts:                []*BucketLayoutSummary{},
+		LayoutCount:            len(hist.BucketLayouts),
+		ActiveSeriesOTLP:       otlpSeries,
+		ActiveSeriesPrometheus: EstimatePrometheusActiveSeries(otlpSeries, hist),
+	}
+
+	var total int64
+	for _, l := range hist.BucketLayouts {
+		total += l.DataPoints
+	}
+	oversized := 0
+	for _, l := range hist.BucketLayouts {
+		services := make(map[string]int64, len(l.Services))
+		for service, count := range l.Services {
+			services[service] = count
+		}
+		s := &BucketLayoutSummary{
+			Bounds:      append([]float64{}, l.Bounds...),
+			BucketCount: l.BucketCount(),
+			SeriesCost:  l.SeriesCost(),
+			DataPoints:  l.DataPoints,
+			Services:    services,
+			Oversized:   l.BucketCount() > maxBuckets,
+		}
+		if total > 0 {
+			s.SampleShare = float64(l.DataPoints) / float64(total)
+		}
+		if s.Oversized {
+			oversized++
+		}
+		a.Layouts = append(a.Layouts, s)
+	}
+	sort.SliceStable(a.Layouts, func(i, j int) bool {
+		return a.Layouts[i].DataPoints > a.Layouts[j].DataPoints
+	})
+
+	if a.LayoutCount > 1 {
+		a.Recommendations = append(a.Recommendations, fmt.Sprintf(
+			"%d different bucket layouts are in use; align them with a view so every series shares one set of le labels",
+			a.LayoutCount))
+	}
+	if oversized > 0 {
+		a.Recommendations = append(a.Recommendations, fmt.Sprintf(
+			"%d layout(s) have more than %d buckets; drop bounds that are never hit or widen the bucket spacing",
+			oversized, maxBuckets))
+	}
+	if a.ActiveSeriesPrometheus >= expensiveSeries && a.ActiveSeriesPrometheus > a.ActiveSeriesOTLP {
+		a.RecommendExponential = true
+		a.Recommendations = append(a.Recommendations, fmt.Sprintf(
+			"buckets turn %d OTLP series into about %d Prometheus series; an exponential histogram stored as a native histogram keeps one series per label set",
+			a.ActiveSeriesOTLP, a.ActiveSeriesPrometheus))
+	}
+	return a
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newLayoutTestHistogram(name, service string, bounds []float64, dataPoints int64, series int) *MetricMetadata {
+	hist := &HistogramMetric{ExplicitBounds: bounds}
+	hist.AddBucketLayout(bounds, service, dataPoints)
+	m := NewMetricMetadata(name, hist)
+	m.SampleCount = dataPoints
+	m.Services[service] = dataPoints
+	for i := 0; i < series; i++ {
+		m.AddSeriesFingerprint(fmt.Sprintf("%s-%d", service, i))
+	}
+	return m
+}
+
+func TestMergeMetricMetadata_BucketLayouts(t *testing.T) {
+	otelDefault := []float64{0, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 7500, 10000}
+	m := newLayoutTestHistogram("http.server.duration", "checkout", otelDefault, 30, 1)
+	m.MergeMetricMetadata(newLayoutTestHistogram("http.server.duration", "search", []float64{0.1, 1, 10}, 10, 1))
+	m.MergeMetricMetadata(newLayoutTestHistogram("http.server.duration", "cart", otelDefault, 20, 1))
+
+	layouts := m.Data.(*HistogramMetric).BucketLayouts
+	if len(layouts) != 2 {
+		t.Fatalf("layouts = %d, want 2", len(layouts))
+	}
+	if layouts[0].DataPoints != 50 || layouts[0].Services["checkout"] != 30 || layouts[0].Services["cart"] != 20 {
+		t.Errorf("default layout = %+v", layouts[0])
+	}
+	if layouts[0].BucketCount() != 16 || layouts[0].SeriesCost() != 18 {
+		t.Errorf("bucket count = %d, series cost = %d, want 16 and 18", layouts[0].BucketCount(), layouts[0].SeriesCost())
+	}
+}
+
+func TestAnalyzeHistogramLayouts(t *testing.T) {
+	wide := make([]float64, 40)
+	for i := range wide {
+		wide[i] = float64(i)
+	}
+	layouts := newLayoutTestHistogram("rpc.duration", "api", []float64{1, 10, 100}, 75, 2)
+	layouts.MergeMetricMetadata(newLayoutTestHistogram("rpc.duration", "worker", wide, 25, 2))
+	expensive := newLayoutTestHistogram("db.duration", "api", []float64{1, 10, 100}, 10, 300)
+	gauge := NewMetricMetadata("queue.depth", &GaugeMetric{})
+
+	resp := AnalyzeHistogramLayouts([]*MetricMetadata{layouts, expensive, gauge}, DefaultMaxHistogramBuckets, DefaultExpensiveHistogramSeries)
+	if resp.Total != 2 {
+		t.Fatalf("histograms = %d, want 2 (gauges are skipped)", resp.Total)
+	}
+
+	byName := map[string]*HistogramLayoutAnalysis{}
+	for _, h := range resp.Histograms {
+		byName[h.Name] = h
+	}
+
+	rpc := byName["rpc.duration"]
+	if rpc.LayoutCount != 2 || len(rpc.Recommendations) != 2 || rpc.RecommendExponential {
+		t.Errorf("rpc.duration = %+v", rpc)
+	}
+	if l := rpc.Layouts[0]; l.BucketCount != 4 || l.SampleShare != 0.75 || l.Oversized {
+		t.Errorf("rpc.duration main layout = %+v", l)
+	}
+	if l := rpc.Layouts[1]; l.BucketCount != 41 || l.SeriesCost != 43 || !l.Oversized {
+		t.Errorf("rpc.duration wide layout = %+v", l)
+	}
+
+	db := byName["db.duration"]
+	if !db.RecommendExponential || db.LayoutCount != 1 {
+		t.Errorf("db.duration = %+v, want an exponential histogram recommendation", db)
+	}
+	if resp.Histograms[0].Name != "db.duration" {
+		t.Errorf("first histogram = %s, want the most expensive one", resp.Histograms[0].Name)
+	}
+}
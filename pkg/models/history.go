@@ -0,0 +1,352 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of samples a History keeps: six hours at
+// the default 30s sampling interval.
+const DefaultHistorySize = 720
+
+// MaxHistoryNames caps the names tracked per kind. Each name preallocates 8
+// bytes per sample, under 6 KB at the default size, so a History at the cap
+// of all three kinds holds about 170 MB. Names are dropped once none of
+// their values is left in the buffer.
+const MaxHistoryNames = 10000
+
+// HistoryKind is what a history series counts.
+type HistoryKind string
+
+const (
+	// HistoryMetric is a metric's active series.
+	HistoryMetric HistoryKind = "metric"
+	// HistoryAttribute is an attribute key's estimated cardinality.
+	HistoryAttribute HistoryKind = "attribute"
+	// HistoryService is the active series of the metrics a service sends.
+	HistoryService HistoryKind = "service"
+)
+
+// HistorySample is the cardinality of everything stored at one point in time.
+type HistorySample struct {
+	Metrics    map[string]int64
+	Attributes map[string]int64
+	Services   map[string]int64
+}
+
+// NewHistorySample counts the active series of metrics, summed per service,
+// and the cardinality of attrs.
+func NewHistorySample(metrics []*MetricMetadata, attrs []*AttributeMetadata) HistorySample {
+	s := HistorySample{
+		Metrics:    make(map[string]int64, len(metrics)),
+		Attributes: make(map[string]int64, len(attrs)),
+		Services:   make(map[string]int64),
+	}
+	for _, m := range metrics {
+		n := m.GetActiveSeries()
+		s.Metrics[m.Name] = n
+		m.mu.RLock()
+		for svc := range m.Services {
+			s.Services[svc] += n
+		}
+		m.mu.RUnlock()
+	}
+	for _, a := range attrs {
+		s.Attributes[a.Key] = a.Cardinality()
+	}
+	return s
+}
+
+// History is a bounded ring buffer of cardinality samples. Every tracked
+// name keeps one value per sample slot; -1 marks samples taken before the
+// name appeared or while it was missing. It is safe for concurrent use.
+type History struct {
+	mu        sync.RWMutex
+	size      int
+	times     []int64 // unix nanoseconds per slot
+	next      int     // slot written by the next sample
+	n         int     // slots filled
+	seq       int64   // samples recorded
+	series    map[HistoryKind]map[string]*historySeries
+	truncated bool
+}
+
+// historySeries holds one name's value per slot.
+type historySeries struct {
+	values []int64
+	seen   int64 // seq of the newest sample carrying the name
+}
+
+// NewHistory creates a History keeping the last size samples.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	h := &History{size: size}
+	h.reset()
+	return h
+}
+
+func (h *History) reset() {
+	h.times = make([]int64, h.size)
+	h.next, h.n, h.seq = 0, 0, 0
+	h.truncated = false
+	h.series = map[HistoryKind]map[string]*historySeries{
+		HistoryMetric:    {},
+		HistoryAttribute: {},
+		HistoryService:   {},
+	}
+}
+
+// Reset drops every sample.
+func (h *History) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reset()
+}
+
+// Record appends a sample taken at at, overwriting the oldest one when the
+// buffer is full.
+func (h *History) Record(at time.Time, s HistorySample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.record(at.UnixNano(), s)
+}
+
+func (h *History) record(at int64, s HistorySample) {
+	slot := h.next
+	h.times[slot] = at
+	h.recordKind(HistoryMetric, s.Metrics, slot)
+	h.recordKind(HistoryAttribute, s.Attributes, slot)
+	h.recordKind(HistoryService, s.Services, slot)
+	h.next = (h.next + 1) % h.size
+	h.n = min(h.n+1, h.size)
+	h.seq++
+}
+
+func (h *History) recordKind(kind HistoryKind, values map[string]int64, slot int) {
+	series := h.series[kind]
+	for name, hs := range series {
+		if _, ok := values[name]; !ok {
+			hs.values[slot] = -1
+			// Its last value was just overwritten: free the room for
+			// names that are still being sent.
+			if h.seq-hs.seen >= int64(h.size) {
+				delete(series, name)
+			}
+		}
+	}
+	for name, v := range values {
+		hs, ok := series[name]
+		if !ok {
+			if len(series) >= MaxHistoryNames {
+				h.truncated = true
+				continue
+			}
+			hs = &historySeries{values: make([]int64, h.size)}
+			for i := range hs.values {
+				hs.values[i] = -1
+			}
+			series[name] = hs
+		}
+		hs.values[slot] = v
+		hs.seen = h.seq
+	}
+}
+
+// slots returns the filled slots, oldest first.
+func (h *History) slots() []int {
+	out := make([]int, h.n)
+	for i := range out {
+		out[i] = (h.next - h.n + i + h.size) % h.size
+	}
+	return out
+}
+
+// Len returns the number of samples held.
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.n
+}
+
+// HistoryPoint is one sampled value.
+type HistoryPoint struct {
+	Time  time.Time `json:"time"`
+	Value int64     `json:"value"`
+}
+
+// HistoryResponse is how one metric, attribute or service's cardinality
+// evolved over the sampled range.
+type HistoryResponse struct {
+	Kind HistoryKind `json:"kind"`
+	Name string      `json:"name"`
+	// Step is the spacing the points were reduced to; empty for raw samples.
+	Step   string         `json:"step,omitempty"`
+	Points []HistoryPoint `json:"points"`
+	// Growth is the last value minus the first one; Max is the peak.
+	Growth int64 `json:"growth"`
+	Max    int64 `json:"max"`
+	// Truncated is set when names beyond MaxHistoryNames were not sampled.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Query returns the samples of name taken after since. With a positive
+// step, samples are grouped into steps aligned to the epoch and each step
+// reports its last sample. It returns false if name was never sampled.
+func (h *History) Query(kind HistoryKind, name string, since time.Time, step time.Duration) (*HistoryResponse, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hs, ok := h.series[kind][name]
+	if !ok {
+		return nil, false
+	}
+	values := hs.values
+
+	resp := &HistoryResponse{Kind: kind, Name: name, Points: []HistoryPoint{}, Truncated: h.truncated}
+	if step > 0 {
+		resp.Step = FormatWindow(step)
+	}
+	lastStep := int64(-1)
+	for _, slot := range h.slots() {
+		t, v := h.times[slot], values[slot]
+		if v < 0 || t < since.UnixNano() {
+			continue
+		}
+		p := HistoryPoint{Time: time.Unix(0, t).UTC(), Value: v}
+		if step > 0 {
+			s := t / int64(step)
+			if s == lastStep {
+				resp.Points[len(resp.Points)-1] = p
+				continue
+			}
+			lastStep = s
+		}
+		resp.Points = append(resp.Points, p)
+	}
+	for _, p := range resp.Points {
+		resp.Max = max(resp.Max, p.Value)
+	}
+	if len(resp.Points) > 0 {
+		resp.Growth = resp.Points[len(resp.Points)-1].Value - resp.Points[0].Value
+	}
+	return resp, true
+}
+
+// SerializedHistory is the session form of a History: sample times oldest
+// first and, per name, one value per sample with -1 where it is missing.
+type SerializedHistory struct {
+	Times      []time.Time        `json:"times"`
+	Metrics    map[string][]int64 `json:"metrics,omitempty"`
+	Attributes map[string][]int64 `json:"attributes,omitempty"`
+	Services   map[string][]int64 `json:"services,omitempty"`
+}
+
+func (sh *SerializedHistory) kind(kind HistoryKind) map[string][]int64 {
+	switch kind {
+	case HistoryMetric:
+		return sh.Metrics
+	case HistoryAttribute:
+		return sh.Attributes
+	}
+	return sh.Services
+}
+
+// Serialize returns the samples of the names keep accepts, or nil if there
+// are none. A nil keep accepts every name.
+func (h *History) Serialize(keep func(kind HistoryKind, name string) bool) *SerializedHistory {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.serialize(keep)
+}
+
+func (h *History) serialize(keep func(kind HistoryKind, name string) bool) *SerializedHistory {
+	if h.n == 0 {
+		return nil
+	}
+	slots := h.slots()
+	sh := &SerializedHistory{
+		Times:      make([]time.Time, len(slots)),
+		Metrics:    map[string][]int64{},
+		Attributes: map[string][]int64{},
+		Services:   map[string][]int64{},
+	}
+	for i, slot := range slots {
+		sh.Times[i] = time.Unix(0, h.times[slot]).UTC()
+	}
+	for kind, series := range h.series {
+		out := sh.kind(kind)
+		for name, hs := range series {
+			if keep != nil && !keep(kind, name) {
+				continue
+			}
+			vs := make([]int64, len(slots))
+			for i, slot := range slots {
+				vs[i] = hs.values[slot]
+			}
+			out[name] = vs
+		}
+	}
+	return sh
+}
+
+// Merge interleaves the samples of sh with those held by time, keeping the
+// newest ones that fit. Series whose length does not match sh.Times are
+// skipped.
+func (h *History) Merge(sh *SerializedHistory) {
+	if sh == nil || len(sh.Times) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	type timedSample struct {
+		at int64
+		HistorySample
+	}
+	var samples []timedSample
+	add := func(from *SerializedHistory) {
+		start := len(samples)
+		for _, t := range from.Times {
+			samples = append(samples, timedSample{at: t.UnixNano(), HistorySample: HistorySample{
+				Metrics:    map[string]int64{},
+				Attributes: map[string]int64{},
+				Services:   map[string]int64{},
+			}})
+		}
+		for _, kind := range []HistoryKind{HistoryMetric, HistoryAttribute, HistoryService} {
+			for name, values := range from.kind(kind) {
+				if len(values) != len(from.Times) {
+					continue
+				}
+				for i, v := range values {
+					if v >= 0 {
+						samples[start+i].kind(kind)[name] = v
+					}
+				}
+			}
+		}
+	}
+	if current := h.serialize(nil); current != nil {
+		add(current)
+	}
+	add(sh)
+
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].at < samples[j].at })
+	samples = samples[max(0, len(samples)-h.size):]
+	h.reset()
+	for _, s := range samples {
+		h.record(s.at, s.HistorySample)
+	}
+}
+
+func (s HistorySample) kind(kind HistoryKind) map[string]int64 {
+	switch kind {
+	case HistoryMetric:
+		return s.Metrics
+	case HistoryAttribute:
+		return s.Attributes
+	}
+	return s.Services
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRing(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	h := NewHistory(4)
+	for i := 0; i < 6; i++ {
+		s := HistorySample{Metrics: map[string]int64{"up": int64(i * 10)}}
+		if i >= 3 {
+			s.Metrics["late"] = int64(i)
+		}
+		h.Record(start.Add(time.Duration(i)*time.Minute), s)
+	}
+
+	if h.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", h.Len())
+	}
+	resp, ok := h.Query(HistoryMetric, "up", time.Time{}, 0)
+	if !ok {
+		t.Fatal("no history for up")
+	}
+	want := []int64{20, 30, 40, 50}
+	if len(resp.Points) != len(want) {
+		t.Fatalf("points = %+v, want %v", resp.Points, want)
+	}
+	for i, p := range resp.Points {
+		if p.Value != want[i] {
+			t.Errorf("point %d = %d, want %d", i, p.Value, want[i])
+		}
+	}
+	if resp.Growth != 30 || resp.Max != 50 {
+		t.Errorf("growth %d max %d, want 30 and 50", resp.Growth, resp.Max)
+	}
+
+	// Samples before a name appeared are left out.
+	if resp, _ := h.Query(HistoryMetric, "late", time.Time{}, 0); len(resp.Points) != 3 {
+		t.Errorf("late points = %+v, want 3", resp.Points)
+	}
+	if _, ok := h.Query(HistoryAttribute, "up", time.Time{}, 0); ok {
+		t.Error("attribute history found for a metric name")
+	}
+
+	// A name that stopped being sent is dropped with its last value.
+	for i := 6; i < 10; i++ {
+		h.Record(start.Add(time.Duration(i)*time.Minute), HistorySample{Metrics: map[string]int64{"up": 1}})
+		resp, ok := h.Query(HistoryMetric, "late", time.Time{}, 0)
+		if i < 9 && (!ok || len(resp.Points) != 9-i) {
+			t.Errorf("after sample %d late = %+v, want %d points", i, resp, 9-i)
+		}
+		if i == 9 && ok {
+			t.Errorf("late still tracked with no value left: %+v", resp)
+		}
+	}
+}
+
+func TestHistoryQueryRangeAndStep(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	h := NewHistory(0)
+	for i := 0; i < 10; i++ {
+		h.Record(start.Add(time.Duration(i)*30*time.Second), HistorySample{Attributes: map[string]int64{"user_id": int64(i)}})
+	}
+
+	resp, _ := h.Query(HistoryAttribute, "user_id", start.Add(2*time.Minute), 0)
+	if len(resp.Points) != 6 || resp.Points[0].Value != 4 {
+		t.Errorf("range points = %+v, want 6 from value 4", resp.Points)
+	}
+
+	// Two samples per minute step; each step reports its last one.
+	resp, _ = h.Query(HistoryAttribute, "user_id", time.Time{}, time.Minute)
+	if len(resp.Points) != 5 || resp.Points[0].Value != 1 || resp.Points[4].Value != 9 || resp.Step != "1m" {
+		t.Errorf("stepped = %+v, want 5 points 1, 3, … 9", resp)
+	}
+}
+
+func TestHistorySerializeMerge(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	saved := NewHistory(0)
+	for i := 0; i < 3; i++ {
+		saved.Record(start.Add(time.Duration(i)*time.Minute), HistorySample{
+			Metrics:  map[string]int64{"up": int64(i + 1)},
+			Services: map[string]int64{"checkout": int64(i + 1)},
+		})
+	}
+	sh := saved.Serialize(func(kind HistoryKind, name string) bool { return kind == HistoryMetric })
+	if len(sh.Times) != 3 || len(sh.Metrics["up"]) != 3 || len(sh.Services) != 0 {
+		t.Fatalf("serialized = %+v, want 3 samples of up only", sh)
+	}
+
+	// Restored samples are interleaved with live ones by time.
+	live := NewHistory(0)
+	live.Record(start.Add(90*time.Second), HistorySample{Metrics: map[string]int64{"up": 100}})
+	live.Merge(sh)
+
+	resp, _ := live.Query(HistoryMetric, "up", time.Time{}, 0)
+	want := []int64{1, 2, 100, 3}
+	if len(resp.Points) != len(want) {
+		t.Fatalf("points = %+v, want %v", resp.Points, want)
+	}
+	for i, p := range resp.Points {
+		if p.Value != want[i] {
+			t.Errorf("point %d = %d, want %d", i, p.Value, want[i])
+		}
+	}
+
+	if NewHistory(0).Serialize(nil) != nil {
+		t.Error("empty history serialized")
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+// Naming lint targets: what a rule checks.
+const (
+	LintTargetMetricName   = "metric_name"
+	LintTargetMetricLabel  = "metric_label"
+	LintTargetAttributeKey = "attribute_key"
+	LintTargetSpanName     = "span_name"
+	LintTargetLogEventName = "log_event_name"
+)
+
+// LintViolation is one name or key breaking a naming rule.
+type LintViolation struct {
+	Rule     string `json:"rule"`
+	Target   string `json:"target"`
+	Name     string `json:"name"`
+	Severity string `json:"severity"` // "warning" or "critical"
+	Message  string `json:"message"`
+
+	// FoundIn lists the metrics, spans or log severities carrying a
+	// violating label or attribute key.
+	FoundIn  []string `json:"found_in,omitempty"`
+	Services []string `json:"services"`
+}
+
+// LintResponse lists the naming rule violations.
+type LintResponse struct {
+	Rules      int             `json:"rules"`
+	Violations []LintViolation `json:"violations"`
+	Total      int             `json:"total"`
+	ByRule     map[string]int  `json:"by_rule"`
+	// ByService counts violations per sending service.
+	ByService map[string]int `json:"by_service"`
+}
+
+// NewLintResponse wraps violations with their totals.
+func NewLintResponse(rules int, violations []LintViolation) *LintResponse {
+	resp := &LintResponse{
+		Rules:      rules,
+		Violations: violations,
+		Total:      len(violations),
+		ByRule:     make(map[string]int),
+		ByService:  make(map[string]int),
+	}
+	if resp.Violations == nil {
+		resp.Violations = []LintViolation{}
+	}
+	for _, v := range violations {
+		resp.ByRule[v.Rule]++
+		for _, s := range v.Services {
+			resp.ByService[s]++
+		}
+	}
+	return resp
+}
+
+// Filter returns the violations sent by service and matching rule; empty
+// values match everything.
+func (r *LintResponse) Filter(service, rule string) *LintResponse {
+	var kept []LintViolation
+	for _, v := range r.Violations {
+		if rule != "" && v.Rule != rule {
+			continue
+		}
+		if service != "" && !containsString(v.Services, service) {
+			continue
+		}
+		kept = append(kept, v)
+	}
+	return NewLintResponse(r.Rules, kept)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
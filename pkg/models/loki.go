@@ -0,0 +1,150 @@
+package models
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+)
+
+// LokiStream is one stream of a Loki push request: its label set, the
+// service it resolves to and the number of entries pushed.
+type LokiStream struct {
+	Labels  map[string]string
+	Service string
+	Entries int64
+}
+
+// LokiStreamMetadata tracks stream labels received through the Loki push
+// API. Loki indexes every unique label set as a separate stream, so the
+// stream count and the value cardinality of each label drive its cost,
+// independently of how many lines are pushed.
+type LokiStreamMetadata struct {
+	mu sync.Mutex
+
+	labelKeys    map[string]*KeyMetadata
+	labelStreams map[string]*hyperloglog.HyperLogLog // label key -> streams carrying it
+	services     map[string]*lokiServiceStats
+	streams      *hyperloglog.HyperLogLog
+	entries      int64
+}
+
+type lokiServiceStats struct {
+	streams   *hyperloglog.HyperLogLog
+	entries   int64
+	labelKeys map[string]struct{}
+}
+
+// NewLokiStreamMetadata creates an empty tracker.
+func NewLokiStreamMetadata() *LokiStreamMetadata {
+	return &LokiStreamMetadata{
+		labelKeys:    make(map[string]*KeyMetadata),
+		labelStreams: make(map[string]*hyperloglog.HyperLogLog),
+		services:     make(map[string]*lokiServiceStats),
+		streams:      hyperloglog.New(10),
+	}
+}
+
+// Add records one pushed stream.
+func (m *LokiStreamMetadata) Add(stream LokiStream) {
+	fingerprint := CreateSeriesFingerprintFast(stream.Labels)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.streams.Add(fingerprint)
+	m.entries += stream.Entries
+
+	svc, ok := m.services[stream.Service]
+	if !ok {
+		svc = &lokiServiceStats{streams: hyperloglog.New(10), labelKeys: make(map[string]struct{})}
+		m.services[stream.Service] = svc
+	}
+	svc.streams.Add(fingerprint)
+	svc.entries += stream.Entries
+
+	for key, value := range stream.Labels {
+		km, ok := m.labelKeys[key]
+		if !ok {
+			km = NewKeyMetadata()
+			m.labelKeys[key] = km
+			m.labelStreams[key] = hyperloglog.New(10)
+		}
+		km.AddValue(value)
+		m.labelStreams[key].Add(fingerprint)
+		svc.labelKeys[key] = struct{}{}
+	}
+}
+
+// LokiStreamsResponse is the Loki stream label view.
+type LokiStreamsResponse struct {
+	TotalStreams int64                `json:"total_streams"`
+	TotalEntries int64                `json:"total_entries"`
+	Labels       []LokiLabelStats     `json:"labels"`
+	Services     []LokiServiceStreams `json:"services"`
+}
+
+// LokiLabelStats describes one stream label key.
+type LokiLabelStats struct {
+	Key          string   `json:"key"`
+	Cardinality  int64    `json:"cardinality"`
+	Streams      int64    `json:"streams"`
+	SampleValues []string `json:"sample_values"`
+}
+
+// LokiServiceStreams describes the streams pushed by one service.
+type LokiServiceStreams struct {
+	Service   string   `json:"service"`
+	Streams   int64    `json:"streams"`
+	Entries   int64    `json:"entries"`
+	LabelKeys []string `json:"label_keys"`
+}
+
+// Summary returns the current state, labels ordered by value cardinality
+// and services by stream count.
+func (m *LokiStreamMetadata) Summary() *LokiStreamsResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resp := &LokiStreamsResponse{
+		TotalStreams: int64(m.streams.Count()),
+		TotalEntries: m.entries,
+		Labels:       make([]LokiLabelStats, 0, len(m.labelKeys)),
+		Services:     make([]LokiServiceStreams, 0, len(m.services)),
+	}
+	for key, km := range m.labelKeys {
+		resp.Labels = append(resp.Labels, LokiLabelStats{
+			Key:          key,
+			Cardinality:  km.Cardinality(),
+			Streams:      int64(m.labelStreams[key].Count()),
+			SampleValues: km.GetSortedSamples(),
+		})
+	}
+	sort.Slice(resp.Labels, func(i, j int) bool {
+		if resp.Labels[i].Cardinality != resp.Labels[j].Cardinality {
+			return resp.Labels[i].Cardinality > resp.Labels[j].Cardinality
+		}
+		return resp.Labels[i].Key < resp.Labels[j].Key
+	})
+
+	for name, svc := range m.services {
+		keys := make([]string, 0, len(svc.labelKeys))
+		for key := range svc.labelKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		resp.Services = append(resp.Services, LokiServiceStreams{
+			Service:   name,
+			Streams:   int64(svc.streams.Count()),
+			Entries:   svc.entries,
+			LabelKeys: keys,
+		})
+	}
+	sort.Slice(resp.Services, func(i, j int) bool {
+		if resp.Services[i].Streams != resp.Services[j].Streams {
+			return resp.Services[i].Streams > resp.Services[j].Streams
+		}
+		return resp.Services[i].Service < resp.Services[j].Service
+	})
+	return resp
+}
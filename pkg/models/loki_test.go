@@ -0,0 +1,54 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLokiStreamMetadata_Summary(t *testing.T) {
+	m := NewLokiStreamMetadata()
+
+	// The same label set pushed twice is one stream.
+	for i := 0; i < 2; i++ {
+		m.Add(LokiStream{Labels: map[string]string{"app": "api", "env": "prod"}, Service: "api", Entries: 5})
+	}
+	for i := 0; i < 20; i++ {
+		m.Add(LokiStream{
+			Labels:  map[string]string{"app": "worker", "env": "prod", "pod": fmt.Sprintf("worker-%d", i)},
+			Service: "worker",
+			Entries: 1,
+		})
+	}
+
+	s := m.Summary()
+	if s.TotalStreams != 21 {
+		t.Errorf("TotalStreams = %d, want 21", s.TotalStreams)
+	}
+	if s.TotalEntries != 30 {
+		t.Errorf("TotalEntries = %d, want 30", s.TotalEntries)
+	}
+
+	if len(s.Labels) != 3 {
+		t.Fatalf("got %d labels, want 3", len(s.Labels))
+	}
+	pod := s.Labels[0]
+	// Value cardinality beyond the sample list is an HLL estimate.
+	if pod.Key != "pod" || pod.Cardinality <= 10 || pod.Streams != 20 {
+		t.Errorf("first label = %+v, want pod with cardinality > 10 in 20 streams", pod)
+	}
+	for _, l := range s.Labels[1:] {
+		if l.Key == "env" && (l.Cardinality != 1 || l.Streams != 21) {
+			t.Errorf("env = %+v, want cardinality 1 in 21 streams", l)
+		}
+	}
+
+	if len(s.Services) != 2 || s.Services[0].Service != "worker" {
+		t.Fatalf("services = %+v, want worker first", s.Services)
+	}
+	if got := s.Services[0].LabelKeys; len(got) != 3 || got[0] != "app" || got[2] != "pod" {
+		t.Errorf("worker label keys = %v, want [app env pod]", got)
+	}
+	if api := s.Services[1]; api.Streams != 1 || api.Entries != 10 {
+		t.Errorf("api = %+v, want 1 stream and 10 entries", api)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
 )
@@ -63,6 +64,21 @@ type MetricMetadata struct {
 	// Updated from seriesHLL count
 	ActiveSeries int64 `json:"active_series"`
 
+	// seriesWindow counts series by when they were last seen, for
+	// ActiveSeriesIn; nil until the first data point and while windows
+	// are disabled
+	seriesWindow *hyperloglog.Window
+
+	// Exemplars summarizes exemplars on the data points; nil until one is seen
+	Exemplars *ExemplarStats `json:"exemplars,omitempty"`
+
+	// contribution estimates the series left with each label dropped
+	contribution *SeriesContribution
+
+	// savedIdentities are the identity variants restored from a session,
+	// recorded by the store's identity tracker in place of this metric's own
+	savedIdentities []*MetricVariant
+
 	mu sync.RWMutex `json:"-"`
 }
 
@@ -273,6 +289,12 @@ type KeyMetadata struct {
 	// Uses fixed ~16KB memory regardless of cardinality
 	hll *hyperloglog.HyperLogLog `json:"-"`
 
+	// window counts values by when they were seen once hll is allocated;
+	// until then seen holds the last-seen time (Unix ns) of each sample.
+	// Both stay nil while windows are disabled.
+	window *hyperloglog.Window
+	seen   []int64
+
 	// MaxSamples is the maximum number of value samples to keep
 	MaxSamples int `json:"-"`
 
@@ -384,10 +406,15 @@ func (k *KeyMetadata) AddValue(value string) {
 	defer k.mu.Unlock()
 
 	k.Count++
+	now := clock()
 
 	// Fast path: HLL already initialized — add and return.
 	if k.hll != nil {
 		k.hll.Add(value)
+		if k.window == nil { // HLL restored from a session
+			k.window = newWindow()
+		}
+		k.window.Add(value, now)
 		if strings.ContainsRune(value, '\uFFFD') {
 			k.HasInvalidUTF8 = true
 		}
@@ -396,9 +423,10 @@ func (k *KeyMetadata) AddValue(value string) {
 
 	// Slow path: HLL not yet needed — check uniqueness via sample list (O(MaxSamples)).
 	isNew := true
-	for _, sample := range k.ValueSamples {
+	for i, sample := range k.ValueSamples {
 		if sample == value {
 			isNew = false
+			k.touchSample(i, now.UnixNano())
 			break
 		}
 	}
@@ -406,6 +434,7 @@ func (k *KeyMetadata) AddValue(value string) {
 	if isNew {
 		if len(k.ValueSamples) < k.MaxSamples {
 			k.ValueSamples = append(k.ValueSamples, value)
+			k.touchSample(len(k.ValueSamples)-1, now.UnixNano())
 		} else {
 			// Samples full and new value observed — initialize HLL and backfill.
 			k.hll = hyperloglog.New(10)
@@ -413,6 +442,8 @@ func (k *KeyMetadata) AddValue(value string) {
 				k.hll.Add(s)
 			}
 			k.hll.Add(value)
+			k.startWindow()
+			k.window.Add(value, now)
 		}
 	}
 
@@ -423,6 +454,33 @@ func (k *KeyMetadata) AddValue(value string) {
 	}
 }
 
+// touchSample records sample i as seen at now (Unix ns), while windows are
+// enabled. Must be called with k.mu held.
+func (k *KeyMetadata) touchSample(i int, now int64) {
+	if !WindowsEnabled() {
+		return
+	}
+	for len(k.seen) <= i {
+		k.seen = append(k.seen, 0)
+	}
+	if now > k.seen[i] {
+		k.seen[i] = now
+	}
+}
+
+// startWindow allocates the window when the HLL takes over from the sample
+// list, backfilling the samples at their last-seen times.
+// Must be called with k.mu held.
+func (k *KeyMetadata) startWindow() {
+	k.window = newWindow()
+	for i, s := range k.ValueSamples {
+		if i < len(k.seen) && k.seen[i] > 0 {
+			k.window.Add(s, time.Unix(0, k.seen[i]))
+		}
+	}
+	k.seen = nil
+}
+
 // GetSortedSamples returns the value samples in sorted order.
 // This is only called when serializing to JSON, not on every insert.
 func (k *KeyMetadata) GetSortedSamples() []string {
@@ -501,6 +559,10 @@ func (k *KeyMetadata) releaseHLL() {
 		k.hll.Release()
 		k.hll = nil
 	}
+	if k.window != nil {
+		k.window.Release()
+		k.window = nil
+	}
 }
 
 // MergeKeyMetadata merges other into existing, unioning HLL sketches, value
@@ -518,12 +580,23 @@ func MergeKeyMetadata(existing, other *KeyMetadata) {
 			for _, s := range existing.ValueSamples {
 				existing.hll.Add(s)
 			}
+			existing.startWindow()
+		}
+		if existing.window == nil {
+			existing.window = newWindow()
 		}
 		existing.hll.Merge(other.hll) //nolint:errcheck
+		existing.window.Merge(other.window)
 		existing.EstimatedCardinality = int64(existing.hll.Count())
 	} else if existing.hll != nil {
-		for _, s := range other.ValueSamples {
+		if existing.window == nil {
+			existing.window = newWindow()
+		}
+		for i, s := range other.ValueSamples {
 			existing.hll.Add(s)
+			if i < len(other.seen) && other.seen[i] > 0 {
+				existing.window.Add(s, time.Unix(0, other.seen[i]))
+			}
 		}
 		existing.EstimatedCardinality = int64(existing.hll.Count())
 	}
@@ -533,20 +606,29 @@ func MergeKeyMetadata(existing, other *KeyMetadata) {
 		existing.HasInvalidUTF8 = true
 	}
 
-	// Merge value samples (keep first N unique).
-	for _, sample := range other.ValueSamples {
-		if len(existing.ValueSamples) >= existing.MaxSamples {
-			break
+	// Merge value samples (keep first N unique) and, while the samples
+	// still do the counting, their last-seen times.
+	tracking := existing.hll == nil
+	for i, sample := range other.ValueSamples {
+		var seen int64
+		if i < len(other.seen) {
+			seen = other.seen[i]
 		}
 		found := false
-		for _, existingSample := range existing.ValueSamples {
+		for j, existingSample := range existing.ValueSamples {
 			if existingSample == sample {
 				found = true
+				if tracking {
+					existing.touchSample(j, seen)
+				}
 				break
 			}
 		}
-		if !found {
+		if !found && len(existing.ValueSamples) < existing.MaxSamples {
 			existing.ValueSamples = append(existing.ValueSamples, sample)
+			if tracking {
+				existing.touchSample(len(existing.ValueSamples)-1, seen)
+			}
 		}
 	}
 	existing.mu.Unlock()
@@ -597,6 +679,41 @@ func (m *MetricMetadata) MergeMetricMetadata(other *MetricMetadata) {
 		other.seriesHLL.Release()
 		other.seriesHLL = nil
 	}
+	if other.seriesWindow != nil {
+		if m.seriesWindow == nil {
+			m.seriesWindow = other.seriesWindow
+		} else {
+			m.seriesWindow.Merge(other.seriesWindow)
+			other.seriesWindow.Release()
+		}
+		other.seriesWindow = nil
+	}
+
+	// Merge per-label contribution sketches
+	if other.contribution != nil {
+		if m.contribution == nil {
+			m.contribution = other.contribution
+		} else {
+			m.contribution.Merge(other.contribution)
+		}
+		other.contribution = nil
+	}
+
+	// Merge histogram bucket layouts
+	if hist, ok := m.Data.(*HistogramMetric); ok {
+		if otherHist, ok := other.Data.(*HistogramMetric); ok {
+			hist.mergeBucketLayouts(otherHist)
+		}
+	}
+
+	// Merge exemplar statistics
+	if other.Exemplars != nil {
+		if m.Exemplars == nil {
+			m.Exemplars = NewExemplarStats()
+		}
+		m.Exemplars.Merge(other.Exemplars)
+	}
+	m.Exemplars.Update(m.SampleCount)
 
 	// Update percentages
 	for _, keyMeta := range m.LabelKeys {
@@ -694,6 +811,11 @@ func (m *MetricMetadata) AddSeriesFingerprint(fingerprint string) {
 	
 	m.seriesHLL.Add(fingerprint)
 	m.ActiveSeries = int64(m.seriesHLL.Count())
+
+	if m.seriesWindow == nil {
+		m.seriesWindow = newWindow()
+	}
+	m.seriesWindow.Add(fingerprint, clock())
 }
 
 // GetActiveSeries returns the current count of active series (unique label combinations).
@@ -768,11 +890,13 @@ func (m *MetricMetadata) SetSeriesHLL(hll *hyperloglog.HyperLogLog) {
 // ServiceOverview contains a summary of all telemetry for a service.
 // This is used by the API to provide aggregated views across all signal types.
 type ServiceOverview struct {
-	ServiceName string            `json:"service_name"`
-	MetricCount int               `json:"metric_count"`
-	SpanCount   int               `json:"span_count"`
-	LogCount    int               `json:"log_count"`
-	Metrics     []*MetricMetadata `json:"metrics"`
-	Spans       []*SpanMetadata   `json:"spans"`
-	Logs        []*LogMetadata    `json:"logs"`
+	ServiceName  string             `json:"service_name"`
+	MetricCount  int                `json:"metric_count"`
+	SpanCount    int                `json:"span_count"`
+	LogCount     int                `json:"log_count"`
+	ProfileCount int                `json:"profile_count"`
+	Metrics      []*MetricMetadata  `json:"metrics"`
+	Spans        []*SpanMetadata    `json:"spans"`
+	Logs         []*LogMetadata     `json:"logs"`
+	Profiles     []*ProfileMetadata `json:"profiles"`
 }
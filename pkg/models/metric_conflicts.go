@@ -0,0 +1,206 @@
+package models
+
+import (
+	"sort"
+)
+
+// MetricIdentity is the part of a metric's definition that every producer
+// of a metric name must agree on. The store keys metrics by name only, so
+// two services disagreeing on any of these end up merged into one entry,
+// and Prometheus translation and dashboards break for at least one of them.
+type MetricIdentity struct {
+	Type                   string `json:"type"`
+	Unit                   string `json:"unit"`
+	AggregationTemporality string `json:"aggregation_temporality,omitempty"`
+	IsMonotonic            bool   `json:"is_monotonic"`
+	Description            string `json:"description"`
+}
+
+// MetricIdentityOf extracts the identity of a metric.
+func MetricIdentityOf(m *MetricMetadata) MetricIdentity {
+	id := MetricIdentity{
+		Type:        m.GetType(),
+		Unit:        m.Unit,
+		Description: m.Description,
+	}
+	switch d := m.Data.(type) {
+	case *SumMetric:
+		id.AggregationTemporality = d.AggregationTemporality.String()
+		id.IsMonotonic = d.IsMonotonic
+	case *HistogramMetric:
+		id.AggregationTemporality = d.AggregationTemporality.String()
+	case *ExponentialHistogramMetric:
+		id.AggregationTemporality = d.AggregationTemporality.String()
+	}
+	return id
+}
+
+// conflictingFields lists the JSON names of the fields that differ.
+func (id MetricIdentity) conflictingFields(other MetricIdentity) []string {
+	var fields []string
+	if id.Type != other.Type {
+		fields = append(fields, "type")
+	}
+	if id.Unit != other.Unit {
+		fields = append(fields, "unit")
+	}
+	if id.AggregationTemporality != other.AggregationTemporality {
+		fields = append(fields, "aggregation_temporality")
+	}
+	if id.IsMonotonic != other.IsMonotonic {
+		fields = append(fields, "is_monotonic")
+	}
+	if id.Description != other.Description {
+		fields = append(fields, "description")
+	}
+	return fields
+}
+
+// MetricVariant is one identity observed for a metric name together with
+// the services sending it.
+type MetricVariant struct {
+	MetricIdentity
+
+	// SampleCount is the number of data points received with this identity
+	SampleCount int64 `json:"sample_count"`
+
+	// Services maps service names to their data point count for this identity
+	Services map[string]int64 `json:"services"`
+}
+
+// MetricConflict describes a metric name received with more than one
+// identity.
+type MetricConflict struct {
+	Name string `json:"name"`
+
+	// ConflictingFields names the identity fields that differ between variants
+	ConflictingFields []string `json:"conflicting_fields"`
+
+	// Variants are ordered by sample count, most used first
+	Variants []*MetricVariant `json:"variants"`
+}
+
+// MetricConflictsResponse lists all metric identity conflicts.
+type MetricConflictsResponse struct {
+	Conflicts []*MetricConflict `json:"conflicts"`
+	Total     int               `json:"total"`
+}
+
+// MetricIdentityTracker records the identities seen per metric name. It is
+// not safe for concurrent use; the store guards it with its metrics lock.
+type MetricIdentityTracker struct {
+	variants map[string][]*MetricVariant
+}
+
+// NewMetricIdentityTracker creates an empty tracker.
+func NewMetricIdentityTracker() *MetricIdentityTracker {
+	return &MetricIdentityTracker{variants: make(map[string][]*MetricVariant)}
+}
+
+// RestoreIdentities attaches the identity variants saved with a session, so
+// that storing the metric records them rather than its merged identity.
+func (m *MetricMetadata) RestoreIdentities(variants []*MetricVariant) {
+	m.savedIdentities = variants
+}
+
+// Observe records the identity and services of an incoming metric. A metric
+// restored from a session records its saved variants instead, and they are
+// detached from it so that they are only counted once.
+func (t *MetricIdentityTracker) Observe(m *MetricMetadata) {
+	if m.savedIdentities != nil {
+		for _, v := range m.savedIdentities {
+			t.observe(m.Name, v.MetricIdentity, v.SampleCount, v.Services)
+		}
+		m.savedIdentities = nil
+		return
+	}
+	t.observe(m.Name, MetricIdentityOf(m), m.SampleCount, m.Services)
+}
+
+func (t *MetricIdentityTracker) observe(name string, id MetricIdentity, samples int64, services map[string]int64) {
+	variants := t.variants[name]
+
+	var variant *MetricVariant
+	for _, v := range variants {
+		if v.MetricIdentity == id {
+			variant = v
+			break
+		}
+	}
+	if variant == nil {
+		variant = &MetricVariant{MetricIdentity: id, Services: make(map[string]int64)}
+		t.variants[name] = append(variants, variant)
+	}
+
+	variant.SampleCount += samples
+	for service, count := range services {
+		variant.Services[service] += count
+	}
+}
+
+// Conflicting returns a copy of the variants of every metric name with
+// more than one identity, for saving with a session.
+func (t *MetricIdentityTracker) Conflicting() map[string][]*MetricVariant {
+	out := make(map[string][]*MetricVariant)
+	for name, variants := range t.variants {
+		if len(variants) < 2 {
+			continue
+		}
+		copied := make([]*MetricVariant, 0, len(variants))
+		for _, v := range variants {
+			copied = append(copied, v.clone())
+		}
+		out[name] = copied
+	}
+	return out
+}
+
+func (v *MetricVariant) clone() *MetricVariant {
+	services := make(map[string]int64, len(v.Services))
+	for service, count := range v.Services {
+		services[service] = count
+	}
+	return &MetricVariant{
+		MetricIdentity: v.MetricIdentity,
+		SampleCount:    v.SampleCount,
+		Services:       services,
+	}
+}
+
+// Conflicts returns a copy of every metric name with more than one
+// identity, sorted by name.
+func (t *MetricIdentityTracker) Conflicts() *MetricConflictsResponse {
+	resp := &MetricConflictsResponse{Conflicts: []*MetricConflict{}}
+	for name, variants := range t.variants {
+		if len(variants) < 2 {
+			continue
+		}
+
+		conflict := &MetricConflict{Name: name}
+		seen := make(map[string]bool)
+		for i, v := range variants {
+			for _, other := range variants[i+1:] {
+				for _, field := range v.conflictingFields(other.MetricIdentity) {
+					seen[field] = true
+				}
+			}
+			conflict.Variants = append(conflict.Variants, v.clone())
+		}
+		// Keep the identity field order stable rather than alphabetical.
+		for _, field := range []string{"type", "unit", "aggregation_temporality", "is_monotonic", "description"} {
+			if seen[field] {
+				conflict.ConflictingFields = append(conflict.ConflictingFields, field)
+			}
+		}
+		sort.SliceStable(conflict.Variants, func(i, j int) bool {
+			return conflict.Variants[i].SampleCount > conflict.Variants[j].SampleCount
+		})
+		resp.Conflicts = append(resp.Conflicts, conflict)
+	}
+
+	sort.Slice(resp.Conflicts, func(i, j int) bool {
+		return resp.Conflicts[i].Name < resp.Conflicts[j].Name
+	})
+	resp.Total = len(resp.Conflicts)
+	return resp
+}
@@ -84,6 +84,10 @@ type HistogramMetric struct {
 	// ExplicitBounds contains the set of bucket boundaries observed across data points.
 	// This is a union of all explicit_bounds arrays seen.
 	ExplicitBounds []float64 `json:"explicit_bounds,omitempty"`
+
+	// BucketLayouts keeps each distinct explicit_bounds array separately,
+	// with the services and data points using it
+	BucketLayouts []*BucketLayout `json:"bucket_layouts,omitempty"`
 }
 
 func (h *HistogramMetric) GetType() string {
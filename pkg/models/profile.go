@@ -0,0 +1,119 @@
+package models
+
+// ProfileMetadata represents metadata extracted from OTLP profiles.
+// Profiles are grouped by sample type: every Profile carries one or more
+// ValueTypes (e.g. cpu/nanoseconds, alloc_space/bytes) and each combination
+// is tracked separately, the same way spans are grouped by name.
+type ProfileMetadata struct {
+	// ID identifies the sample type as "type:unit" (e.g. "cpu:nanoseconds")
+	ID string `json:"id"`
+
+	// SampleType is the sample value type
+	// Corresponds to Profile.sample_type[].type_strindex
+	SampleType string `json:"sample_type"`
+
+	// SampleUnit is the sample value unit
+	// Corresponds to Profile.sample_type[].unit_strindex
+	SampleUnit string `json:"sample_unit,omitempty"`
+
+	// PeriodType is the sampling period type as "type:unit", if set
+	// Corresponds to Profile.period_type
+	PeriodType string `json:"period_type,omitempty"`
+
+	// AttributeKeys maps profile and sample attribute keys to their metadata
+	// Corresponds to Profile.attribute_indices and Sample.attribute_indices
+	AttributeKeys map[string]*KeyMetadata `json:"attribute_keys"`
+
+	// LocationAttributeKeys tracks attribute keys found on sample locations
+	// Corresponds to Location.attribute_indices
+	LocationAttributeKeys map[string]*KeyMetadata `json:"location_attribute_keys"`
+
+	// MappingAttributeKeys tracks attribute keys found on location mappings
+	// Corresponds to Mapping.attribute_indices
+	MappingAttributeKeys map[string]*KeyMetadata `json:"mapping_attribute_keys"`
+
+	// MappingFiles tracks the binaries and libraries samples were taken from
+	// Corresponds to Mapping.filename_strindex
+	MappingFiles *KeyMetadata `json:"mapping_files,omitempty"`
+
+	// ResourceKeys maps resource attribute key names to their metadata
+	ResourceKeys map[string]*KeyMetadata `json:"resource_keys"`
+
+	// ScopeInfo contains instrumentation scope information
+	ScopeInfo *ScopeMetadata `json:"scope_info,omitempty"`
+
+	// ProfileCount is the number of profiles observed with this sample type
+	ProfileCount int64 `json:"profile_count"`
+
+	// SampleCount is the total number of samples observed
+	SampleCount int64 `json:"sample_count"`
+
+	// MaxStackDepth is the deepest stack observed in a single sample
+	MaxStackDepth int `json:"max_stack_depth"`
+
+	// Services maps service names to sample counts
+	Services map[string]int64 `json:"services"`
+}
+
+// NewProfileMetadata creates a new ProfileMetadata instance.
+func NewProfileMetadata(sampleType, sampleUnit string) *ProfileMetadata {
+	return &ProfileMetadata{
+		ID:                    ProfileID(sampleType, sampleUnit),
+		SampleType:            sampleType,
+		SampleUnit:            sampleUnit,
+		AttributeKeys:         make(map[string]*KeyMetadata),
+		LocationAttributeKeys: make(map[string]*KeyMetadata),
+		MappingAttributeKeys:  make(map[string]*KeyMetadata),
+		ResourceKeys:          make(map[string]*KeyMetadata),
+		Services:              make(map[string]int64),
+	}
+}
+
+// ProfileID returns the identifier used for a sample type and unit.
+func ProfileID(sampleType, sampleUnit string) string {
+	if sampleUnit == "" {
+		return sampleType
+	}
+	return sampleType + ":" + sampleUnit
+}
+
+// MergeProfileMetadata merges other into p. Key metadata from other is moved
+// into p, so other must not be used afterwards.
+func (p *ProfileMetadata) MergeProfileMetadata(other *ProfileMetadata) {
+	p.ProfileCount += other.ProfileCount
+	p.SampleCount += other.SampleCount
+	if other.MaxStackDepth > p.MaxStackDepth {
+		p.MaxStackDepth = other.MaxStackDepth
+	}
+	if p.PeriodType == "" {
+		p.PeriodType = other.PeriodType
+	}
+
+	mergeKeyMaps(p.AttributeKeys, other.AttributeKeys)
+	mergeKeyMaps(p.LocationAttributeKeys, other.LocationAttributeKeys)
+	mergeKeyMaps(p.MappingAttributeKeys, other.MappingAttributeKeys)
+	mergeKeyMaps(p.ResourceKeys, other.ResourceKeys)
+
+	if other.MappingFiles != nil {
+		if p.MappingFiles == nil {
+			p.MappingFiles = other.MappingFiles
+		} else {
+			MergeKeyMetadata(p.MappingFiles, other.MappingFiles)
+		}
+	}
+
+	for service, count := range other.Services {
+		p.Services[service] += count
+	}
+}
+
+// mergeKeyMaps merges the key metadata in src into dst.
+func mergeKeyMaps(dst, src map[string]*KeyMetadata) {
+	for key, keyMeta := range src {
+		if existing, exists := dst[key]; exists {
+			MergeKeyMetadata(existing, keyMeta)
+		} else {
+			dst[key] = keyMeta
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+// Semantic convention finding rules.
+const (
+	SemconvRuleDeprecated           = "deprecated"
+	SemconvRuleUnknown              = "unknown"
+	SemconvRuleUnitMismatch         = "unit_mismatch"
+	SemconvRuleInstrumentMismatch   = "instrument_mismatch"
+	SemconvRuleMissingRequired      = "missing_required"
+	SemconvRuleMissingConditionally = "missing_conditionally_required"
+	SemconvRuleMissingRecommended   = "missing_recommended"
+)
+
+// Semantic convention finding severities.
+const (
+	SemconvSeverityWarning = "warning"
+	SemconvSeverityInfo    = "info"
+)
+
+// SemconvFinding is one deviation from the OpenTelemetry semantic
+// conventions.
+type SemconvFinding struct {
+	// Signal is "attribute", "metric" or "span"
+	Signal string `json:"signal"`
+
+	// Name is the attribute key, metric name or span name
+	Name string `json:"name"`
+
+	// Key is the attribute key a span finding is about
+	Key string `json:"key,omitempty"`
+
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+
+	// Replacement is the key or metric name to use instead, if any
+	Replacement string `json:"replacement,omitempty"`
+
+	// Services sending the metric or span
+	Services []string `json:"services,omitempty"`
+
+	// SignalTypes using the attribute key
+	SignalTypes []string `json:"signal_types,omitempty"`
+}
+
+// SemconvFindingsResponse lists semantic convention findings.
+type SemconvFindingsResponse struct {
+	RegistryVersion string           `json:"registry_version"`
+	Findings        []SemconvFinding `json:"findings"`
+	Total           int              `json:"total"`
+	// ByRule counts findings per rule
+	ByRule map[string]int `json:"by_rule"`
+}
+
+// NewSemconvFindingsResponse wraps findings with their totals.
+func NewSemconvFindingsResponse(registryVersion string, findings []SemconvFinding) *SemconvFindingsResponse {
+	resp := &SemconvFindingsResponse{
+		RegistryVersion: registryVersion,
+		Findings:        findings,
+		Total:           len(findings),
+		ByRule:          make(map[string]int),
+	}
+	if resp.Findings == nil {
+		resp.Findings = []SemconvFinding{}
+	}
+	for _, f := range findings {
+		resp.ByRule[f.Rule]++
+	}
+	return resp
+}
+
+// Filter returns the findings for signal, or all signals if empty. A
+// severity of "warning" drops informational findings.
+func (r *SemconvFindingsResponse) Filter(signal, severity string) *SemconvFindingsResponse {
+	var kept []SemconvFinding
+	for _, f := range r.Findings {
+		if signal != "" && f.Signal != signal {
+			continue
+		}
+		if severity == SemconvSeverityWarning && f.Severity != SemconvSeverityWarning {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return NewSemconvFindingsResponse(r.RegistryVersion, kept)
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+)
+
+// MaxContributionLabels caps the labels whose contribution is tracked per
+// metric. Each one costs a 1 KB sketch.
+const MaxContributionLabels = 32
+
+// contributionNoise is one standard error of a precision 10 sketch,
+// 1.04/√1024 ≈ 0.0325. Smaller reductions are indistinguishable from
+// estimation noise and are reported as none.
+const contributionNoise = 0.032
+
+// SeriesContribution estimates, for every label of a metric, how many series
+// would remain if that label were dropped.
+//
+// A series is hashed as the sum of the hashes of its key=value pairs, so the
+// series with one label projected away is the sum minus that pair's hash.
+// This keeps the cost per data point linear in the number of labels.
+type SeriesContribution struct {
+	all *hyperloglog.HyperLogLog
+	// without is keyed "D:<key>" for data point attributes and
+	// "R:<key>" for resource attributes, as in series fingerprints.
+	without   map[string]*hyperloglog.HyperLogLog
+	truncated bool
+}
+
+// NewSeriesContribution creates an empty tracker.
+func NewSeriesContribution() *SeriesContribution {
+	return &SeriesContribution{
+		all:     hyperloglog.New(10),
+		without: make(map[string]*hyperloglog.HyperLogLog),
+	}
+}
+
+// Add records the series of one data point.
+func (c *SeriesContribution) Add(resourceAttrs, attrs map[string]string) {
+	pairs := make(map[string]uint64, len(resourceAttrs)+len(attrs))
+	var sum uint64
+	for k, v := range resourceAttrs {
+		h := pairHash("R:", k, v)
+		pairs["R:"+k] = h
+		sum += h
+	}
+	for k, v := range attrs {
+		h := pairHash("D:", k, v)
+		pairs["D:"+k] = h
+		sum += h
+	}
+
+	// A label seen for the first time was absent from every earlier series,
+	// so dropping it leaves those series unchanged.
+	for id := range pairs {
+		if _, ok := c.without[id]; ok {
+			continue
+		}
+		if len(c.without) >= MaxContributionLabels {
+			c.truncated = true
+			continue
+		}
+		c.without[id] = cloneHLL(c.all)
+	}
+
+	// Sums of pair hashes are mixed again so the HLL sees well-spread bits.
+	c.all.AddHash(hyperloglog.Mix64(sum))
+	for id, sketch := range c.without {
+		sketch.AddHash(hyperloglog.Mix64(sum - pairs[id]))
+	}
+}
+
+// Merge adds the series of other into c and releases other's sketches.
+func (c *SeriesContribution) Merge(other *SeriesContribution) {
+	for id, sketch := range c.without {
+		if o, ok := other.without[id]; ok {
+			sketch.Merge(o)
+		} else {
+			// other never saw the label: its series are unchanged without it
+			sketch.Merge(other.all)
+		}
+	}
+	for id, o := range other.without {
+		if _, ok := c.without[id]; ok {
+			continue
+		}
+		if len(c.without) >= MaxContributionLabels {
+			c.truncated = true
+			continue
+		}
+		sketch := cloneHLL(c.all)
+		sketch.Merge(o)
+		c.without[id] = sketch
+	}
+	c.all.Merge(other.all)
+	c.truncated = c.truncated || other.truncated
+	other.release()
+}
+
+func (c *SeriesContribution) release() {
+	c.all.Release()
+	for _, sketch := range c.without {
+		sketch.Release()
+	}
+	c.without = nil
+}
+
+// LabelContribution is the estimated effect of dropping one label.
+type LabelContribution struct {
+	Label string `json:"label"`
+	// Scope is "attribute" for data point attributes, "resource" for
+	// resource attributes.
+	Scope            string  `json:"scope"`
+	SeriesWithout    int64   `json:"series_without"`
+	Reduction        int64   `json:"reduction"`
+	ReductionPercent float64 `json:"reduction_percent"`
+	Summary          string  `json:"summary"`
+}
+
+// MetricContributionResponse ranks the labels of a metric by how many
+// series dropping them would remove.
+type MetricContributionResponse struct {
+	Name         string               `json:"name"`
+	ActiveSeries int64                `json:"active_series"`
+	Labels       []*LabelContribution `json:"labels"`
+	// Truncated is set when the metric had more than MaxContributionLabels
+	// labels; labels first seen after the cap are missing.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Ranking estimates the series left after dropping each label, largest
+// reduction first.
+func (c *SeriesContribution) Ranking() ([]*LabelContribution, int64) {
+	total := int64(c.all.Count())
+	labels := make([]*LabelContribution, 0, len(c.without))
+	for id, sketch := range c.without {
+		without := int64(sketch.Count())
+		if without > total || float64(total-without) < contributionNoise*float64(total) {
+			without = total
+		}
+		lc := &LabelContribution{
+			Label:         id[2:],
+			Scope:         "attribute",
+			SeriesWithout: without,
+			Reduction:     total - without,
+		}
+		if strings.HasPrefix(id, "R:") {
+			lc.Scope = "resource"
+		}
+		if total > 0 {
+			lc.ReductionPercent = float64(lc.Reduction) / float64(total) * 100
+		}
+		lc.Summary = fmt.Sprintf("dropping %s reduces series by %.0f%% (%d → %d)",
+			lc.Label, lc.ReductionPercent, total, without)
+		labels = append(labels, lc)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Reduction != labels[j].Reduction {
+			return labels[i].Reduction > labels[j].Reduction
+		}
+		if labels[i].Label != labels[j].Label {
+			return labels[i].Label < labels[j].Label
+		}
+		return labels[i].Scope < labels[j].Scope
+	})
+	return labels, total
+}
+
+// AddSeries records the series of one data point: its fingerprint for the
+// cumulative and windowed active series counts and its labels for the
+// contribution ranking.
+func (m *MetricMetadata) AddSeries(resourceAttrs, attrs map[string]string) {
+	fingerprint := CreateSeriesFingerprintWithResource(resourceAttrs, attrs)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.seriesHLL == nil {
+		m.seriesHLL = hyperloglog.New(10)
+	}
+	m.seriesHLL.Add(fingerprint)
+	m.ActiveSeries = int64(m.seriesHLL.Count())
+
+	if m.seriesWindow == nil {
+		m.seriesWindow = newWindow()
+	}
+	m.seriesWindow.Add(fingerprint, clock())
+
+	if m.contribution == nil {
+		m.contribution = NewSeriesContribution()
+	}
+	m.contribution.Add(resourceAttrs, attrs)
+}
+
+// SeriesContribution ranks the metric's labels by the series dropping them
+// would remove.
+func (m *MetricMetadata) SeriesContribution() *MetricContributionResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resp := &MetricContributionResponse{
+		Name:   m.Name,
+		Labels: []*LabelContribution{},
+	}
+	if m.contribution == nil {
+		resp.ActiveSeries = m.ActiveSeries
+		return resp
+	}
+	resp.Labels, resp.ActiveSeries = m.contribution.Ranking()
+	resp.Truncated = m.contribution.truncated
+	return resp
+}
+
+// SerializedContribution is a JSON-serializable SeriesContribution.
+type SerializedContribution struct {
+	All       *SerializedHLL            `json:"all"`
+	Without   map[string]*SerializedHLL `json:"without"`
+	Truncated bool                      `json:"truncated,omitempty"`
+}
+
+// MarshalContribution serializes the contribution sketches for session
+// storage; nil if the metric has none.
+func (m *MetricMetadata) MarshalContribution() (*SerializedContribution, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c := m.contribution
+	if c == nil {
+		return nil, nil
+	}
+	all, err := MarshalHLL(c.all)
+	if err != nil {
+		return nil, err
+	}
+	sc := &SerializedContribution{
+		All:       all,
+		Without:   make(map[string]*SerializedHLL, len(c.without)),
+		Truncated: c.truncated,
+	}
+	for id, sketch := range c.without {
+		if sc.Without[id], err = MarshalHLL(sketch); err != nil {
+			return nil, err
+		}
+	}
+	return sc, nil
+}
+
+// UnmarshalContribution restores sketches written by MarshalContribution.
+func (m *MetricMetadata) UnmarshalContribution(sc *SerializedContribution) error {
+	if sc == nil || sc.All == nil {
+		return nil
+	}
+	all, err := UnmarshalHLL(sc.All)
+	if err != nil {
+		return err
+	}
+	c := &SeriesContribution{
+		all:       all,
+		without:   make(map[string]*hyperloglog.HyperLogLog, len(sc.Without)),
+		truncated: sc.Truncated,
+	}
+	for id, s := range sc.Without {
+		if c.without[id], err = UnmarshalHLL(s); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contribution = c
+	return nil
+}
+
+func pairHash(prefix, key, value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(prefix))
+	h.Write([]byte(key))
+	h.Write([]byte{'='})
+	h.Write([]byte(value))
+	return hyperloglog.Mix64(h.Sum64())
+}
+
+func cloneHLL(h *hyperloglog.HyperLogLog) *hyperloglog.HyperLogLog {
+	c := hyperloglog.New(10)
+	c.Merge(h)
+	return c
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+// within reports whether got is within 5% of want.
+func within(got, want int64) bool {
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) <= float64(want)*0.05+1
+}
+
+func contributionOf(t *testing.T, resp *MetricContributionResponse, label string) *LabelContribution {
+	t.Helper()
+	for _, lc := range resp.Labels {
+		if lc.Label == label {
+			return lc
+		}
+	}
+	t.Fatalf("label %q not ranked: %+v", label, resp.Labels)
+	return nil
+}
+
+func TestSeriesContribution_Ranking(t *testing.T) {
+	m := NewMetricMetadata("http_requests_total", &SumMetric{IsMonotonic: true})
+	for pod := 0; pod < 100; pod++ {
+		for method := 0; method < 5; method++ {
+			m.AddSeries(
+				map[string]string{"k8s.pod.name": fmt.Sprintf("pod-%d", pod)},
+				map[string]string{"method": fmt.Sprintf("m%d", method), "env": "prod"},
+			)
+		}
+	}
+
+	resp := m.SeriesContribution()
+	if !within(resp.ActiveSeries, 500) {
+		t.Fatalf("active series = %d, want ~500", resp.ActiveSeries)
+	}
+	if len(resp.Labels) != 3 || resp.Labels[0].Label != "k8s.pod.name" {
+		t.Fatalf("ranking = %+v, want k8s.pod.name first", resp.Labels)
+	}
+
+	pod := contributionOf(t, resp, "k8s.pod.name")
+	if pod.Scope != "resource" || !within(pod.SeriesWithout, 5) || pod.ReductionPercent < 95 {
+		t.Errorf("k8s.pod.name = %+v, want ~5 series left", pod)
+	}
+	method := contributionOf(t, resp, "method")
+	if method.Scope != "attribute" || !within(method.SeriesWithout, 100) {
+		t.Errorf("method = %+v, want ~100 series left", method)
+	}
+	if env := contributionOf(t, resp, "env"); env.Reduction != 0 {
+		t.Errorf("env = %+v, want no reduction for a constant label", env)
+	}
+}
+
+func TestSeriesContribution_LabelAddedLater(t *testing.T) {
+	m := NewMetricMetadata("queue_depth", &GaugeMetric{})
+	for i := 0; i < 200; i++ {
+		m.AddSeries(nil, map[string]string{"queue": fmt.Sprintf("q%d", i)})
+	}
+	// The same queues again, now with a region label.
+	for i := 0; i < 200; i++ {
+		m.AddSeries(nil, map[string]string{"queue": fmt.Sprintf("q%d", i), "region": "eu"})
+	}
+
+	resp := m.SeriesContribution()
+	if !within(resp.ActiveSeries, 400) {
+		t.Fatalf("active series = %d, want ~400", resp.ActiveSeries)
+	}
+	if region := contributionOf(t, resp, "region"); !within(region.SeriesWithout, 200) {
+		t.Errorf("region = %+v, want ~200 series left", region)
+	}
+}
+
+func TestSeriesContribution_Merge(t *testing.T) {
+	single := NewMetricMetadata("jobs", &GaugeMetric{})
+	stored := NewMetricMetadata("jobs", &GaugeMetric{})
+	batch := NewMetricMetadata("jobs", &GaugeMetric{})
+	for i := 0; i < 300; i++ {
+		attrs := map[string]string{"job": fmt.Sprintf("j%d", i%50)}
+		target := stored
+		if i >= 150 {
+			attrs["worker"] = fmt.Sprintf("w%d", i)
+			target = batch
+		}
+		single.AddSeries(nil, attrs)
+		target.AddSeries(nil, attrs)
+	}
+	stored.MergeMetricMetadata(batch)
+
+	want := single.SeriesContribution()
+	got := stored.SeriesContribution()
+	if got.ActiveSeries != want.ActiveSeries || len(got.Labels) != len(want.Labels) {
+		t.Fatalf("merged = %+v, want %+v", got, want)
+	}
+	for i := range want.Labels {
+		if *got.Labels[i] != *want.Labels[i] {
+			t.Errorf("label %d = %+v, want %+v", i, got.Labels[i], want.Labels[i])
+		}
+	}
+	if batch.contribution != nil {
+		t.Error("merged batch still holds its sketches")
+	}
+}
+
+func TestSeriesContribution_Truncated(t *testing.T) {
+	m := NewMetricMetadata("wide", &GaugeMetric{})
+	attrs := make(map[string]string)
+	for i := 0; i < MaxContributionLabels+8; i++ {
+		attrs[fmt.Sprintf("l%02d", i)] = "v"
+	}
+	m.AddSeries(nil, attrs)
+
+	resp := m.SeriesContribution()
+	if !resp.Truncated || len(resp.Labels) != MaxContributionLabels {
+		t.Errorf("truncated = %v with %d labels, want true with %d", resp.Truncated, len(resp.Labels), MaxContributionLabels)
+	}
+}
+
+func TestSeriesContribution_NoSeries(t *testing.T) {
+	resp := NewMetricMetadata("idle", nil).SeriesContribution()
+	if resp.Labels == nil || len(resp.Labels) != 0 || resp.Truncated {
+		t.Errorf("empty metric = %+v", resp)
+	}
+}
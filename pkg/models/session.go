@@ -64,6 +64,7 @@ type SessionStats struct {
 	MetricsCount    int      `json:"metrics_count"`
 	SpansCount      int      `json:"spans_count"`
 	LogsCount       int      `json:"logs_count"`
+	ProfilesCount   int      `json:"profiles_count"`
 	AttributesCount int      `json:"attributes_count"`
 	Services        []string `json:"services"`
 }
@@ -97,8 +98,10 @@ type SessionData struct {
 	Metrics           []*SerializedMetric            `json:"metrics,omitempty"`
 	Spans             []*SerializedSpan               `json:"spans,omitempty"`
 	Logs              []*SerializedLog                `json:"logs,omitempty"`
+	Profiles          []*SerializedProfile            `json:"profiles,omitempty"`
 	Attributes        []*SerializedAttribute          `json:"attributes,omitempty"`
 	WatchedAttributes []*SerializedWatchedAttribute   `json:"watched_attributes,omitempty"`
+	History           *SerializedHistory              `json:"history,omitempty"`
 }
 
 // SerializedWatchedAttribute is a JSON-serializable version of WatchedAttribute.
@@ -131,8 +134,27 @@ type SerializedMetric struct {
 	IsMonotonic    bool                         `json:"is_monotonic,omitempty"`
 	// ExplicitBounds stores histogram bucket boundaries (HistogramMetric only)
 	ExplicitBounds []float64                    `json:"explicit_bounds,omitempty"`
+	// BucketLayouts stores each distinct bound set (HistogramMetric only)
+	BucketLayouts  []*BucketLayout              `json:"bucket_layouts,omitempty"`
 	// Scales stores observed histogram scales (ExponentialHistogramMetric only)
 	Scales         []int32                      `json:"scales,omitempty"`
+	// Exemplars stores exemplar statistics, if any exemplars were observed
+	Exemplars      *SerializedExemplars         `json:"exemplars,omitempty"`
+	// Contribution stores the per-label series contribution sketches
+	Contribution   *SerializedContribution      `json:"contribution,omitempty"`
+	// SeriesWindow stores the sliding-window buckets of the series (base64)
+	SeriesWindow   string                       `json:"series_window,omitempty"`
+	// Identities stores the identity variants of a conflicting metric
+	Identities     []*MetricVariant             `json:"identities,omitempty"`
+}
+
+// SerializedExemplars is a JSON-serializable version of ExemplarStats.
+type SerializedExemplars struct {
+	DataPointsWithExemplars int64                     `json:"data_points_with_exemplars"`
+	ExemplarCount           int64                     `json:"exemplar_count"`
+	WithTraceID             int64                     `json:"with_trace_id"`
+	WithSpanID              int64                     `json:"with_span_id"`
+	FilteredAttributeKeys   map[string]*SerializedKey `json:"filtered_attribute_keys,omitempty"`
 }
 
 // SerializedSpan is a JSON-serializable version of SpanMetadata.
@@ -163,6 +185,23 @@ type SerializedLog struct {
 	Services       map[string]int64          `json:"services"`
 }
 
+// SerializedProfile is a JSON-serializable version of ProfileMetadata.
+type SerializedProfile struct {
+	ID                    string                    `json:"id"`
+	SampleType            string                    `json:"sample_type"`
+	SampleUnit            string                    `json:"sample_unit,omitempty"`
+	PeriodType            string                    `json:"period_type,omitempty"`
+	AttributeKeys         map[string]*SerializedKey `json:"attribute_keys"`
+	LocationAttributeKeys map[string]*SerializedKey `json:"location_attribute_keys,omitempty"`
+	MappingAttributeKeys  map[string]*SerializedKey `json:"mapping_attribute_keys,omitempty"`
+	MappingFiles          *SerializedKey            `json:"mapping_files,omitempty"`
+	ResourceKeys          map[string]*SerializedKey `json:"resource_keys"`
+	ProfileCount          int64                     `json:"profile_count"`
+	SampleCount           int64                     `json:"sample_count"`
+	MaxStackDepth         int                       `json:"max_stack_depth"`
+	Services              map[string]int64          `json:"services"`
+}
+
 // SerializedAttribute is a JSON-serializable version of AttributeMetadata.
 type SerializedAttribute struct {
 	Key                  string         `json:"key"`
@@ -174,6 +213,7 @@ type SerializedAttribute struct {
 	FirstSeen            time.Time      `json:"first_seen"`
 	LastSeen             time.Time      `json:"last_seen"`
 	HLL                  *SerializedHLL `json:"hll,omitempty"`
+	Window               string         `json:"window,omitempty"` // base64-encoded sliding window
 }
 
 // SerializedKey is a JSON-serializable version of KeyMetadata with HLL state.
@@ -183,6 +223,8 @@ type SerializedKey struct {
 	EstimatedCardinality int64          `json:"estimated_cardinality"`
 	ValueSamples         []string       `json:"value_samples,omitempty"`
 	HLL                  *SerializedHLL `json:"hll,omitempty"`
+	SampleSeen           []int64        `json:"sample_seen,omitempty"` // last-seen times (Unix ns) of ValueSamples
+	Window               string         `json:"window,omitempty"`      // base64-encoded sliding window
 }
 
 // SerializedHLL contains HyperLogLog state for JSON serialization.
@@ -243,6 +285,7 @@ func SerializeKeyMetadata(k *KeyMetadata) (*SerializedKey, error) {
 		EstimatedCardinality: k.Cardinality(),
 		ValueSamples:         k.GetSortedSamples(),
 	}
+	sk.SampleSeen = k.sampleSeen(sk.ValueSamples)
 
 	// Serialize HLL if present
 	hllBytes, err := k.MarshalHLL()
@@ -255,6 +298,12 @@ func SerializeKeyMetadata(k *KeyMetadata) (*SerializedKey, error) {
 			Registers: base64.StdEncoding.EncodeToString(hllBytes[1:]),
 		}
 	}
+	k.mu.RLock()
+	sk.Window, err = encodeWindow(k.window)
+	k.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
 
 	return sk, nil
 }
@@ -285,6 +334,14 @@ func DeserializeKeyMetadata(sk *SerializedKey) (*KeyMetadata, error) {
 			}
 		}
 	}
+	if WindowsEnabled() && len(sk.SampleSeen) == len(k.ValueSamples) {
+		k.seen = sk.SampleSeen
+	}
+	window, err := decodeWindow(sk.Window)
+	if err != nil {
+		return nil, err
+	}
+	k.window = window
 
 	return k, nil
 }
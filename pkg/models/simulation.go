@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// Simulation rule actions.
+const (
+	SimulationDropLabels = "drop_labels"
+	SimulationKeepLabels = "keep_labels"
+	SimulationRelabel    = "relabel"
+	SimulationDrop       = "drop"
+)
+
+// SimulationRule is one candidate transformation. Label rules apply to
+// resource and record attributes alike.
+type SimulationRule struct {
+	Action string `json:"action"`
+	// Signals limits the rule to "metrics", "traces" and/or "logs"; empty
+	// means all three.
+	Signals []string `json:"signals,omitempty"`
+	// Match limits the rule to metric names, span names or log severity
+	// texts fully matching this regex.
+	Match string `json:"match,omitempty"`
+	// WhereLabel and WhereValue limit the rule to records whose WhereLabel
+	// matches the WhereValue regex (any value if empty).
+	WhereLabel string `json:"where_label,omitempty"`
+	WhereValue string `json:"where_value,omitempty"`
+
+	// Labels for drop_labels and keep_labels.
+	Labels []string `json:"labels,omitempty"`
+
+	// SourceLabel, Regex, TargetLabel and Replacement for relabel, as in
+	// Prometheus: when the source value fully matches Regex, TargetLabel
+	// (SourceLabel if empty) is set to Replacement with $1-style groups
+	// expanded, or removed if the result is empty.
+	SourceLabel string `json:"source_label,omitempty"`
+	Regex       string `json:"regex,omitempty"`
+	TargetLabel string `json:"target_label,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// SimulationSpec is the body of a simulation create request.
+type SimulationSpec struct {
+	Name  string           `json:"name"`
+	Rules []SimulationRule `json:"rules"`
+}
+
+// SimulationCounts are the totals on one side of a simulation.
+type SimulationCounts struct {
+	ActiveSeries  int64 `json:"active_series"`
+	MetricSamples int64 `json:"metric_samples"`
+	Spans         int64 `json:"spans"`
+	LogRecords    int64 `json:"log_records"`
+}
+
+// SimulationComparison holds the counts without and with the rules applied.
+type SimulationComparison struct {
+	Before SimulationCounts `json:"before"`
+	After  SimulationCounts `json:"after"`
+	// SeriesReductionPercent is the share of active series the rules remove.
+	SeriesReductionPercent float64 `json:"series_reduction_percent"`
+}
+
+// SimulationService compares one service's data.
+type SimulationService struct {
+	Service string `json:"service"`
+	SimulationComparison
+}
+
+// SimulationAttribute compares the estimated unique values of one
+// attribute key; After is 0 when the rules remove the key.
+type SimulationAttribute struct {
+	Key    string `json:"key"`
+	Before int64  `json:"before"`
+	After  int64  `json:"after"`
+}
+
+// SimulationSummary describes a registered simulation.
+type SimulationSummary struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Rules     []SimulationRule `json:"rules"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// SimulationResult compares the data seen since a simulation was created
+// with the same data after its rules.
+type SimulationResult struct {
+	SimulationSummary
+	Totals     SimulationComparison   `json:"totals"`
+	Services   []*SimulationService   `json:"services"`
+	Attributes []*SimulationAttribute `json:"attributes"`
+	// AttributesTruncated is set when more attribute keys were seen than
+	// are tracked.
+	AttributesTruncated bool `json:"attributes_truncated,omitempty"`
+}
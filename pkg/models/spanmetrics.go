@@ -0,0 +1,46 @@
+package models
+
+// SpanMetricsEstimate projects the series the collector's spanmetrics
+// connector would generate from the spans received so far. Every unique
+// combination of the dimensions becomes one calls counter and one duration
+// histogram.
+type SpanMetricsEstimate struct {
+	// Dimensions in series identity order: service.name, the remaining
+	// default dimensions, then the configured attributes.
+	Dimensions []string `json:"dimensions"`
+	Histogram  string   `json:"histogram"` // explicit or exponential
+	// Buckets of the explicit histogram, including +Inf.
+	Buckets int `json:"buckets,omitempty"`
+	// Series per combination: calls plus duration as OTLP streams, and as
+	// Prometheus series where the explicit histogram turns into one series
+	// per bucket plus _sum and _count.
+	SeriesPerCombinationOTLP       int `json:"series_per_combination_otlp"`
+	SeriesPerCombinationPrometheus int `json:"series_per_combination_prometheus"`
+	// MaxExemplarsPerDataPoint is 0 when exemplars are disabled or
+	// unlimited; see ExemplarsUnbounded.
+	Exemplars                bool `json:"exemplars"`
+	MaxExemplarsPerDataPoint int  `json:"max_exemplars_per_data_point,omitempty"`
+	ExemplarsUnbounded       bool `json:"exemplars_unbounded,omitempty"`
+
+	Services []*SpanMetricsService `json:"services"`
+	Total    SpanMetricsProjection `json:"total"`
+}
+
+// SpanMetricsProjection is the projected output for a set of spans.
+type SpanMetricsProjection struct {
+	Spans            int64 `json:"spans"`
+	Combinations     int64 `json:"combinations"`
+	SeriesOTLP       int64 `json:"series_otlp"`
+	SeriesPrometheus int64 `json:"series_prometheus"`
+	// MaxExemplars bounds the exemplars held between flushes.
+	MaxExemplars int64 `json:"max_exemplars,omitempty"`
+	// DimensionValues is the estimated number of unique values of each
+	// dimension, absent counting as one value.
+	DimensionValues map[string]int64 `json:"dimension_values"`
+}
+
+// SpanMetricsService is the projection for the spans of one service.
+type SpanMetricsService struct {
+	Service string `json:"service"`
+	SpanMetricsProjection
+}
@@ -0,0 +1,356 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+)
+
+// windowConfig is the bucket layout of every windowed sketch, or nil while
+// windows are disabled. Sketches with different layouts cannot be merged,
+// so it is set once at startup.
+var windowConfig atomic.Pointer[hyperloglog.WindowConfig]
+
+// clock is replaced in tests.
+var clock = time.Now
+
+// SetWindowConfig sets the bucket layout of the sliding windows next to the
+// cumulative sketches. Windows are opt-in: a zero Horizon, the default,
+// disables them. Each windowed metric, attribute and high-cardinality label
+// then costs up to about 21 KB with the default layout, 84 sketches of 256
+// bytes, against 1 KB for a label's cumulative sketch. Call it before any
+// data is ingested.
+func SetWindowConfig(cfg hyperloglog.WindowConfig) {
+	if cfg.Horizon <= 0 {
+		windowConfig.Store(nil)
+		return
+	}
+	windowConfig.Store(&cfg)
+}
+
+// WindowsEnabled reports whether sliding windows are counted.
+func WindowsEnabled() bool {
+	return windowConfig.Load() != nil
+}
+
+// newWindow returns a window for the configured layout, or nil while
+// windows are disabled; a nil window records nothing.
+func newWindow() *hyperloglog.Window {
+	cfg := windowConfig.Load()
+	if cfg == nil {
+		return nil
+	}
+	return hyperloglog.NewWindow(*cfg)
+}
+
+// WindowHorizon is the longest window the sketches can count, 0 while
+// windows are disabled.
+func WindowHorizon() time.Duration {
+	cfg := windowConfig.Load()
+	if cfg == nil {
+		return 0
+	}
+	return cfg.Span()
+}
+
+// StandardWindows are the windows reported when none is asked for: 15m, 1h
+// and 24h, as far as the horizon allows. It is empty while windows are
+// disabled.
+func StandardWindows() []time.Duration {
+	horizon := WindowHorizon()
+	if horizon == 0 {
+		return nil
+	}
+	var out []time.Duration
+	for _, d := range []time.Duration{15 * time.Minute, time.Hour, 24 * time.Hour} {
+		if d <= horizon {
+			out = append(out, d)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, horizon)
+	}
+	return out
+}
+
+// ParseWindow parses a window such as "15m" or "24h" and checks it against
+// the horizon.
+func ParseWindow(s string) (time.Duration, error) {
+	if !WindowsEnabled() {
+		return 0, errors.New("sliding windows are disabled; set --window-horizon to enable them")
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q: must be a positive duration such as 15m or 24h", s)
+	}
+	if horizon := WindowHorizon(); d > horizon {
+		return 0, fmt.Errorf("window %s exceeds the %s horizon", s, FormatWindow(horizon))
+	}
+	return d, nil
+}
+
+// FormatWindow renders a window the way ParseWindow accepts it, without
+// trailing zero units ("1h" rather than "1h0m0s").
+func FormatWindow(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return d.String()
+}
+
+// ActiveSeriesIn estimates the series that received data within d. It never
+// exceeds the cumulative count, which uses a more precise sketch.
+func (m *MetricMetadata) ActiveSeriesIn(d time.Duration) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.seriesWindow == nil {
+		return 0
+	}
+	n := int64(m.seriesWindow.Count(d, clock()))
+	if m.seriesHLL != nil {
+		n = min(n, int64(m.seriesHLL.Count()))
+	}
+	return n
+}
+
+// CardinalityIn estimates the unique values seen within d. Keys that never
+// outgrew their samples are counted exactly.
+func (k *KeyMetadata) CardinalityIn(d time.Duration) int64 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := clock()
+	if k.window != nil {
+		return min(int64(k.window.Count(d, now)), int64(k.hll.Count()))
+	}
+	since := now.Add(-d).UnixNano()
+	var n int64
+	for i := range k.ValueSamples {
+		if i < len(k.seen) && k.seen[i] > since {
+			n++
+		}
+	}
+	return n
+}
+
+// CardinalityIn estimates the unique values seen within d.
+func (a *AttributeMetadata) CardinalityIn(d time.Duration) int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.window == nil {
+		return 0
+	}
+	n := int64(a.window.Count(d, clock()))
+	if a.hll != nil {
+		n = min(n, int64(a.hll.Count()))
+	}
+	return n
+}
+
+// Sessions store windows base64-encoded. A window saved with a different
+// layout, or loaded while windows are disabled, is dropped.
+
+func encodeWindow(w *hyperloglog.Window) (string, error) {
+	if w == nil {
+		return "", nil
+	}
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeWindow(s string) (*hyperloglog.Window, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	w := new(hyperloglog.Window)
+	if err := w.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if !newWindow().SameLayout(w) {
+		w.Release()
+		return nil, nil
+	}
+	return w, nil
+}
+
+// MarshalSeriesWindow encodes the metric's series window for a session.
+func (m *MetricMetadata) MarshalSeriesWindow() (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return encodeWindow(m.seriesWindow)
+}
+
+// UnmarshalSeriesWindow restores a series window saved by
+// MarshalSeriesWindow.
+func (m *MetricMetadata) UnmarshalSeriesWindow(s string) error {
+	w, err := decodeWindow(s)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seriesWindow.Release()
+	m.seriesWindow = w
+	return nil
+}
+
+// MarshalWindow encodes the attribute's window for a session.
+func (a *AttributeMetadata) MarshalWindow() (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return encodeWindow(a.window)
+}
+
+// UnmarshalWindow restores a window saved by MarshalWindow.
+func (a *AttributeMetadata) UnmarshalWindow(s string) error {
+	w, err := decodeWindow(s)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.window.Release()
+	a.window = w
+	return nil
+}
+
+// sampleSeen returns the last-seen times of samples, which must be value
+// samples of k, or nil when they are not tracked.
+func (k *KeyMetadata) sampleSeen(samples []string) []int64 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if len(k.seen) == 0 {
+		return nil
+	}
+	byValue := make(map[string]int64, len(k.ValueSamples))
+	for i, v := range k.ValueSamples {
+		if i < len(k.seen) {
+			byValue[v] = k.seen[i]
+		}
+	}
+	seen := make([]int64, len(samples))
+	for i, v := range samples {
+		seen[i] = byValue[v]
+	}
+	return seen
+}
+
+// MetricWindow is a metric's series and label cardinality within one window.
+type MetricWindow struct {
+	Window       string           `json:"window"`
+	ActiveSeries int64            `json:"active_series"`
+	Labels       map[string]int64 `json:"labels"`
+}
+
+// MetricWindowsResponse compares a metric's cumulative series count with
+// its recent windows.
+type MetricWindowsResponse struct {
+	Name string `json:"name"`
+	// ActiveSeries counts every series since the metric was first seen.
+	ActiveSeries int64           `json:"active_series"`
+	Windows      []*MetricWindow `json:"windows"`
+}
+
+// Windows counts the metric's series and label values within each window.
+func (m *MetricMetadata) Windows(windows []time.Duration) *MetricWindowsResponse {
+	resp := &MetricWindowsResponse{
+		Name:         m.Name,
+		ActiveSeries: m.GetActiveSeries(),
+		Windows:      make([]*MetricWindow, 0, len(windows)),
+	}
+
+	m.mu.RLock()
+	keys := make(map[string]*KeyMetadata, len(m.LabelKeys))
+	for k, km := range m.LabelKeys {
+		keys[k] = km
+	}
+	m.mu.RUnlock()
+
+	for _, d := range windows {
+		mw := &MetricWindow{
+			Window:       FormatWindow(d),
+			ActiveSeries: m.ActiveSeriesIn(d),
+			Labels:       make(map[string]int64, len(keys)),
+		}
+		for k, km := range keys {
+			mw.Labels[k] = km.CardinalityIn(d)
+		}
+		resp.Windows = append(resp.Windows, mw)
+	}
+	return resp
+}
+
+// WindowedMetric compares a metric's series within a window with its
+// cumulative count.
+type WindowedMetric struct {
+	Name               string `json:"name"`
+	ActiveSeries       int64  `json:"active_series"`
+	ActiveSeriesWindow int64  `json:"active_series_window"`
+}
+
+// WindowedAttribute compares an attribute's values within a window with its
+// cumulative cardinality.
+type WindowedAttribute struct {
+	Key                  string `json:"key"`
+	EstimatedCardinality int64  `json:"estimated_cardinality"`
+	CardinalityWindow    int64  `json:"cardinality_window"`
+}
+
+// WindowedCardinality ranks the metrics and attributes active within a
+// window by their windowed cardinality.
+type WindowedCardinality struct {
+	Window     string               `json:"window"`
+	Metrics    []*WindowedMetric    `json:"metrics"`
+	Attributes []*WindowedAttribute `json:"attributes"`
+}
+
+// RankWindowed ranks metrics and attributes by cardinality within d, keeping
+// the top limit of each. Those without data in the window are left out.
+func RankWindowed(metrics []*MetricMetadata, attrs []*AttributeMetadata, d time.Duration, limit int) *WindowedCardinality {
+	out := &WindowedCardinality{
+		Window:     FormatWindow(d),
+		Metrics:    []*WindowedMetric{},
+		Attributes: []*WindowedAttribute{},
+	}
+	for _, m := range metrics {
+		if n := m.ActiveSeriesIn(d); n > 0 {
+			out.Metrics = append(out.Metrics, &WindowedMetric{Name: m.Name, ActiveSeries: m.GetActiveSeries(), ActiveSeriesWindow: n})
+		}
+	}
+	for _, a := range attrs {
+		if n := a.CardinalityIn(d); n > 0 {
+			out.Attributes = append(out.Attributes, &WindowedAttribute{Key: a.Key, EstimatedCardinality: a.Cardinality(), CardinalityWindow: n})
+		}
+	}
+	sort.Slice(out.Metrics, func(i, j int) bool {
+		if out.Metrics[i].ActiveSeriesWindow != out.Metrics[j].ActiveSeriesWindow {
+			return out.Metrics[i].ActiveSeriesWindow > out.Metrics[j].ActiveSeriesWindow
+		}
+		return out.Metrics[i].Name < out.Metrics[j].Name
+	})
+	sort.Slice(out.Attributes, func(i, j int) bool {
+		if out.Attributes[i].CardinalityWindow != out.Attributes[j].CardinalityWindow {
+			return out.Attributes[i].CardinalityWindow > out.Attributes[j].CardinalityWindow
+		}
+		return out.Attributes[i].Key < out.Attributes[j].Key
+	})
+	if limit > 0 {
+		out.Metrics = out.Metrics[:min(limit, len(out.Metrics))]
+		out.Attributes = out.Attributes[:min(limit, len(out.Attributes))]
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fidde/otlp_cardinality_checker/pkg/hyperloglog"
+)
+
+// enableWindows turns on the default sliding windows until the test ends.
+func enableWindows(t *testing.T) {
+	t.Helper()
+	SetWindowConfig(hyperloglog.DefaultWindowConfig())
+	t.Cleanup(func() { SetWindowConfig(hyperloglog.WindowConfig{}) })
+}
+
+// fakeClock makes clock return t until the test ends.
+func fakeClock(t *testing.T, at time.Time) *time.Time {
+	t.Helper()
+	now := at
+	prev := clock
+	clock = func() time.Time { return now }
+	t.Cleanup(func() { clock = prev })
+	return &now
+}
+
+func TestActiveSeriesIn(t *testing.T) {
+	enableWindows(t)
+	now := fakeClock(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	m := NewMetricMetadata("http_requests", &SumMetric{})
+
+	// 200 pods two hours ago, 20 of them still reporting now.
+	*now = now.Add(-2 * time.Hour)
+	for i := 0; i < 200; i++ {
+		m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+	}
+	*now = now.Add(2 * time.Hour)
+	for i := 0; i < 20; i++ {
+		m.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("pod-%d", i)})
+	}
+
+	if got := m.ActiveSeriesIn(15 * time.Minute); got < 18 || got > 22 {
+		t.Errorf("ActiveSeriesIn(15m) = %d, want ~20", got)
+	}
+	if got := m.ActiveSeriesIn(24 * time.Hour); got < 180 || got > 220 {
+		t.Errorf("ActiveSeriesIn(24h) = %d, want ~200", got)
+	}
+	if got, cumulative := m.ActiveSeriesIn(24*time.Hour), m.GetActiveSeries(); got > cumulative {
+		t.Errorf("windowed %d exceeds cumulative %d", got, cumulative)
+	}
+
+	// Merging keeps the windows of both sides.
+	other := NewMetricMetadata("http_requests", &SumMetric{})
+	for i := 0; i < 10; i++ {
+		other.AddSeries(nil, map[string]string{"pod": fmt.Sprintf("new-%d", i)})
+	}
+	m.MergeMetricMetadata(other)
+	if got := m.ActiveSeriesIn(15 * time.Minute); got < 27 || got > 33 {
+		t.Errorf("ActiveSeriesIn(15m) after merge = %d, want ~30", got)
+	}
+
+	*now = now.Add(25 * time.Hour)
+	if got := m.ActiveSeriesIn(24 * time.Hour); got != 0 {
+		t.Errorf("ActiveSeriesIn(24h) a day later = %d, want 0", got)
+	}
+	if m.GetActiveSeries() < 200 {
+		t.Errorf("cumulative series = %d, want it kept", m.GetActiveSeries())
+	}
+}
+
+func TestKeyCardinalityIn(t *testing.T) {
+	enableWindows(t)
+	now := fakeClock(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	k := NewKeyMetadata()
+
+	// Sample-backed keys are counted exactly.
+	k.AddValue("GET")
+	k.AddValue("POST")
+	*now = now.Add(time.Hour)
+	k.AddValue("GET")
+	if got := k.CardinalityIn(15 * time.Minute); got != 1 {
+		t.Errorf("CardinalityIn(15m) = %d, want 1", got)
+	}
+	if got := k.CardinalityIn(2 * time.Hour); got != 2 {
+		t.Errorf("CardinalityIn(2h) = %d, want 2", got)
+	}
+
+	// Outgrowing the samples backfills the window at the sample times.
+	for i := 0; i < 50; i++ {
+		k.AddValue(fmt.Sprintf("v%d", i))
+	}
+	if k.window == nil {
+		t.Fatal("window not allocated with the HLL")
+	}
+	if got := k.CardinalityIn(15 * time.Minute); got < 48 || got > 54 {
+		t.Errorf("CardinalityIn(15m) = %d, want ~51", got)
+	}
+	if got := k.CardinalityIn(2 * time.Hour); got < 49 || got > 55 {
+		t.Errorf("CardinalityIn(2h) = %d, want ~52", got)
+	}
+
+	// Merging a sample-backed key carries its last-seen times.
+	other := NewKeyMetadata()
+	*now = now.Add(3 * time.Hour)
+	other.AddValue("late")
+	MergeKeyMetadata(k, other)
+	if got := k.CardinalityIn(15 * time.Minute); got != 1 {
+		t.Errorf("CardinalityIn(15m) after merge = %d, want 1", got)
+	}
+}
+
+func TestAttributeCardinalityIn(t *testing.T) {
+	enableWindows(t)
+	now := fakeClock(t, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	a := NewAttributeMetadata("user_id")
+	for i := 0; i < 100; i++ {
+		a.AddValue(fmt.Sprintf("u%d", i), "metric", "attribute")
+	}
+	*now = now.Add(30 * time.Minute)
+	a.AddValue("u1", "metric", "attribute")
+
+	if got := a.CardinalityIn(15 * time.Minute); got != 1 {
+		t.Errorf("CardinalityIn(15m) = %d, want 1", got)
+	}
+	if got := a.CardinalityIn(time.Hour); got < 95 || got > 105 {
+		t.Errorf("CardinalityIn(1h) = %d, want ~100", got)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	enableWindows(t)
+	if d, err := ParseWindow("15m"); err != nil || d != 15*time.Minute {
+		t.Errorf("ParseWindow(15m) = %s, %v", d, err)
+	}
+	for _, s := range []string{"", "soon", "-1h", "48h"} {
+		if _, err := ParseWindow(s); err == nil {
+			t.Errorf("ParseWindow(%q) succeeded, want error", s)
+		}
+	}
+	if got := FormatWindow(24 * time.Hour); got != "24h" {
+		t.Errorf("FormatWindow(24h) = %q", got)
+	}
+	if got := len(StandardWindows()); got != 3 {
+		t.Errorf("StandardWindows = %d windows, want 3 with the default horizon", got)
+	}
+}
+
+func TestWindowsDisabledByDefault(t *testing.T) {
+	m := NewMetricMetadata("http_requests", &SumMetric{})
+	m.AddSeries(nil, map[string]string{"pod": "pod-0"})
+	k := NewKeyMetadata()
+	k.AddValue("GET")
+	a := NewAttributeMetadata("user_id")
+	a.AddValue("u1", "metric", "attribute")
+
+	if m.seriesWindow != nil || k.seen != nil || a.window != nil {
+		t.Error("window state allocated while windows are disabled")
+	}
+	if got := m.ActiveSeriesIn(time.Hour); got != 0 {
+		t.Errorf("ActiveSeriesIn(1h) = %d, want 0", got)
+	}
+	if _, err := ParseWindow("15m"); err == nil {
+		t.Error("ParseWindow succeeded with windows disabled")
+	}
+	if got := StandardWindows(); len(got) != 0 {
+		t.Errorf("StandardWindows = %v, want none", got)
+	}
+}
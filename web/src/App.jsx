@@ -10,6 +10,7 @@ import ServiceExplorer from './components/ServiceExplorer'
 import Details from './components/Details'
 import MemoryView from './components/MemoryView'
 import NoisyNeighbors from './components/NoisyNeighbors'
+import LokiStreams from './components/LokiStreams'
 import TemplateDetails from './components/TemplateDetails'
 import LogServiceDetails from './components/LogServiceDetails'
 import LogPatternDetails from './components/LogPatternDetails'
@@ -238,6 +239,10 @@ function App() {
               <NoisyNeighbors />
             )}
 
+            {activeTab === 'loki-streams' && (
+              <LokiStreams />
+            )}
+
             {activeTab === 'memory' && (
               <MemoryView />
             )}
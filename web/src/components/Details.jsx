@@ -21,6 +21,7 @@ function Details({ type, name, onBack }) {
   const [showSeriesExplanation, setShowSeriesExplanation] = useState(false)
   const [watchedKeys, setWatchedKeys] = useState({})
   const [watchLoading, setWatchLoading] = useState({})
+  const [contribution, setContribution] = useState(null)
 
   useEffect(() => {
     const endpoint = type === 'metrics' || type === 'metric' ? `/api/v1/metrics/${encodeURIComponent(name)}` :
@@ -32,6 +33,15 @@ function Details({ type, name, onBack }) {
       .catch(err => { setError(err.message); setLoading(false) })
   }, [type, name])
 
+  useEffect(() => {
+    setContribution(null)
+    if (type !== 'metrics' && type !== 'metric') return
+    fetch(`/api/v1/metrics/${encodeURIComponent(name)}/contribution`)
+      .then(r => r.ok ? r.json() : null)
+      .then(setContribution)
+      .catch(() => setContribution(null))
+  }, [type, name])
+
   const handleWatch = async (key) => {
     setWatchLoading(prev => ({ ...prev, [key]: true }))
     try {
@@ -159,6 +169,22 @@ function Details({ type, name, onBack }) {
                 </Badge>
               ))}
             </div>
+            {data.data.bucket_layouts?.length > 1 && (
+              <div className="mt-4 flex flex-col gap-2">
+                <p className="text-sm text-destructive">
+                  {data.data.bucket_layouts.length} different bucket layouts are in use; each adds its own le label values
+                </p>
+                {data.data.bucket_layouts.map((layout, idx) => (
+                  <div key={idx} className="rounded-md border p-2 text-xs">
+                    <code className="break-all">[{layout.bounds.join(', ')}]</code>
+                    <p className="mt-1 text-muted-foreground">
+                      {layout.bounds.length + 1} buckets · {layout.data_points.toLocaleString()} data points
+                      {Object.keys(layout.services || {}).length > 0 && ` · ${Object.keys(layout.services).join(', ')}`}
+                    </p>
+                  </div>
+                ))}
+              </div>
+            )}
           </CardContent>
         </Card>
       )}
@@ -296,6 +322,90 @@ function Details({ type, name, onBack }) {
         </Card>
       )}
 
+      {/* Series contribution per label (metrics only) */}
+      {contribution && contribution.labels?.length > 0 && (
+        <Card>
+          <CardHeader>
+            <CardTitle className="text-base">Series Contribution</CardTitle>
+          </CardHeader>
+          <CardContent className="p-0">
+            <p className="px-6 pb-4 text-sm text-muted-foreground">
+              Estimated series left with each label dropped, out of {contribution.active_series.toLocaleString()}
+              {contribution.truncated && ' · only the first labels seen are tracked'}
+            </p>
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>Label</TableHead>
+                  <TableHead>Scope</TableHead>
+                  <TableHead>Series Without</TableHead>
+                  <TableHead>Reduction</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {contribution.labels.map(lc => (
+                  <TableRow key={`${lc.scope}:${lc.label}`}>
+                    <TableCell><code className="text-xs">{lc.label}</code></TableCell>
+                    <TableCell className="text-xs text-muted-foreground">{lc.scope}</TableCell>
+                    <TableCell>{lc.series_without.toLocaleString()}</TableCell>
+                    <TableCell>
+                      <Badge variant={lc.reduction_percent >= 50 ? 'destructive' : 'secondary'}>
+                        {lc.reduction_percent.toFixed(0)}%
+                      </Badge>
+                    </TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          </CardContent>
+        </Card>
+      )}
+
+      {/* Exemplars (metrics only) */}
+      {type === 'metrics' && data.exemplars && (
+        <Card>
+          <CardHeader>
+            <CardTitle className="text-base">Exemplars</CardTitle>
+          </CardHeader>
+          <CardContent className="p-0">
+            <p className="px-6 pb-4 text-sm text-muted-foreground">
+              {(data.exemplars.fraction * 100).toFixed(1)}% of data points carry exemplars
+              {' · '}{data.exemplars.exemplar_count.toLocaleString()} exemplars
+              {' · '}{data.exemplars.with_trace_id.toLocaleString()} with trace ID
+              {' · '}{data.exemplars.with_span_id.toLocaleString()} with span ID
+            </p>
+            {data.exemplars.filtered_attribute_keys && Object.keys(data.exemplars.filtered_attribute_keys).length > 0 && (
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>Filtered Attribute</TableHead>
+                    <TableHead>Cardinality</TableHead>
+                    <TableHead>Usage</TableHead>
+                    <TableHead>Sample Values</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {Object.entries(data.exemplars.filtered_attribute_keys).map(([key, metadata]) => (
+                    <TableRow key={key}>
+                      <TableCell><code className="text-xs">{key}</code></TableCell>
+                      <TableCell>
+                        <Badge variant={getCardinalityVariant(metadata.estimated_cardinality)}>
+                          {metadata.estimated_cardinality}
+                        </Badge>
+                      </TableCell>
+                      <TableCell>{metadata.percentage?.toFixed(1)}%</TableCell>
+                      <TableCell className="text-xs text-muted-foreground">
+                        {metadata.value_samples?.slice(0, 5).join(', ')}
+                      </TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            )}
+          </CardContent>
+        </Card>
+      )}
+
       {/* Services */}
       {data.services && Object.keys(data.services).length > 0 && (
         <Card>
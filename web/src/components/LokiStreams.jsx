@@ -0,0 +1,169 @@
+import { useState, useEffect } from 'react'
+import { Card, CardContent, CardHeader, CardTitle, CardDescription } from '@/components/ui/card'
+import { Badge } from '@/components/ui/badge'
+import { Button } from '@/components/ui/button'
+import { Skeleton } from '@/components/ui/skeleton'
+import {
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+} from '@/components/ui/table'
+import { fetchJSON } from '@/lib/fetchJSON'
+
+function LokiStreams() {
+  const [data, setData] = useState(null)
+  const [loading, setLoading] = useState(true)
+  const [error, setError] = useState(null)
+
+  useEffect(() => {
+    fetchStreams()
+  }, [])
+
+  const fetchStreams = async () => {
+    setLoading(true)
+    setError(null)
+    try {
+      setData(await fetchJSON('/api/v1/loki/streams'))
+    } catch (err) {
+      setError(err.message)
+    }
+    setLoading(false)
+  }
+
+  const header = (
+    <div className="flex items-start justify-between">
+      <div>
+        <h1 className="text-2xl font-bold tracking-tight">Loki Streams</h1>
+        <p className="text-muted-foreground">Stream label cardinality for logs pushed through the Loki API</p>
+      </div>
+      <Button variant="outline" size="sm" onClick={fetchStreams}>Refresh</Button>
+    </div>
+  )
+
+  if (loading) {
+    return (
+      <div className="flex flex-col gap-6">
+        {header}
+        <Skeleton className="h-[400px] w-full" />
+      </div>
+    )
+  }
+
+  if (error) {
+    return (
+      <div className="flex flex-col gap-6">
+        {header}
+        <Card className="border-destructive">
+          <CardContent className="pt-6">
+            <p className="text-destructive">Error: {error}</p>
+          </CardContent>
+        </Card>
+      </div>
+    )
+  }
+
+  const labels = data?.labels || []
+  const services = data?.services || []
+
+  return (
+    <div className="flex flex-col gap-6">
+      {header}
+
+      <div className="grid gap-4 md:grid-cols-2">
+        <Card>
+          <CardHeader>
+            <CardDescription>Active Streams</CardDescription>
+            <CardTitle className="text-3xl">{(data?.total_streams || 0).toLocaleString()}</CardTitle>
+          </CardHeader>
+        </Card>
+        <Card>
+          <CardHeader>
+            <CardDescription>Entries Received</CardDescription>
+            <CardTitle className="text-3xl">{(data?.total_entries || 0).toLocaleString()}</CardTitle>
+          </CardHeader>
+        </Card>
+      </div>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>Stream Labels</CardTitle>
+          <CardDescription>Every unique label set is a separate stream in Loki; high-cardinality labels multiply the stream count</CardDescription>
+        </CardHeader>
+        <CardContent className="p-0">
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>Label</TableHead>
+                <TableHead>Cardinality</TableHead>
+                <TableHead>Streams</TableHead>
+                <TableHead>Sample Values</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {labels.length === 0 ? (
+                <TableRow>
+                  <TableCell colSpan={4} className="py-8 text-center text-muted-foreground">No Loki streams received</TableCell>
+                </TableRow>
+              ) : labels.map(label => (
+                <TableRow key={label.key}>
+                  <TableCell className="font-semibold">{label.key}</TableCell>
+                  <TableCell>
+                    <Badge variant={label.cardinality > 100 ? 'destructive' : 'outline'}>
+                      {label.cardinality.toLocaleString()}
+                    </Badge>
+                  </TableCell>
+                  <TableCell>{label.streams.toLocaleString()}</TableCell>
+                  <TableCell className="font-mono text-xs text-muted-foreground">
+                    {(label.sample_values || []).slice(0, 5).join(', ')}
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </CardContent>
+      </Card>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>Streams by Service</CardTitle>
+          <CardDescription>Services ordered by the number of streams they create</CardDescription>
+        </CardHeader>
+        <CardContent className="p-0">
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>Service</TableHead>
+                <TableHead>Streams</TableHead>
+                <TableHead>Entries</TableHead>
+                <TableHead>Labels</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {services.length === 0 ? (
+                <TableRow>
+                  <TableCell colSpan={4} className="py-8 text-center text-muted-foreground">No services found</TableCell>
+                </TableRow>
+              ) : services.map(service => (
+                <TableRow key={service.service}>
+                  <TableCell className="font-medium">{service.service}</TableCell>
+                  <TableCell>{service.streams.toLocaleString()}</TableCell>
+                  <TableCell>{service.entries.toLocaleString()}</TableCell>
+                  <TableCell>
+                    <div className="flex gap-1 flex-wrap">
+                      {service.label_keys.map(k => <Badge key={k} variant="outline" className="text-xs">{k}</Badge>)}
+                    </div>
+                  </TableCell>
+                </TableRow>
+              ))}
+            </TableBody>
+          </Table>
+        </CardContent>
+      </Card>
+    </div>
+  )
+}
+
+export default LokiStreams
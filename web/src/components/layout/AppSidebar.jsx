@@ -9,6 +9,7 @@ import {
   CircleAlertIcon,
   DatabaseIcon,
   LayersIcon,
+  ListTreeIcon,
   ClipboardListIcon,
   LayoutDashboardIcon,
   SearchCodeIcon,
@@ -52,6 +53,7 @@ const NAV_GROUPS = [
     items: [
       { id: 'noisy-neighbors', label: 'Noisy Neighbors', icon: CircleAlertIcon },
       { id: 'metadata-complexity', label: 'Metadata Complexity', icon: LayersIcon },
+      { id: 'loki-streams', label: 'Loki Streams', icon: ListTreeIcon },
     ],
   },
   {